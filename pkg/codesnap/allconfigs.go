@@ -0,0 +1,231 @@
+package codesnap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// configSnapshot is the snapshot of one config found by --all-configs
+type configSnapshot struct {
+	Config   string          `json:"config"`
+	Snapshot json.RawMessage `json:"snapshot"`
+	content  string
+}
+
+// findConfigs returns the codesnap.yml files below root, relative to it.
+// Version control, editor and dependency directories and codesnap's own
+// state are not searched.
+func findConfigs(root string) ([]string, error) {
+	var configs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (toolDirNames[name] || dependencyDirNames[name] || name == ".codesnap") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "codesnap.yml" {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			configs = append(configs, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to search for configs: %v"), err)
+	}
+	if len(configs) == 0 {
+		return nil, nothingCollected{errors.New(T("no codesnap.yml found below the current directory"))}
+	}
+	return configs, nil
+}
+
+// allConfigsOwnFlags are the flags that decide where a snapshot goes and
+// what happens to it afterwards. With --all-configs they apply to the
+// combined snapshot, not to the snapshot of each config.
+var allConfigsOwnFlags = map[string]bool{
+	"all-configs": true, "c": true, "O": true, "o": true, "p": true, "stdout": true, "to": true,
+	"sink": true, "chunk-tokens": true, "split-by": true, "summary-json": true, "safe": true,
+	"encrypt": true, "anonymize": true, "anonymize-seed": true, "anonymize-map": true,
+	"q": true, "quiet": true, "tokens": true,
+}
+
+// childArgs returns the flags set for this run as arguments for the
+// snapshot of one config, without the flags the combined snapshot uses
+func childArgs(set *flag.FlagSet) []string {
+	var args []string
+	set.Visit(func(f *flag.Flag) {
+		if allConfigsOwnFlags[f.Name] {
+			return
+		}
+		if list, ok := f.Value.(*stringList); ok {
+			for _, value := range *list {
+				args = append(args, "-"+f.Name+"="+value)
+			}
+			return
+		}
+		args = append(args, "-"+f.Name+"="+f.Value.String())
+	})
+	return args
+}
+
+// snapshotConfigs runs codesnap for each config in a child process with
+// args, so every config is collected with its own settings exactly as a run
+// in its directory would. A config that fails is reported and left out.
+func (cs *CodeSnap) snapshotConfigs(configs, args []string) ([]configSnapshot, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to find the codesnap executable: %v"), err)
+	}
+	// --safe is the combined snapshot's, but every config must still be
+	// redacted and leave out its credential files
+	if cs.safe {
+		args = append(args, "-safe")
+	}
+	var snaps []configSnapshot
+	for _, config := range configs {
+		var out bytes.Buffer
+		cmd := exec.Command(exe, append([]string{"-c", config, "-q", "--stdout"}, args...)...)
+		cmd.Dir = cs.baseDir
+		cmd.Stdout, cmd.Stderr = &out, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Printf(T("Warning: skipping %s: %v\n"), filepath.ToSlash(config), err)
+			continue
+		}
+		fmt.Printf(T("Collected %s\n"), filepath.ToSlash(config))
+		snaps = append(snaps, configSnapshot{Config: filepath.ToSlash(config), content: out.String()})
+	}
+	if len(snaps) == 0 {
+		return nil, nothingCollected{errors.New(T("no config produced a snapshot"))}
+	}
+	return snaps, nil
+}
+
+// combineSnapshots joins the snapshots of the configs into one, each
+// labeled with its config
+func (cs *CodeSnap) combineSnapshots(snaps []configSnapshot) (string, error) {
+	var b strings.Builder
+	switch cs.format {
+	case "json":
+		for i := range snaps {
+			snaps[i].Snapshot = json.RawMessage(snaps[i].content)
+		}
+		data, err := json.MarshalIndent(struct {
+			Configs []configSnapshot `json:"configs"`
+		}{snaps}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf(T("failed to combine the snapshots: %v"), err)
+		}
+		return string(data) + "\n", nil
+	case "markdown":
+		for _, s := range snaps {
+			b.WriteString(fmt.Sprintf("# Config: %s\n\n", s.Config))
+			b.WriteString(strings.TrimRight(s.content, "\n") + "\n\n")
+		}
+	default:
+		for _, s := range snaps {
+			b.WriteString(fmt.Sprintf("%s\nConfig: %s\n%s\n", strings.Repeat("#", 50), s.Config, strings.Repeat("#", 50)))
+			b.WriteString(strings.TrimRight(s.content, "\n") + "\n\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// splitByConfig writes the snapshot of each config and an index of them
+// into dir (default: a new timestamped directory). It returns the directory
+// and the total bytes and estimated tokens written.
+func (cs *CodeSnap) splitByConfig(dir string, snaps []configSnapshot, anon *anonymizer) (string, int, int, error) {
+	if dir == "" {
+		dir = fmt.Sprintf("codesnap_%s", time.Now().Format("20060102_150405"))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, 0, fmt.Errorf(T("failed to create output directory: %v"), err)
+	}
+	ext := map[string]string{"markdown": ".md", "json": ".json"}[cs.format]
+	if ext == "" {
+		ext = ".txt"
+	}
+
+	var parts []splitPart
+	size, tokens := 0, 0
+	used := map[string]int{"index": 1}
+	for _, s := range snaps {
+		content := s.content
+		if anon != nil {
+			content = anon.apply(content)
+		}
+		name := strings.Trim(unsafeNameChars.ReplaceAllString(strings.TrimSuffix(filepath.Dir(s.Config), "."), "-"), "-")
+		if name == "" {
+			name = filepath.Base(cs.baseDir)
+		}
+		if used[name]++; used[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, used[name])
+		}
+		file := name + ext
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			return "", 0, 0, fmt.Errorf(T("failed to save content to file: %v"), err)
+		}
+		part := splitPart{Name: name, File: file, Folder: s.Config, Tokens: estimateTokens(content)}
+		parts = append(parts, part)
+		size += len(content)
+		tokens += part.Tokens
+	}
+
+	index := cs.configsIndex(parts)
+	if anon != nil {
+		index = anon.apply(index)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index"+ext), []byte(index), 0644); err != nil {
+		return "", 0, 0, fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return dir, size + len(index), tokens + estimateTokens(index), nil
+}
+
+// configsIndex lists the snapshots of --split-by config in the selected
+// format
+func (cs *CodeSnap) configsIndex(parts []splitPart) string {
+	var b strings.Builder
+	switch cs.format {
+	case "json":
+		type entry struct {
+			Name   string `json:"name"`
+			File   string `json:"file"`
+			Config string `json:"config"`
+			Tokens int    `json:"estimated_tokens"`
+		}
+		var entries []entry
+		for _, p := range parts {
+			entries = append(entries, entry{p.Name, p.File, p.Folder, p.Tokens})
+		}
+		data, _ := json.MarshalIndent(struct {
+			Configs []entry `json:"configs"`
+		}{entries}, "", "  ")
+		return string(data) + "\n"
+	case "markdown":
+		b.WriteString("# Snapshot index\n\n")
+		for _, p := range parts {
+			b.WriteString(fmt.Sprintf("- [%s](%s): %s, ~%s tokens\n", p.Name, p.File, p.Folder, formatCount(p.Tokens)))
+		}
+	default:
+		b.WriteString("Snapshot index\n\n")
+		for _, p := range parts {
+			b.WriteString(fmt.Sprintf("%s: %s, ~%s tokens\n", p.File, p.Folder, formatCount(p.Tokens)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPipelineAllConfigs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":   "folders:\n  - path: a\npipelines:\n  all:\n    all_configs: true\n    stdout: true\n",
+		"a/codesnap.yml": "folders:\n  - path: .\n",
+		"a/a.go":         "package a\n",
+		"b/codesnap.yml": "folders:\n  - path: .\n",
+		"b/b.go":         "package b\n",
+	})
+
+	r := runCodesnap(t, dir, "run", "all", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, want := range []string{"Config: a/codesnap.yml", "Config: b/codesnap.yml", "File: b.go"} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("combined snapshot lacks %q, got:\n%s", want, r.stdout)
+		}
+	}
+}
+
+func TestSplitByConfigRunsPostHooks(t *testing.T) {
+	hook := "folders:\n  - path: .\nhooks:\n  post: touch posted\n"
+	dir := writeFiles(t, map[string]string{
+		"a/codesnap.yml": hook,
+		"a/a.go":         "package a\n",
+		"b/codesnap.yml": hook,
+		"b/b.go":         "package b\n",
+	})
+
+	r := runCodesnap(t, dir, "--all-configs", "--split-by", "config", "-O", "out", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, config := range []string{"a", "b"} {
+		if _, err := os.Stat(filepath.Join(dir, config, "posted")); err != nil {
+			t.Errorf("post hook of %s did not run: %v", config, err)
+		}
+	}
+}
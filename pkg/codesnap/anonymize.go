@@ -0,0 +1,86 @@
+package codesnap
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// AnonymizeConfig lists the patterns whose matches are pseudonymized when
+// running with --anonymize
+type AnonymizeConfig struct {
+	// Patterns are regular expressions, e.g. internal hostnames or names
+	Patterns []string `yaml:"patterns"`
+	// Seed makes pseudonyms stable across runs; a random seed is used if empty
+	Seed string `yaml:"seed"`
+}
+
+// anonymizer replaces matches with pseudonyms derived from the seed, so the
+// same identifier always maps to the same pseudonym within a snapshot
+type anonymizer struct {
+	seed     []byte
+	patterns []*regexp.Regexp
+	mapping  map[string]string // pseudonym -> original
+}
+
+func newAnonymizer(cfg AnonymizeConfig, seed string) (*anonymizer, error) {
+	if len(cfg.Patterns) == 0 {
+		return nil, errors.New(T("--anonymize requires anonymize.patterns in the config"))
+	}
+	if seed == "" {
+		seed = cfg.Seed
+	}
+	if seed == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf(T("failed to generate anonymization seed: %v"), err)
+		}
+		seed = hex.EncodeToString(buf)
+	}
+
+	a := &anonymizer{seed: []byte(seed), mapping: make(map[string]string)}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(T("invalid anonymize pattern %q: %v"), pattern, err)
+		}
+		a.patterns = append(a.patterns, re)
+	}
+	return a, nil
+}
+
+// pseudonym derives a stable replacement for s from the seed
+func (a *anonymizer) pseudonym(s string) string {
+	mac := hmac.New(sha256.New, a.seed)
+	mac.Write([]byte(s))
+	name := "ANON_" + hex.EncodeToString(mac.Sum(nil))[:8]
+	a.mapping[name] = s
+	return name
+}
+
+// apply pseudonymizes every match of the configured patterns in content
+func (a *anonymizer) apply(content string) string {
+	for _, re := range a.patterns {
+		content = re.ReplaceAllStringFunc(content, a.pseudonym)
+	}
+	return content
+}
+
+// writeMapping saves the pseudonym to original mapping as JSON so responses
+// referring to pseudonyms can be translated back
+func (a *anonymizer) writeMapping(path string) error {
+	data, err := json.MarshalIndent(a.mapping, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf(T("failed to write anonymization mapping: %v"), err)
+	}
+	return nil
+}
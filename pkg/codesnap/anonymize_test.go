@@ -0,0 +1,93 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizer(t *testing.T) {
+	cfg := AnonymizeConfig{Patterns: []string{`\bacme-[a-z]+\b`, `alice|bob`}, Seed: "config seed"}
+	for _, tc := range []struct {
+		name, in   string
+		pseudonyms int
+		// same is whether the two pseudonyms must be equal
+		same bool
+	}{
+		{"no match", "nothing to hide", 0, false},
+		{"one match", "host acme-db", 1, false},
+		{"repeated", "acme-db and acme-db", 2, true},
+		{"different", "alice and bob", 2, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := newAnonymizer(cfg, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := a.apply(tc.in)
+			names := regexp.MustCompile(`ANON_[0-9a-f]{8}`).FindAllString(got, -1)
+			if len(names) != tc.pseudonyms {
+				t.Fatalf("apply(%q) = %q, want %d pseudonyms", tc.in, got, tc.pseudonyms)
+			}
+			if len(names) == 2 && (names[0] == names[1]) != tc.same {
+				t.Errorf("apply(%q) = %q, same pseudonyms: %v, want %v", tc.in, got, names[0] == names[1], tc.same)
+			}
+			for _, name := range names {
+				if original := a.mapping[name]; !strings.Contains(tc.in, original) || original == "" {
+					t.Errorf("%s maps to %q, not to a match in %q", name, original, tc.in)
+				}
+			}
+		})
+	}
+
+	first, _ := newAnonymizer(cfg, "")
+	again, _ := newAnonymizer(cfg, "")
+	other, _ := newAnonymizer(cfg, "flag seed")
+	if first.apply("acme-db") != again.apply("acme-db") {
+		t.Error("the same seed gives different pseudonyms")
+	}
+	if first.apply("acme-db") == other.apply("acme-db") {
+		t.Error("the --anonymize-seed seed does not override the config seed")
+	}
+
+	if _, err := newAnonymizer(AnonymizeConfig{}, ""); err == nil {
+		t.Error("no error without patterns")
+	}
+	if _, err := newAnonymizer(AnonymizeConfig{Patterns: []string{"("}}, ""); err == nil {
+		t.Error("no error for an invalid pattern")
+	}
+}
+
+func TestAnonymizeFlag(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\nanonymize:\n  patterns:\n    - acme-[a-z]+\n",
+		"a.go":         "package a\n\nconst host = \"acme-db\"\n",
+	})
+	mapping := filepath.Join(t.TempDir(), "mapping.json")
+	r := runCodesnap(t, dir, "--anonymize", "--anonymize-seed", "s", "--anonymize-map", mapping, "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	if strings.Contains(r.stdout, "acme-db") {
+		t.Errorf("the snapshot has the original name:\n%s", r.stdout)
+	}
+	data, err := os.ReadFile(mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names map[string]string
+	if err := json.Unmarshal(data, &names); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 {
+		t.Fatalf("mapping = %v, want one pseudonym", names)
+	}
+	for name, original := range names {
+		if original != "acme-db" || !strings.Contains(r.stdout, name) {
+			t.Errorf("mapping %s -> %s does not match the snapshot:\n%s", name, original, r.stdout)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package codesnap
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Collector collects snapshots of the files selected by a config, for
+// programs that embed codesnap instead of running the command line tool:
+//
+//	c, err := codesnap.NewCollector("codesnap.yml", codesnap.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	snap, err := c.Collect(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	return codesnap.Markdown.Format(os.Stdout, snap)
+//
+// Collect and Tree may be called concurrently, as every call works on
+// state of its own; the Config must not be changed meanwhile.
+type Collector struct {
+	cs *CodeSnap
+}
+
+// Options adjust how a Collector loads its config
+type Options struct {
+	// Profile is the config profile to apply, if any
+	Profile string
+	// Transforms run on every file after those configured with
+	// transform_cmd, see CodeSnap.AddTransform
+	Transforms []Transform
+	// ChangedSince, DiffHunks and Staged select the changed files like
+	// --changed, --diff-hunks and --staged
+	ChangedSince string
+	DiffHunks    string
+	Staged       bool
+	// DiffContext is the lines of context around the hunks of DiffHunks
+	// (default: 3)
+	DiffContext int
+}
+
+// Config returns the loaded config. Changes to it apply to the following
+// calls of Collect and Tree.
+func (c *Collector) Config() *Config {
+	return c.cs.config
+}
+
+// NewCollector loads the config at configPath (default: codesnap.yml).
+// Unlike the command line tool, it does not create a missing config and
+// never asks on the terminal: dependency_dirs: prompt skips large
+// dependency directories.
+func NewCollector(configPath string, opts Options) (*Collector, error) {
+	cs, err := newCodeSnap(configPath, opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	cs.quiet = true
+	if err := cs.loadConfig(); err != nil {
+		return nil, err
+	}
+	if cs.config.DependencyDirs == "prompt" {
+		cs.config.DependencyDirs = "skip"
+	}
+	for _, t := range opts.Transforms {
+		cs.AddTransform(t)
+	}
+	cs.changedSince, cs.diffHunks, cs.staged = opts.ChangedSince, opts.DiffHunks, opts.Staged
+	cs.diffContext = opts.DiffContext
+	if cs.diffContext <= 0 {
+		cs.diffContext = 3
+	}
+	return &Collector{cs: cs}, nil
+}
+
+// Snapshot is the result of Collect
+type Snapshot struct {
+	Stats Stats
+
+	files   []FileResult
+	cs      *CodeSnap
+	results []fileResult
+}
+
+// FileResult is one file of a Snapshot, as it would be rendered
+type FileResult struct {
+	Path        string // absolute path
+	RelPath     string // path shown in the snapshot, relative to the config
+	Content     string
+	Size        int64
+	Language    string
+	Section     string // configured section, if any
+	Tokens      int    // estimated tokens of Content
+	Empty       bool
+	DuplicateOf string // RelPath of an earlier file with the same content
+	// Skipped files are not part of the rendered snapshot; Err says why and
+	// SkipReason classifies it as binary, invalid_utf8, license, too_large,
+	// credentials or unreadable
+	Skipped    bool
+	SkipReason string
+	Err        error
+}
+
+// Files returns the selected files in snapshot order, including the
+// skipped ones. The slice is shared by all callers; do not modify it.
+func (snap *Snapshot) Files() []FileResult {
+	return snap.files
+}
+
+// Stats are the counters of a Snapshot
+type Stats struct {
+	Processed  int
+	Empty      int
+	Skipped    int
+	Duplicates int
+	Tokens     int // estimated tokens of the included file contents
+}
+
+// Collect reads the selected files and returns the snapshot. Once ctx is
+// done, the remaining files are not read and its error is returned.
+func (c *Collector) Collect(ctx context.Context) (*Snapshot, error) {
+	cs := c.session()
+	paths := cs.gatherFiles()
+	if cs.changedSince != "" || cs.diffHunks != "" || cs.staged {
+		var err error
+		if paths, err = cs.filterChanged(paths); err != nil {
+			return nil, err
+		}
+	}
+	results := cs.readAllContext(ctx, paths)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cs.processResults(results)
+	cs.schemas = cs.introspectDatabases()
+	cs.redactSections(results)
+
+	snap := &Snapshot{Stats: cs.exportStats(), cs: cs, results: results}
+	for _, r := range results {
+		snap.files = append(snap.files, exportResult(r))
+	}
+	return snap, nil
+}
+
+func (cs *CodeSnap) exportStats() Stats {
+	return Stats{
+		Processed:  cs.stats.processed,
+		Empty:      cs.stats.empty,
+		Skipped:    cs.stats.skipped,
+		Duplicates: cs.stats.duplicates,
+		Tokens:     cs.stats.tokens,
+	}
+}
+
+func exportResult(r fileResult) FileResult {
+	f := FileResult{
+		Path:        r.path,
+		RelPath:     r.relPath,
+		Content:     r.content,
+		Size:        r.size,
+		Language:    r.languageOrExt(),
+		Section:     r.section,
+		Tokens:      r.tokens,
+		Empty:       r.empty,
+		DuplicateOf: r.duplicateOf,
+	}
+	if r.err != nil {
+		f.Skipped, f.SkipReason, f.Err = true, skipReason(r.err), r.err
+	}
+	return f
+}
+
+// Tree returns the folder structure of the configured folders, as
+// codesnap -t prints it
+func (c *Collector) Tree() (string, error) {
+	return c.session().generateFolderStructure()
+}
+
+// session returns a CodeSnap with the collector's config and options but
+// none of the state of a run, so concurrent calls share nothing they
+// write, and a snapshot is not affected by a later Collect
+func (c *Collector) session() *CodeSnap {
+	cs := *c.cs
+	cs.editorConfigs = nil
+	cs.dependencyDecisions = nil
+	cs.hunks = nil
+	cs.readCache = nil
+	return &cs
+}
+
+// Formatter renders a Snapshot
+type Formatter interface {
+	Format(w io.Writer, snap *Snapshot) error
+}
+
+// The formats of codesnap --format
+var (
+	Text     Formatter = builtinFormat("text")
+	Markdown Formatter = builtinFormat("markdown")
+	JSON     Formatter = builtinFormat("json")
+	// Chunks writes JSON lines of up to 512 estimated tokens for embedding
+	Chunks Formatter = builtinFormat("chunks")
+)
+
+type builtinFormat string
+
+func (f builtinFormat) Format(w io.Writer, snap *Snapshot) error {
+	if snap.cs == nil {
+		return errors.New(T("snapshot was not collected by a Collector"))
+	}
+	if snap.cs.stats.processed == 0 {
+		return nothingCollected{errors.New(T("no valid files were processed"))}
+	}
+	cs := *snap.cs
+	cs.format = string(f)
+	return cs.renderTo(w, snap.results)
+}
@@ -0,0 +1,224 @@
+package codesnap
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCollectConcurrently(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":  "folders:\n  - path: .\nexpand_tabs: true\n",
+		".editorconfig": "[*.go]\ntab_width = 2\n",
+		"a.go":          "package a\n\nfunc A() {\n\treturn\n}\n",
+		"b/b.go":        "package b\n\nfunc B() {\n\treturn\n}\n",
+		// A dependency directory makes every run remember its decision
+		"node_modules/x/index.js": "module.exports = 1\n",
+	})
+	c, err := NewCollector(filepath.Join(dir, "codesnap.yml"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	snaps := make([]*Snapshot, 4)
+	errs := make([]error, len(snaps))
+	for i := range snaps {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			snaps[i], errs[i] = c.Collect(context.Background())
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Tree(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, snap := range snaps {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if !reflect.DeepEqual(snap.Files(), snaps[0].Files()) || snap.Stats != snaps[0].Stats {
+			t.Errorf("snapshot %d differs from the first", i)
+		}
+	}
+	if got := snaps[0].Stats.Processed; got != 5 {
+		t.Errorf("processed %d files, want 5", got)
+	}
+}
+
+func TestCollectChanged(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  Options
+		want  []string
+		diffs bool
+	}{
+		{"everything", Options{}, []string{".editorconfig", "a.go", "b.go", "codesnap.yml"}, false},
+		{"changed since", Options{ChangedSince: "HEAD"}, []string{"a.go", "b.go"}, false},
+		{"staged", Options{Staged: true}, []string{"b.go"}, false},
+		{"diff hunks", Options{DiffHunks: "HEAD", DiffContext: 1}, []string{"a.go", "b.go"}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml":  "folders:\n  - path: .\n",
+				".editorconfig": "root = true\n",
+				"a.go":          "package a\n",
+				"b.go":          "package b\n",
+			})
+			git(t, dir, "init", "-q")
+			git(t, dir, "add", ".")
+			git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "initial")
+			for _, name := range []string{"a.go", "b.go"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("package x\n\nvar changed = true\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			git(t, dir, "add", "b.go")
+
+			c, err := NewCollector(filepath.Join(dir, "codesnap.yml"), tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			snap, err := c.Collect(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range snap.Files() {
+				got = append(got, f.RelPath)
+				if diff := f.Content[0] == '@'; diff != tc.diffs {
+					t.Errorf("%s is a diff: %v, want %v; content:\n%s", f.RelPath, diff, tc.diffs, f.Content)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("files %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: .\nignore:\n  - codesnap.yml\n",
+		"a.go":         "package a\n",
+	})
+	c, err := NewCollector(filepath.Join(dir, "codesnap.yml"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		f    Formatter
+		want string
+	}{
+		{"text", Text, "File: a.go\n"},
+		{"markdown", Markdown, "## a.go\n\n```go\npackage a\n```\n"},
+		{"json", JSON, `"path": "a.go"`},
+		{"chunks", Chunks, `"path":"a.go"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tc.f.Format(&b, snap); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(b.String(), tc.want) {
+				t.Errorf("output lacks %q, got:\n%s", tc.want, b.String())
+			}
+		})
+	}
+
+	empty := writeFiles(t, map[string]string{"codesnap.yml": "folders:\n  - path: .\nignore:\n  - codesnap.yml\n"})
+	c, err = NewCollector(filepath.Join(empty, "codesnap.yml"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nothing, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		snap *Snapshot
+		err  string
+	}{
+		{&Snapshot{}, "snapshot was not collected by a Collector"},
+		{nothing, "no valid files were processed"},
+	} {
+		if err := Text.Format(io.Discard, tc.snap); err == nil || err.Error() != tc.err {
+			t.Errorf("Format = %v, want %q", err, tc.err)
+		}
+	}
+
+	if _, err := NewCollector(filepath.Join(empty, "missing.yml"), Options{}); err == nil {
+		t.Error("NewCollector succeeded without a config")
+	}
+}
+
+func TestSnapshotFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":  "folders:\n  - path: .\nignore:\n  - codesnap.yml\ndedupe: true\nsections:\n  API: api/**\n",
+		"api/server.go": "package api\n",
+		"main.py":       "print('hi')\n",
+		"copy.py":       "print('hi')\n",
+		"empty.txt":     "",
+		"logo.png":      "\x89PNG\x00\x00",
+	})
+	c, err := NewCollector(filepath.Join(dir, "codesnap.yml"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]FileResult)
+	for _, f := range snap.Files() {
+		if f.Path != filepath.Join(dir, filepath.FromSlash(f.RelPath)) {
+			t.Errorf("%s has path %s", f.RelPath, f.Path)
+		}
+		files[f.RelPath] = f
+	}
+	for _, tc := range []struct {
+		rel, language, section, duplicateOf, skipReason string
+		empty                                           bool
+	}{
+		{"api/server.go", "go", "API", "", "", false},
+		{"copy.py", "python", "Other", "", "", false},
+		{"main.py", "python", "Other", "copy.py", "", false},
+		{"empty.txt", "", "Other", "", "", true},
+		{"logo.png", "", "Other", "", "binary", false},
+	} {
+		f, ok := files[tc.rel]
+		if !ok {
+			t.Errorf("%s is not among the files", tc.rel)
+			continue
+		}
+		if f.Language != tc.language || f.Section != tc.section || f.DuplicateOf != tc.duplicateOf || f.Empty != tc.empty {
+			t.Errorf("%s = %+v, want language %s, section %q, duplicate of %q, empty %v", tc.rel, f, tc.language, tc.section, tc.duplicateOf, tc.empty)
+		}
+		if skipped := tc.skipReason != ""; f.Skipped != skipped || f.SkipReason != tc.skipReason || (f.Err != nil) != skipped {
+			t.Errorf("%s skipped %v (%s, %v), want reason %q", tc.rel, f.Skipped, f.SkipReason, f.Err, tc.skipReason)
+		}
+		if !f.Skipped && !f.Empty && f.DuplicateOf == "" && (f.Tokens != estimateTokens(f.Content) || f.Size != int64(len(f.Content))) {
+			t.Errorf("%s has %d bytes and %d tokens for %q", tc.rel, f.Size, f.Tokens, f.Content)
+		}
+	}
+	if want := (Stats{Processed: 4, Empty: 1, Skipped: 1, Duplicates: 1}); snap.Stats.Processed != want.Processed ||
+		snap.Stats.Empty != want.Empty || snap.Stats.Skipped != want.Skipped || snap.Stats.Duplicates != want.Duplicates {
+		t.Errorf("stats = %+v, want %+v", snap.Stats, want)
+	}
+}
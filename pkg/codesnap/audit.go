@@ -0,0 +1,253 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AuditConfig tunes codesnap audit
+type AuditConfig struct {
+	// Patterns are further forbidden regular expressions, e.g. internal
+	// domains or project code names
+	Patterns []string `yaml:"patterns"`
+	// Allow are regular expressions of matches that are not reported
+	Allow []string `yaml:"allow"`
+	// Skip names checks to leave out: secrets, pii, network or markers
+	Skip []string `yaml:"skip"`
+}
+
+// auditCheck is a named set of patterns codesnap audit looks for
+type auditCheck struct {
+	name     string
+	patterns []*regexp.Regexp
+	// valid filters matches that only look like a finding, if set
+	valid func(match string) bool
+}
+
+var (
+	emailAddress = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)
+	cardNumber   = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	ssnNumber    = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	ibanNumber   = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){3,7}(?: ?[A-Z0-9]{1,3})?\b`)
+
+	ipv4Address      = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	internalHostname = regexp.MustCompile(`(?i)\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:internal|intranet|corp|lan|local|localdomain)\b`)
+
+	proprietaryMarker = regexp.MustCompile(`(?i)\b(?:confidential|proprietary|internal use only|do not distribute|trade secret|not for (?:public )?release)\b`)
+)
+
+// auditChecks are the built-in checks in report order
+var auditChecks = []auditCheck{
+	{name: "secrets", patterns: append(append([]*regexp.Regexp{}, secretTokens...), secretAssignment, secretEnvLine, bearerToken, urlCredentials)},
+	{name: "pii", patterns: []*regexp.Regexp{emailAddress, ssnNumber, ibanNumber}},
+	{name: "pii", patterns: []*regexp.Regexp{cardNumber}, valid: luhnValid},
+	{name: "network", patterns: []*regexp.Regexp{internalHostname}},
+	{name: "network", patterns: []*regexp.Regexp{ipv4Address}, valid: isPrivateIPv4},
+	{name: "markers", patterns: []*regexp.Regexp{proprietaryMarker}},
+}
+
+// auditFinding is a forbidden match in a file
+type auditFinding struct {
+	Path  string `json:"path"`
+	Line  int    `json:"line"`
+	Check string `json:"check"`
+	Match string `json:"match"`
+}
+
+// auditReport is the outcome of codesnap audit
+type auditReport struct {
+	Passed   bool           `json:"passed"`
+	Files    int            `json:"files"`
+	Findings []auditFinding `json:"findings"`
+}
+
+// audit reads the files a snapshot would include, as they are on disk,
+// and lists the secrets, personal data, internal addresses and
+// proprietary markers in them. Nothing is rendered or delivered.
+func (cs *CodeSnap) audit() (auditReport, error) {
+	checks, err := cs.auditChecks()
+	if err != nil {
+		return auditReport{}, err
+	}
+	var allow []*regexp.Regexp
+	for _, pattern := range cs.config.Audit.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return auditReport{}, fmt.Errorf(T("invalid audit.allow pattern %q: %v"), pattern, err)
+		}
+		allow = append(allow, re)
+	}
+
+	paths := cs.gatherFiles()
+	if cs.changedSince != "" || cs.diffHunks != "" || cs.staged {
+		if paths, err = cs.filterChanged(paths); err != nil {
+			return auditReport{}, err
+		}
+	}
+	// Secrets are reported even where redaction would mask them, as it
+	// only knows common formats
+	cs.redact = false
+	secrets := false
+	for _, check := range checks {
+		secrets = secrets || check.name == "secrets"
+	}
+	report := auditReport{Findings: []auditFinding{}}
+	for _, r := range cs.readAll(paths) {
+		if r.err != nil {
+			continue
+		}
+		report.Files++
+		if secrets && isCredentialFile(r.path) {
+			report.Findings = append(report.Findings, auditFinding{Path: r.relPath, Check: "secrets", Match: T("credential file")})
+		}
+		for _, check := range checks {
+			for _, re := range check.patterns {
+				for _, loc := range re.FindAllStringIndex(r.content, -1) {
+					match := r.content[loc[0]:loc[1]]
+					if (check.valid != nil && !check.valid(match)) || matchesAnyRegexp(allow, match) {
+						continue
+					}
+					report.Findings = append(report.Findings, auditFinding{
+						Path:  r.relPath,
+						Line:  strings.Count(r.content[:loc[0]], "\n") + 1,
+						Check: check.name,
+						Match: maskFinding(check.name, match),
+					})
+				}
+			}
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	report.Passed = len(report.Findings) == 0
+	return report, nil
+}
+
+// auditChecks are the built-in checks not skipped by the config, followed
+// by its own patterns as the check "patterns"
+func (cs *CodeSnap) auditChecks() ([]auditCheck, error) {
+	skip := make(map[string]bool)
+	for _, name := range cs.config.Audit.Skip {
+		if name != "secrets" && name != "pii" && name != "network" && name != "markers" {
+			return nil, fmt.Errorf(T("unknown audit check %q (expected secrets, pii, network or markers)"), name)
+		}
+		skip[name] = true
+	}
+	var checks []auditCheck
+	for _, check := range auditChecks {
+		if !skip[check.name] {
+			checks = append(checks, check)
+		}
+	}
+	custom := auditCheck{name: "patterns"}
+	for _, pattern := range cs.config.Audit.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(T("invalid audit.patterns pattern %q: %v"), pattern, err)
+		}
+		custom.patterns = append(custom.patterns, re)
+	}
+	if len(custom.patterns) > 0 {
+		checks = append(checks, custom)
+	}
+	return checks, nil
+}
+
+func matchesAnyRegexp(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskFinding shortens a match for the report. Secrets keep only their
+// first characters, so the report itself can be shared.
+func maskFinding(check, match string) string {
+	match = strings.TrimSpace(match)
+	if i := strings.IndexByte(match, '\n'); i >= 0 {
+		match = match[:i] + "..."
+	}
+	if check == "secrets" {
+		if len(match) > 8 {
+			return match[:8] + "..."
+		}
+		return match[:len(match)/2] + "..."
+	}
+	if len(match) > 80 {
+		return match[:80] + "..."
+	}
+	return match
+}
+
+// luhnValid reports whether the digits of s pass the Luhn check of
+// payment card numbers
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// isPrivateIPv4 reports whether s is an address of a private range, which
+// reveals the layout of an internal network
+func isPrivateIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && ip.IsPrivate()
+}
+
+// showAudit prints the audit report and reports whether it passed
+func (cs *CodeSnap) showAudit(asJSON bool) (bool, error) {
+	report, err := cs.audit()
+	if err != nil {
+		return false, err
+	}
+	if asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Println(string(data))
+		return report.Passed, nil
+	}
+
+	for _, f := range report.Findings {
+		if f.Line > 0 {
+			fmt.Printf("%s:%d: %s: %s\n", f.Path, f.Line, f.Check, f.Match)
+		} else {
+			fmt.Printf("%s: %s: %s\n", f.Path, f.Check, f.Match)
+		}
+	}
+	if report.Passed {
+		fmt.Printf(T("Audit passed: nothing forbidden in %d files\n"), report.Files)
+	} else {
+		files := make(map[string]bool)
+		for _, f := range report.Findings {
+			files[f.Path] = true
+		}
+		fmt.Printf(T("Audit failed: %d findings in %d of %d files\n"), len(report.Findings), len(files), report.Files)
+	}
+	return report.Passed, nil
+}
@@ -0,0 +1,123 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAuditHelpers(t *testing.T) {
+	for _, tc := range []struct {
+		number string
+		want   bool
+	}{
+		{"4111 1111 1111 1111", true},
+		{"4111-1111-1111-1112", false},
+		{"5500005555555559", true},
+		{"0000 0000 0000", false},
+	} {
+		if got := luhnValid(tc.number); got != tc.want {
+			t.Errorf("luhnValid(%s) = %v, want %v", tc.number, got, tc.want)
+		}
+	}
+	for _, tc := range []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"192.168.0.1", true},
+		{"172.16.5.4", true},
+		{"8.8.8.8", false},
+		{"999.1.1.1", false},
+	} {
+		if got := isPrivateIPv4(tc.ip); got != tc.want {
+			t.Errorf("isPrivateIPv4(%s) = %v, want %v", tc.ip, got, tc.want)
+		}
+	}
+	for _, tc := range []struct {
+		check, match, want string
+	}{
+		{"secrets", githubToken, "ghp_x1x1..."},
+		{"secrets", "abcd", "ab..."},
+		{"pii", " jane@example.org ", "jane@example.org"},
+		{"markers", "CONFIDENTIAL\nmore", "CONFIDENTIAL..."},
+		{"patterns", strings.Repeat("x", 90), strings.Repeat("x", 80) + "..."},
+	} {
+		if got := maskFinding(tc.check, tc.match); got != tc.want {
+			t.Errorf("maskFinding(%s, %q) = %q, want %q", tc.check, tc.match, got, tc.want)
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	files := map[string]string{
+		"main.go":  "package main\n\nconst token = \"" + githubToken + "\"\n",
+		"users.md": "Contact jane@corp.example.org or bob@example.com\nCard 4111 1111 1111 1111, not 4111 1111 1111 1112\n",
+		"net.yml":  "db: 10.0.0.5\ndns: 8.8.8.8\nhost: build.corp\n",
+		"NOTICE":   "CONFIDENTIAL - internal use only\nacme-internal.tools\n",
+		"id_rsa":   "not really a key\n",
+	}
+	for _, tc := range []struct {
+		name, options string
+		args          []string
+		code          int
+		want          []string
+		unwanted      []string
+	}{
+		{"findings", "", nil, exitAuditFailed, []string{
+			"main.go:3: secrets: ghp_x1x1...\n",
+			"users.md:1: pii: jane@corp.example.org\n",
+			"users.md:2: pii: 4111 1111 1111 1111\n",
+			"net.yml:1: network: 10.0.0.5\n",
+			"net.yml:3: network: build.corp\n",
+			"NOTICE:1: markers: CONFIDENTIAL\n",
+			"NOTICE:1: markers: internal use only\n",
+			"id_rsa: secrets: credential file\n",
+			"Audit failed: ",
+		}, []string{"8.8.8.8", "1112", githubToken}},
+		{"configured", "audit:\n  patterns:\n    - 'acme-internal\\.\\w+'\n  allow:\n    - '@example\\.com$'\n  skip: [secrets, markers]\n", nil, exitAuditFailed,
+			[]string{"NOTICE:2: patterns: acme-internal.tools\n", "users.md:1: pii: jane@corp.example.org\n"},
+			[]string{"bob@example.com", "secrets:", "markers:"}},
+		{"passed", "  - \"*.md\"\n  - \"*.yml\"\n  - main.go\n  - NOTICE\n  - id_rsa\n", nil, 0,
+			[]string{"Audit passed: nothing forbidden in 0 files\n"}, nil},
+		{"unknown check", "audit:\n  skip: [emails]\n", nil, exitError, []string{`unknown audit check "emails"`}, nil},
+		{"invalid pattern", "audit:\n  patterns: ['(']\n", nil, exitError, []string{`invalid audit.patterns pattern "("`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			all := map[string]string{"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options}
+			for name, content := range files {
+				all[name] = content
+			}
+			dir := writeFiles(t, all)
+			r := runCodesnap(t, dir, append([]string{"audit"}, tc.args...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"a.txt":        "ssn 123-45-6789\n",
+	})
+	r := runCodesnap(t, dir, "audit", "--json")
+	var report auditReport
+	if err := json.Unmarshal([]byte(r.stdout), &report); err != nil {
+		t.Fatalf("audit --json is not JSON: %v\n%s", err, r.stdout)
+	}
+	if r.code != exitAuditFailed || report.Passed || report.Files != 1 || len(report.Findings) != 1 ||
+		report.Findings[0] != (auditFinding{Path: "a.txt", Line: 1, Check: "pii", Match: "123-45-6789"}) {
+		t.Errorf("exit code %d, report %+v", r.code, report)
+	}
+}
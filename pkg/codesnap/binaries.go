@@ -0,0 +1,59 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// binaryFile is an entry of the --list-binaries appendix
+type binaryFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	MIME string `json:"mime"`
+}
+
+// binaryFiles lists the files skipped as binary, with their MIME type
+// sniffed from the first bytes, or guessed from the extension when the
+// content says nothing more specific than application/octet-stream
+func binaryFiles(results []fileResult) []binaryFile {
+	var found []binaryFile
+	for _, r := range results {
+		if !errors.Is(r.err, errBinaryFile) {
+			continue
+		}
+		found = append(found, binaryFile{Path: r.relPath, Size: r.size, MIME: detectMIME(r.path)})
+	}
+	return found
+}
+
+func detectMIME(path string) string {
+	detected := "application/octet-stream"
+	if file, err := os.Open(path); err == nil {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(file, head)
+		file.Close()
+		detected = http.DetectContentType(head[:n])
+	}
+	if detected == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+			detected = byExt
+		}
+	}
+	detected, _, _ = strings.Cut(detected, ";")
+	return detected
+}
+
+// renderBinaries lists the binary files as "- path (size, type)" lines
+func renderBinaries(files []binaryFile) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(fmt.Sprintf("- %s (%s, %s)\n", f.Path, formatSize(f.Size), f.MIME))
+	}
+	return b.String()
+}
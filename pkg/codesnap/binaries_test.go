@@ -0,0 +1,47 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListBinaries(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		want, unwanted []string
+	}{
+		{"text", []string{"--list-binaries"},
+			[]string{"\nBinary files:\n" + strings.Repeat("=", 50) + "\n- app.wasm (8 B, application/wasm)\n- data.bin (5 B, application/octet-stream)\n- logo.png (10 B, image/png)\n"},
+			[]string{"a.go ("}},
+		{"markdown", []string{"--list-binaries", "--format", "markdown"},
+			[]string{"## Binary files\n\n- app.wasm (8 B, application/wasm)\n"}, nil},
+		{"json", []string{"--list-binaries", "--format", "json"},
+			[]string{`"binaries": [`, `"path": "logo.png",`, `"size": 10,`, `"mime": "image/png"`}, nil},
+		{"off", nil, nil, []string{"Binary files", "binaries"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+				"logo.png":     "\x89PNG\r\n\x1a\n\x00\x00",
+				"app.wasm":     "\x00asm\x01\x00\x00\x00",
+				"data.bin":     "\x00\x01\x02\x03\x04",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("output has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
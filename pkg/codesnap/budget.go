@@ -0,0 +1,221 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Errors of the files left out to keep the snapshot within max_tokens or
+// max_bytes
+var (
+	errOverBudget     = errors.New("left out to stay within max_tokens")
+	errOverByteBudget = errors.New("left out to stay within max_bytes")
+)
+
+// maxDroppedListed is how many of the files left out for the budget the
+// warning names
+const maxDroppedListed = 20
+
+// budgetGroup is the share of the token budget a file counts against: its
+// configured section, or else its top-level directory
+func budgetGroup(r fileResult) string {
+	if r.section != "" {
+		return r.section
+	}
+	if dir, _, ok := strings.Cut(filepath.ToSlash(r.relPath), "/"); ok {
+		return dir
+	}
+	return "."
+}
+
+// fitBudget leaves out files until the included contents fit cs.maxTokens,
+// or cs.maxBytes. The drop_policy decides which files go, see evenDrops
+// and rankedDrops.
+func (cs *CodeSnap) fitBudget(results []fileResult) {
+	limit, reason := cs.maxTokens, errOverBudget
+	costs := make([]int, len(results))
+	for i, r := range results {
+		costs[i] = r.tokens
+	}
+	if cs.maxBytes > 0 {
+		limit, reason = int(cs.maxBytes), errOverByteBudget
+		for i, r := range results {
+			if r.tokens > 0 {
+				costs[i] = len(cs.shownContent(r))
+			}
+		}
+	}
+	if limit <= 0 {
+		return
+	}
+	total := 0
+	for i, r := range results {
+		if r.err == nil {
+			total += costs[i]
+		}
+	}
+	if total <= limit {
+		return
+	}
+
+	var over []int
+	switch cs.config.DropPolicy {
+	case "", "even":
+		over = evenDrops(results, costs, limit, total)
+	default:
+		over = cs.rankedDrops(results, costs, limit, total)
+	}
+
+	droppedCost := 0
+	droppedPaths := make(map[string]bool)
+	var listed []string
+	drop := func(result *fileResult) {
+		cs.logf("Skipping %s: %v", result.relPath, reason)
+		cs.stats.processed--
+		cs.stats.skipped++
+		cs.stats.tokens -= result.tokens
+		result.err, result.tokens = reason, 0
+	}
+	for _, i := range over {
+		droppedCost += costs[i]
+		droppedPaths[results[i].relPath] = true
+		listed = append(listed, results[i].relPath)
+		drop(&results[i])
+	}
+	// Duplicates cannot point to a file that was left out
+	for i := range results {
+		if result := &results[i]; result.err == nil && droppedPaths[result.duplicateOf] {
+			cs.stats.duplicates--
+			drop(result)
+		}
+	}
+	if len(over) == 0 || cs.quiet {
+		return
+	}
+	if cs.maxBytes > 0 {
+		fmt.Printf(T("Warning: %d files (%s) were left out to stay within max_bytes (%s)\n"),
+			len(over), formatSize(int64(droppedCost)), formatSize(cs.maxBytes))
+	} else {
+		fmt.Printf(T("Warning: %d files (~%d tokens) were left out to stay within max_tokens (%d)\n"),
+			len(over), droppedCost, cs.maxTokens)
+	}
+	for i, path := range listed {
+		if i == maxDroppedListed {
+			fmt.Printf(T("    ... and %d more\n"), len(listed)-i)
+			break
+		}
+		fmt.Printf("    %s\n", path)
+	}
+}
+
+// evenDrops returns the files to leave out with drop_policy: even. Every
+// directory (or section) keeps a share of the budget in proportion to its
+// size, so all of them are trimmed alike instead of the last folders being
+// dropped whole. Within a share files are kept in snapshot order; the
+// budget left over once the shares are filled goes to the files that did
+// not fit, again in order.
+func evenDrops(results []fileResult, costs []int, limit, total int) []int {
+	totals := make(map[string]int)
+	for i, r := range results {
+		if r.err == nil {
+			totals[budgetGroup(r)] += costs[i]
+		}
+	}
+	used := make(map[string]int)
+	kept := 0
+	var over []int
+	for i, r := range results {
+		if r.err != nil || costs[i] == 0 {
+			continue
+		}
+		group := budgetGroup(r)
+		share := int(int64(limit) * int64(totals[group]) / int64(total))
+		if used[group]+costs[i] <= share {
+			used[group] += costs[i]
+			kept += costs[i]
+			continue
+		}
+		over = append(over, i)
+	}
+
+	var dropped []int
+	for _, i := range over {
+		if kept+costs[i] <= limit {
+			kept += costs[i]
+			continue
+		}
+		dropped = append(dropped, i)
+	}
+	return dropped
+}
+
+// rankedDrops returns the files to leave out with the other drop policies:
+// they are ranked, and left out in that order until the rest fits.
+// largest-first drops the biggest files, oldest-first the files modified
+// longest ago, and by-weight the files with the lowest drop_weights, the
+// biggest first among equal weights.
+func (cs *CodeSnap) rankedDrops(results []fileResult, costs []int, limit, total int) []int {
+	var candidates []int
+	for i, r := range results {
+		if r.err == nil && costs[i] > 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	switch cs.config.DropPolicy {
+	case "oldest-first":
+		modified := make(map[int]int64, len(candidates))
+		for _, i := range candidates {
+			if info, err := os.Stat(results[i].path); err == nil {
+				modified[i] = info.ModTime().UnixNano()
+			}
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return modified[candidates[a]] < modified[candidates[b]]
+		})
+	case "by-weight":
+		weights := make(map[int]float64, len(candidates))
+		for _, i := range candidates {
+			weights[i] = cs.dropWeight(results[i])
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			wa, wb := weights[candidates[a]], weights[candidates[b]]
+			if wa != wb {
+				return wa < wb
+			}
+			return costs[candidates[a]] > costs[candidates[b]]
+		})
+	default: // largest-first
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return costs[candidates[a]] > costs[candidates[b]]
+		})
+	}
+
+	var dropped []int
+	for _, i := range candidates {
+		if total <= limit {
+			break
+		}
+		total -= costs[i]
+		dropped = append(dropped, i)
+	}
+	return dropped
+}
+
+// dropWeight is the highest drop_weights weight of the globs a file
+// matches, or 1
+func (cs *CodeSnap) dropWeight(r fileResult) float64 {
+	rel := filepath.ToSlash(cs.relPath(r.path))
+	weight, found := 1.0, false
+	for pattern, w := range cs.config.DropWeights {
+		if matched, _ := doublestar.Match(pattern, rel); matched && (!found || w > weight) {
+			weight, found = w, true
+		}
+	}
+	return weight
+}
@@ -0,0 +1,194 @@
+package codesnap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudgetGroup(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		r    fileResult
+		want string
+	}{
+		{"top-level directory", fileResult{relPath: "api/v1/handler.go"}, "api"},
+		{"native separators", fileResult{relPath: filepath.Join("api", "v1", "handler.go")}, "api"},
+		{"top-level file", fileResult{relPath: "main.go"}, "."},
+		{"section wins", fileResult{relPath: "api/handler.go", section: "Backend"}, "Backend"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := budgetGroup(tc.r); got != tc.want {
+				t.Errorf("budgetGroup(%q) = %q, want %q", tc.r.relPath, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvenDrops(t *testing.T) {
+	skipped := fileResult{relPath: "a/skipped.bin", err: errors.New("binary")}
+	for _, tc := range []struct {
+		name    string
+		results []fileResult
+		costs   []int
+		limit   int
+		want    []int
+	}{
+		{
+			name:    "every directory keeps its share",
+			results: []fileResult{{relPath: "a/1.go"}, {relPath: "a/2.go"}, {relPath: "b/1.go"}},
+			costs:   []int{60, 40, 100},
+			limit:   100,
+			want:    []int{2},
+		},
+		{
+			name:    "the budget left over goes to the files that did not fit",
+			results: []fileResult{{relPath: "a/1.go"}, {relPath: "b/1.go"}, {relPath: "b/2.go"}},
+			costs:   []int{10, 50, 50},
+			limit:   70,
+			want:    []int{2},
+		},
+		{
+			name:    "skipped files take no share",
+			results: []fileResult{skipped, {relPath: "a/1.go"}, {relPath: "b/1.go"}},
+			costs:   []int{500, 50, 50},
+			limit:   50,
+			want:    []int{2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			total := 0
+			for i, r := range tc.results {
+				if r.err == nil {
+					total += tc.costs[i]
+				}
+			}
+			if got := evenDrops(tc.results, tc.costs, tc.limit, total); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("evenDrops = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRankedDrops(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"new.go": "", "old.go": "", "mid.go": "", "docs/a.md": "", "docs/b.md": "", "internal/x.go": "", "main.go": ""})
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"new.go": 0, "old.go": 48 * time.Hour, "mid.go": 24 * time.Hour} {
+		if err := os.Chtimes(filepath.Join(dir, name), now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := func(names ...string) []fileResult {
+		var results []fileResult
+		for _, name := range names {
+			results = append(results, fileResult{path: filepath.Join(dir, name), relPath: name})
+		}
+		return results
+	}
+	skipped := fileResult{relPath: "blob.bin", err: errors.New("binary")}
+	weights := map[string]float64{"docs/**": 0.5, "internal/**": 2, "internal/gen/**": 0.25}
+	for _, tc := range []struct {
+		name, policy string
+		results      []fileResult
+		costs        []int
+		limit        int
+		want         []int
+	}{
+		{"largest-first", "largest-first", files("new.go", "old.go", "mid.go"), []int{10, 50, 30}, 45, []int{1}},
+		{"largest-first keeps ties in order", "largest-first", files("new.go", "old.go", "mid.go"), []int{30, 30, 30}, 60, []int{0}},
+		{"oldest-first", "oldest-first", files("new.go", "old.go", "mid.go"), []int{10, 10, 10}, 15, []int{1, 2}},
+		{"by-weight", "by-weight", files("docs/a.md", "docs/b.md", "internal/x.go", "main.go"), []int{20, 40, 100, 10}, 100, []int{1, 0, 3}},
+		{"skipped and empty files are not dropped", "largest-first", append([]fileResult{skipped}, files("new.go", "old.go")...), []int{500, 0, 50}, 10, []int{2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &CodeSnap{configDir: dir, config: &Config{DropPolicy: tc.policy, DropWeights: weights}}
+			total := 0
+			for i, r := range tc.results {
+				if r.err == nil {
+					total += tc.costs[i]
+				}
+			}
+			if got := cs.rankedDrops(tc.results, tc.costs, tc.limit, total); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("rankedDrops = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	cs := &CodeSnap{configDir: dir, config: &Config{DropWeights: weights}}
+	for _, tc := range []struct {
+		path string
+		want float64
+	}{
+		{"main.go", 1},
+		{"docs/a.md", 0.5},
+		{"internal/gen/y.go", 2},
+	} {
+		if got := cs.dropWeight(fileResult{path: filepath.Join(dir, tc.path)}); got != tc.want {
+			t.Errorf("dropWeight(%s) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name, options  string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"even", "max_bytes: 1KB\n", nil, 0,
+			[]string{"File: big.txt\n", "File: small.txt\n", "Warning: 1 files (400 B) were left out to stay within max_bytes (1 KB)\n    mid.txt\n"},
+			[]string{"File: mid.txt"}},
+		{"largest-first", "max_bytes: 1KB\ndrop_policy: largest-first\n", nil, 0,
+			[]string{"File: mid.txt\n", "File: small.txt\n", "    big.txt\n"}, []string{"File: big.txt"}},
+		{"flags", "", []string{"--max-bytes", "1KB", "--drop-policy", "largest-first"}, 0,
+			[]string{"File: mid.txt\n", "    big.txt\n"}, []string{"File: big.txt"}},
+		{"--max-bytes over max_tokens", "max_tokens: 10\n", []string{"--max-bytes", "1KB"}, 0,
+			[]string{"File: big.txt\n", "File: small.txt\n"}, []string{"File: mid.txt", "within max_tokens"}},
+		{"within the budget", "max_bytes: 2KB\n", nil, 0, []string{"File: mid.txt\n"}, []string{"Warning"}},
+		{"both budgets", "max_bytes: 1KB\nmax_tokens: 100\n", nil, exitError, []string{"max_tokens and max_bytes cannot be combined"}, nil},
+		{"both budget flags", "", []string{"--max-tokens", "100", "--max-bytes", "1KB"}, exitError, []string{"--max-tokens and --max-bytes cannot be combined"}, nil},
+		{"invalid size", "", []string{"--max-bytes", "lots"}, exitError, []string{"invalid --max-bytes: "}, nil},
+		{"invalid drop_policy", "drop_policy: random\n", nil, exitError, []string{`invalid drop_policy value "random"`}, nil},
+		{"invalid --drop-policy", "", []string{"--drop-policy", "random"}, exitError, []string{`invalid drop policy "random"`}, nil},
+		{"invalid drop_weights", "drop_weights:\n  \"docs/[\": 2\n", nil, exitError, []string{`invalid drop_weights pattern "docs/["`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"big.txt":      strings.Repeat("b", 699) + "\n",
+				"mid.txt":      strings.Repeat("m", 399) + "\n",
+				"small.txt":    strings.Repeat("s", 99) + "\n",
+			})
+			r := runCodesnap(t, dir, append([]string{"--stdout"}, tc.args...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\nmax_bytes: 100B\n",
+		"a.txt":        strings.Repeat("a", 80) + "\n",
+		"b.txt":        strings.Repeat("b", 80) + "\n",
+	})
+	r := runCodesnap(t, dir, "--format", "json", "--stdout", "-q")
+	if r.code != 0 || strings.Count(r.stdout, `"skip_reason": "byte_budget"`) != 1 {
+		t.Errorf("exit code %d, want b.txt skipped for byte_budget:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+}
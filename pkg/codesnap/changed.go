@@ -0,0 +1,176 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// changedFiles returns the files modified since ref according to git:
+// committed, staged and unstaged changes plus untracked files that are not
+// gitignored. Deleted files are left out.
+func (cs *CodeSnap) changedFiles(ref string) (map[string]bool, error) {
+	diff, err := cs.git("diff", "--name-only", "-z", "--diff-filter=d", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := cs.git("ls-files", "--others", "--exclude-standard", "-z", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+	return cs.repoPaths(diff + untracked)
+}
+
+// stagedFiles returns the files with staged changes, excluding deletions
+func (cs *CodeSnap) stagedFiles() (map[string]bool, error) {
+	names, err := cs.git("diff", "--cached", "--name-only", "-z", "--diff-filter=d")
+	if err != nil {
+		return nil, err
+	}
+	return cs.repoPaths(names)
+}
+
+// repoPaths converts NUL separated paths relative to the repository root,
+// as git prints them, to absolute paths based on the config directory
+func (cs *CodeSnap) repoPaths(names string) (map[string]bool, error) {
+	prefix, err := cs.git("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]bool)
+	for _, name := range strings.Split(names, "\x00") {
+		if name != "" {
+			paths[cs.fromRepo(prefix, name)] = true
+		}
+	}
+	return paths, nil
+}
+
+// fromRepo converts a path relative to the repository root to an absolute
+// path, given the config directory's prefix below the root
+func (cs *CodeSnap) fromRepo(prefix, name string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(strings.TrimSpace(prefix)), filepath.FromSlash(name))
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cs.configDir, rel)
+}
+
+// stagedContent returns the staged version of a file from the git index
+func (cs *CodeSnap) stagedContent(path string) ([]byte, error) {
+	content, err := cs.git("cat-file", "blob", ":./"+filepath.ToSlash(cs.relPath(path)))
+	return []byte(content), err
+}
+
+// git runs a git command in the config directory and returns its output
+func (cs *CodeSnap) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = cs.configDir
+	return gitOutput(cmd)
+}
+
+// gitOutput runs a prepared git command and returns its output, or an error
+// with git's message
+func gitOutput(cmd *exec.Cmd) (string, error) {
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf(T("git %s failed: %s"), cmd.Args[1], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf(T("git %s failed: %v"), cmd.Args[1], err)
+	}
+	return string(out), nil
+}
+
+// filterChanged keeps the paths that changed since cs.changedSince or
+// cs.diffHunks and, with cs.staged, those that are staged
+func (cs *CodeSnap) filterChanged(paths []string) ([]string, error) {
+	if cs.changedSince != "" {
+		changed, err := cs.changedFiles(cs.changedSince)
+		if err != nil {
+			return nil, err
+		}
+		if paths = keepPaths(paths, changed); len(paths) == 0 {
+			return nil, nothingCollected{fmt.Errorf(T("no selected files changed since %s"), cs.changedSince)}
+		}
+	}
+	if cs.diffHunks != "" {
+		changed, err := cs.changedFiles(cs.diffHunks)
+		if err != nil {
+			return nil, err
+		}
+		if paths = keepPaths(paths, changed); len(paths) == 0 {
+			return nil, nothingCollected{fmt.Errorf(T("no selected files changed since %s"), cs.diffHunks)}
+		}
+		if cs.hunks, err = cs.diffHunksSince(cs.diffHunks); err != nil {
+			return nil, err
+		}
+	}
+	if cs.staged {
+		staged, err := cs.stagedFiles()
+		if err != nil {
+			return nil, err
+		}
+		if paths = keepPaths(paths, staged); len(paths) == 0 {
+			return nil, nothingCollected{errors.New(T("no selected files are staged"))}
+		}
+	}
+	return paths, nil
+}
+
+func keepPaths(paths []string, keep map[string]bool) []string {
+	kept := paths[:0]
+	for _, path := range paths {
+		if keep[path] {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// diffHunksSince returns the changed hunks of every file modified since ref,
+// with cs.diffContext lines of context, keyed by absolute path. Untracked
+// files have no hunks and are included in full.
+func (cs *CodeSnap) diffHunksSince(ref string) (map[string]string, error) {
+	diff, err := cs.git("diff", fmt.Sprintf("-U%d", cs.diffContext), "--no-color", "--no-ext-diff",
+		"--no-renames", "--diff-filter=d", "--src-prefix=a/", "--dst-prefix=b/", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := cs.git("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	// Each file's patch starts with a diff --git line; keep it from the
+	// first @@ hunk header on
+	hunks := make(map[string]string)
+	for _, patch := range strings.SplitAfter("\n"+diff, "\ndiff --git ")[1:] {
+		patch = strings.TrimSuffix(patch, "diff --git ")
+		var name string
+		lines := strings.SplitAfter(patch, "\n")
+		for i, line := range lines {
+			if strings.HasPrefix(line, "+++ ") {
+				// git ends names with spaces with a tab
+				name = strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\n")
+				name = strings.TrimSuffix(name, "\t")
+				if unquoted, err := strconv.Unquote(name); err == nil {
+					name = unquoted
+				}
+				name = strings.TrimPrefix(name, "b/")
+			}
+			if strings.HasPrefix(line, "@@") {
+				if name != "" {
+					hunks[cs.fromRepo(prefix, name)] = strings.Join(lines[i:], "")
+				}
+				break
+			}
+		}
+	}
+	return hunks, nil
+}
@@ -0,0 +1,246 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a git repository with the given files committed
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir := writeFiles(t, files)
+	git(t, dir, "init", "-q")
+	git(t, dir, "add", ".")
+	git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "initial")
+	return dir
+}
+
+func TestChanged(t *testing.T) {
+	for _, tc := range []struct {
+		name, config   string // the directory of the config in the repository
+		change         map[string]string
+		remove         string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"modified and untracked", ".", map[string]string{"a.go": "package a // changed\n", "new.go": "package a\n"}, "b.go", []string{"--changed", "HEAD"}, 0,
+			[]string{"File: a.go", "File: new.go"}, []string{"File: b.go", "File: sub/c.go"}},
+		{"config in a subdirectory", "sub", map[string]string{"sub/c.go": "package c // changed\n", "a.go": "package a // changed\n"}, "sub/d.go", []string{"--changed", "HEAD"}, 0,
+			[]string{"File: c.go"}, []string{"a.go", "File: d.go"}},
+		{"nothing changed", ".", nil, "", []string{"--changed", "HEAD"}, exitNothingCollected,
+			[]string{"no selected files changed since HEAD"}, nil},
+		{"unknown ref", ".", nil, "", []string{"--changed", "nope"}, exitError, []string{"git diff failed"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := gitRepo(t, map[string]string{
+				"codesnap.yml":     "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"sub/codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":             "package a\n",
+				"b.go":             "package a\n",
+				"sub/c.go":         "package c\n",
+				"sub/d.go":         "package c\n",
+			})
+			for name, content := range tc.change {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.remove != "" {
+				if err := os.Remove(filepath.Join(dir, tc.remove)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := runCodesnap(t, filepath.Join(dir, tc.config), append(tc.args, "--stdout", "-q")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestStaged(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		stage, change  map[string]string // files written before and after git add
+		remove         string            // a file removed with git rm
+		code           int
+		want, unwanted []string
+	}{
+		{"the staged content", map[string]string{"a.go": "package a // staged\n", "new.go": "package a // new\n"},
+			map[string]string{"a.go": "package a // unstaged\n", "b.go": "package a // unstaged\n"}, "", 0,
+			[]string{"File: a.go\n", "package a // staged\n", "File: new.go\n", "package a // new\n"}, []string{"// unstaged", "File: b.go"}},
+		{"a staged deletion", map[string]string{"a.go": "package a // staged\n"}, nil, "b.go", 0,
+			[]string{"File: a.go\n"}, []string{"File: b.go"}},
+		{"nothing staged", nil, map[string]string{"a.go": "package a // unstaged\n"}, "", exitNothingCollected,
+			[]string{"no selected files are staged"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := gitRepo(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+				"b.go":         "package a\n",
+			})
+			for name, content := range tc.stage {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+				git(t, dir, "add", name)
+			}
+			if tc.remove != "" {
+				git(t, dir, "rm", "-q", tc.remove)
+			}
+			for name, content := range tc.change {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := runCodesnap(t, dir, "--staged", "--stdout", "-q")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffHunksSince(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+	lines[9] = "changed 10"
+	changed := strings.Join(lines, "\n") + "\n"
+
+	for _, tc := range []struct {
+		name, config string // the directory of the config in the repository
+		context      int
+		want         map[string]string // hunks by path relative to the config
+	}{
+		{"no context", ".", 0, map[string]string{
+			"a.txt":        "@@ -10 +10 @@ line 9\n-line 10\n+changed 10\n",
+			"sub/é.txt":    "@@ -10 +10 @@ line 9\n-line 10\n+changed 10\n",
+			"with space.x": "@@ -1 +1 @@\n-old\n+new\n",
+		}},
+		{"default context", ".", 3, map[string]string{
+			"a.txt":        "@@ -7,7 +7,7 @@ line 6\n line 7\n line 8\n line 9\n-line 10\n+changed 10\n line 11\n line 12\n line 13\n",
+			"sub/é.txt":    "@@ -7,7 +7,7 @@ line 6\n line 7\n line 8\n line 9\n-line 10\n+changed 10\n line 11\n line 12\n line 13\n",
+			"with space.x": "@@ -1 +1 @@\n-old\n+new\n",
+		}},
+		{"config in a subdirectory", "sub", 0, map[string]string{
+			"é.txt":           "@@ -10 +10 @@ line 9\n-line 10\n+changed 10\n",
+			"../a.txt":        "@@ -10 +10 @@ line 9\n-line 10\n+changed 10\n",
+			"../with space.x": "@@ -1 +1 @@\n-old\n+new\n",
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := gitRepo(t, map[string]string{
+				"a.txt":        original,
+				"sub/é.txt":    original,
+				"with space.x": "old\n",
+				"gone.txt":     "deleted\n",
+			})
+			for name, content := range map[string]string{"a.txt": changed, "sub/é.txt": changed, "with space.x": "new\n", "untracked.txt": "new\n"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+				t.Fatal(err)
+			}
+
+			cs := &CodeSnap{configDir: filepath.Join(dir, tc.config), diffContext: tc.context}
+			hunks, err := cs.diffHunksSince("HEAD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hunks) != len(tc.want) {
+				t.Errorf("hunks of %d files, want %d: %v", len(hunks), len(tc.want), hunks)
+			}
+			for name, want := range tc.want {
+				if got := hunks[filepath.Join(cs.configDir, name)]; got != want {
+					t.Errorf("hunks of %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	for _, tc := range []struct {
+		name, ignore   string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"text", "*.log", []string{"--diff-hunks", "HEAD", "--diff-context", "0"}, 0,
+			[]string{"File: a.go (diff)\n", "@@ -3 +3 @@ package a\n-var x = 1\n+var x = 2\n", "File: new.go\n", "package a // new\n"},
+			[]string{"File: b.go", "package a\n\nfunc"}},
+		{"markdown", "*.log", []string{"--diff-hunks", "HEAD", "--format", "markdown"}, 0,
+			[]string{"## a.go (diff)\n", "```diff\n@@ -1,5 +1,5 @@\n package a\n"}, []string{"## b.go"}},
+		{"nothing changed", "*.go", []string{"--diff-hunks", "HEAD"}, exitNothingCollected,
+			[]string{"no selected files changed since HEAD"}, nil},
+		{"negative context", "*.log", []string{"--diff-hunks", "HEAD", "--diff-context", "-1"}, exitError,
+			[]string{"--diff-context must not be negative"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := gitRepo(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n  - \"" + tc.ignore + "\"\n",
+				"a.go":         "package a\n\nvar x = 1\n\nfunc f() {}\n",
+				"b.go":         "package a\n\nfunc g() {}\n",
+			})
+			for name, content := range map[string]string{"a.go": "package a\n\nvar x = 2\n\nfunc f() {}\n", "new.go": "package a // new\n"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
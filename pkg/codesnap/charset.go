@@ -0,0 +1,72 @@
+package codesnap
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252 to their runes.
+// Its five unassigned bytes keep their Latin-1 meaning, as in browsers.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// hasUTF16BOM reports whether content starts with a UTF-16 byte order mark
+func hasUTF16BOM(content []byte) bool {
+	return bytes.HasPrefix(content, []byte{0xff, 0xfe}) || bytes.HasPrefix(content, []byte{0xfe, 0xff})
+}
+
+// decodeText returns content as UTF-8 along with the encoding it was
+// transcoded from: utf-16le or utf-16be for files with a byte order mark,
+// otherwise windows-1252 (or latin-1 when it has none of the bytes the two
+// disagree on) for content that is not valid UTF-8. The encoding is empty
+// for valid UTF-8, which is returned unchanged.
+func decodeText(content []byte) (string, string) {
+	if hasUTF16BOM(content) {
+		return decodeUTF16(content)
+	}
+	if utf8.Valid(content) {
+		return string(content), ""
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(content) + len(content)/8)
+	encoding := "latin-1"
+	for _, b := range content {
+		switch {
+		case b < 0x80:
+			sb.WriteByte(b)
+		case b < 0xa0:
+			sb.WriteRune(windows1252[b-0x80])
+			encoding = "windows-1252"
+		default:
+			sb.WriteRune(rune(b))
+		}
+	}
+	return sb.String(), encoding
+}
+
+// decodeUTF16 decodes content starting with a byte order mark, which is
+// dropped. An odd trailing byte, e.g. of a file cut at max_file_size, is
+// ignored.
+func decodeUTF16(content []byte) (string, string) {
+	bigEndian := content[0] == 0xfe
+	content = content[2:]
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(content[2*i])<<8 | uint16(content[2*i+1])
+		} else {
+			units[i] = uint16(content[2*i+1])<<8 | uint16(content[2*i])
+		}
+	}
+	if bigEndian {
+		return string(utf16.Decode(units)), "utf-16be"
+	}
+	return string(utf16.Decode(units)), "utf-16le"
+}
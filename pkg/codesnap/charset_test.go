@@ -0,0 +1,65 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	for _, tc := range []struct {
+		content        string
+		want, encoding string
+	}{
+		{"plain ascii\n", "plain ascii\n", ""},
+		{"caf\xc3\xa9\n", "café\n", ""},
+		{"caf\xe9\n", "café\n", "latin-1"},
+		{"\x93quoted\x94 \x80 5\n", "“quoted” € 5\n", "windows-1252"},
+		{"\x81\n", "\u0081\n", "windows-1252"},
+		{"\xff\xfeh\x00i\x00\n\x00", "hi\n", "utf-16le"},
+		{"\xfe\xff\x00h\x00i\x00\n", "hi\n", "utf-16be"},
+		{"\xff\xfeh\x00i\x00\n", "hi", "utf-16le"},
+		{"\xff\xfe=\xd8\x00\xde", "😀", "utf-16le"},
+	} {
+		got, encoding := decodeText([]byte(tc.content))
+		if got != tc.want || encoding != tc.encoding {
+			t.Errorf("decodeText(%q) = %q, %q; want %q, %q", tc.content, got, encoding, tc.want, tc.encoding)
+		}
+	}
+}
+
+func TestStrictUTF8(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		want, unwanted []string
+	}{
+		{"transcoded", nil,
+			[]string{`"path": "latin.txt"`, `"encoding": "latin-1"`, `"content": "café\n"`, `"path": "wide.txt"`, `"encoding": "utf-16le"`, `"content": "hi\n"`},
+			[]string{"skip_reason"}},
+		{"strict", []string{"--strict-utf8"},
+			[]string{`"skip_reason": "invalid_utf8"`, `"skip_reason": "binary"`, `"content": "ok\n"`}, []string{"café", `"encoding": "latin-1"`}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"latin.txt":    "caf\xe9\n",
+				"wide.txt":     "\xff\xfeh\x00i\x00\n\x00",
+				"utf8.txt":     "ok\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--format", "json", "--stdout", "-q")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("output has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
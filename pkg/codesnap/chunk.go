@@ -0,0 +1,239 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// chunkHeaderTokens is reserved in every part for its recap header; the
+// file lists in it are shortened to chunkListTokens each
+const (
+	chunkHeaderTokens = 120
+	chunkListTokens   = 50
+)
+
+// textBoundary matches the banners that start a file or other section in
+// the text format
+var textBoundary = regexp.MustCompile(`\n\n={50}\n`)
+
+var (
+	textFileHeading     = regexp.MustCompile(`^\n*={50}\nFile: (.+)\n`)
+	markdownFileHeading = regexp.MustCompile(`^## (.+)\n`)
+)
+
+// chunkContent splits a rendered snapshot into parts of at most budget
+// estimated tokens each. Parts end at file boundaries where possible; a
+// file larger than the budget is split between lines. Every part starts
+// with a recap for models that receive the parts in separate messages:
+// project, "Part i/n", the files in the part and those in earlier parts.
+func chunkContent(content string, budget int, markdown bool, project string) []string {
+	budget -= chunkHeaderTokens
+	if budget < 1 {
+		budget = 1
+	}
+
+	var pieces []string
+	if markdown {
+		pieces = markdownSections(content)
+	} else {
+		start := 0
+		for _, loc := range textBoundary.FindAllStringIndex(content, -1) {
+			if loc[0] > start {
+				pieces = append(pieces, content[start:loc[0]])
+				start = loc[0]
+			}
+		}
+		pieces = append(pieces, content[start:])
+	}
+
+	var parts []string
+	var partFiles [][]chunkFile
+	var current strings.Builder
+	var files []chunkFile
+	tokens := 0
+	lastFile := ""
+	add := func(piece, file string) {
+		n := estimateTokens(piece)
+		if tokens > 0 && tokens+n > budget {
+			parts = append(parts, current.String())
+			partFiles = append(partFiles, files)
+			current.Reset()
+			files = nil
+			tokens = 0
+		}
+		if file != "" && (len(files) == 0 || files[len(files)-1].name != file) {
+			files = append(files, chunkFile{file, len(files) == 0 && file == lastFile})
+		}
+		lastFile = file
+		current.WriteString(piece)
+		tokens += n
+	}
+	for _, piece := range pieces {
+		file := pieceFile(piece, markdown)
+		if estimateTokens(piece) <= budget {
+			add(piece, file)
+			continue
+		}
+		for _, line := range strings.SplitAfter(piece, "\n") {
+			for _, chunk := range splitLine(line, budget) {
+				add(chunk, file)
+			}
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+		partFiles = append(partFiles, files)
+	}
+
+	// Markdown needs a hard line break to keep the recap on separate lines
+	newline := "\n"
+	if markdown {
+		newline = "  \n"
+	}
+	var previous []string
+	for i := range parts {
+		header := fmt.Sprintf("Part %d/%d of the snapshot of %s", i+1, len(parts), project)
+		if markdown {
+			header = "**" + header + "**"
+		}
+		var names []string
+		for _, f := range partFiles[i] {
+			if f.continued {
+				names = append(names, f.name+" (continued)")
+			} else {
+				names = append(names, f.name)
+			}
+		}
+		if len(names) > 0 {
+			header += newline + fmt.Sprintf("Files in this part: %s", listFiles(names))
+		}
+		if len(previous) > 0 {
+			header += newline + fmt.Sprintf("Previous parts contained: %s", listFiles(previous))
+		}
+		for _, f := range partFiles[i] {
+			if !f.continued {
+				previous = append(previous, f.name)
+			}
+		}
+		parts[i] = header + "\n\n" + strings.TrimLeft(parts[i], "\n")
+	}
+	return parts
+}
+
+// chunkFile is a file shown in a part; continued files started in the
+// previous part
+type chunkFile struct {
+	name      string
+	continued bool
+}
+
+// pieceFile returns the file a piece of the rendered snapshot shows, or ""
+// for the summary, the tree and other sections
+func pieceFile(piece string, markdown bool) string {
+	if !markdown {
+		if m := textFileHeading.FindStringSubmatch(piece); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+	m := markdownFileHeading.FindStringSubmatch(piece)
+	if m == nil {
+		return ""
+	}
+	for _, heading := range []string{"Summary", "Notes", "Environment", "Folder structure",
+		"Dependency graph", "Symbol index", "Empty files", "Binary files"} {
+		if m[1] == heading {
+			return ""
+		}
+	}
+	if strings.HasPrefix(m[1], "Database: ") || strings.HasPrefix(m[1], "Command: ") {
+		return ""
+	}
+	return m[1]
+}
+
+// listFiles joins names with commas, ending with "and N more" once the
+// list would exceed chunkListTokens
+func listFiles(names []string) string {
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 && estimateTokens(b.String()+name) > chunkListTokens {
+			b.WriteString(fmt.Sprintf(" and %d more", len(names)-i))
+			break
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+	}
+	return b.String()
+}
+
+// markdownSections splits Markdown before each heading that is not inside
+// a fenced code block
+func markdownSections(content string) []string {
+	var sections []string
+	var current strings.Builder
+	fence := ""
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		switch {
+		case fence != "":
+			if trimmed == fence {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"):
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, "`"))]
+		case strings.HasPrefix(trimmed, "#") && current.Len() > 0:
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+// splitLine cuts a line that alone exceeds budget into smaller pieces
+func splitLine(line string, budget int) []string {
+	if estimateTokens(line) <= budget {
+		return []string{line}
+	}
+	var pieces []string
+	runes := []rune(line)
+	size := int(float64(budget) * defaultCharsPerToken * 0.9)
+	if size < 1 {
+		size = 1
+	}
+	for len(runes) > 0 {
+		n := min(size, len(runes))
+		pieces = append(pieces, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return pieces
+}
+
+// saveChunks writes the parts to numbered files next to base (default: a
+// timestamped name), e.g. snapshot.part1.txt, and returns their names
+func saveChunks(parts []string, base, ext string) ([]string, error) {
+	if base == "" {
+		base = fmt.Sprintf("codesnap_%s%s", time.Now().Format("20060102_150405"), ext)
+	}
+	ext = filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		names[i] = fmt.Sprintf("%s.part%d%s", stem, i+1, ext)
+		if err := os.WriteFile(names[i], []byte(part), 0644); err != nil {
+			return nil, fmt.Errorf(T("failed to save content to file: %v"), err)
+		}
+	}
+	return names, nil
+}
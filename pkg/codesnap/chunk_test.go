@@ -0,0 +1,184 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderedFile renders a file the way the text format does
+func renderedFile(name, content string) string {
+	return fmt.Sprintf("\n\n%s\nFile: %s\n%s\n\n%s", strings.Repeat("=", 50), name, strings.Repeat("=", 50), content)
+}
+
+func TestChunkContent(t *testing.T) {
+	var big strings.Builder
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&big, "line %02d of the big file\n", i)
+	}
+	medium := strings.Repeat("var medium = 1\n", 27)
+	summary := fmt.Sprintf("\n\n%s\nSummary:\n- Files processed: 2\n%s\n", strings.Repeat("=", 50), strings.Repeat("=", 50))
+
+	for _, tc := range []struct {
+		name     string
+		content  string
+		budget   int
+		markdown bool
+		headers  []string // the recap of each part
+	}{
+		{"one part", renderedFile("a.go", "package a\n") + renderedFile("b.go", "package b\n") + summary, 1000, false, []string{
+			"Part 1/1 of the snapshot of project\nFiles in this part: a.go, b.go\n\n",
+		}},
+		{"a part per file", renderedFile("a.go", medium) + renderedFile("b.go", medium) + summary, chunkHeaderTokens + 200, false, []string{
+			"Part 1/2 of the snapshot of project\nFiles in this part: a.go\n\n",
+			"Part 2/2 of the snapshot of project\nFiles in this part: b.go\nPrevious parts contained: a.go\n\n",
+		}},
+		{"a file split between lines", renderedFile("big.txt", big.String()) + renderedFile("b.go", "package b\n"), chunkHeaderTokens + 200, false, []string{
+			"Part 1/2 of the snapshot of project\nFiles in this part: big.txt\n\n",
+			"Part 2/2 of the snapshot of project\nFiles in this part: big.txt (continued), b.go\nPrevious parts contained: big.txt\n\n",
+		}},
+		{"markdown", "# Snapshot\n\n## a.go\n\n```go\n" + medium + "```\n\n## b.md\n\n````markdown\n# not a heading\n" + medium + "````\n\n## Summary\n\n- Files processed: 2\n",
+			chunkHeaderTokens + 200, true, []string{
+				"**Part 1/2 of the snapshot of project**  \nFiles in this part: a.go\n\n",
+				"**Part 2/2 of the snapshot of project**  \nFiles in this part: b.md  \nPrevious parts contained: a.go\n\n",
+			}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			parts := chunkContent(tc.content, tc.budget, tc.markdown, "project")
+			if len(parts) != len(tc.headers) {
+				t.Fatalf("%d parts, want %d:\n%s", len(parts), len(tc.headers), strings.Join(parts, "\n-----\n"))
+			}
+			var body strings.Builder
+			for i, part := range parts {
+				if !strings.HasPrefix(part, tc.headers[i]) {
+					t.Errorf("part %d starts with %q, want %q", i+1, part[:min(len(part), len(tc.headers[i]))], tc.headers[i])
+				}
+				if n := estimateTokens(part); n > tc.budget {
+					t.Errorf("part %d has ~%d tokens, over the budget of %d", i+1, n, tc.budget)
+				}
+				body.WriteString(strings.TrimPrefix(part, tc.headers[i]))
+			}
+			if got, want := strings.ReplaceAll(body.String(), "\n", ""), strings.ReplaceAll(tc.content, "\n", ""); got != want {
+				t.Errorf("the parts do not add up to the snapshot:\n%s", body.String())
+			}
+		})
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	var many []string
+	for i := 0; i < 40; i++ {
+		many = append(many, fmt.Sprintf("pkg/file%02d.go", i))
+	}
+	for _, tc := range []struct {
+		names []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"a.go"}, "a.go"},
+		{[]string{"a.go", "b.go"}, "a.go, b.go"},
+		{many, strings.Join(many[:12], ", ") + " and 28 more"},
+	} {
+		if got := listFiles(tc.names); got != tc.want {
+			t.Errorf("listFiles(%d names) = %q, want %q", len(tc.names), got, tc.want)
+		}
+	}
+}
+
+func TestSplitLine(t *testing.T) {
+	for _, tc := range []struct {
+		line   string
+		budget int
+		pieces int
+	}{
+		{"short line\n", 10, 1},
+		{strings.Repeat("x", 100), 10, 4},
+		{strings.Repeat("é", 100), 10, 4},
+		{"abc", 0, 3},
+	} {
+		pieces := splitLine(tc.line, tc.budget)
+		if len(pieces) != tc.pieces || strings.Join(pieces, "") != tc.line {
+			t.Errorf("splitLine(%d runes, %d) = %q, want %d pieces", len([]rune(tc.line)), tc.budget, pieces, tc.pieces)
+		}
+	}
+}
+
+func TestChunkTokens(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		parts  []string
+		code   int
+		output string
+	}{
+		{"text", []string{"--chunk-tokens", "300"}, []string{"snap.part1.txt", "snap.part2.txt", "snap.part3.txt"}, 0, "Content saved in 3 parts of at most ~300 tokens:\n"},
+		{"markdown", []string{"--chunk-tokens", "300", "--format", "markdown"}, []string{"snap.part1.md", "snap.part2.md"}, 0, "Content saved in 2 parts"},
+		{"one part", []string{"--chunk-tokens", "5000"}, []string{"snap.part1.txt"}, 0, "Content saved in 1 parts"},
+		{"json", []string{"--chunk-tokens", "300", "--format", "json"}, nil, exitError, "--chunk-tokens"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n\n" + strings.Repeat("var a = 1\n", 50),
+				"b.go":         "package a\n\n" + strings.Repeat("var b = 1\n", 50),
+			})
+			out := t.TempDir()
+			ext := ".txt"
+			if len(tc.parts) > 0 {
+				ext = filepath.Ext(tc.parts[0])
+			}
+			r := runCodesnap(t, dir, append(tc.args, "-O", filepath.Join(out, "snap"+ext))...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if !strings.Contains(r.stdout+r.stderr, tc.output) {
+				t.Errorf("output lacks %q, got:\n%s%s", tc.output, r.stdout, r.stderr)
+			}
+			entries, err := os.ReadDir(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			if strings.Join(names, " ") != strings.Join(tc.parts, " ") {
+				t.Fatalf("parts = %v, want %v", names, tc.parts)
+			}
+			for i, name := range names {
+				part, err := os.ReadFile(filepath.Join(out, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := fmt.Sprintf("Part %d/%d of the snapshot of %s", i+1, len(names), filepath.Base(dir)); !strings.Contains(string(part), want) {
+					t.Errorf("%s lacks %q", name, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPieceFile(t *testing.T) {
+	for _, tc := range []struct {
+		piece    string
+		markdown bool
+		want     string
+	}{
+		{renderedFile("src/a.go", "package a\n"), false, "src/a.go"},
+		{fmt.Sprintf("\n\n%s\nSummary:\n%s\n", strings.Repeat("=", 50), strings.Repeat("=", 50)), false, ""},
+		{"line 12 of a split file\n", false, ""},
+		{"## src/a.go\n\n```go\npackage a\n```\n", true, "src/a.go"},
+		{"## a.go (diff)\n\n```diff\n", true, "a.go (diff)"},
+		{"## Summary\n\n- Files processed: 2\n", true, ""},
+		{"## Symbol index\n\n", true, ""},
+		{"## Database: app.db\n\n", true, ""},
+		{"## Command: go test ./...\n\n", true, ""},
+		{"# Snapshot\n\n", true, ""},
+	} {
+		if got := pieceFile(tc.piece, tc.markdown); got != tc.want {
+			t.Errorf("pieceFile(%q, %v) = %q, want %q", tc.piece, tc.markdown, got, tc.want)
+		}
+	}
+}
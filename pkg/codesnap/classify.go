@@ -0,0 +1,263 @@
+package codesnap
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// languagesByExtension maps file extensions to language names. The names
+// double as Markdown code fence tags, so they follow the common highlighter
+// identifiers.
+var languagesByExtension = map[string]string{
+	".go":         "go",
+	".py":         "python",
+	".js":         "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".jsx":        "jsx",
+	".ts":         "typescript",
+	".tsx":        "tsx",
+	".java":       "java",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".scala":      "scala",
+	".rs":         "rust",
+	".c":          "c",
+	".h":          "c",
+	".cc":         "cpp",
+	".cpp":        "cpp",
+	".cxx":        "cpp",
+	".hpp":        "cpp",
+	".cs":         "csharp",
+	".swift":      "swift",
+	".m":          "objectivec",
+	".rb":         "ruby",
+	".php":        "php",
+	".pl":         "perl",
+	".lua":        "lua",
+	".r":          "r",
+	".dart":       "dart",
+	".ex":         "elixir",
+	".exs":        "elixir",
+	".erl":        "erlang",
+	".hs":         "haskell",
+	".clj":        "clojure",
+	".sh":         "bash",
+	".bash":       "bash",
+	".zsh":        "zsh",
+	".fish":       "fish",
+	".ps1":        "powershell",
+	".bat":        "batch",
+	".cmd":        "batch",
+	".sql":        "sql",
+	".html":       "html",
+	".htm":        "html",
+	".css":        "css",
+	".scss":       "scss",
+	".sass":       "sass",
+	".less":       "less",
+	".vue":        "vue",
+	".svelte":     "svelte",
+	".json":       "json",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".toml":       "toml",
+	".ini":        "ini",
+	".xml":        "xml",
+	".md":         "markdown",
+	".rst":        "rst",
+	".tex":        "latex",
+	".proto":      "protobuf",
+	".graphql":    "graphql",
+	".gql":        "graphql",
+	".tf":         "hcl",
+	".hcl":        "hcl",
+	".mk":         "makefile",
+	".gradle":     "groovy",
+	".groovy":     "groovy",
+	".dockerfile": "dockerfile",
+}
+
+// languageFor returns the language of a file based on its extension, or ""
+// when it is not recognized
+func languageFor(file string) string {
+	return languagesByExtension[strings.ToLower(filepath.Ext(file))]
+}
+
+// languagesByName maps the lowercase names of well-known files without a
+// telling extension to their language
+var languagesByName = map[string]string{
+	"makefile":       "makefile",
+	"gnumakefile":    "makefile",
+	"dockerfile":     "dockerfile",
+	"containerfile":  "dockerfile",
+	"jenkinsfile":    "groovy",
+	"rakefile":       "ruby",
+	"gemfile":        "ruby",
+	"vagrantfile":    "ruby",
+	"podfile":        "ruby",
+	"brewfile":       "ruby",
+	"build":          "python",
+	"workspace":      "python",
+	"sconstruct":     "python",
+	"cmakelists.txt": "cmake",
+	".bashrc":        "bash",
+	".bash_profile":  "bash",
+	".profile":       "bash",
+	".zshrc":         "zsh",
+	".zprofile":      "zsh",
+	"pkgbuild":       "bash",
+}
+
+// languagesByInterpreter maps shebang interpreters, without their version
+// suffix, to their language
+var languagesByInterpreter = map[string]string{
+	"python":     "python",
+	"node":       "javascript",
+	"nodejs":     "javascript",
+	"deno":       "typescript",
+	"bun":        "javascript",
+	"sh":         "bash",
+	"bash":       "bash",
+	"dash":       "bash",
+	"ksh":        "bash",
+	"zsh":        "zsh",
+	"fish":       "fish",
+	"ruby":       "ruby",
+	"perl":       "perl",
+	"php":        "php",
+	"lua":        "lua",
+	"rscript":    "r",
+	"pwsh":       "powershell",
+	"groovy":     "groovy",
+	"elixir":     "elixir",
+	"escript":    "erlang",
+	"runhaskell": "haskell",
+	"swift":      "swift",
+	"make":       "makefile",
+}
+
+// languageOf returns the language of a file like languageFor, and for
+// files whose extension says nothing falls back to the interpreter of a
+// shebang line and to well-known names such as Makefile or Dockerfile.dev.
+// The shebang comes first, as a script may well be called build.
+func languageOf(file, content string) string {
+	if language := languageFor(file); language != "" {
+		return language
+	}
+	if language := shebangLanguage(content); language != "" {
+		return language
+	}
+	name := strings.ToLower(path.Base(filepath.ToSlash(file)))
+	if language, ok := languagesByName[name]; ok {
+		return language
+	}
+	if base, _, ok := strings.Cut(name, "."); ok && (base == "dockerfile" || base == "containerfile") {
+		return "dockerfile"
+	}
+	return ""
+}
+
+// languageOrExt is the detected language of an included file, or the one
+// its extension suggests for a skipped file
+func (r fileResult) languageOrExt() string {
+	if r.language != "" {
+		return r.language
+	}
+	return languageFor(r.path)
+}
+
+// shebangLanguage returns the language of the interpreter on a "#!" first
+// line, e.g. python for "#!/usr/bin/env python3"
+func shebangLanguage(content string) string {
+	line, ok := strings.CutPrefix(content, "#!")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		// Skip env's options, as in "#!/usr/bin/env -S deno run"
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = path.Base(field)
+				break
+			}
+		}
+	}
+	interpreter = strings.TrimRight(strings.ToLower(interpreter), "0123456789.")
+	return languagesByInterpreter[interpreter]
+}
+
+// generatedNames are file name patterns of generated or vendored artifacts
+var generatedNames = []string{
+	"*.pb.go", "*_pb2.py", "*.pb.cc", "*.pb.h", "*_generated.*", "*.gen.*",
+	"*.min.js", "*.min.css", "*.map",
+	"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+	"Cargo.lock", "poetry.lock", "Pipfile.lock", "composer.lock", "Gemfile.lock",
+}
+
+// generatedMarker matches the usual "do not edit" headers of generated code
+var generatedMarker = regexp.MustCompile(`(?i)code generated .* do not edit|@generated|auto-?generated|this file was generated`)
+
+// isGenerated reports whether a file looks generated, by name or by a
+// marker comment near the top
+func isGenerated(file, content string) bool {
+	name := path.Base(filepath.ToSlash(file))
+	for _, pattern := range generatedNames {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	head := content
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return generatedMarker.MatchString(head)
+}
+
+// testNames are file name patterns of test files across ecosystems
+var testNames = []string{
+	"*_test.go", "test_*.py", "*_test.py", "*.test.*", "*.spec.*",
+	"*Test.java", "*Tests.java", "*Test.kt", "*Tests.cs", "*_spec.rb", "*_test.rb",
+}
+
+// testDirs are directory names that only contain tests
+var testDirs = map[string]bool{"test": true, "tests": true, "__tests__": true, "spec": true, "testdata": true}
+
+// isTestFile reports whether a file is a test by its name or location
+func isTestFile(file string) bool {
+	slashed := filepath.ToSlash(file)
+	name := path.Base(slashed)
+	for _, pattern := range testNames {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	for _, dir := range strings.Split(path.Dir(slashed), "/") {
+		if testDirs[strings.ToLower(dir)] {
+			return true
+		}
+	}
+	return false
+}
+
+// encodingOf describes the encoding of validated file content
+func encodingOf(content string) string {
+	if content == "" {
+		return ""
+	}
+	if strings.HasPrefix(content, "\ufeff") {
+		return "utf-8-bom"
+	}
+	return "utf-8"
+}
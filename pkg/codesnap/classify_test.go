@@ -0,0 +1,118 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLanguageFor(t *testing.T) {
+	for _, tc := range []struct {
+		file, want string
+	}{
+		{"main.go", "go"},
+		{"src/App.TSX", "tsx"},
+		{"lib/util.mjs", "javascript"},
+		{"build.gradle", "groovy"},
+		{"notes.txt", ""},
+		{"Makefile", ""},
+	} {
+		if got := languageFor(tc.file); got != tc.want {
+			t.Errorf("languageFor(%s) = %q, want %q", tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	for _, tc := range []struct {
+		file, content string
+		want          bool
+	}{
+		{"api.pb.go", "package api\n", true},
+		{"web/app.min.js", "var a=1", true},
+		{"go.sum", "", true},
+		{"model.go", "// Code generated by sqlc. DO NOT EDIT.\npackage model\n", true},
+		{"schema.ts", "/* @generated */\n", true},
+		{"late.go", strings.Repeat("\n", 1024) + "// Code generated by hand. DO NOT EDIT.\n", false},
+		{"main.go", "package main\n", false},
+	} {
+		if got := isGenerated(tc.file, tc.content); got != tc.want {
+			t.Errorf("isGenerated(%s) = %v, want %v", tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		want bool
+	}{
+		{"codesnap_test.go", true},
+		{"pkg/test_api.py", true},
+		{"web/app.spec.ts", true},
+		{"src/FooTest.java", true},
+		{"spec/models/user_spec.rb", true},
+		{"Tests/helpers.cs", true},
+		{"src/__tests__/app.js", true},
+		{"contest.go", false},
+		{"src/latest/app.go", false},
+	} {
+		if got := isTestFile(tc.file); got != tc.want {
+			t.Errorf("isTestFile(%s) = %v, want %v", tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestLanguageOf(t *testing.T) {
+	for _, tc := range []struct {
+		file, content, want string
+	}{
+		{"main.go", "#!/bin/sh\n", "go"},
+		{"Makefile", "all:\n", "makefile"},
+		{"src/GNUmakefile", "", "makefile"},
+		{"Dockerfile.dev", "FROM alpine\n", "dockerfile"},
+		{"Containerfile", "FROM alpine\n", "dockerfile"},
+		{"Jenkinsfile", "pipeline {}\n", "groovy"},
+		{"BUILD", "go_library()\n", "python"},
+		{"scripts/build", "#!/bin/sh\nmake\n", "bash"},
+		{"bin/tool", "#!/usr/bin/env python3\n", "python"},
+		{"bin/tool", "#!/usr/local/bin/node\n", "javascript"},
+		{"bin/tool", "#!/usr/bin/env -S deno run --allow-net\n", "typescript"},
+		{"bin/tool", "#!/usr/bin/env VAR=1 ruby\n", "ruby"},
+		{"bin/tool", "#!/usr/bin/perl5.36 -w\n", "perl"},
+		{"bin/tool", "#! /bin/bash\n", "bash"},
+		{"bin/tool", "#!/usr/bin/env\n", ""},
+		{"bin/tool", "#!/opt/unknown\n", ""},
+		{"bin/tool", "echo hi\n#!/bin/sh\n", ""},
+		{"notes", "", ""},
+	} {
+		if got := languageOf(tc.file, tc.content); got != tc.want {
+			t.Errorf("languageOf(%s, %q) = %q, want %q", tc.file, tc.content, got, tc.want)
+		}
+	}
+}
+
+func TestLanguageFence(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":   "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"Dockerfile.dev": "FROM alpine\n",
+		"bin/deploy":     "#!/usr/bin/env bash\necho deploy\n",
+		"notes":          "plain\n",
+	})
+	for _, tc := range []struct {
+		format string
+		want   []string
+	}{
+		{"markdown", []string{"## Dockerfile.dev\n\n```dockerfile\n", "## bin/deploy\n\n```bash\n", "## notes\n\n```\nplain\n"}},
+		{"json", []string{`"path": "Dockerfile.dev",`, `"language": "dockerfile"`, `"language": "bash"`}},
+	} {
+		r := runCodesnap(t, dir, "--format", tc.format, "--stdout", "-q")
+		if r.code != 0 {
+			t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(r.stdout, s) {
+				t.Errorf("%s output lacks %q, got:\n%s", tc.format, s, r.stdout)
+			}
+		}
+	}
+}
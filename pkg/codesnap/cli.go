@@ -0,0 +1,1183 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stringList is a flag that can be given several times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func printHelp() {
+	helpText := `
+CodeSnap - Copy your code structure to clipboard
+
+Usage: 
+    codesnap [options]
+    codesnap snap [options]
+    codesnap tree [options]
+    codesnap init [-c PATH]
+    codesnap help [COMMAND]
+    codesnap pick [options]
+    codesnap preview [options]
+    codesnap run NAME [options]
+    codesnap metrics [-c PATH]
+    codesnap serve [--addr HOST:PORT] [options]
+    codesnap whatchanged [--against REV:PATH]
+    codesnap render [--only GLOB] [options]
+    codesnap import FILE [-c PATH]
+    codesnap stats [--json] [options]
+    codesnap estimate [--json] [options]
+    codesnap audit [--json] [options]
+    codesnap config validate [-c PATH] [--profile NAME]
+
+Commands:
+    snap                Collect the configured files into a snapshot (the default
+                        when no command is given)
+    tree                Copy the folder structure tree, the same as -t
+    init                Create codesnap.yml (or -c PATH), with the setup wizard on a
+                        terminal
+    help COMMAND        Show the usage and the options of a command; the same as
+                        codesnap COMMAND --help
+    pick                Choose files interactively with fzf, then snapshot them
+    preview             Show the snapshot in $PAGER without copying or saving it
+    run NAME            Run with the options of the named pipeline from the config
+    metrics             Show the local usage metrics (enable with metrics: true)
+    whatchanged         List the files entering or leaving the selection since an
+                        earlier config version (default: HEAD:codesnap.yml)
+    serve               Serve the snapshot files over an OpenAI-compatible /v1/files API
+    render              Re-render the files of the last run without reading them
+                        again, e.g. a subset with --only or in another --format
+    import FILE         Create codesnap.yml (or -c PATH) from the file selection of a
+                        repomix.config.json or .gitingest file
+    stats               Show the files, lines of code and bytes per language of the
+                        configured sources
+    estimate            Size the configured files from their metadata alone: files,
+                        bytes, estimated tokens and the largest files, without
+                        reading them
+    audit               Check the files a snapshot would include for secrets,
+                        personal data, internal hosts and addresses, and
+                        proprietary markers, without generating or copying it
+    config validate     Check the config for unknown keys, invalid values, missing
+                        folders and files, and ignore rules that match nothing
+
+Options:
+    -h, --help          Show this help message
+    -c, --config PATH   Specify path to config file (default: codesnap.yml in current directory)
+    --profile NAME      Use the named profile from the config's profiles section
+    -p, --print         Print the collected content to terminal
+    -q, --quiet         Print nothing but errors (on stderr); see the exit codes below
+    -o, --output        Save content to a timestamped text file
+    -O PATH             Write the snapshot to PATH instead of the clipboard; a
+                        named pipe (FIFO) is streamed into as files are read
+    -l, --log           Save log of processed files to a log file
+    -t, --tree          Generate and copy folder structure tree
+    --list, --dry-run   List the files that would be included with their sizes, and
+                        the rule that excludes each other file, without reading
+                        any file or touching the clipboard
+    --with-tree         Start the snapshot with the folder structure tree, so
+                        structure and contents come in one payload
+    --no-tree           Leave the tree out even if include_tree is set
+    --hidden            Include every dotfile and dot-directory, even .git, .idea and
+                        .vscode (overrides include_hidden)
+    --format FMT        Snapshot format: text (default), markdown, json or chunks
+                        (JSON lines of --chunk-tokens, default 512, for embedding)
+    --chunk-overlap N   Estimated tokens consecutive chunks of --format chunks share
+    --template FILE     Lay out the snapshot with a Go text/template instead of the
+                        format's layout (overrides template: in the config)
+    --paths STYLE       Path separators in headers and the tree: posix (default,
+                        forward slashes) or native
+    --note TEXT         Append a note for the reader to the end of the snapshot
+                        (repeatable)
+    --tokens            List the estimated tokens of every file in the summary
+                        and the log, and print the snapshot's total
+    --chunk-tokens N    Save the snapshot as numbered parts of at most ~N tokens each,
+                        headed "Part i/n" (-O PATH names them PATH.partI.ext)
+    --stdout            Write the snapshot to stdout, e.g. to pipe it into another
+                        tool; all other output goes to stderr
+    --sink NAME         Send the snapshot to the named sink command from the config
+                        instead of the clipboard
+    --to LIST           Send the snapshot to each of these targets instead: clipboard,
+                        stdout, file=PATH and sink=NAME, e.g.
+                        --to clipboard,file=out.md,stdout
+    --all-configs       Collect every codesnap.yml below the current directory, each with
+                        its own settings, into one snapshot labeled by config
+    --split-by folder   Write one snapshot per configured folder and an index into
+                        a directory (-O DIR, default: codesnap_<timestamp>); with
+                        --all-configs, --split-by config writes one per config
+    --watch             Regenerate the snapshot (clipboard or output file) whenever
+                        the configured files or the config change
+    --changed REF       Only include files changed since the git ref REF (committed,
+                        staged, unstaged and untracked), e.g. --changed main
+    --exec CMD          Run CMD in the config directory and include its output (the
+                        last 64 KB) in the snapshot, e.g. failing tests (repeatable)
+    --diff-hunks REF    Only include the hunks changed since the git ref REF (untracked
+                        files in full), e.g. --diff-hunks HEAD
+    --diff-context N    Lines of context around each hunk (default: 3)
+    --staged            Only include files staged in git, with their staged content
+    --go-package PKG    Include the Go packages matching PKG (e.g. ./internal/auth/...)
+                        and the packages of the module they import, instead of the
+                        configured folders and files (repeatable)
+    --strip-comments    Remove line and block comments from source files (Go, JS/TS,
+                        Python, C-style and others) to shrink the snapshot
+    --exclude-licenses LIST
+                        Leave out files whose license header names one of the
+                        comma separated licenses, e.g. GPL-3.0,AGPL-3.0
+    --incremental       Only include files changed since the last incremental run
+    --graph             Append a dependency graph of the included files
+    --graph-format FMT  Graph syntax: mermaid (default) or dot
+    --encrypt TOOL:RCPT Encrypt the file saved with -O, -o or --safe to an age
+                        (age:age1...) or gpg (gpg:KEY-ID) recipient
+    --no-redact         Keep API keys, tokens and passwords instead of replacing them
+                        with [REDACTED] (overrides redact_secrets)
+    --safe              For shared machines: write the snapshot into a new private
+                        directory (0600) instead of the clipboard, redact secrets
+                        and leave out credential files such as .env and *.pem
+    --workers N         Read N files concurrently instead of adapting the number to
+                        the storage (overrides workers: in the config)
+    --no-cache          Read every file again instead of using the read_cache of
+                        unchanged files
+    --strict-utf8       Skip files that are not valid UTF-8 instead of transcoding
+                        Latin-1, Windows-1252 and UTF-16 files
+    --max-tokens N      Leave out files, evenly across directories, so the file
+                        contents fit about N estimated tokens (overrides max_tokens;
+                        also --max-total-tokens)
+    --max-bytes SIZE    Leave out files so the file contents fit SIZE, e.g. 800KB
+                        (overrides max_bytes)
+    --drop-policy P     Which files go to fit the budget: even (default),
+                        largest-first, oldest-first or by-weight (drop_weights)
+    --max-file-size N   Include at most N (e.g. 512KB) of any file, overriding
+                        max_file_size; large_files: skip leaves such files out
+    --order KEY         Order the files by config-order (default), path, size, mtime
+                        or tokens; -KEY reverses it (overrides order)
+    --line-numbers      Prefix every line of the included files with its number,
+                        e.g. "  12 | ", so answers can refer to exact lines
+    --symbols           Append an index of the exported functions and types and the
+                        files defining them (Go via go/ast, others via ctags)
+    --env               Append the OS and the versions of the project's tools, from
+                        the environment commands or detected from its files
+    --list-binaries     Append the skipped binary files with their sizes and MIME
+                        types, so the snapshot shows they exist
+    -v, --version       Show version number
+    --anonymize         Replace configured identifiers with stable pseudonyms
+    --anonymize-seed S  Seed for the pseudonyms (overrides anonymize.seed)
+    --anonymize-map F   Write the pseudonym mapping as JSON to file F
+    --summary-json      Print a JSON summary of the run as the last line on stderr
+    --lang CODE         Language for messages (default: from LANG, e.g. de, es)
+    --addr HOST:PORT    Address for codesnap serve (default: 127.0.0.1:8765)
+    --against REV:PATH  Config to compare with in codesnap whatchanged: a git
+                        revision and path, or a file
+    --only GLOB         Files of the last run to include in codesnap render,
+                        relative to the config (repeatable)
+    --json              Print the report of codesnap stats, estimate or audit as JSON
+
+Exit codes:
+    0                   The snapshot was delivered
+    1                   Invalid config or options, or another error
+    2                   Nothing was collected (no file matched, changed or was readable)
+    3                   No clipboard backend took the snapshot
+    4                   codesnap audit found forbidden content
+`
+	fmt.Println(helpText)
+}
+
+// Exit codes of codesnap
+const (
+	exitError            = 1 // invalid config or options, or a failed run
+	exitNothingCollected = 2 // no file matched, changed or could be read
+	exitClipboard        = 3 // no clipboard backend took the snapshot
+	exitAuditFailed      = 4 // codesnap audit found forbidden content
+)
+
+// exitCode is the exit code for a run that failed with err
+func exitCode(err error) int {
+	var nothing nothingCollected
+	if errors.As(err, &nothing) {
+		return exitNothingCollected
+	}
+	return exitError
+}
+
+// Main runs the codesnap command line tool with the arguments in os.Args
+func Main() {
+	// Until --lang is parsed, messages follow the locale
+	setLanguage("")
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "help" {
+		showCommandHelp(args[1:])
+		return
+	}
+
+	// A leading subcommand is consumed before the flags are parsed; without
+	// one, codesnap runs snap
+	cmd, _ := findSubcommand("snap")
+	named := false
+	if len(args) > 0 {
+		if sub, ok := findSubcommand(args[0]); ok {
+			cmd, named, args = sub, true, args[1:]
+		}
+	}
+	inv := &invocation{start: time.Now(), command: cmd.name}
+	inv.flags = newFlagSet(cmd, &inv.options)
+
+	// codesnap NAME --help needs none of the arguments of the command
+	if wantsHelp(args) {
+		if named {
+			printCommandHelp(cmd, inv.flags)
+		} else {
+			printHelp()
+		}
+		return
+	}
+	cmd.run(inv, args)
+}
+
+// showCommandHelp prints the help of codesnap help [NAME]
+func showCommandHelp(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		printHelp()
+		return
+	}
+	cmd, ok := findSubcommand(args[0])
+	if !ok {
+		fmt.Printf(T("Error: unknown command %q\n"), args[0])
+		os.Exit(exitError)
+	}
+	printCommandHelp(cmd, newFlagSet(cmd, &options{}))
+}
+
+// options are the values of the command line flags
+type options struct {
+	configPath, profile, lang            string
+	quiet, showHelp, showVersion         bool
+	printContent, saveOutput, logOutput  bool
+	outputTo, sinkName, toTargets        string
+	toStdout, safe, summaryJSON          bool
+	encryptTo                            string
+	showTree, withTree, noTree           bool
+	hidden                               bool
+	showGraph, showSymbols, listBinaries bool
+	lineNumbers, showTokens, showEnv     bool
+	maxTokens, workers                   int
+	maxBytes, maxFileSize                string
+	order, dropPolicy                    string
+	noCache, strictUTF8, noRedact        bool
+	stripComments, incremental, staged   bool
+	graphFormat, format, templatePath    string
+	pathStyle                            string
+	anonymize                            bool
+	anonymizeSeed, anonymizeMap          string
+	notes, execs, goPackages             stringList
+	excludeLicenses, only                stringList
+	changedSince, diffHunks              string
+	diffContext                          int
+	chunkTokens, chunkOverlap            int
+	against, serveAddr                   string
+	json, dryRun                         bool
+	allConfigs, watch                    bool
+	splitBy                              string
+}
+
+// define defines every flag of codesnap on fs, with its value in o. The flag
+// set of a subcommand takes the flags it has from these.
+func (o *options) define(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "c", "", "Path to config file")
+	fs.StringVar(&o.profile, "profile", "", "Use the named profile of the config")
+	fs.BoolVar(&o.printContent, "p", false, "Print the collected content to terminal")
+	fs.BoolVar(&o.saveOutput, "o", false, "Save the content to a text file")
+	fs.StringVar(&o.outputTo, "O", "", "Write the snapshot to this file or named pipe instead of the clipboard")
+	fs.BoolVar(&o.logOutput, "l", false, "Save log of processed files to a log file")
+	fs.BoolVar(&o.showVersion, "v", false, "Show version number")
+	fs.BoolVar(&o.showHelp, "h", false, "Show help message")
+	fs.BoolVar(&o.showHelp, "help", false, "Show help message")
+	fs.BoolVar(&o.quiet, "q", false, "Print nothing but errors")
+	fs.BoolVar(&o.quiet, "quiet", false, "Print nothing but errors")
+	fs.BoolVar(&o.showTree, "t", false, "Generate and copy folder structure tree")
+	fs.BoolVar(&o.withTree, "with-tree", false, "Start the snapshot with the folder structure tree")
+	fs.BoolVar(&o.hidden, "hidden", false, "Include all dotfiles and dot-directories, .git too (overrides include_hidden)")
+	fs.BoolVar(&o.noTree, "no-tree", false, "Leave the tree out of the snapshot (overrides include_tree)")
+	fs.BoolVar(&o.showGraph, "graph", false, "Append a dependency graph of the included files")
+	fs.BoolVar(&o.showSymbols, "symbols", false, "Append an index of the exported symbols of the included files")
+	fs.BoolVar(&o.listBinaries, "list-binaries", false, "Append the paths, sizes and MIME types of the skipped binary files")
+	fs.BoolVar(&o.lineNumbers, "line-numbers", false, "Prefix every line of the included files with its number")
+	fs.IntVar(&o.maxTokens, "max-tokens", 0, "Leave out files, evenly across directories, to fit this many estimated tokens (overrides max_tokens)")
+	fs.IntVar(&o.maxTokens, "max-total-tokens", 0, "Same as --max-tokens")
+	fs.StringVar(&o.maxBytes, "max-bytes", "", "Leave out files so the file contents fit this size, e.g. 800KB (overrides max_bytes)")
+	fs.StringVar(&o.order, "order", "", "Order of the files: config-order, path, size, mtime or tokens, - reverses (overrides order)")
+	fs.StringVar(&o.dropPolicy, "drop-policy", "", "Which files to leave out for the budget: even, largest-first, oldest-first or by-weight (overrides drop_policy)")
+	fs.StringVar(&o.maxFileSize, "max-file-size", "", "Cap on the size of a single file, e.g. 512KB (overrides max_file_size)")
+	fs.IntVar(&o.workers, "workers", 0, "Number of files read concurrently (default: adapts to the storage)")
+	fs.BoolVar(&o.noCache, "no-cache", false, "Read every file again, ignoring read_cache")
+	fs.BoolVar(&o.strictUTF8, "strict-utf8", false, "Skip files that are not UTF-8 instead of transcoding them")
+	fs.StringVar(&o.encryptTo, "encrypt", "", "Encrypt the saved snapshot to age:RECIPIENT or gpg:RECIPIENT")
+	fs.BoolVar(&o.noRedact, "no-redact", false, "Keep secrets in the snapshot instead of replacing them with [REDACTED]")
+	fs.BoolVar(&o.safe, "safe", false, "For shared machines: no clipboard, secrets redacted, credential files left out, private output")
+	fs.StringVar(&o.graphFormat, "graph-format", "mermaid", "Dependency graph syntax: mermaid or dot")
+	fs.BoolVar(&o.anonymize, "anonymize", false, "Replace configured identifiers with stable pseudonyms")
+	fs.StringVar(&o.anonymizeSeed, "anonymize-seed", "", "Seed for the pseudonyms")
+	fs.StringVar(&o.anonymizeMap, "anonymize-map", "", "Write the pseudonym mapping as JSON to this file")
+	fs.BoolVar(&o.summaryJSON, "summary-json", false, "Print a JSON summary of the run as the last line on stderr")
+	fs.StringVar(&o.format, "format", "text", "Snapshot format: text, markdown, json or chunks")
+	fs.StringVar(&o.templatePath, "template", "", "Go text/template file that lays out the snapshot")
+	fs.StringVar(&o.lang, "lang", "", "Language for messages (default: from LANG)")
+	fs.BoolVar(&o.incremental, "incremental", false, "Only include files changed since the last incremental run")
+	fs.BoolVar(&o.showTokens, "tokens", false, "List the estimated tokens of every file in the summary and the log")
+	fs.StringVar(&o.pathStyle, "paths", "posix", "Path separators in the snapshot: posix or native")
+	fs.BoolVar(&o.showEnv, "env", false, "Append the OS and the versions of the project's tools")
+	fs.Var(&o.execs, "exec", "Run a command and include its output in the snapshot (repeatable)")
+	fs.Var(&o.goPackages, "go-package", "Include these Go packages and their in-module imports instead of the folders (repeatable)")
+	fs.Var(&o.excludeLicenses, "exclude-licenses", "Leave out files whose header names one of these licenses, e.g. GPL-3.0,AGPL-3.0")
+	fs.Var(&o.notes, "note", "Append a note to the snapshot (repeatable)")
+	fs.StringVar(&o.against, "against", "", "Config version to compare with for codesnap whatchanged, e.g. HEAD~1:codesnap.yml")
+	fs.BoolVar(&o.json, "json", false, "Print the report of codesnap stats, estimate or audit as JSON")
+	fs.BoolVar(&o.dryRun, "list", false, "List the files that would be included, and why the others are not, without reading them")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Same as --list")
+	fs.StringVar(&o.serveAddr, "addr", defaultServeAddr, "Address to listen on for codesnap serve")
+	fs.StringVar(&o.changedSince, "changed", "", "Only include files changed since this git ref, e.g. main")
+	fs.StringVar(&o.diffHunks, "diff-hunks", "", "Only include the hunks changed since this git ref, e.g. HEAD")
+	fs.IntVar(&o.diffContext, "diff-context", 3, "Lines of context around the hunks of --diff-hunks")
+	fs.BoolVar(&o.staged, "staged", false, "Only include files staged in git, as staged")
+	fs.BoolVar(&o.stripComments, "strip-comments", false, "Remove comments from source files before they are included")
+	fs.IntVar(&o.chunkTokens, "chunk-tokens", 0, "Split the snapshot into numbered parts of at most this many estimated tokens")
+	fs.IntVar(&o.chunkOverlap, "chunk-overlap", 0, "Estimated tokens consecutive chunks of --format chunks share")
+	fs.BoolVar(&o.toStdout, "stdout", false, "Write the snapshot to stdout and all other output to stderr")
+	fs.StringVar(&o.sinkName, "sink", "", "Send the snapshot to the named sink from the config instead of the clipboard")
+	fs.StringVar(&o.toTargets, "to", "", "Send the snapshot to each of these targets: clipboard, stdout, file=PATH, sink=NAME")
+	fs.BoolVar(&o.allConfigs, "all-configs", false, "Collect every codesnap.yml below the current directory into one snapshot")
+	fs.StringVar(&o.splitBy, "split-by", "", "Write one output file per configured folder (folder) and an index")
+	fs.BoolVar(&o.watch, "watch", false, "Regenerate the snapshot whenever the configured files change")
+	fs.Var(&o.only, "only", "Files of the last run to include in codesnap render (repeatable)")
+}
+
+// newFlagSet returns the flag set of cmd with the flags it has, their values
+// in o. Every subcommand has -h, and --quiet along with -q.
+func newFlagSet(cmd subcommand, o *options) *flag.FlagSet {
+	all := flag.NewFlagSet("codesnap", flag.ContinueOnError)
+	o.define(all)
+	has := map[string]bool{"h": true, "help": true}
+	for _, name := range cmd.flags {
+		has[name] = true
+	}
+	has["quiet"] = has["q"]
+
+	// Parse errors are printed like every other error
+	fs := flag.NewFlagSet("codesnap "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	all.VisitAll(func(f *flag.Flag) {
+		if has[f.Name] {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	return fs
+}
+
+// invocation is a run of the command line tool: a subcommand, the values of
+// its flags and where its output goes
+type invocation struct {
+	options
+	start      time.Time
+	command    string         // the subcommand
+	flags      *flag.FlagSet  // the flags of the subcommand
+	pipeline   string         // the pipeline of codesnap run
+	targets    []outputTarget // from --to
+	stdout     *os.File       // the snapshot's stdout, which --stdout keeps for it alone
+	errOut     io.Writer      // where errors go; stderr with --quiet
+	contentOut *os.File       // where -p prints the content, also with --quiet
+	configs    []string       // the configs --all-configs collects
+	output     snapshotOutput // what the snapshot is streamed into, if anything
+	pipe       bool           // whether -O is a named pipe
+}
+
+// parse parses the flags of the subcommand in args. With presets, the
+// pipeline and the flags of the config are defaults for the flags not
+// given, so they are applied before any flag is read.
+func (inv *invocation) parse(args []string, presets bool) {
+	if err := inv.flags.Parse(args); err != nil {
+		fmt.Printf(T("Error: %v\n"), err)
+		os.Exit(exitError)
+	}
+	if presets && !inv.showVersion {
+		opts, err := readConfigOptions(inv.configPath, inv.profile)
+		if err != nil {
+			fmt.Printf(T("Error: %v\n"), err)
+			os.Exit(exitError)
+		}
+		// A pipeline is applied first, so its options win over the flags
+		// of the profile
+		if inv.pipeline != "" {
+			preset, ok := opts.Pipelines[inv.pipeline]
+			if !ok {
+				fmt.Printf(T("Error: unknown pipeline %q (available: %s)\n"), inv.pipeline, presetNames(opts.Pipelines))
+				os.Exit(exitError)
+			}
+			if err := preset.apply(inv.flags, inv.flags); err != nil {
+				fmt.Printf(T("Error: pipelines.%s: %v\n"), inv.pipeline, err)
+				os.Exit(exitError)
+			}
+		}
+		// The flags of the config are for every subcommand that has them
+		known := flag.NewFlagSet("codesnap", flag.ContinueOnError)
+		new(options).define(known)
+		if err := opts.Flags.apply(inv.flags, known); err != nil {
+			fmt.Printf(T("Error: flags: %v\n"), err)
+			os.Exit(exitError)
+		}
+	}
+	setLanguage(inv.lang)
+
+	// With --stdout, only the snapshot goes to stdout; everything printed
+	// along the way, including hook output, goes to stderr
+	targets, err := parseTargets(inv.toTargets)
+	if err != nil {
+		fmt.Printf(T("Error: %v\n"), err)
+		os.Exit(exitError)
+	}
+	inv.targets, inv.stdout = targets, os.Stdout
+	if inv.toStdout || hasStdout(targets) {
+		os.Stdout = os.Stderr
+	}
+	inv.errOut, inv.contentOut = os.Stdout, os.Stdout
+}
+
+// silence prints nothing but errors, on stderr, for --quiet, so the exit
+// code tells what happened
+func (inv *invocation) silence() {
+	if !inv.quiet {
+		return
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		fmt.Printf(T("Error: %v\n"), err)
+		os.Exit(exitError)
+	}
+	inv.errOut, os.Stdout = os.Stderr, devNull
+}
+
+// fail prints the error err and exits with its exit code
+func (inv *invocation) fail(err error) {
+	fmt.Fprintf(inv.errOut, T("Error: %v\n"), err)
+	os.Exit(exitCode(err))
+}
+
+// usageError prints the message of an invalid combination of options and
+// exits
+func (inv *invocation) usageError(format string, args ...interface{}) {
+	fmt.Fprintf(inv.errOut, format, args...)
+	os.Exit(exitError)
+}
+
+// snapCommand collects the configured files into a snapshot and delivers it
+func snapCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	if inv.showVersion {
+		fmt.Printf(T("CodeSnap version %s\n"), version)
+		return
+	}
+	inv.snapshot(nil)
+}
+
+// treeCommand delivers the folder structure tree, as -t does
+func treeCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	inv.showTree = true
+	inv.snapshot(nil)
+}
+
+// pickCommand snapshots the files chosen with fzf
+func pickCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	inv.snapshot(func(cs *CodeSnap) (string, error) {
+		selected, err := cs.pickFiles(cs.gatherFiles())
+		if err != nil {
+			return "", err
+		}
+		return cs.collectFiles(selected)
+	})
+}
+
+// renderCommand re-renders the files of the last run
+func renderCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	inv.snapshot(func(cs *CodeSnap) (string, error) {
+		return cs.renderLastRun(inv.only)
+	})
+}
+
+// pipelineCommand snapshots with the options of the pipeline named by the
+// first argument
+func pipelineCommand(inv *invocation, args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Println(T("Error: run requires a pipeline name, e.g. codesnap run review"))
+		os.Exit(exitError)
+	}
+	inv.pipeline = args[0]
+	inv.parse(args[1:], true)
+	inv.snapshot(nil)
+}
+
+// previewCommand shows the snapshot in the pager, its summary first
+func previewCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	inv.silence()
+	cs := inv.codeSnap()
+	cs.summaryFirst = true
+	anon := inv.prepare(cs)
+	content, size, tokens := inv.collect(cs, anon, nil)
+	if err := showInPager(content); err != nil {
+		inv.fail(err)
+	}
+	if err := cs.recordMetrics(inv.command, inv.pipeline, size, tokens, time.Since(inv.start)); err != nil {
+		fmt.Printf(T("Warning: %v\n"), err)
+	}
+}
+
+// initCommand creates the config, with the setup wizard on a terminal
+func initCommand(inv *invocation, args []string) {
+	inv.parse(args, false)
+	cs, err := newCodeSnap(inv.configPath, "")
+	if err != nil {
+		inv.fail(err)
+	}
+	if _, err := os.Stat(cs.configPath); err == nil {
+		fmt.Printf(T("Error: %s already exists\n"), cs.configPath)
+		os.Exit(exitError)
+	}
+	if err := cs.findOrCreateConfig(); err != nil {
+		inv.fail(err)
+	}
+}
+
+// importCommand creates the config from the repomix or gitingest config named
+// by the first argument
+func importCommand(inv *invocation, args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Println(T("Error: import requires a config to convert, e.g. codesnap import repomix.config.json"))
+		os.Exit(exitError)
+	}
+	from := args[0]
+	inv.parse(args[1:], false)
+	path := inv.configPath
+	if path == "" {
+		path = "codesnap.yml"
+	}
+	unsupported, err := importConfig(from, path)
+	if err != nil {
+		inv.fail(err)
+	}
+	fmt.Printf(T("Created %s from %s\n"), path, from)
+	for _, p := range unsupported {
+		fmt.Printf(T("Warning: pattern %q has no codesnap equivalent and was left out\n"), p)
+	}
+}
+
+// metricsCommand shows the local usage metrics
+func metricsCommand(inv *invocation, args []string) {
+	inv.parse(args, false)
+	if err := showMetrics(inv.configPath); err != nil {
+		inv.fail(err)
+	}
+}
+
+// configCommand runs the action of codesnap config, of which there is validate
+func configCommand(inv *invocation, args []string) {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Println(T("Error: config requires an action, e.g. codesnap config validate"))
+		os.Exit(exitError)
+	}
+	inv.parse(args[1:], false)
+	inv.silence()
+	cs, err := newCodeSnap(inv.configPath, inv.profile)
+	if err != nil {
+		inv.fail(err)
+	}
+	cs.quiet = true
+	valid, err := cs.showValidation(inv.errOut)
+	if err != nil {
+		inv.fail(err)
+	}
+	if !valid {
+		os.Exit(exitError)
+	}
+}
+
+// statsCommand shows the files, lines and bytes per language
+func statsCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	cs := inv.codeSnap()
+	cs.quiet = true
+	if err := cs.showStats(inv.json); err != nil {
+		inv.fail(err)
+	}
+}
+
+// estimateCommand sizes the configured files from their metadata
+func estimateCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	cs := inv.codeSnap()
+	cs.quiet = true
+	if err := cs.showEstimate(inv.json); err != nil {
+		inv.fail(err)
+	}
+}
+
+// auditCommand checks the files a snapshot would include and exits with
+// exitAuditFailed when it finds forbidden content
+func auditCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	inv.silence()
+	cs := inv.codeSnap()
+	cs.quiet = true
+	passed, err := cs.showAudit(inv.json)
+	if err != nil {
+		inv.fail(err)
+	}
+	if !passed {
+		os.Exit(exitAuditFailed)
+	}
+}
+
+// whatChangedCommand lists the files entering or leaving the selection since
+// an earlier config version
+func whatChangedCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	inv.silence()
+	if err := inv.codeSnap().whatChanged(inv.against); err != nil {
+		inv.fail(err)
+	}
+}
+
+// serveCommand serves the snapshot files over HTTP
+func serveCommand(inv *invocation, args []string) {
+	inv.parse(args, true)
+	inv.silence()
+	cs := inv.codeSnap()
+	var transform func(string) string
+	if inv.anonymize {
+		a, err := newAnonymizer(cs.config.Anonymize, inv.anonymizeSeed)
+		if err != nil {
+			inv.fail(err)
+		}
+		transform = a.apply
+	}
+	if err := cs.serve(inv.serveAddr, transform); err != nil {
+		inv.fail(err)
+	}
+}
+
+// codeSnap loads the config and applies the flags to it
+func (inv *invocation) codeSnap() *CodeSnap {
+	// With --all-configs each config found is collected by a child run
+	// with its own settings; this run combines and delivers the snapshots
+	var cs *CodeSnap
+	var err error
+	if inv.allConfigs {
+		if inv.configPath != "" || inv.watch || inv.dryRun || inv.format == "chunks" {
+			inv.usageError("%s\n", T("Error: --all-configs cannot be combined with -c, --watch, --list or --format chunks"))
+		}
+		if cs, err = newCodeSnap("", inv.profile); err == nil {
+			cs.config = &Config{}
+			inv.configs, err = findConfigs(cs.baseDir)
+		}
+	} else {
+		cs, err = NewCodeSnap(inv.configPath, inv.profile)
+	}
+	if err != nil {
+		inv.fail(err)
+	}
+
+	if inv.showGraph {
+		if inv.graphFormat != "mermaid" && inv.graphFormat != "dot" {
+			inv.usageError(T("Error: invalid graph format %q (expected mermaid or dot)\n"), inv.graphFormat)
+		}
+		cs.graphFormat = inv.graphFormat
+	}
+
+	if inv.format != "text" && inv.format != "markdown" && inv.format != "json" && inv.format != "chunks" {
+		inv.usageError(T("Error: invalid format %q (expected text, markdown, json or chunks)\n"), inv.format)
+	}
+	cs.format = inv.format
+	if inv.templatePath != "" {
+		if cs.outputTemplate, err = loadTemplate(inv.templatePath); err != nil {
+			inv.fail(err)
+		}
+	}
+	if cs.format == "chunks" {
+		// --chunk-tokens sizes the chunks instead of splitting the output
+		// into parts
+		cs.chunkTokens, cs.chunkOverlap, inv.chunkTokens = inv.chunkTokens, inv.chunkOverlap, 0
+		if cs.chunkTokens <= 0 {
+			cs.chunkTokens = defaultEmbeddingChunkTokens
+		}
+		if cs.chunkOverlap < 0 || cs.chunkOverlap >= cs.chunkTokens {
+			inv.usageError("%s\n", T("Error: --chunk-overlap must be at least 0 and less than --chunk-tokens"))
+		}
+	} else if inv.chunkOverlap != 0 {
+		inv.usageError("%s\n", T("Error: --chunk-overlap needs --format chunks"))
+	}
+	if inv.chunkTokens > 0 && cs.format == "json" {
+		inv.usageError("%s\n", T("Error: --chunk-tokens cannot be combined with --format json"))
+	}
+
+	if inv.pathStyle != "posix" && inv.pathStyle != "native" {
+		inv.usageError(T("Error: invalid paths value %q (expected posix or native)\n"), inv.pathStyle)
+	}
+	cs.pathStyle = inv.pathStyle
+	cs.symbolIndex = inv.showSymbols
+	cs.listBinaries = inv.listBinaries
+	cs.lineNumbers = inv.lineNumbers
+	if inv.workers < 0 {
+		inv.usageError("%s\n", T("Error: --workers must not be negative"))
+	}
+	if inv.workers > 0 {
+		cs.workers = inv.workers
+	}
+	cs.noReadCache = inv.noCache
+	cs.strictUTF8 = inv.strictUTF8
+	if inv.maxFileSize != "" {
+		if cs.maxFileSize, err = parseSize(inv.maxFileSize); err != nil {
+			inv.usageError(T("Error: invalid --max-file-size: %v\n"), err)
+		}
+	}
+	if inv.maxTokens < 0 {
+		inv.usageError("%s\n", T("Error: --max-tokens must not be negative"))
+	}
+	if inv.maxTokens > 0 && inv.maxBytes != "" {
+		inv.usageError("%s\n", T("Error: --max-tokens and --max-bytes cannot be combined"))
+	}
+	if inv.maxTokens > 0 {
+		cs.maxTokens, cs.maxBytes = inv.maxTokens, 0
+	}
+	if inv.maxBytes != "" {
+		if cs.maxBytes, err = parseSize(inv.maxBytes); err != nil {
+			inv.usageError(T("Error: invalid --max-bytes: %v\n"), err)
+		}
+		cs.maxTokens = 0
+	}
+	if inv.order != "" {
+		if err := validOrder(inv.order); err != nil {
+			inv.fail(err)
+		}
+		cs.config.Order = inv.order
+	}
+	switch inv.dropPolicy {
+	case "":
+	case "even", "largest-first", "oldest-first", "by-weight":
+		cs.config.DropPolicy = inv.dropPolicy
+	default:
+		inv.usageError(T("Error: invalid drop policy %q (expected even, largest-first, oldest-first or by-weight)\n"), inv.dropPolicy)
+	}
+	cs.incremental = inv.incremental
+	cs.showTokens = inv.showTokens
+	cs.savesFile = inv.saveOutput
+	cs.changedSince = inv.changedSince
+	cs.staged = inv.staged
+	cs.diffHunks = inv.diffHunks
+	cs.diffContext = inv.diffContext
+	if cs.diffContext < 0 {
+		inv.usageError("%s\n", T("Error: --diff-context must not be negative"))
+	}
+	cs.notes = inv.notes
+	cs.excludeLicenses = splitLicenses(inv.excludeLicenses)
+	if inv.hidden {
+		cs.config.IncludeHidden = &inv.hidden
+	}
+	if len(inv.goPackages) > 0 && !inv.allConfigs {
+		if cs.goFiles, err = cs.loadGoPackages(inv.goPackages); err != nil {
+			inv.fail(err)
+		}
+	}
+	if inv.stripComments {
+		// First, so that transform_cmd commands see the stripped source
+		cs.transforms = append([]Transform{stripCommentsTransform}, cs.transforms...)
+	}
+
+	if inv.encryptTo != "" {
+		if cs.encryption, err = parseEncryption(inv.encryptTo); err != nil {
+			inv.fail(err)
+		}
+	}
+
+	if inv.noRedact {
+		cs.redact = false
+	}
+
+	if inv.safe {
+		// Nothing may end up where other users of the machine can read it:
+		// the snapshot goes into a new private directory instead of the
+		// shared clipboard, and every file written is 0600
+		if inv.outputTo != "" || inv.saveOutput || len(inv.targets) > 0 {
+			inv.usageError("%s\n", T("Error: --safe writes into a private directory and cannot be combined with -O, -o or --to"))
+		}
+		restrictFileModes()
+		cs.safe = true
+		if inv.noRedact {
+			inv.usageError("%s\n", T("Error: --safe always redacts secrets and cannot be combined with --no-redact"))
+		}
+		cs.redact = true
+		if inv.sinkName == "" && !inv.toStdout {
+			ext := map[string]string{"markdown": ".md", "json": ".json", "chunks": ".jsonl"}[cs.format]
+			if ext == "" || inv.showTree {
+				ext = ".txt"
+			}
+			if inv.splitBy != "" {
+				ext = ""
+			} else if cs.encryption != nil {
+				ext += cs.encryption.ext()
+			}
+			if inv.outputTo, err = safeOutputPath(ext); err != nil {
+				inv.fail(err)
+			}
+		}
+	}
+
+	if cs.encryption != nil {
+		// Only a saved file is encrypted, and it is encrypted as a whole
+		if (inv.outputTo == "" && !inv.saveOutput) || inv.chunkTokens > 0 || inv.splitBy != "" || isNamedPipe(inv.outputTo) {
+			inv.usageError("%s\n", T("Error: --encrypt needs -O FILE, -o or --safe and cannot be combined with --chunk-tokens, --split-by or a named pipe"))
+		}
+	}
+
+	if inv.logOutput {
+		cs.logFile = fmt.Sprintf("codesnap_log_%s.txt", time.Now().Format("20060102_150405"))
+	}
+	return cs
+}
+
+// snapshot collects the snapshot with collect, or the configured files when
+// it is nil, and delivers it as the flags say
+func (inv *invocation) snapshot(collect func(cs *CodeSnap) (string, error)) {
+	if !inv.dryRun {
+		inv.silence()
+	}
+	cs := inv.codeSnap()
+
+	if inv.watch && os.Getenv(watchEnv) == "" {
+		if err := cs.watch(withoutWatchFlag(os.Args[1:]), []string{inv.outputTo}); err != nil {
+			inv.fail(err)
+		}
+		return
+	}
+	if inv.dryRun {
+		cs.quiet = true
+		if err := cs.showFileList(); err != nil {
+			inv.fail(err)
+		}
+		return
+	}
+
+	anon := inv.prepare(cs)
+	if inv.sinkName != "" && (inv.outputTo != "" || inv.chunkTokens > 0 || inv.splitBy != "") {
+		inv.usageError("%s\n", T("Error: --sink cannot be combined with -O, --chunk-tokens or --split-by"))
+	}
+	if len(inv.targets) > 0 && (inv.outputTo != "" || inv.toStdout || inv.sinkName != "" || inv.chunkTokens > 0 || inv.splitBy != "") {
+		inv.usageError("%s\n", T("Error: --to cannot be combined with -O, --stdout, --sink, --chunk-tokens or --split-by"))
+	}
+	if inv.toStdout && (inv.outputTo != "" || inv.sinkName != "" || inv.chunkTokens > 0 || inv.splitBy != "") {
+		inv.usageError("%s\n", T("Error: --stdout cannot be combined with -O, --sink, --chunk-tokens or --split-by"))
+	}
+	if inv.splitBy != "" {
+		inv.split(cs, anon)
+		return
+	}
+
+	content, size, tokens := inv.collect(cs, anon, collect)
+	inv.deliver(cs, content, size, tokens)
+	if cs.showTokens {
+		fmt.Printf(T("Estimated tokens: ~%s for the snapshot, ~%s of them file contents\n"),
+			formatCount(tokens), formatCount(cs.stats.tokens))
+	}
+	mode := inv.command
+	if inv.showTree {
+		mode = "tree"
+	}
+	inv.finish(cs, mode, size, tokens)
+}
+
+// prepare runs what comes before the snapshot is collected: the pre hook,
+// --exec and --env. It returns the anonymizer of --anonymize.
+func (inv *invocation) prepare(cs *CodeSnap) *anonymizer {
+	var anon *anonymizer
+	if inv.anonymize {
+		var err error
+		if anon, err = newAnonymizer(cs.config.Anonymize, inv.anonymizeSeed); err != nil {
+			inv.fail(err)
+		}
+	}
+
+	if err := cs.runHook(cs.preHook, hookData{Config: cs.configPath, Format: cs.format}); err != nil {
+		inv.fail(err)
+	}
+
+	if !inv.allConfigs {
+		cs.commands = cs.runCommands(inv.execs)
+		if inv.showEnv {
+			cs.environment = cs.detectEnvironment()
+		}
+	}
+	return anon
+}
+
+// split writes one file per config of --all-configs or per configured
+// folder, and an index
+func (inv *invocation) split(cs *CodeSnap, anon *anonymizer) {
+	var dir string
+	var size, tokens int
+	var err error
+	if inv.allConfigs {
+		if inv.splitBy != "config" {
+			inv.usageError(T("Error: invalid split-by value %q with --all-configs (expected config)\n"), inv.splitBy)
+		}
+		var snaps []configSnapshot
+		if snaps, err = cs.snapshotConfigs(inv.configs, childArgs(inv.flags)); err == nil {
+			dir, size, tokens, err = cs.splitByConfig(inv.outputTo, snaps, anon)
+		}
+	} else {
+		if inv.splitBy != "folder" {
+			inv.usageError(T("Error: invalid split-by value %q (expected folder)\n"), inv.splitBy)
+		}
+		if inv.showTree || inv.withTree || cs.incremental || cs.format == "chunks" {
+			inv.usageError("%s\n", T("Error: --split-by cannot be combined with -t, --with-tree, --incremental or --format chunks"))
+		}
+		dir, size, tokens, err = cs.splitByFolder(inv.outputTo, anon)
+	}
+	if err != nil {
+		inv.fail(err)
+	}
+	inv.writeMapping(anon)
+	cs.outputPath = dir
+	fmt.Printf("\n"+T("Split snapshot saved to: %s\n"), dir)
+	inv.finish(cs, "split", size, tokens)
+}
+
+// collect renders the snapshot with collect, or the configured files when
+// it is nil, and returns it with its size and estimated tokens. A named
+// pipe or stdout is written to while the snapshot is rendered and a file is
+// rendered into through a memory map, so the whole snapshot is never held
+// in memory; -p, -o, --chunk-tokens and --encrypt need the content itself.
+func (inv *invocation) collect(cs *CodeSnap, anon *anonymizer, collect func(cs *CodeSnap) (string, error)) (string, int, int) {
+	var err error
+	inv.pipe = inv.outputTo != "" && isNamedPipe(inv.outputTo)
+	if inv.outputTo != "" || inv.toStdout {
+		needContent := inv.printContent || inv.saveOutput || inv.chunkTokens > 0 || cs.encryption != nil
+		if inv.pipe && needContent {
+			inv.usageError("%s\n", T("Error: -O with a named pipe cannot be combined with -p, -o or --chunk-tokens"))
+		}
+		if !needContent {
+			var output snapshotOutput
+			switch {
+			case inv.toStdout:
+				output = &snapshotStream{file: inv.stdout}
+			case inv.pipe:
+				output, err = openStream(inv.outputTo)
+			default:
+				output, err = createMappedFile(inv.outputTo)
+			}
+			if err != nil {
+				inv.fail(err)
+			}
+			if anon != nil {
+				output = &anonymizingOutput{snapshotOutput: output, anon: anon}
+			}
+			inv.output, cs.output = output, output
+		}
+	}
+
+	if (inv.withTree || cs.config.IncludeTree) && !inv.noTree && !inv.showTree && !inv.allConfigs {
+		if cs.tree, err = cs.generateFolderStructure(); err != nil {
+			inv.fail(err)
+		}
+	}
+
+	var content string
+	switch {
+	case inv.allConfigs:
+		var snaps []configSnapshot
+		if snaps, err = cs.snapshotConfigs(inv.configs, childArgs(inv.flags)); err == nil {
+			content, err = cs.combineSnapshots(snaps)
+		}
+	case collect != nil:
+		content, err = collect(cs)
+	case inv.showTree:
+		content, err = cs.generateFolderStructure()
+	default:
+		content, err = cs.collectContent()
+	}
+
+	if inv.output != nil {
+		// The tree is not rendered through cs.output, so it is written here
+		if err == nil {
+			_, err = io.WriteString(inv.output, content)
+		}
+		if cerr := inv.output.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil && !inv.pipe && !inv.toStdout {
+			os.Remove(inv.outputTo)
+		}
+	}
+	if err != nil {
+		inv.fail(err)
+	}
+
+	if anon != nil && inv.output == nil {
+		content = anon.apply(content)
+	}
+	inv.writeMapping(anon)
+
+	if inv.output != nil {
+		size, tokens := inv.output.written()
+		return content, size, tokens
+	}
+	return content, len(content), estimateTokens(content)
+}
+
+// writeMapping writes the pseudonyms of --anonymize to --anonymize-map
+func (inv *invocation) writeMapping(anon *anonymizer) {
+	if anon == nil || inv.anonymizeMap == "" {
+		return
+	}
+	if err := anon.writeMapping(inv.anonymizeMap); err != nil {
+		inv.fail(err)
+	}
+}
+
+// deliver sends the snapshot where the flags say, by default to the
+// clipboard
+func (inv *invocation) deliver(cs *CodeSnap, content string, size, tokens int) {
+	switch {
+	case inv.chunkTokens > 0:
+		ext := ".txt"
+		if cs.format == "markdown" && !inv.showTree {
+			ext = ".md"
+		}
+		names, err := saveChunks(chunkContent(content, inv.chunkTokens, ext == ".md", filepath.Base(cs.configDir)), inv.outputTo, ext)
+		if err != nil {
+			inv.fail(err)
+		}
+		cs.outputPath = names[0]
+		fmt.Printf("\n"+T("Content saved in %d parts of at most ~%s tokens:\n"), len(names), formatCount(inv.chunkTokens))
+		for _, name := range names {
+			fmt.Printf("  %s\n", name)
+		}
+	case len(inv.targets) > 0:
+		if err := cs.deliverTo(inv.targets, content, cs.metadata(size, tokens), inv.stdout); err != nil {
+			fmt.Fprintf(inv.errOut, T("Error: %v\n"), err)
+			if errors.Is(err, errClipboardTarget) {
+				os.Exit(exitClipboard)
+			}
+			os.Exit(exitError)
+		}
+	case inv.toStdout:
+		if inv.output == nil {
+			if _, err := io.WriteString(inv.stdout, content); err != nil {
+				inv.usageError(T("Error: failed to write to stdout: %v\n"), err)
+			}
+		}
+	case inv.sinkName != "":
+		response, err := cs.sendToSink(inv.sinkName, content, cs.metadata(size, tokens))
+		if err != nil {
+			inv.fail(err)
+		}
+		cs.outputPath = response.Location
+		fmt.Printf("\n"+T("Snapshot sent to %s"), inv.sinkName)
+		if response.Location != "" {
+			fmt.Printf(": %s", response.Location)
+		}
+		fmt.Println()
+		if response.Message != "" {
+			fmt.Println(response.Message)
+		}
+	case inv.pipe:
+		cs.outputPath = inv.outputTo
+		fmt.Printf("\n"+T("Snapshot streamed to: %s\n"), inv.outputTo)
+	case inv.outputTo != "":
+		if inv.output == nil {
+			if err := cs.writeSnapshotFile(inv.outputTo, content); err != nil {
+				inv.fail(err)
+			}
+		}
+		cs.outputPath = inv.outputTo
+		fmt.Printf("\n"+T("Content saved to: %s\n"), inv.outputTo)
+	default:
+		backend, err := cs.copyWithFallback(content, cs.metadata(size, tokens))
+		switch {
+		case err == nil:
+			printCopied(backend)
+		case inv.saveOutput:
+			// The file -o saves below is what was asked for, so a machine
+			// without a clipboard does not fail the run
+			fmt.Fprintf(inv.errOut, T("Warning: copying to clipboard failed, the snapshot is only saved to a file: %v\n"), err)
+		default:
+			fmt.Fprintf(inv.errOut, T("Error copying to clipboard: %v\n"), err)
+			os.Exit(exitClipboard)
+		}
+	}
+
+	if inv.printContent {
+		fmt.Fprintf(inv.contentOut, "\n%s\n%s\n", T("Content:"), content)
+	}
+
+	if inv.saveOutput {
+		if err := cs.saveToFile(content); err != nil {
+			inv.fail(err)
+		}
+	}
+}
+
+// finish reports the time the run took and records its metrics, then runs
+// the post hook
+func (inv *invocation) finish(cs *CodeSnap, mode string, size, tokens int) {
+	elapsed := time.Since(inv.start)
+	fmt.Printf("\n"+T("Total execution time: %v\n"), elapsed)
+	if err := cs.recordMetrics(mode, inv.pipeline, size, tokens, elapsed); err != nil {
+		fmt.Printf(T("Warning: %v\n"), err)
+	}
+	cs.runPostHook(size, tokens)
+	if inv.summaryJSON {
+		printSummaryJSON(cs, size, tokens, elapsed)
+	}
+}
+
+// printSummaryJSON writes the run metrics as a single JSON object on the last
+// line of stderr, for wrapper scripts that should not parse the human output
+func printSummaryJSON(cs *CodeSnap, size, tokens int, elapsed time.Duration) {
+	summary := struct {
+		Files        int    `json:"files"`
+		Empty        int    `json:"empty"`
+		Skipped      int    `json:"skipped"`
+		Duplicates   int    `json:"duplicates"`
+		Bytes        int    `json:"bytes"`
+		Tokens       int    `json:"tokens"`
+		TokensMethod string `json:"tokens_method"`
+		Output       string `json:"output,omitempty"`
+		DurationMs   int64  `json:"duration_ms"`
+	}{
+		Files:        cs.stats.processed,
+		Empty:        cs.stats.empty,
+		Skipped:      cs.stats.skipped,
+		Duplicates:   cs.stats.duplicates,
+		Bytes:        size,
+		Tokens:       tokens,
+		TokensMethod: "heuristic",
+		Output:       cs.outputPath,
+		DurationMs:   elapsed.Milliseconds(),
+	}
+	data, _ := json.Marshal(summary)
+	fmt.Fprintln(os.Stderr, string(data))
+}
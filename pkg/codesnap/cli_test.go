@@ -0,0 +1,174 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSummaryJSON(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "snapshot.txt")
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		output string
+	}{
+		{"clipboard", nil, ""},
+		{"file", []string{"-O", out}, out},
+		{"stdout", []string{"--stdout"}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\ndedupe: true\n",
+				"a.go":         "package a\n",
+				"b/a.go":       "package a\n",
+				"empty.go":     "",
+			})
+			r := runCodesnap(t, dir, append([]string{"--summary-json"}, tc.args...)...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			lines := strings.Split(strings.TrimSpace(r.stderr), "\n")
+			var summary struct {
+				Files, Empty, Skipped, Duplicates, Bytes, Tokens int
+				TokensMethod                                     string `json:"tokens_method"`
+				Output                                           string
+			}
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+				t.Fatalf("the last line of stderr is not the summary: %v\n%s", err, r.stderr)
+			}
+			if summary.Files != 3 || summary.Empty != 1 || summary.Duplicates != 1 || summary.Bytes == 0 || summary.Tokens == 0 {
+				t.Errorf("summary = %+v, want 3 files, 1 empty and 1 duplicate", summary)
+			}
+			if summary.TokensMethod != "heuristic" || summary.Output != tc.output {
+				t.Errorf("summary = %+v, want the heuristic token count and output %q", summary, tc.output)
+			}
+		})
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	for _, tc := range []struct {
+		name, format string
+		want         string
+	}{
+		{"text", "text", "File: a.go"},
+		{"markdown", "markdown", "## a.go"},
+		{"json", "json", `"path": "a.go"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+			})
+			out := filepath.Join(t.TempDir(), "snapshot")
+			r := runCodesnap(t, dir, "--format", tc.format, "-O", out)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), tc.want) || !strings.Contains(string(content), "package a") {
+				t.Errorf("%s lacks %q, got:\n%s", out, tc.want, content)
+			}
+			if !strings.Contains(r.stdout, "Content saved to: "+out) {
+				t.Errorf("output does not name the file, got:\n%s", r.stdout)
+			}
+			if r.clipboard != "" {
+				t.Errorf("-O copied to the clipboard:\n%s", r.clipboard)
+			}
+		})
+	}
+}
+
+func TestStdout(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string // the start of stdout
+		stderr []string
+	}{
+		{"text", nil, 0, "\n\n" + strings.Repeat("=", 50) + "\nFile: a.go\n", []string{"Processing folder:", "Total execution time:"}},
+		{"json", []string{"--format", "json"}, 0, "{", []string{"Processing folder:"}},
+		{"quiet", []string{"-q"}, 0, "\n\n", nil},
+		{"with -O", []string{"-O", "out.txt"}, exitError, "", []string{"--stdout"}},
+		{"with --chunk-tokens", []string{"--chunk-tokens", "100"}, exitError, "", []string{"--stdout"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+			})
+			r := runCodesnap(t, dir, append([]string{"--stdout"}, tc.args...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if !strings.HasPrefix(r.stdout, tc.stdout) {
+				t.Errorf("stdout does not start with %q, got:\n%s", tc.stdout, r.stdout)
+			}
+			for _, s := range tc.stderr {
+				if !strings.Contains(r.stderr, s) {
+					t.Errorf("stderr lacks %q, got:\n%s", s, r.stderr)
+				}
+			}
+			if tc.stderr == nil && r.stderr != "" {
+				t.Errorf("stderr = %q, want nothing", r.stderr)
+			}
+			if r.clipboard != "" {
+				t.Errorf("--stdout copied to the clipboard:\n%s", r.clipboard)
+			}
+			if tc.code != 0 {
+				return
+			}
+			if strings.Contains(r.stdout, "Processing folder") || strings.Contains(r.stdout, "Total execution time") {
+				t.Errorf("status output on stdout:\n%s", r.stdout)
+			}
+			if tc.name == "json" && !json.Valid([]byte(r.stdout)) {
+				t.Errorf("stdout is not JSON:\n%s", r.stdout)
+			}
+		})
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	for _, tc := range []struct {
+		name, config   string
+		args           []string
+		code           int
+		stdout, stderr string // what they contain; empty means nothing is printed
+		clipboard      bool
+	}{
+		{"copied", "", nil, 0, "", "", true},
+		{"printed", "", []string{"-p"}, 0, "File: a.go\n", "", true},
+		{"saved", "", []string{"-O", "out.txt"}, 0, "", "", false},
+		{"nothing collected", "  - \"*.go\"\n", nil, exitNothingCollected, "", "no valid files were processed", false},
+		{"invalid config", "  - pattern: \"\"\n", nil, exitError, "", "ignore rule needs a pattern, older_than or owner", false},
+		{"invalid option", "", []string{"--format", "xml"}, exitError, "", `invalid format "xml"`, false},
+		{"clipboard failure", "clipboard:\n  - \"false\"\n", nil, exitClipboard, "", "clipboard", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.config,
+				"a.go":         "package a\n",
+			})
+			r := runCodesnap(t, dir, append([]string{"-q"}, tc.args...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if tc.stdout == "" && r.stdout != "" || !strings.Contains(r.stdout, tc.stdout) {
+				t.Errorf("stdout = %q, want %q", r.stdout, tc.stdout)
+			}
+			if tc.stderr == "" && r.stderr != "" || !strings.Contains(r.stderr, tc.stderr) {
+				t.Errorf("stderr = %q, want %q", r.stderr, tc.stderr)
+			}
+			if copied := r.clipboard != ""; copied != tc.clipboard {
+				t.Errorf("copied to the clipboard: %v, want %v", copied, tc.clipboard)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package codesnap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// snapshotPasteboardType is the macOS pasteboard type carrying the
+// snapshot's metadata next to its plain text
+const snapshotPasteboardType = "com.codesnap.snapshot"
+
+// snapshotMetadata describes a delivered snapshot to companion apps and sinks
+type snapshotMetadata struct {
+	Version string    `json:"version"`
+	Config  string    `json:"config"`
+	Format  string    `json:"format"`
+	Files   int       `json:"files"`
+	Bytes   int       `json:"bytes"`
+	Tokens  int       `json:"estimated_tokens"`
+	Created time.Time `json:"created"`
+}
+
+// metadata describes the snapshot about to be delivered
+func (cs *CodeSnap) metadata(size, tokens int) snapshotMetadata {
+	return snapshotMetadata{
+		Version: version,
+		Config:  cs.configPath,
+		Format:  cs.format,
+		Files:   cs.stats.processed,
+		Bytes:   size,
+		Tokens:  tokens,
+		Created: time.Now(),
+	}
+}
+
+// defaultClipboardChain is tried without a clipboard list in the config:
+// the system clipboard, then the terminal's through OSC 52. The file
+// backend is opt-in, so that a run without a clipboard fails (exit 3)
+// instead of leaving files behind.
+var defaultClipboardChain = []string{"system", "osc52"}
+
+// copyWithFallback delivers the snapshot through the first backend of the
+// clipboard chain that takes it, warning about each one that fails, and
+// returns the name of that backend. Besides system, osc52 and file, an entry
+// is a command that reads the snapshot on stdin, e.g. wl-copy.
+func (cs *CodeSnap) copyWithFallback(content string, meta snapshotMetadata) (string, error) {
+	chain := cs.config.Clipboard
+	if len(chain) == 0 {
+		chain = defaultClipboardChain
+	}
+	var errs []error
+	for _, backend := range chain {
+		if backend == "file" && cs.savesFile {
+			continue
+		}
+		var err error
+		switch backend {
+		case "system":
+			err = copyToClipboard(content, meta)
+		case "osc52":
+			err = copyOSC52(content)
+		case "file":
+			err = cs.saveToFile(content)
+		default:
+			err = cs.copyWithCommand(backend, content)
+		}
+		if err == nil {
+			return backend, nil
+		}
+		if !cs.quiet {
+			fmt.Printf(T("Warning: clipboard backend %s failed: %v\n"), backend, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", backend, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+// copyOSC52 asks the terminal to set its clipboard with an OSC 52 escape
+// sequence, which also works over SSH. Terminals do not answer, so
+// success only means the sequence was written; some cap its size.
+func copyOSC52(content string) error {
+	if runtime.GOOS == "windows" {
+		return errors.New(T("not supported on Windows"))
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(content)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		// tmux passes the sequence on to the outer terminal when wrapped
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	_, err = tty.WriteString(seq)
+	return err
+}
+
+// copyWithCommand pipes the snapshot into a clipboard command such as
+// wl-copy or xsel --clipboard --input
+func (cs *CodeSnap) copyWithCommand(command, content string) error {
+	name, _, _ := strings.Cut(strings.TrimSpace(command), " ")
+	if _, err := exec.LookPath(name); err != nil {
+		return err
+	}
+	cmd := shellCommand(command)
+	cmd.Dir = cs.configDir
+	cmd.Stdin = strings.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build darwin
+
+package codesnap
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+
+	"github.com/atotto/clipboard"
+)
+
+// pasteboardScript writes stdin as plain text and $CODESNAP_PASTEBOARD_META
+// as the snapshot type to the general pasteboard in one change, so readers
+// see both or neither
+const pasteboardScript = `ObjC.import('AppKit');
+var env = $.NSProcessInfo.processInfo.environment;
+var data = $.NSFileHandle.fileHandleWithStandardInput.readDataToEndOfFile;
+var text = $.NSString.alloc.initWithDataEncoding(data, $.NSUTF8StringEncoding);
+var pb = $.NSPasteboard.generalPasteboard;
+pb.clearContents;
+pb.setStringForType(text, 'public.utf8-plain-text');
+pb.setStringForType(env.objectForKey('CODESNAP_PASTEBOARD_META'), env.objectForKey('CODESNAP_PASTEBOARD_TYPE'));`
+
+// copyToClipboard puts the snapshot on the pasteboard as plain text, with
+// its metadata as JSON under snapshotPasteboardType. Without osascript
+// only the text is copied.
+func copyToClipboard(content string, meta snapshotMetadata) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("osascript", "-l", "JavaScript", "-e", pasteboardScript)
+	cmd.Stdin = bytes.NewReader([]byte(content))
+	cmd.Env = append(os.Environ(),
+		"CODESNAP_PASTEBOARD_META="+string(data),
+		"CODESNAP_PASTEBOARD_TYPE="+snapshotPasteboardType,
+	)
+	if err := cmd.Run(); err != nil {
+		return clipboard.WriteAll(content)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+//go:build darwin
+
+package codesnap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	meta := snapshotMetadata{Version: version, Config: "/work/codesnap.yml", Format: "text", Files: 2, Bytes: 5, Tokens: 2}
+	for _, tc := range []struct {
+		name, osascript string
+		wantMeta        bool
+	}{
+		{"with the metadata", "cat > \"$OUT/text\"\nprintf '%s' \"$CODESNAP_PASTEBOARD_META\" > \"$OUT/meta\"\nprintf '%s' \"$CODESNAP_PASTEBOARD_TYPE\" > \"$OUT/type\"\n", true},
+		{"osascript failing", "exit 1\n", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := t.TempDir()
+			t.Setenv("OUT", out)
+			fakeCommand(t, "osascript", tc.osascript)
+			if !tc.wantMeta {
+				fakeCommand(t, "pbcopy", "cat > \"$OUT/text\"\n")
+			}
+			if err := copyToClipboard("hello", meta); err != nil {
+				t.Fatal(err)
+			}
+			if text, _ := os.ReadFile(filepath.Join(out, "text")); string(text) != "hello" {
+				t.Errorf("copied %q, want hello", text)
+			}
+			data, err := os.ReadFile(filepath.Join(out, "meta"))
+			if !tc.wantMeta {
+				if err == nil {
+					t.Errorf("metadata copied without osascript: %s", data)
+				}
+				return
+			}
+			var got snapshotMetadata
+			if err := json.Unmarshal(data, &got); err != nil || got != meta {
+				t.Errorf("metadata = %s, %v; want %+v", data, err, meta)
+			}
+			if typ, _ := os.ReadFile(filepath.Join(out, "type")); string(typ) != snapshotPasteboardType {
+				t.Errorf("pasteboard type %q, want %s", typ, snapshotPasteboardType)
+			}
+		})
+	}
+}
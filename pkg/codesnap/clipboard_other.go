@@ -0,0 +1,11 @@
+//go:build !darwin
+
+package codesnap
+
+import "github.com/atotto/clipboard"
+
+// copyToClipboard puts the snapshot on the clipboard as plain text; only
+// macOS has a place for the metadata
+func copyToClipboard(content string, _ snapshotMetadata) error {
+	return clipboard.WriteAll(content)
+}
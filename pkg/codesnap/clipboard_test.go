@@ -0,0 +1,64 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDefaultClipboardChainSavesNoFile(t *testing.T) {
+	if slices.Contains(defaultClipboardChain, "file") {
+		t.Errorf("default clipboard chain %v saves a file", defaultClipboardChain)
+	}
+}
+
+func TestClipboardFailureExitCode(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: .\nclipboard:\n  - \"false\"\n",
+		"a.go":         "package a\n",
+	})
+
+	r := runCodesnap(t, dir)
+	if r.code != exitClipboard {
+		t.Errorf("exit code %d, want %d; stderr: %s", r.code, exitClipboard, r.stderr)
+	}
+	if saved, _ := filepath.Glob(filepath.Join(dir, "codesnap_*.txt")); len(saved) > 0 {
+		t.Errorf("failed clipboard saved %v", saved)
+	}
+}
+
+func TestClipboardFileBackendSkippedWithOutput(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: .\nclipboard:\n  - \"false\"\n  - file\n",
+		"a.go":         "package a\n",
+	})
+
+	r := runCodesnap(t, dir, "-o")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	if saved, _ := filepath.Glob(filepath.Join(dir, "codesnap_*.txt")); len(saved) != 1 {
+		t.Errorf("want the snapshot saved once, got %v", saved)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	cs := &CodeSnap{configPath: "/work/codesnap.yml", format: "markdown"}
+	cs.stats.processed = 3
+	before := time.Now()
+	meta := cs.metadata(120, 30)
+	if meta.Created.Before(before) || meta.Created.After(time.Now()) {
+		t.Errorf("created %v, want the current time", meta.Created)
+	}
+	meta.Created = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":"` + version + `","config":"/work/codesnap.yml","format":"markdown","files":3,"bytes":120,"estimated_tokens":30,"created":"2026-01-02T03:04:05Z"}`
+	if string(data) != want {
+		t.Errorf("metadata = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,1469 @@
+package codesnap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v2"
+)
+
+const version = "1.1.0"
+const templateConfig = `# CodeSnap Configuration File
+# Examples:
+# folders:
+#   - src           # relative to this config file
+#   - ../shared     # parent directory
+#   - utils         # project subdirectory
+#   - \\server\share\project  # Windows network share (UNC path)
+#   - ${PROJECT_ROOT}/lib  # ~, $VAR and ${VAR} are expanded in folders, files and ignore
+#   - path: src                  # the same folder from another git worktree,
+#     worktree: feature/retry    # found by branch or directory name and
+#     label: feature             # shown as feature/... in the snapshot
+#   - github.com/org/lib@v1.2.0/pkg  # a shallow checkout of a remote repository
+#   - repo: https://git.example.com/team/tool.git  # (ref: branch, tag or commit;
+#     ref: feature/x                                #  path: within the repo)
+#
+# files:
+#   - package.json  # individual files to include
+#   - config.js     # relative to this config file
+#
+# rename:           # show real paths under virtual names in headers and the tree
+#   internal/secretsvc: service-x
+#
+# hooks:            # shell commands run in this directory before and after a
+#   pre: make generate                  # snapshot; a failing pre hook aborts it
+#   post: ./notify.sh {{.Output}}       # also .Files, .Bytes, .Tokens, .Format
+#
+# include:          # only collect folder files matching one of these globs
+#   - "**/*.go"     # (files listed under files: are always included)
+#   - "**/*.proto"
+#
+# ignore:
+#   - "**/*.test.js"    # ignore test files
+#   - "**/node_modules/**"  # ignore node_modules
+#   - "**/.git/**"     # ignore git directory
+#   - "**/*.jpg"       # ignore image files
+#   - "**/*.png"       # ignore image files
+#   - "**/*.gif"       # ignore image files
+#   - "**/*.pdf"       # ignore PDF files
+#   - "**/*.exe"       # ignore executable files
+#   - "**/*.dll"       # ignore DLL files
+#   - "@archives"      # or a file group: @images, @fonts, @binaries, @source...
+#   - pattern: "vendor/**"  # rules can also filter on file metadata:
+#     older_than: 2y        # not modified for 2 years (also 6mo, 3w, 10d, 12h)
+#   - owner: root           # owned by a given user (not on Windows)
+#
+# tree_depth: 3       # maximum depth for folder structure (default: unlimited)
+# tree_max_entries: 200  # list at most 200 entries per directory in the tree
+# tree_compact: true  # show single-child directory chains as one a/b/c/ node
+# include_tree: true  # start every snapshot with the tree (opt out with --no-tree)
+# include_hidden: false  # leave out dotfiles and dot-directories; true includes
+#                     # them all (default: all but .git, .hg, .svn, .idea, .vscode)
+# follow_symlinks: true  # enter symlinked directories (links that loop are skipped)
+# workers: 16        # files read concurrently (default: adapts to the storage)
+# read_cache: true   # remember unchanged files between runs (in the user cache dir)
+#
+# environment:       # version commands for --env (default: detected from go.mod,
+#   - go version     # package.json, pyproject.toml, Cargo.toml...)
+#   - node --version
+#
+# dependency_dirs: skip      # large node_modules, site-packages, .terraform... that
+# dependency_max_entries: 200 # are not ignored: prompt (default), skip or include
+#
+# transform_cmd:     # pipe matching files through a command (stdin -> stdout)
+#   - pattern: "**/*.pb"
+#     run: protoc --decode_raw
+# transforms:        # the same with several globs and a timeout (default: 1m)
+#   - match: ["**/*.ipynb"]
+#     cmd: jupyter nbconvert --to markdown --stdin --stdout
+#     timeout: 30s
+#
+# databases:         # include the schema (tables, columns, indexes) as DDL
+#   - name: app
+#     dialect: postgres    # postgres, mysql or sqlite
+#     dsn: ${DATABASE_URL} # environment variables are expanded
+#
+# condense:          # reduce OpenAPI documents and .proto files to their
+#   - "api/**/*.yaml" # operations, messages and services
+#   - "**/*.proto"
+#
+# normalize_line_endings: true  # convert CRLF/CR to LF and drop UTF-8 BOMs
+# check_syntax: true # list JSON, YAML and TOML files that do not parse
+# redact_secrets: false # keep API keys, tokens and passwords (default: [REDACTED])
+# template: snapshot.tmpl  # lay out the snapshot with a Go text/template
+# max_file_size: 10MB # include only the first 10MB of larger files (the default)
+# large_files: skip  # leave them out instead: truncate (default) or skip
+# text_detection: extension  # read .go, .md, .ts, ... as text without probing them
+#                     # for binary content; other files are still probed (default: probe)
+# text_extensions: [.txt, .tmpl]  # more extensions to trust with text_detection: extension
+# max_tokens: 100000  # leave out files, evenly across directories, to fit the budget
+# max_bytes: 800KB    # or fit the file contents into a size instead
+# drop_policy: largest-first  # which files go: even (default), largest-first,
+#                     # oldest-first or by-weight
+# drop_weights:       # with by-weight, the lightest files go first (default: 1)
+#   "docs/**": 0.5
+#   "internal/**": 2
+# deny_licenses:      # warn when files with these license headers are included
+#   - GPL-3.0         # (also -only, -or-later); exclude them with --exclude-licenses
+#   - AGPL-3.0
+#
+# empty_files: list   # include (default), omit, or list empty files in an appendix
+# skipped_files: annotate  # name skipped files (binary, too large...) where they
+#                     # would have appeared, instead of leaving them out (omit)
+# expand_tabs: true   # expand tabs using .editorconfig tab widths (Makefiles excepted)
+# dedupe: true        # print files with identical content only once
+# hash: xxhash        # content hash: xxhash (default), blake3 or sha256
+#
+# pipelines:          # named option bundles, run with: codesnap run review
+#   review:
+#     graph: true
+#     graph_format: dot
+#     output: true
+#
+# clipboard:          # backends tried in order until one works (default:
+#   - system          # system, osc52); osc52 sets the terminal's clipboard,
+#   - wl-copy         # also over SSH, file saves codesnap_<time>.txt (unless -o
+#   - osc52           # saves one anyway), and anything else is a command that
+#   - file            # reads the snapshot on stdin
+#
+# audit:              # codesnap audit checks for secrets, pii, network (private
+#   patterns:         # IPs, internal hostnames) and markers (CONFIDENTIAL ...)
+#     - 'acme-internal\.\w+'   # more forbidden regular expressions
+#   allow:            # matches not to report
+#     - '@example\.com$'
+#   skip: [pii]       # checks to leave out
+#
+# sinks:              # external destinations, used with: codesnap --sink paste
+#   paste:            # the command reads a JSON header line and the snapshot
+#     cmd: my-uploader --project foo   # on stdin
+#
+# metrics: true       # keep local usage stats in .codesnap/metrics.json
+#
+# profiles:           # named variants of this config, used with:
+#   backend:          #   codesnap --profile backend
+#     folders: [cmd, internal]   # keys set here replace the ones above
+#   frontend:
+#     folders: [web]
+#   review:
+#     flags:          # default flags of the profile, as in pipelines;
+#       format: markdown   # flags given on the command line win
+#       with_tree: true
+#       max_tokens: 120000
+#
+# sections:           # group the snapshot by feature area, in this order;
+#   Auth: ["internal/auth/**", "pkg/jwt/**"]   # files matching no section
+#   Billing: "internal/billing/**"             # come last under "Other"
+#
+# order: path         # sequence of the files: config-order (default), path, size,
+#                     # mtime or tokens; -size puts the largest first
+#
+# anonymize:          # pseudonymize matches when run with --anonymize
+#   patterns:
+#     - '[a-z0-9-]+\.corp\.example\.com'   # internal hostnames
+#     - 'Project Falcon'                    # codenames
+#   seed: my-team-seed   # keeps pseudonyms stable across runs (default: random)
+#
+# flags:              # defaults for command line options, by their long
+#   tree: true        # names (tree: --with-tree, tree_only: -t); options
+#                     # given on the command line win
+
+folders:
+
+files:
+
+ignore:
+
+tree_depth:
+`
+
+type Config struct {
+	Folders     []FolderEntry     `yaml:"folders"`
+	Files       []string          `yaml:"files"`
+	Include     []string          `yaml:"include"`
+	Ignore      []IgnoreRule      `yaml:"ignore"`
+	TreeDepth   int               `yaml:"tree_depth"`
+	EmptyFiles  string            `yaml:"empty_files"`
+	Dedupe      bool              `yaml:"dedupe"`
+	Hash        string            `yaml:"hash"`
+	Anonymize   AnonymizeConfig   `yaml:"anonymize"`
+	ExpandTabs  bool              `yaml:"expand_tabs"`
+	TreeCompact bool              `yaml:"tree_compact"`
+	IncludeTree bool              `yaml:"include_tree"`
+	Pipelines   map[string]Preset `yaml:"pipelines"`
+	// SkippedFiles decides whether skipped files are left out (omit, the
+	// default) or named where they would have appeared (annotate)
+	SkippedFiles string `yaml:"skipped_files"`
+	// IncludeHidden decides which dotfiles and dot-directories are
+	// collected, see hiddenExcluded
+	IncludeHidden *bool `yaml:"include_hidden"`
+	// Workers fixes the number of files read concurrently
+	Workers int `yaml:"workers"`
+	// Environment lists the version commands of the --env section, which
+	// otherwise detects the tools from the project's files
+	Environment []string `yaml:"environment"`
+	// FollowSymlinks enters symlinked directories when collecting and in
+	// the tree; links that would loop are never followed
+	FollowSymlinks bool `yaml:"follow_symlinks"`
+	// ReadCache keeps the contents of unchanged files in the user cache
+	// directory, so repeated runs do not read them again
+	ReadCache bool `yaml:"read_cache"`
+	// TreeMaxEntries caps the entries listed per directory in the tree
+	TreeMaxEntries int  `yaml:"tree_max_entries"`
+	Metrics        bool `yaml:"metrics"`
+	// Sections groups the snapshot by feature area instead of directory order
+	Sections Sections `yaml:"sections"`
+	// Order sequences the files of the snapshot, see orderResults
+	Order string `yaml:"order"`
+	// DependencyDirs decides what happens to large dependency trees such as
+	// node_modules that are not ignored: prompt (default), skip or include
+	DependencyDirs       string `yaml:"dependency_dirs"`
+	DependencyMaxEntries int    `yaml:"dependency_max_entries"`
+	// TransformCmd pipes matching files through external commands
+	TransformCmd []TransformCmd `yaml:"transform_cmd"`
+	// Transforms is the pipeline of external commands with more options,
+	// run after TransformCmd
+	Transforms []Transformer `yaml:"transforms"`
+	// Databases are introspected and their schema included as DDL
+	Databases []DatabaseConfig `yaml:"databases"`
+	// Condense lists globs of OpenAPI and .proto files reduced to signatures
+	Condense []string `yaml:"condense"`
+	// NormalizeLineEndings converts CRLF and CR to LF and drops BOMs
+	NormalizeLineEndings bool `yaml:"normalize_line_endings"`
+	// CheckSyntax reports JSON, YAML and TOML files that do not parse
+	CheckSyntax bool `yaml:"check_syntax"`
+	// RedactSecrets replaces API keys, tokens and passwords with
+	// [REDACTED]; on unless set to false
+	RedactSecrets *bool `yaml:"redact_secrets"`
+	// Template is a text/template file, relative to the config, that
+	// replaces the layout of the selected format
+	Template string `yaml:"template"`
+	// Rename maps real paths to the virtual paths shown in the snapshot
+	Rename map[string]string `yaml:"rename"`
+	// Hooks run shell commands before and after the snapshot
+	Hooks Hooks `yaml:"hooks"`
+	// MaxFileSize caps how much of a single file is included, e.g. 10MB
+	MaxFileSize string `yaml:"max_file_size"`
+	// LargeFiles decides what happens to files over MaxFileSize: truncate
+	// (default) or skip
+	LargeFiles string `yaml:"large_files"`
+	// TextDetection decides how text files are told from binary ones:
+	// probe (default) reads the start of every file, extension trusts
+	// known source extensions and TextExtensions
+	TextDetection  string   `yaml:"text_detection"`
+	TextExtensions []string `yaml:"text_extensions"`
+	// MaxTokens trims the snapshot to about this many estimated tokens of
+	// file contents, see fitBudget
+	MaxTokens int `yaml:"max_tokens"`
+	// MaxBytes trims the file contents to about this size, e.g. 800KB,
+	// instead of a number of tokens
+	MaxBytes string `yaml:"max_bytes"`
+	// DropPolicy decides which files fitBudget leaves out: even (default),
+	// largest-first, oldest-first or by-weight
+	DropPolicy string `yaml:"drop_policy"`
+	// DropWeights weighs files by glob for drop_policy: by-weight; the
+	// lightest are left out first
+	DropWeights map[string]float64 `yaml:"drop_weights"`
+	// DenyLicenses are licenses warned about when files under them are included
+	DenyLicenses []string `yaml:"deny_licenses"`
+	// Clipboard is the chain of clipboard backends tried in order, see
+	// copyWithFallback
+	Clipboard []string `yaml:"clipboard"`
+	// Audit configures codesnap audit
+	Audit AuditConfig `yaml:"audit"`
+	// Sinks are external commands snapshots can be sent to with --sink
+	Sinks map[string]Sink `yaml:"sinks"`
+	// Flags are defaults for the command line flags, usually set by a
+	// profile, see readConfigOptions
+	Flags Preset `yaml:"flags"`
+	// Profiles are named overrides of the settings above, see applyProfile
+	Profiles map[string]yaml.MapSlice `yaml:"profiles"`
+}
+
+// nothingCollected marks the errors of runs that found no file to snapshot,
+// which codesnap reports with its own exit code
+type nothingCollected struct{ error }
+
+func (e nothingCollected) Unwrap() error { return e.error }
+
+// runStats are the counters of a collection run
+type runStats struct {
+	processed  int
+	empty      int
+	skipped    int
+	duplicates int
+	unchanged  int // incremental mode only
+	tokens     int // estimated tokens of the included file contents
+	redacted   int // secrets replaced with [REDACTED]
+}
+
+type CodeSnap struct {
+	configPath string
+	configDir  string
+	config     *Config
+	baseDir    string
+	logFile    string
+	// profile is the name of the config profile in use, if any
+	profile string
+	// format is the snapshot format: text (default) or json
+	format string
+	// summaryFirst places the summary before the content instead of after it
+	summaryFirst bool
+	// preHook and postHook are the compiled hook commands, if configured
+	preHook, postHook *template.Template
+	// renames are the parsed rename rules, longest path first
+	renames []renameRule
+	// labels maps the roots of labeled folders to their label
+	labels map[string]string
+	// stats holds the counters of the last content or tree run
+	stats runStats
+	// outputPath is the file the last snapshot was saved to, if any
+	outputPath string
+	// editorConfigs caches parsed .editorconfig files by directory
+	editorConfigs map[string]*editorConfigFile
+	// incremental snapshots only the files changed since the last incremental run
+	incremental bool
+	// dependencyDecisions remembers which dependency directories to skip
+	dependencyDecisions map[string]bool
+	// commands are the captured outputs of the --exec commands
+	commands []commandOutput
+	// notes are user guidance appended to the snapshot in a Notes section
+	notes []string
+	// transforms rewrite file contents before they are validated
+	transforms []Transform
+	// maxFileSize is the parsed max_file_size: larger files are truncated
+	maxFileSize int64
+	// maxTokens is max_tokens or --max-tokens; 0 means no budget
+	maxTokens int
+	// maxBytes is max_bytes or --max-bytes, the budget in bytes instead
+	maxBytes int64
+	// excludeLicenses are the licenses whose files are left out
+	excludeLicenses []string
+	// goFiles are the files of the --go-package packages, which replace
+	// the configured folders and files when set
+	goFiles []string
+	// changedSince limits the snapshot to files changed since this git ref
+	changedSince string
+	// diffHunks limits the snapshot to files changed since this git ref and
+	// includes only their changed hunks with diffContext lines of context
+	diffHunks   string
+	diffContext int
+	// hunks are the changed hunks by path in diffHunks mode
+	hunks map[string]string
+	// staged limits the snapshot to staged files, read from the git index
+	staged bool
+	// showTokens lists the estimated tokens of every file in the summary
+	// and the log
+	showTokens bool
+	// savesFile is set when -o saves the snapshot anyway, so the file
+	// clipboard backend is skipped instead of saving it twice
+	savesFile bool
+	// fileTokens are the per-file counts of the last run with showTokens
+	fileTokens []fileTokens
+	// sectionTokens are the per-section counts of the last run with sections
+	sectionTokens []sectionTokens
+	// pathStyle renders paths with forward slashes ("posix", the default) or
+	// with the OS separator ("native")
+	pathStyle string
+	// schemas are the database schemas of the current snapshot
+	schemas []databaseSchema
+	// inconsistencies are the line ending and encoding outliers of the last run
+	inconsistencies []inconsistency
+	// syntaxErrors are the files of the last run that failed check_syntax
+	syntaxErrors []syntaxError
+	// outputTemplate, when set, renders the snapshot instead of the format's
+	// layout, see Config.Template
+	outputTemplate *template.Template
+	// output, when set, receives the rendered snapshot directly instead of
+	// it being returned as a string, e.g. to stream into a named pipe
+	output snapshotOutput
+	// quiet suppresses the progress messages of gatherFiles
+	quiet bool
+	// graphFormat selects the dependency graph appendix ("mermaid" or "dot");
+	// empty disables it
+	graphFormat string
+	// symbolIndex appends the index of the files' exported symbols
+	symbolIndex bool
+	// listBinaries appends the binary files that were skipped
+	listBinaries bool
+	// encryption, when set, encrypts the saved snapshot file, see --encrypt
+	encryption *encryption
+	// environment is the --env section
+	environment []envFact
+	// tree is the folder structure shown before the files, with --with-tree
+	tree string
+	// chunkTokens and chunkOverlap size the chunks of --format chunks
+	chunkTokens, chunkOverlap int
+	// lineNumbers prefixes the lines of the included files with their numbers
+	lineNumbers bool
+	// safe leaves out credential-like files, see --safe
+	safe bool
+	// redact replaces secrets in every emitted section, see redactSections
+	redact bool
+	// workers is the number of files read concurrently; 0 adapts it to the
+	// storage, see parallelFor
+	workers int
+	// strictUTF8 skips files that are not UTF-8 instead of transcoding them
+	strictUTF8 bool
+	// noReadCache ignores read_cache for this run, see --no-cache
+	noReadCache bool
+	readCache   *readCache
+}
+
+// stateDir holds the files codesnap keeps for itself next to the config,
+// such as the usage metrics and the incremental cache
+const stateDir = ".codesnap"
+
+// Locked files are retried with exponential backoff before giving up
+const (
+	openRetries      = 5
+	openRetryBackoff = 50 * time.Millisecond
+)
+
+// openWithRetry opens a file, retrying a bounded number of times while it
+// is locked by another process (e.g. an editor or antivirus scan on Windows)
+func openWithRetry(path string) (*os.File, error) {
+	delay := openRetryBackoff
+	for attempt := 1; ; attempt++ {
+		file, err := os.Open(path)
+		if err == nil || attempt == openRetries || !isLockedError(err) {
+			return file, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Errors returned by validateFile for files that are readable but not text
+var (
+	errBinaryFile  = errors.New("file appears to be binary (contains null bytes)")
+	errInvalidUTF8 = errors.New("file contains invalid UTF-8 characters")
+)
+
+// textFile is a file as read for the snapshot
+type textFile struct {
+	valid   bool
+	content string // UTF-8, transcoded if needed
+	// truncatedFrom is the size on disk of a file cut at the size limit
+	truncatedFrom int64
+	// encoding is what the content was transcoded from, see decodeText;
+	// empty for UTF-8 files
+	encoding string
+}
+
+// validateFile checks if a file is a readable text file and reads at most
+// limit bytes of it. For a larger file, the size on disk is returned along
+// with the content up to the last line that fits. Text in another common
+// encoding is transcoded to UTF-8, unless strict rejects anything that is
+// not UTF-8. A trusted file, see trustsExtension, is not probed for binary
+// content.
+func validateFile(filepath string, limit int64, strict, trusted bool) (textFile, error) {
+	// Check if file exists and is readable
+	file, err := openWithRetry(filepath)
+	if err != nil {
+		return textFile{}, fmt.Errorf("cannot open file: %v", err)
+	}
+	defer file.Close()
+
+	// Check file size
+	info, err := file.Stat()
+	if err != nil {
+		return textFile{}, fmt.Errorf("cannot stat file: %v", err)
+	}
+
+	// Handle empty files
+	if info.Size() == 0 {
+		return textFile{valid: true}, nil // Empty files are valid but have no content
+	}
+
+	var content []byte
+	var truncated bool
+	if trusted {
+		if content, truncated, err = readTrusted(file, info.Size(), limit); err != nil {
+			return textFile{}, fmt.Errorf("error reading file: %v", err)
+		}
+		if strict && !utf8.Valid(content) {
+			return textFile{}, errInvalidUTF8
+		}
+	} else {
+		// Read first 8KB of the file
+		// This is usually enough to detect if it's text while not reading entire large files
+		buf := make([]byte, 8*1024)
+		n, err := file.Read(buf)
+		if err != nil && err != io.EOF {
+			return textFile{}, fmt.Errorf("error reading file: %v", err)
+		}
+		buf = buf[:n]
+
+		// Check for null bytes (common in binary files, but also in UTF-16)
+		if bytes.Contains(buf, []byte{0}) && (strict || !hasUTF16BOM(buf)) {
+			return textFile{}, errBinaryFile
+		}
+
+		// Check if content is valid UTF-8
+		if strict && !utf8.Valid(buf) {
+			return textFile{}, errInvalidUTF8
+		}
+
+		// Read the rest if validation passed, continuing on the open handle so a
+		// locked file does not have to be opened twice
+		if content, truncated, err = readLimited(file, buf, limit); err != nil {
+			return textFile{}, fmt.Errorf("error reading full file content: %v", err)
+		}
+	}
+	text := textFile{valid: true, content: string(content)}
+	if !strict {
+		text.content, text.encoding = decodeText(content)
+	}
+	if truncated {
+		text.truncatedFrom = info.Size()
+	}
+	return text, nil
+}
+
+// NewCodeSnap loads the config at configPath (default: codesnap.yml),
+// with the named profile applied unless profile is empty. A missing config
+// is created from the template and ends the program.
+func NewCodeSnap(configPath, profile string) (*CodeSnap, error) {
+	cs, err := newCodeSnap(configPath, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cs.findOrCreateConfig(); err != nil {
+		return nil, err
+	}
+
+	if err := cs.loadConfig(); err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// newCodeSnap resolves the directories of a CodeSnap for the config at
+// configPath without loading it
+func newCodeSnap(configPath, profile string) (*CodeSnap, error) {
+	if configPath == "" {
+		configPath = "codesnap.yml"
+	}
+
+	baseDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to get working directory: %v"), err)
+	}
+
+	// Resolve the config directory up front so relative paths can always be
+	// computed, even when the sources live on a UNC share like \\server\share
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to resolve config directory: %v"), err)
+	}
+
+	cs := &CodeSnap{
+		configPath: configPath,
+		configDir:  configDir,
+		baseDir:    baseDir,
+		profile:    profile,
+	}
+	return cs, nil
+}
+
+// findOrCreateConfig searches for a configuration file at the specified path
+// and creates one if not found. On a terminal the setup wizard writes a
+// working config and the run continues with it; otherwise, or when the
+// wizard is declined, the template is written and the program exits with
+// code 0 after printing instructions to the user.
+func (cs *CodeSnap) findOrCreateConfig() error {
+	if _, err := os.Stat(cs.configPath); os.IsNotExist(err) {
+		if isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+			created, err := cs.setupWizard(os.Stdin)
+			if err != nil {
+				return err
+			}
+			if created {
+				return nil
+			}
+		}
+		fmt.Println(T("No codesnap.yml found. Creating template configuration file..."))
+		if err := os.WriteFile(cs.configPath, []byte(templateConfig), 0644); err != nil {
+			return fmt.Errorf(T("failed to create template configuration: %v"), err)
+		}
+		fmt.Printf(T("Created template configuration at: %s\n"), cs.configPath)
+		fmt.Println(T("Please edit the file and run codesnap again."))
+		os.Exit(0)
+	}
+	return nil
+}
+
+func (cs *CodeSnap) loadConfig() error {
+	data, err := os.ReadFile(cs.configPath)
+	if err != nil {
+		return fmt.Errorf(T("failed to read config file: %v"), err)
+	}
+	return cs.parseConfig(data)
+}
+
+// parseConfig decodes, validates and resolves a configuration
+func (cs *CodeSnap) parseConfig(data []byte) error {
+	if cs.profile != "" {
+		var err error
+		if data, err = applyProfile(data, cs.profile); err != nil {
+			return err
+		}
+	}
+
+	cs.config = &Config{}
+	if err := yaml.Unmarshal(data, cs.config); err != nil {
+		return fmt.Errorf(T("invalid YAML format: %v"), err)
+	}
+
+	// Initialize empty slices if they're nil
+	if cs.config.Folders == nil {
+		cs.config.Folders = []FolderEntry{}
+	}
+	if cs.config.Files == nil {
+		cs.config.Files = []string{}
+	}
+	if cs.config.Ignore == nil {
+		cs.config.Ignore = []IgnoreRule{}
+	}
+	if err := cs.expandConfigPaths(); err != nil {
+		return err
+	}
+	for i := range cs.config.Ignore {
+		if err := cs.config.Ignore[i].prepare(); err != nil {
+			return err
+		}
+	}
+
+	switch cs.config.EmptyFiles {
+	case "":
+		cs.config.EmptyFiles = "include"
+	case "include", "omit", "list":
+	default:
+		return fmt.Errorf(T("invalid empty_files value %q (expected include, omit or list)"), cs.config.EmptyFiles)
+	}
+	switch cs.config.SkippedFiles {
+	case "":
+		cs.config.SkippedFiles = "omit"
+	case "omit", "annotate":
+	default:
+		return fmt.Errorf(T("invalid skipped_files value %q (expected omit or annotate)"), cs.config.SkippedFiles)
+	}
+
+	switch cs.config.DependencyDirs {
+	case "":
+		cs.config.DependencyDirs = "prompt"
+	case "prompt", "skip", "include":
+	default:
+		return fmt.Errorf(T("invalid dependency_dirs value %q (expected prompt, skip or include)"), cs.config.DependencyDirs)
+	}
+	if cs.config.DependencyMaxEntries <= 0 {
+		cs.config.DependencyMaxEntries = defaultDependencyMaxEntries
+	}
+
+	if cs.config.Hash == "" {
+		cs.config.Hash = "xxhash"
+	}
+	if err := validateHashAlgorithm(cs.config.Hash); err != nil {
+		return err
+	}
+
+	if cs.config.Workers < 0 {
+		return fmt.Errorf(T("invalid workers value %d (expected a positive number)"), cs.config.Workers)
+	}
+	cs.workers = cs.config.Workers
+
+	cs.maxFileSize = defaultMaxFileSize
+	if cs.config.MaxFileSize != "" {
+		size, err := parseSize(cs.config.MaxFileSize)
+		if err != nil {
+			return fmt.Errorf(T("invalid max_file_size: %v"), err)
+		}
+		cs.maxFileSize = size
+	}
+	if cs.config.MaxTokens < 0 {
+		return errors.New(T("max_tokens must not be negative"))
+	}
+	cs.maxTokens = cs.config.MaxTokens
+	cs.maxBytes = 0
+	if cs.config.MaxBytes != "" {
+		size, err := parseSize(cs.config.MaxBytes)
+		if err != nil {
+			return fmt.Errorf(T("invalid max_bytes: %v"), err)
+		}
+		cs.maxBytes = size
+	}
+	if cs.maxTokens > 0 && cs.maxBytes > 0 {
+		return errors.New(T("max_tokens and max_bytes cannot be combined"))
+	}
+	if cs.config.Order != "" {
+		if err := validOrder(cs.config.Order); err != nil {
+			return err
+		}
+	}
+	switch cs.config.DropPolicy {
+	case "":
+		cs.config.DropPolicy = "even"
+	case "even", "largest-first", "oldest-first", "by-weight":
+	default:
+		return fmt.Errorf(T("invalid drop_policy value %q (expected even, largest-first, oldest-first or by-weight)"), cs.config.DropPolicy)
+	}
+	for pattern := range cs.config.DropWeights {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf(T("invalid drop_weights pattern %q"), pattern)
+		}
+	}
+	switch cs.config.LargeFiles {
+	case "":
+		cs.config.LargeFiles = "truncate"
+	case "truncate", "skip":
+	default:
+		return fmt.Errorf(T("invalid large_files value %q (expected truncate or skip)"), cs.config.LargeFiles)
+	}
+	switch cs.config.TextDetection {
+	case "":
+		cs.config.TextDetection = "probe"
+	case "probe", "extension":
+	default:
+		return fmt.Errorf(T("invalid text_detection value %q (expected probe or extension)"), cs.config.TextDetection)
+	}
+
+	var err error
+	if cs.preHook, err = parseHook("pre", cs.config.Hooks.Pre); err != nil {
+		return err
+	}
+	if cs.postHook, err = parseHook("post", cs.config.Hooks.Post); err != nil {
+		return err
+	}
+	if cs.renames, err = parseRenames(cs.config.Rename); err != nil {
+		return err
+	}
+	if cs.config.Template != "" {
+		path := cs.config.Template
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cs.configDir, path)
+		}
+		if cs.outputTemplate, err = loadTemplate(path); err != nil {
+			return err
+		}
+	}
+
+	for _, pattern := range cs.config.Include {
+		if err := checkGroup(pattern); err != nil {
+			return err
+		}
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf(T("invalid include pattern %q"), pattern)
+		}
+	}
+
+	for _, pattern := range cs.config.Condense {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf(T("invalid condense pattern %q"), pattern)
+		}
+	}
+
+	for _, db := range cs.config.Databases {
+		if err := db.validate(); err != nil {
+			return err
+		}
+	}
+
+	for name, sink := range cs.config.Sinks {
+		if err := sink.validate(name); err != nil {
+			return err
+		}
+	}
+
+	for _, tc := range cs.config.TransformCmd {
+		if err := tc.validate(); err != nil {
+			return err
+		}
+		cs.transforms = append(cs.transforms, tc.transform(cs))
+	}
+	for _, t := range cs.config.Transforms {
+		if err := t.validate(); err != nil {
+			return err
+		}
+		cs.transforms = append(cs.transforms, t.transform(cs))
+	}
+	cs.redact = cs.config.RedactSecrets == nil || *cs.config.RedactSecrets
+
+	if err := cs.resolveFolders(); err != nil {
+		return err
+	}
+
+	if len(cs.config.Folders) == 0 && len(cs.config.Files) == 0 {
+		return errors.New(T("configuration must specify at least one file or folder to process"))
+	}
+
+	return nil
+}
+
+// isUNCPath reports whether path is a Windows UNC path such as
+// \\server\share\project or //server/share/project
+func isUNCPath(path string) bool {
+	if len(path) < 3 {
+		return false
+	}
+	isSep := func(c byte) bool { return c == '\\' || c == '/' }
+	return isSep(path[0]) && isSep(path[1]) && !isSep(path[2])
+}
+
+// formatPath renders a relative path for the snapshot in the configured
+// style, so snapshots made on Windows do not mix \ and /
+func (cs *CodeSnap) formatPath(path string) string {
+	if cs.pathStyle == "native" {
+		return filepath.FromSlash(path)
+	}
+	return filepath.ToSlash(path)
+}
+
+func (cs *CodeSnap) resolvePath(path string) string {
+	if isUNCPath(path) {
+		// Normalize separators so \\server\share and //server/share resolve alike
+		return filepath.Clean(filepath.FromSlash(path))
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	// Join the relative path with the config file's directory
+	return filepath.Join(cs.configDir, path)
+}
+
+// relPath returns path relative to the config directory. Paths that cannot be
+// made relative (e.g. on a different drive or network share) are returned as is.
+func (cs *CodeSnap) relPath(path string) string {
+	rel, err := filepath.Rel(cs.configDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// displayName returns the name used for a path in the folder tree. The root of
+// a drive or share has no base name, so the volume itself is shown instead.
+func displayName(path string) string {
+	name := filepath.Base(path)
+	if name == string(filepath.Separator) || name == "." {
+		if vol := filepath.VolumeName(path); vol != "" {
+			return vol
+		}
+	}
+	return name
+}
+
+func (cs *CodeSnap) shouldIncludeFile(path string) bool {
+	if cs.exclusionOf(path) == "" {
+		return true
+	}
+	if !cs.quiet {
+		fmt.Printf(T("Ignoring file: %s\n"), path)
+	}
+	return false
+}
+
+// exclusionOf returns why path is left out: the ignore rule that matches
+// it, or codesnap's state directory. It is empty for files to include.
+func (cs *CodeSnap) exclusionOf(path string) string {
+	// Convert to forward slashes for consistent matching
+	relPath := filepath.ToSlash(cs.relPath(path))
+
+	// Never snapshot codesnap's own state
+	if strings.HasPrefix(relPath, stateDir+"/") {
+		return T("codesnap state")
+	}
+
+	for i := range cs.config.Ignore {
+		if cs.config.Ignore[i].matches(path, relPath) {
+			return "ignore: " + cs.config.Ignore[i].String()
+		}
+	}
+	return ""
+}
+
+// matchesInclude reports whether a file found in a folder matches the
+// include globs, which are relative to the config like the ignore rules.
+// Without include patterns every file matches.
+func (cs *CodeSnap) matchesInclude(path string) bool {
+	return matchesAny(cs.config.Include, filepath.ToSlash(cs.relPath(path)))
+}
+
+// Add this function for saving output
+func saveToOutput(message string, outputFile string) error {
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	logEntry := fmt.Sprintf("[%s] %s\n", timestamp, message)
+
+	// Open output file in append mode, create if doesn't exist
+	file, err := os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(logEntry); err != nil {
+		return fmt.Errorf("failed to write to output file: %v", err)
+	}
+
+	return nil
+}
+
+// logf appends a message to the run's log file when logging is enabled
+func (cs *CodeSnap) logf(format string, args ...interface{}) {
+	if cs.logFile == "" {
+		return
+	}
+	saveToOutput(fmt.Sprintf(format, args...), cs.logFile)
+}
+
+// gatherFiles returns the paths of all configured folders and files that
+// pass the ignore rules, in config order
+func (cs *CodeSnap) gatherFiles() []string {
+	var paths []string
+	if cs.goFiles != nil {
+		for _, path := range cs.goFiles {
+			if cs.shouldIncludeFile(path) {
+				paths = append(paths, path)
+			}
+		}
+		return paths
+	}
+
+	// Process configured folders
+	for _, folder := range cs.config.Folders {
+		folderPath := cs.folderPath(folder)
+
+		// Check if folder exists
+		if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+			cs.logf("Folder not found: %s", folderPath)
+			continue
+		}
+
+		if !cs.quiet {
+			fmt.Printf(T("Processing folder: %s\n"), folderPath)
+		}
+
+		skipDir := func(dir string) bool {
+			return cs.hiddenExcluded(dir, true) || cs.skipDependencyDir(dir)
+		}
+		err := cs.walkFolder(folderPath, skipDir, func(full string) {
+			if !cs.hiddenExcluded(full, false) && cs.matchesInclude(full) && cs.shouldIncludeFile(full) {
+				paths = append(paths, full)
+			}
+		})
+		if err != nil {
+			cs.logf("Error reading folder %s: %v", folderPath, err)
+		}
+	}
+
+	// Process individual files
+	for _, file := range cs.config.Files {
+		filePath := cs.resolvePath(file)
+		if cs.shouldIncludeFile(filePath) {
+			paths = append(paths, filePath)
+		}
+	}
+
+	return paths
+}
+
+func (cs *CodeSnap) collectContent() (string, error) {
+	paths := cs.gatherFiles()
+	if cs.changedSince != "" || cs.diffHunks != "" || cs.staged {
+		var err error
+		if paths, err = cs.filterChanged(paths); err != nil {
+			return "", err
+		}
+	}
+	if cs.incremental {
+		return cs.collectIncremental(paths)
+	}
+	return cs.collectFiles(paths)
+}
+
+// fileResult is the outcome of reading one file of the snapshot
+type fileResult struct {
+	path        string
+	relPath     string // path shown in the snapshot
+	content     string
+	size        int64
+	section     string // configured section the file belongs to, if any
+	hash        string // content hash, computed only when dedupe or incremental mode needs it
+	empty       bool
+	condensed   bool   // reduced to its API signatures
+	diff        bool   // reduced to its changed hunks
+	lineEndings string // as found on disk, see lineEndingsOf
+	encoding    string // as found on disk, see encodingOf and decodeText
+	language    string // see languageOf; set for included files
+	duplicateOf string // display path of an earlier file with the same content
+	tokens      int    // estimated tokens of the included content
+	err         error  // set when the file was skipped
+	// truncatedFrom is the size on disk of a file cut at max_file_size
+	truncatedFrom int64
+	// license lists the licenses found in the file's header, if checked
+	license string
+}
+
+// collectFiles reads the given files and assembles them into a snapshot
+func (cs *CodeSnap) collectFiles(paths []string) (string, error) {
+	results := cs.readAll(paths)
+	if err := cs.saveLastRun(results); err != nil {
+		fmt.Printf(T("Warning: %v\n"), err)
+	}
+	cs.processResults(results)
+	return cs.render(results)
+}
+
+// render assembles read files into a snapshot in the selected format
+func (cs *CodeSnap) render(results []fileResult) (string, error) {
+	if cs.stats.processed == 0 {
+		return "", nothingCollected{errors.New(T("no valid files were processed"))}
+	}
+
+	cs.schemas = cs.introspectDatabases()
+	cs.redactSections(results)
+	if cs.output != nil {
+		if err := cs.output.reserve(estimateOutputSize(results)); err != nil {
+			return "", err
+		}
+		return "", cs.renderTo(cs.output, results)
+	}
+	var b strings.Builder
+	if err := cs.renderTo(&b, results); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// estimateOutputSize is the preflight estimate of the rendered snapshot's
+// size: the file contents plus their headers, with slack for the summary
+// and JSON escaping
+func estimateOutputSize(results []fileResult) int64 {
+	size := int64(4096)
+	for _, r := range results {
+		size += int64(len(r.content)+2*len(r.relPath)) + 256
+	}
+	return size + size/16
+}
+
+// renderTo writes the snapshot in the selected format to w
+func (cs *CodeSnap) renderTo(w io.Writer, results []fileResult) error {
+	if cs.outputTemplate != nil && cs.format != "chunks" {
+		return cs.renderTemplate(w, results)
+	}
+	switch cs.format {
+	case "json":
+		return cs.renderJSON(w, results)
+	case "chunks":
+		return cs.renderChunks(w, results)
+	case "markdown":
+		return cs.renderMarkdown(w, results)
+	}
+	return cs.renderText(w, results)
+}
+
+// readFiles validates and reads each file, updating the run statistics
+func (cs *CodeSnap) readFiles(paths []string) []fileResult {
+	results := cs.readAll(paths)
+	cs.processResults(results)
+	return results
+}
+
+// readAll validates, reads and, if needed, hashes the files in parallel.
+// The results keep the order of paths.
+func (cs *CodeSnap) readAll(paths []string) []fileResult {
+	return cs.readAllContext(context.Background(), paths)
+}
+
+// readAllContext is readAll with cancellation: once ctx is done, the files
+// not read yet are skipped with ctx's error
+func (cs *CodeSnap) readAllContext(ctx context.Context, paths []string) []fileResult {
+	needHash := cs.config.Dedupe || cs.incremental
+	if cs.config.ReadCache && !cs.noReadCache && !cs.safe && cs.readCache == nil {
+		cs.readCache = cs.openReadCache()
+	}
+	results := make([]fileResult, len(paths))
+	parallelFor(len(paths), cs.workers, func(i int) {
+		path := paths[i]
+		result := fileResult{path: path, relPath: cs.displayPath(path)}
+		if err := ctx.Err(); err != nil {
+			result.err = err
+			results[i] = result
+			return
+		}
+		if cs.safe && isCredentialFile(path) {
+			result.err = errCredentialFile
+			results[i] = result
+			return
+		}
+
+		text, err := cs.readFile(path)
+		if err != nil {
+			result.err = err
+			if info, statErr := os.Stat(path); statErr == nil {
+				result.size = info.Size()
+			}
+			results[i] = result
+			return
+		}
+
+		if text.truncatedFrom > 0 && cs.config.LargeFiles == "skip" {
+			result.size = text.truncatedFrom
+			result.err = fmt.Errorf("%w (%s)", errFileTooLarge, formatSize(text.truncatedFrom))
+			results[i] = result
+			return
+		}
+		result.content = text.content
+		result.size = int64(len(text.content))
+		result.truncatedFrom = text.truncatedFrom
+		result.encoding = text.encoding
+		if err := cs.checkLicense(&result); err != nil {
+			result.err = err
+			results[i] = result
+			return
+		}
+		result.empty = !text.valid || len(text.content) == 0
+		if needHash {
+			result.hash = cs.contentHash([]byte(text.content))
+		}
+		results[i] = result
+	})
+	if cs.readCache != nil {
+		if err := cs.readCache.save(); err != nil && !cs.quiet {
+			fmt.Printf(T("Warning: failed to save the read cache: %v\n"), err)
+		}
+	}
+	return results
+}
+
+// processResults updates the run statistics, expands tabs and marks
+// duplicates. It runs sequentially so the output does not depend on the
+// order in which the workers finished.
+func (cs *CodeSnap) processResults(results []fileResult) {
+	cs.orderResults(results)
+	cs.groupBySection(results)
+
+	seen := make(map[string]string) // content hash -> first file with that content
+	var syntaxErrors []syntaxError
+	cs.stats = runStats{}
+	stats := &cs.stats
+
+	for i := range results {
+		result := &results[i]
+		if result.err != nil {
+			stats.skipped++
+			cs.logf("Skipping %s: %v", result.relPath, result.err)
+			continue
+		}
+
+		stats.processed++
+		if id := matchLicense(cs.config.DenyLicenses, strings.Split(result.license, ", ")); id != "" && !cs.quiet {
+			fmt.Printf(T("Warning: %s is licensed under %s, which is on the deny_licenses list\n"), result.relPath, id)
+		}
+		if result.truncatedFrom > 0 && !cs.quiet {
+			fmt.Printf(T("Warning: %s is larger than max_file_size, only its first %s are included\n"),
+				result.relPath, formatSize(int64(len(result.content))))
+		}
+		result.lineEndings = lineEndingsOf(result.content)
+		result.language = languageOf(result.path, result.content)
+		if result.encoding == "" {
+			result.encoding = encodingOf(result.content)
+		}
+		if cs.config.CheckSyntax && !result.empty {
+			if msg := checkSyntax(result.path, result.content); msg != "" {
+				syntaxErrors = append(syntaxErrors, syntaxError{result.relPath, msg})
+			}
+		}
+		if hunks, ok := cs.hunks[result.path]; ok {
+			result.content, result.diff = hunks, true
+		}
+		if cs.config.NormalizeLineEndings {
+			result.content = normalizeText(result.content)
+		}
+		if cs.config.ExpandTabs && !isMakefile(result.path) {
+			result.content = expandTabs(result.content, cs.tabWidth(result.path))
+		}
+		if !result.empty && !result.diff && cs.shouldCondense(result.path) {
+			if condensed, ok := condense(result.path, result.content); ok {
+				result.content, result.condensed = condensed, true
+			}
+		}
+
+		if result.empty {
+			stats.empty++
+		} else if original, ok := cs.duplicateOf(seen, result.relPath, result.hash); ok {
+			stats.duplicates++
+			result.duplicateOf = original
+		} else {
+			result.tokens = estimateTokensFor(cs.shownContent(*result), result.language)
+			stats.tokens += result.tokens
+			if cs.showTokens {
+				cs.logf("Included %s: ~%d tokens", result.relPath, result.tokens)
+			}
+		}
+	}
+
+	cs.fitBudget(results)
+	cs.inconsistencies = findInconsistencies(results)
+	cs.syntaxErrors = syntaxErrors
+	if cs.showTokens {
+		cs.fileTokens = fileTokensOf(results)
+	}
+	cs.sectionTokens = nil
+	if len(cs.config.Sections) > 0 {
+		cs.sectionTokens = sectionTokensOf(results)
+	}
+}
+
+// duplicateOf reports whether a file with the content hash sum was already
+// included under another path when deduplication is enabled, and records it
+// otherwise
+func (cs *CodeSnap) duplicateOf(seen map[string]string, relPath, sum string) (string, bool) {
+	if !cs.config.Dedupe {
+		return "", false
+	}
+	if original, ok := seen[sum]; ok {
+		return original, true
+	}
+	seen[sum] = relPath
+	return "", false
+}
+
+func (cs *CodeSnap) saveToFile(content string) error {
+	timestamp := time.Now().Format("20060102_150405")
+	filename := fmt.Sprintf("codesnap_%s.txt", timestamp)
+	if cs.encryption != nil {
+		filename += cs.encryption.ext()
+	}
+
+	if err := cs.writeSnapshotFile(filename, content); err != nil {
+		return err
+	}
+
+	cs.outputPath = filename
+	fmt.Printf(T("Content saved to: %s\n"), filename)
+	return nil
+}
+
+// treeEntries returns the entries of dir that pass the ignore rules and
+// are not hidden, see hiddenExcluded. With
+// tree_max_entries set, the directory is read in batches and only the first
+// entries are kept; the number of further entries is returned as more.
+func (cs *CodeSnap) treeEntries(dir string) (entries []os.DirEntry, more int, err error) {
+	limit := cs.config.TreeMaxEntries
+	if limit <= 0 {
+		all, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, entry := range all {
+			full := filepath.Join(dir, entry.Name())
+			if !cs.hiddenExcluded(full, entry.IsDir()) && cs.shouldIncludeFile(full) {
+				entries = append(entries, entry)
+			}
+		}
+		return entries, 0, nil
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	for {
+		batch, err := f.ReadDir(1024)
+		for _, entry := range batch {
+			full := filepath.Join(dir, entry.Name())
+			if cs.hiddenExcluded(full, entry.IsDir()) || !cs.shouldIncludeFile(full) {
+				continue
+			}
+			if len(entries) < limit {
+				entries = append(entries, entry)
+			} else {
+				more++
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, more, nil
+}
+
+// formatCount formats n with thousands separators, e.g. 12,380
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func (cs *CodeSnap) generateFolderStructure() (string, error) {
+	var buffer strings.Builder
+	var stats struct {
+		dirs  int
+		files int
+	}
+	var hidden hiddenByDepth
+
+	// The real paths of the directories being printed, to break symlink
+	// cycles like walkFolder does
+	walking := map[string]bool{}
+
+	// Helper function to print the tree structure
+	var printTree func(path string, prefix string, isLast bool, depth int) error
+
+	printTree = func(path string, prefix string, isLast bool, depth int) error {
+		if cs.config.TreeDepth > 0 && depth > cs.config.TreeDepth {
+			cs.countHidden(path, &hidden)
+			return nil
+		}
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+
+		// Create the current line prefix
+		currentPrefix := prefix
+		if isLast {
+			currentPrefix += "└── "
+		} else {
+			currentPrefix += "├── "
+		}
+
+		// Symlinks that are not followed are listed with their target
+		real := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			followed := false
+			if target, ok := cs.followSymlink(path); ok {
+				if target == "" {
+					info, _ = os.Stat(path)
+					followed = true
+				} else if !walking[target] {
+					info, _ = os.Stat(path)
+					real, followed = target, true
+				}
+			}
+			if !followed {
+				link, _ := os.Readlink(path)
+				buffer.WriteString(fmt.Sprintf("%s%s -> %s\n", currentPrefix, displayName(path), link))
+				return nil
+			}
+		} else if info.IsDir() {
+			if real, err = filepath.EvalSymlinks(path); err != nil {
+				return err
+			}
+		}
+
+		// Add the current item to the output
+		parent := filepath.Dir(path)
+		if !info.IsDir() {
+			buffer.WriteString(fmt.Sprintf("%s%s\n", currentPrefix, cs.treeName(parent, path, displayName(path))))
+			stats.files++
+			return nil
+		}
+		walking[real] = true
+		defer delete(walking, real)
+
+		name := displayName(path)
+		if label, ok := cs.labels[path]; ok && depth == 0 {
+			name = label
+		}
+		stats.dirs++
+		entries, more, err := cs.treeEntries(path)
+		if err != nil {
+			return err
+		}
+
+		// Fold chains of single-child directories into one a/b/c/ node
+		for cs.config.TreeCompact && len(entries) == 1 && more == 0 && entries[0].IsDir() &&
+			(cs.config.TreeDepth == 0 || depth < cs.config.TreeDepth) {
+			path = filepath.Join(path, entries[0].Name())
+			name += "/" + entries[0].Name()
+			depth++
+			stats.dirs++
+			if entries, more, err = cs.treeEntries(path); err != nil {
+				return err
+			}
+		}
+		name = cs.treeName(parent, path, name)
+		buffer.WriteString(fmt.Sprintf("%s%s/\n", currentPrefix, name))
+
+		nextPrefix := prefix
+		if isLast {
+			nextPrefix += "    "
+		} else {
+			nextPrefix += "│   "
+		}
+
+		for i, entry := range entries {
+			isLastEntry := i == len(entries)-1 && more == 0
+			err := printTree(filepath.Join(path, entry.Name()), nextPrefix, isLastEntry, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+
+		if more > 0 {
+			buffer.WriteString(fmt.Sprintf("%s└── (+%s more entries)\n", nextPrefix, formatCount(more)))
+		}
+
+		return nil
+	}
+
+	// Process configured folders
+	for i, folder := range cs.config.Folders {
+		folderPath := cs.folderPath(folder)
+		header := folder.String()
+		if virtual, ok := cs.renamed(filepath.ToSlash(cs.relPath(folderPath))); ok {
+			header = virtual
+		}
+		buffer.WriteString(fmt.Sprintf("Folder: %s\n", cs.formatPath(header)))
+		if err := printTree(folderPath, "", i == len(cs.config.Folders)-1, 0); err != nil {
+			return "", fmt.Errorf(T("error processing folder %s: %v"), folder, err)
+		}
+		buffer.WriteString("\n")
+	}
+
+	// Add summary
+	summary := "\nStructure Summary:\n" +
+		fmt.Sprintf("- Directories: %d\n", stats.dirs) +
+		fmt.Sprintf("- Files: %d\n", stats.files)
+	if hidden.files > 0 {
+		summary += fmt.Sprintf("- Not shown (below tree_depth %d): %d files in %d directories\n",
+			cs.config.TreeDepth, hidden.files, len(hidden.dirs))
+		hidden.warn(cs)
+	}
+
+	cs.stats = runStats{processed: stats.files}
+	if stats.dirs == 0 && stats.files == 0 {
+		return "", nothingCollected{errors.New(T("no valid folders or files were found"))}
+	}
+
+	if cs.summaryFirst {
+		return strings.TrimPrefix(summary, "\n") + "\n" + buffer.String(), nil
+	}
+	return buffer.String() + summary, nil
+}
@@ -0,0 +1,225 @@
+package codesnap
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsUNCPath(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{`\\server\share\project`, true},
+		{"//server/share/project", true},
+		{`\\?`, true},
+		{`\\`, false},
+		{`\\\server`, false},
+		{"/srv/project", false},
+		{`C:\project`, false},
+		{"project", false},
+	} {
+		if got := isUNCPath(tc.path); got != tc.want {
+			t.Errorf("isUNCPath(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	cs := &CodeSnap{configDir: filepath.FromSlash("/work/repo")}
+	for _, tc := range []struct {
+		path, want string
+	}{
+		{"src", "/work/repo/src"},
+		{"../shared", "/work/shared"},
+		{"/opt/lib", "/opt/lib"},
+		{"//server/share/project", "//server/share/project"},
+	} {
+		want := filepath.FromSlash(tc.want)
+		if runtime.GOOS != "windows" && isUNCPath(tc.path) {
+			// Clean folds the leading slashes of a path outside Windows
+			want = filepath.Clean(tc.want)
+		}
+		if got := cs.resolvePath(tc.path); got != want {
+			t.Errorf("resolvePath(%q) = %q, want %q", tc.path, got, want)
+		}
+	}
+}
+
+func TestFolderWithGlobCharacters(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":      "folders:\n  - \"[id]\"\n",
+		"[id]/page.tsx":     "export default 1\n",
+		"[id]/{slug}/a.tsx": "export const a = 2\n",
+	})
+	r := runCodesnap(t, dir, "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, want := range []string{"File: [id]/page.tsx", "File: [id]/{slug}/a.tsx"} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("snapshot lacks %q, got:\n%s", want, r.stdout)
+		}
+	}
+}
+
+func TestTreeCompact(t *testing.T) {
+	for _, tc := range []struct {
+		name, options  string
+		want, unwanted []string
+	}{
+		{"off", "", []string{"├── a/\n", "│   └── b/\n", "└── d/\n"}, []string{"a/b/"}},
+		{"on", "tree_compact: true\n", []string{"├── a/b/c/\n    │   └── f.go\n", "└── d/\n", "├── e/\n", "- Directories: 6\n"}, []string{"├── a/\n"}},
+		{"within tree_depth", "tree_compact: true\ntree_depth: 2\n", []string{"├── a/b/\n", "below tree_depth 2"}, []string{"a/b/c/", "f.go"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"a/b/c/f.go":   "package c\n",
+				"d/e/g.go":     "package e\n",
+				"d/h.go":       "package d\n",
+			})
+			r := runCodesnap(t, dir, "tree", "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("tree lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("tree has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for _, tc := range []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{12380, "12,380"},
+		{1234567, "1,234,567"},
+	} {
+		if got := formatCount(tc.n); got != tc.want {
+			t.Errorf("formatCount(%d) = %q, want %q", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestTreeMaxEntries(t *testing.T) {
+	for _, tc := range []struct {
+		name, options string
+		shown         int
+		more          string
+	}{
+		{"unlimited", "", 5, ""},
+		{"limited", "tree_max_entries: 2\n", 2, "└── (+3 more entries)\n"},
+		{"at the limit", "tree_max_entries: 5\n", 5, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n  - \"*.log\"\n" + tc.options,
+				"debug.log":    "ignored\n",
+			}
+			for _, name := range []string{"a", "b", "c", "d", "e"} {
+				files[name+".go"] = "package " + name + "\n"
+			}
+			dir := writeFiles(t, files)
+			r := runCodesnap(t, dir, "tree", "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			if n := strings.Count(r.stdout, ".go\n"); n != tc.shown {
+				t.Errorf("tree shows %d files, want %d:\n%s", n, tc.shown, r.stdout)
+			}
+			if got := strings.Contains(r.stdout, "more entries"); got != (tc.more != "") || !strings.Contains(r.stdout, tc.more) {
+				t.Errorf("tree does not end the directory with %q, got:\n%s", tc.more, r.stdout)
+			}
+		})
+	}
+}
+
+func TestPathStyle(t *testing.T) {
+	native := filepath.FromSlash("sub/dir/a.go")
+	for _, tc := range []struct {
+		style, path, want string
+	}{
+		{"posix", "sub/dir/a.go", "sub/dir/a.go"},
+		{"posix", native, "sub/dir/a.go"},
+		{"native", "sub/dir/a.go", native},
+		{"native", native, native},
+	} {
+		cs := &CodeSnap{pathStyle: tc.style}
+		if got := cs.formatPath(tc.path); got != tc.want {
+			t.Errorf("formatPath(%q) with %s paths = %q, want %q", tc.path, tc.style, got, tc.want)
+		}
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"sub/dir/a.go": "package dir\n",
+	})
+	for _, style := range []string{"posix", "native"} {
+		r := runCodesnap(t, dir, "--paths", style, "--with-tree", "--stdout", "-q")
+		want := "File: sub/dir/a.go"
+		if style == "native" {
+			want = "File: " + native
+		}
+		if r.code != 0 || !strings.Contains(r.stdout, want) {
+			t.Errorf("--paths %s: exit code %d, snapshot lacks %q:\n%s%s", style, r.code, want, r.stdout, r.stderr)
+		}
+	}
+	if r := runCodesnap(t, dir, "--paths", "windows", "--stdout"); r.code != exitError || !strings.Contains(r.stdout+r.stderr, `invalid paths value "windows"`) {
+		t.Errorf("exit code %d for an invalid style, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+}
+
+func TestInclude(t *testing.T) {
+	for _, tc := range []struct {
+		name, ignore, options string
+		code                  int
+		want, unwanted        []string
+	}{
+		{"everything", "", "", 0, []string{"File: a.go", "File: api/a.proto", "File: README.md"}, nil},
+		{"globs", "", "include:\n  - \"**/*.go\"\n  - \"**/*.proto\"\n", 0,
+			[]string{"File: a.go", "File: api/a.proto"}, []string{"File: README.md"}},
+		{"listed files stay", "", "include:\n  - \"**/*.proto\"\nfiles:\n  - README.md\n", 0,
+			[]string{"File: api/a.proto", "File: README.md"}, []string{"File: a.go"}},
+		{"ignore wins", "  - api/**\n", "include:\n  - \"**/*.go\"\n  - \"**/*.proto\"\n", 0, []string{"File: a.go"}, []string{"File: api/a.proto"}},
+		{"invalid glob", "", "include:\n  - \"[\"\n", exitError, []string{`invalid include pattern "["`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.ignore + tc.options,
+				"a.go":         "package a\n",
+				"api/a.proto":  "syntax = \"proto3\";\n",
+				"README.md":    "# a\n",
+			})
+			r := runCodesnap(t, dir, "--stdout", "-q")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
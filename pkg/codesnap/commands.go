@@ -0,0 +1,128 @@
+package codesnap
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// subcommand describes a codesnap subcommand for the dispatcher and for
+// codesnap help NAME
+type subcommand struct {
+	name    string
+	args    string // what follows the name in the usage line
+	summary string
+	flags   []string // the flags it has, in help order
+	run     func(inv *invocation, args []string)
+}
+
+// The flags shared by the subcommands, grouped as in their help
+var (
+	commonFlags    = []string{"c", "profile", "q", "lang"}
+	selectionFlags = []string{"go-package", "hidden", "changed", "staged", "diff-hunks", "diff-context", "incremental", "max-file-size", "max-tokens",
+		"max-total-tokens", "max-bytes", "drop-policy", "exclude-licenses", "strip-comments", "strict-utf8", "no-redact", "no-cache", "workers"}
+	renderFlags = []string{"format", "template", "order", "with-tree", "no-tree", "paths", "line-numbers", "tokens", "graph", "graph-format",
+		"symbols", "list-binaries", "env", "exec", "note", "anonymize", "anonymize-seed", "anonymize-map", "chunk-tokens", "chunk-overlap"}
+	deliveryFlags = []string{"p", "o", "O", "stdout", "sink", "to", "l", "safe", "encrypt", "summary-json"}
+	snapFlags     = []string{"t", "all-configs", "split-by", "watch", "list", "dry-run"}
+)
+
+// flagValues name the values of flags in the help of a subcommand
+var flagValues = map[string]string{
+	"c": "PATH", "profile": "NAME", "lang": "CODE", "O": "PATH", "sink": "NAME", "encrypt": "RECIPIENT",
+	"changed": "REF", "diff-hunks": "REF", "max-file-size": "SIZE", "exclude-licenses": "LIST",
+	"format": "FMT", "template": "FILE", "paths": "STYLE", "graph-format": "FMT", "exec": "CMD", "note": "TEXT",
+	"anonymize-seed": "SEED", "anonymize-map": "FILE", "split-by": "folder", "max-bytes": "SIZE", "drop-policy": "POLICY", "order": "KEY", "only": "GLOB", "go-package": "PKG", "to": "LIST",
+	"addr": "HOST:PORT", "against": "REV:PATH",
+}
+
+func flagList(groups ...[]string) []string {
+	var names []string
+	for _, group := range groups {
+		names = append(names, group...)
+	}
+	return names
+}
+
+// subcommands are the commands of codesnap in help order, each with its
+// own flags. Without one, codesnap runs snap.
+var subcommands = []subcommand{
+	{"snap", "[options]", "Collect the configured files into a snapshot and copy it (the default)",
+		flagList(commonFlags, selectionFlags, renderFlags, deliveryFlags, snapFlags, []string{"v"}), snapCommand},
+	{"tree", "[options]", "Copy the folder structure tree of the configured folders",
+		flagList(commonFlags, []string{"paths"}, deliveryFlags), treeCommand},
+	{"init", "[-c PATH]", "Create codesnap.yml (or -c PATH), with the setup wizard on a terminal",
+		[]string{"c", "lang"}, initCommand},
+	{"pick", "[options]", "Choose files interactively with fzf, then snapshot them",
+		flagList(commonFlags, selectionFlags, renderFlags, deliveryFlags), pickCommand},
+	{"preview", "[options]", "Show the snapshot in $PAGER without copying or saving it",
+		flagList(commonFlags, selectionFlags, renderFlags), previewCommand},
+	{"run", "NAME [options]", "Run with the options of the named pipeline from the config",
+		flagList(commonFlags, selectionFlags, renderFlags, deliveryFlags, snapFlags), pipelineCommand},
+	{"render", "[--only GLOB] [options]", "Re-render the files of the last run without reading them again",
+		flagList(commonFlags, []string{"only"}, renderFlags, deliveryFlags), renderCommand},
+	{"serve", "[--addr HOST:PORT] [options]", "Serve the snapshot files over an OpenAI-compatible /v1/files API",
+		flagList(commonFlags, []string{"addr"}, selectionFlags, []string{"anonymize", "anonymize-seed"}), serveCommand},
+	{"stats", "[--json] [options]", "Show the files, lines of code and bytes per language of the configured sources",
+		flagList(commonFlags, []string{"json"}, selectionFlags), statsCommand},
+	{"estimate", "[--json] [options]", "Size the configured files from their metadata alone, without reading them",
+		flagList(commonFlags, []string{"json"}, selectionFlags), estimateCommand},
+	{"audit", "[--json] [options]", "Check the files a snapshot would include for content that should not be shared",
+		flagList(commonFlags, []string{"json"}, selectionFlags), auditCommand},
+	{"config", "validate [-c PATH] [--profile NAME]", "Check the config for unknown keys, invalid values and missing paths",
+		[]string{"c", "profile", "q", "lang"}, configCommand},
+	{"whatchanged", "[--against REV:PATH]", "List the files entering or leaving the selection since an earlier config version",
+		[]string{"c", "profile", "lang", "against"}, whatChangedCommand},
+	{"import", "FILE [-c PATH]", "Create codesnap.yml from the file selection of a repomix or gitingest config",
+		[]string{"c", "lang"}, importCommand},
+	{"metrics", "[-c PATH]", "Show the local usage metrics (enable with metrics: true)",
+		[]string{"c", "lang"}, metricsCommand},
+}
+
+// findSubcommand returns the subcommand called name
+func findSubcommand(name string) (subcommand, bool) {
+	for _, cmd := range subcommands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return subcommand{}, false
+}
+
+// wantsHelp reports whether args ask for help, so a subcommand's own
+// arguments need not be given
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "-h", "--h", "-help", "--help":
+			return true
+		case "--":
+			return false
+		}
+	}
+	return false
+}
+
+// printCommandHelp prints the usage of a subcommand and its flags, with
+// their descriptions from its flag set fs
+func printCommandHelp(cmd subcommand, fs *flag.FlagSet) {
+	fmt.Printf("Usage: codesnap %s %s\n\n%s\n\nOptions:\n", cmd.name, cmd.args, cmd.summary)
+	for _, name := range cmd.flags {
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		value, usage := flag.UnquoteUsage(f)
+		if name, ok := flagValues[f.Name]; ok {
+			value = name
+		} else if value == "int" {
+			value = "N"
+		}
+		dashes := "--"
+		if len(name) == 1 {
+			dashes = "-"
+		}
+		option := strings.TrimSpace(dashes + name + " " + value)
+		fmt.Printf("    %-24s %s\n", option, usage)
+	}
+}
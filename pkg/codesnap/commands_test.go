@@ -0,0 +1,52 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubcommandFlags(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nflags:\n  graph: true\npipelines:\n  serve:\n    addr: localhost:9000\n",
+		"a.go":         "package a\n",
+	})
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"flag of another command", []string{"stats", "--graph"}, exitError,
+			[]string{"flag provided but not defined: -graph"}, nil},
+		{"config flags the command lacks", []string{"stats"}, 0,
+			[]string{"Language"}, nil},
+		{"option a pipeline cannot set", []string{"run", "serve"}, exitError,
+			[]string{`pipelines.serve: unknown option "addr"`}, nil},
+		{"help lists the command's flags", []string{"tree", "--help"}, 0,
+			[]string{"Usage: codesnap tree", "--paths STYLE", "--stdout"}, []string{"--graph", "--json"}},
+		{"help command", []string{"help", "stats"}, 0,
+			[]string{"Usage: codesnap stats", "--json"}, []string{"--stdout"}},
+		{"unknown command", []string{"help", "nope"}, exitError,
+			[]string{`unknown command "nope"`}, nil},
+		{"bare help", []string{"-h"}, 0,
+			[]string{"codesnap tree [options]"}, []string{"Usage: codesnap snap"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := runCodesnap(t, dir, tc.args...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
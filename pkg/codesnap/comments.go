@@ -0,0 +1,240 @@
+package codesnap
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// quoteRule describes a string literal delimiter
+type quoteRule struct {
+	delim     string
+	multiline bool // may span lines; other strings end at a newline
+	escapes   bool // a backslash escapes the next character
+}
+
+// commentSyntax is the comment and string syntax of a language family
+type commentSyntax struct {
+	line       []string // line comment markers
+	blockStart string
+	blockEnd   string
+	quotes     []quoteRule // longest delimiters first
+	keep       []string    // line comments kept, e.g. Go build directives
+}
+
+var (
+	cQuotes = []quoteRule{{`"`, false, true}, {`'`, false, true}}
+
+	cStyle = commentSyntax{line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: cQuotes}
+
+	goStyle = commentSyntax{
+		line: []string{"//"}, blockStart: "/*", blockEnd: "*/",
+		quotes: append([]quoteRule{{"`", true, false}}, cQuotes...),
+		keep:   []string{"//go:", "// +build", "//line "},
+	}
+
+	jsStyle = commentSyntax{
+		line: []string{"//"}, blockStart: "/*", blockEnd: "*/",
+		quotes: append([]quoteRule{{"`", true, true}}, cQuotes...),
+	}
+
+	// Rust lifetimes such as 'a would read as unterminated character literals
+	rustStyle = commentSyntax{line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: cQuotes[:1]}
+
+	cssStyle = commentSyntax{blockStart: "/*", blockEnd: "*/", quotes: cQuotes}
+
+	pythonStyle = commentSyntax{
+		line:   []string{"#"},
+		quotes: append([]quoteRule{{`"""`, true, true}, {`'''`, true, true}}, cQuotes...),
+	}
+
+	hashStyle = commentSyntax{line: []string{"#"}, quotes: cQuotes}
+
+	sqlStyle = commentSyntax{line: []string{"--"}, blockStart: "/*", blockEnd: "*/", quotes: cQuotes}
+
+	markupStyle = commentSyntax{blockStart: "<!--", blockEnd: "-->"}
+)
+
+// commentSyntaxes maps the languages of languageFor to their comment syntax
+var commentSyntaxes = map[string]commentSyntax{
+	"go":         goStyle,
+	"javascript": jsStyle,
+	"jsx":        jsStyle,
+	"typescript": jsStyle,
+	"tsx":        jsStyle,
+	"c":          cStyle,
+	"cpp":        cStyle,
+	"objectivec": cStyle,
+	"java":       cStyle,
+	"kotlin":     cStyle,
+	"scala":      cStyle,
+	"groovy":     cStyle,
+	"csharp":     cStyle,
+	"swift":      cStyle,
+	"dart":       cStyle,
+	"protobuf":   cStyle,
+	"php":        cStyle,
+	"rust":       rustStyle,
+	"css":        cssStyle,
+	"scss":       {line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: cQuotes},
+	"less":       {line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: cQuotes},
+	"python":     pythonStyle,
+	"ruby":       hashStyle,
+	"perl":       hashStyle,
+	"r":          hashStyle,
+	"bash":       hashStyle,
+	"zsh":        hashStyle,
+	"fish":       hashStyle,
+	"yaml":       hashStyle,
+	"toml":       hashStyle,
+	"makefile":   hashStyle,
+	"dockerfile": hashStyle,
+	"sql":        sqlStyle,
+	"html":       markupStyle,
+	"xml":        markupStyle,
+}
+
+// stripCommentsTransform is the Transform behind --strip-comments. Files of
+// unknown languages and content that is not text are returned unchanged.
+func stripCommentsTransform(path string, content []byte) ([]byte, error) {
+	head := content
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	syntax, ok := commentSyntaxes[languageOf(path, string(head))]
+	if !ok || bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content) {
+		return content, nil
+	}
+	return stripComments(content, syntax), nil
+}
+
+// stripComments removes the line and block comments from src, leaving
+// string literals alone. Lines that held nothing but comments are dropped,
+// and whitespace left before a removed comment is trimmed. A shebang line
+// is kept. This is a lexical pass, so constructs such as JavaScript regular
+// expression literals containing comment markers can confuse it.
+func stripComments(src []byte, syntax commentSyntax) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+	lineStart := 0    // offset of the current line in out
+	stripped := false // a comment was removed from the current line
+
+	// endLine ends the current output line, dropping it if it is empty
+	// because of a removed comment
+	endLine := func() {
+		if stripped {
+			line := bytes.TrimRight(out.Bytes()[lineStart:], " \t\r")
+			out.Truncate(lineStart + len(line))
+			stripped = false
+			if len(bytes.TrimSpace(line)) == 0 {
+				out.Truncate(lineStart)
+				return
+			}
+		}
+		out.WriteByte('\n')
+		lineStart = out.Len()
+	}
+
+	i := 0
+	if bytes.HasPrefix(src, []byte("#!")) {
+		i = bytes.IndexByte(src, '\n')
+		if i < 0 {
+			return src
+		}
+		out.Write(src[:i+1])
+		lineStart, i = out.Len(), i+1
+	}
+
+scan:
+	for i < len(src) {
+		rest := src[i:]
+		if rest[0] == '\n' {
+			endLine()
+			i++
+			continue
+		}
+
+		for _, q := range syntax.quotes {
+			if bytes.HasPrefix(rest, []byte(q.delim)) {
+				n := stringLength(rest, q)
+				out.Write(rest[:n])
+				if j := bytes.LastIndexByte(rest[:n], '\n'); j >= 0 {
+					lineStart, stripped = out.Len()-n+j+1, false
+				}
+				i += n
+				continue scan
+			}
+		}
+
+		for _, marker := range syntax.line {
+			if !bytes.HasPrefix(rest, []byte(marker)) {
+				continue
+			}
+			// In shells and YAML a # within a word is not a comment
+			if marker == "#" && i > 0 && src[i-1] != ' ' && src[i-1] != '\t' && src[i-1] != '\n' {
+				continue
+			}
+			end := bytes.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			if hasAnyPrefix(rest, syntax.keep) {
+				out.Write(rest[:end])
+			} else {
+				stripped = true
+			}
+			i += end
+			continue scan
+		}
+
+		if syntax.blockStart != "" && bytes.HasPrefix(rest, []byte(syntax.blockStart)) {
+			end := bytes.Index(rest[len(syntax.blockStart):], []byte(syntax.blockEnd))
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += len(syntax.blockStart) + len(syntax.blockEnd)
+			}
+			// Keep the lines of the code around the comment apart
+			for range bytes.Count(rest[:end], []byte("\n")) {
+				stripped = true
+				endLine()
+			}
+			stripped = true
+			i += end
+			continue
+		}
+
+		out.WriteByte(rest[0])
+		i++
+	}
+	if stripped {
+		line := bytes.TrimRight(out.Bytes()[lineStart:], " \t\r")
+		out.Truncate(lineStart + len(line))
+	}
+	return out.Bytes()
+}
+
+// stringLength returns the length of the string literal at the start of s,
+// up to and including its closing delimiter. An unterminated single-line
+// string ends before the newline.
+func stringLength(s []byte, q quoteRule) int {
+	for i := len(q.delim); i < len(s); i++ {
+		switch {
+		case q.escapes && s[i] == '\\':
+			i++
+		case s[i] == '\n' && !q.multiline:
+			return i
+		case bytes.HasPrefix(s[i:], []byte(q.delim)):
+			return i + len(q.delim)
+		}
+	}
+	return len(s)
+}
+
+func hasAnyPrefix(s []byte, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(s, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
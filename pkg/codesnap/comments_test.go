@@ -0,0 +1,104 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripComments(t *testing.T) {
+	for _, tc := range []struct {
+		name, language, src, want string
+	}{
+		{"line comments", "go",
+			"package a\n\n// F does f\nfunc F() {} // trailing\n",
+			"package a\n\nfunc F() {}\n"},
+		{"block comments", "go",
+			"package a\n\n/*\n  doc\n*/\nvar x = 1 /* one */ + 2\nvar y /* a\nb */ = 3\n",
+			"package a\n\nvar x = 1  + 2\nvar y\n = 3\n"},
+		{"Go directives and strings", "go",
+			"//go:build linux\n\npackage a\n\nvar s = \"// not a comment\"\nvar r = `/* raw\n// too */`\nvar c = '\\''\n",
+			"//go:build linux\n\npackage a\n\nvar s = \"// not a comment\"\nvar r = `/* raw\n// too */`\nvar c = '\\''\n"},
+		{"escaped quotes", "javascript",
+			"const s = \"a \\\" // b\"; // c\nconst t = `x ${y} // z`\n",
+			"const s = \"a \\\" // b\";\nconst t = `x ${y} // z`\n"},
+		{"Rust lifetimes", "rust",
+			"fn f<'a>(s: &'a str) {} // f\n",
+			"fn f<'a>(s: &'a str) {}\n"},
+		{"Python docstrings and hashes", "python",
+			"#!/usr/bin/env python3\n# comment\ns = \"\"\"# kept\n\"\"\"  # gone\nt = '#' + \"#\"\n",
+			"#!/usr/bin/env python3\ns = \"\"\"# kept\n\"\"\"\nt = '#' + \"#\"\n"},
+		{"a hash within a word", "bash",
+			"echo a#b # comment\nx=${#list}\n",
+			"echo a#b\nx=${#list}\n"},
+		{"SQL", "sql",
+			"-- header\nSELECT 1; /* note */\nSELECT '--';\n",
+			"SELECT 1;\nSELECT '--';\n"},
+		{"markup", "html",
+			"<p>a</p>\n<!-- hidden\n-->\n<p>b</p>\n",
+			"<p>a</p>\n<p>b</p>\n"},
+		{"CSS has no line comments", "css",
+			"a { background: url(//example.com/x.png); } /* c */\n",
+			"a { background: url(//example.com/x.png); }\n"},
+		{"an unterminated block comment", "c",
+			"int x;\n/* open\nint y;\n",
+			"int x;\n"},
+		{"no trailing newline", "go",
+			"package a // a",
+			"package a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(stripComments([]byte(tc.src), commentSyntaxes[tc.language])); got != tc.want {
+				t.Errorf("stripComments = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStripCommentsTransform(t *testing.T) {
+	for _, tc := range []struct {
+		path, content, want string
+	}{
+		{"a.go", "package a // a\n", "package a\n"},
+		{"deploy", "#!/bin/sh\n# deploy\necho hi\n", "#!/bin/sh\necho hi\n"},
+		{"notes.txt", "text // not code\n", "text // not code\n"},
+		{"a.c", "int x; // \xff\n", "int x; // \xff\n"},
+		{"b.c", "int x;\x00 // c\n", "int x;\x00 // c\n"},
+	} {
+		got, err := stripCommentsTransform(tc.path, []byte(tc.content))
+		if err != nil || string(got) != tc.want {
+			t.Errorf("stripCommentsTransform(%s) = %q, %v; want %q", tc.path, got, err, tc.want)
+		}
+	}
+}
+
+func TestStripCommentsFlag(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"main.go":      "// Package main is the entry point\npackage main\n\nfunc main() {} // run\n",
+		"app.py":       "# settings\nDEBUG = False  # off\n",
+		"README.md":    "# Title // kept\n",
+	})
+	for _, tc := range []struct {
+		args           []string
+		want, unwanted []string
+	}{
+		{nil, []string{"// Package main", "# settings", "# Title // kept"}, nil},
+		{[]string{"--strip-comments"}, []string{"package main\n\nfunc main() {}\n", "DEBUG = False\n", "# Title // kept"},
+			[]string{"// Package main", "// run", "# settings", "# off"}},
+	} {
+		r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+		if r.code != 0 {
+			t.Fatalf("%v: exit code %d, stderr: %s", tc.args, r.code, r.stderr)
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(r.stdout, s) {
+				t.Errorf("%v: snapshot lacks %q, got:\n%s", tc.args, s, r.stdout)
+			}
+		}
+		for _, s := range tc.unwanted {
+			if strings.Contains(r.stdout, s) {
+				t.Errorf("%v: snapshot has %q, got:\n%s", tc.args, s, r.stdout)
+			}
+		}
+	}
+}
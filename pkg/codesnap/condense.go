@@ -0,0 +1,212 @@
+package codesnap
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v2"
+)
+
+// shouldCondense reports whether file matches one of the condense globs
+func (cs *CodeSnap) shouldCondense(file string) bool {
+	rel := filepath.ToSlash(cs.relPath(file))
+	for _, pattern := range cs.config.Condense {
+		if matched, _ := doublestar.Match(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// condense reduces an API schema to its signatures: the operations of an
+// OpenAPI/Swagger document, or the messages, enums and services of a .proto
+// file. ok is false for files that are neither, which are kept as they are.
+func condense(file, content string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".proto":
+		return condenseProto(content), true
+	case ".yaml", ".yml", ".json":
+		return condenseOpenAPI(content)
+	}
+	return "", false
+}
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// condenseOpenAPI lists the operations and schema names of an OpenAPI 3 or
+// Swagger 2 document. JSON documents parse as YAML too.
+func condenseOpenAPI(content string) (string, bool) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", false
+	}
+	spec, version := "OpenAPI", mapValue(doc, "openapi")
+	if version == nil {
+		spec, version = "Swagger", mapValue(doc, "swagger")
+	}
+	if version == nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	info, _ := mapValue(doc, "info").(yaml.MapSlice)
+	title := strings.TrimSpace(valueOr(mapValue(info, "title"), "") + " " + valueOr(mapValue(info, "version"), ""))
+	b.WriteString(fmt.Sprintf("%s %v: %s\n", spec, version, title))
+
+	paths, _ := mapValue(doc, "paths").(yaml.MapSlice)
+	if len(paths) > 0 {
+		b.WriteString("\nOperations:\n")
+	}
+	for _, p := range paths {
+		operations, _ := p.Value.(yaml.MapSlice)
+		for _, op := range operations {
+			method := fmt.Sprint(op.Key)
+			if !contains(httpMethods, strings.ToLower(method)) {
+				continue
+			}
+			line := fmt.Sprintf("%s %v", strings.ToUpper(method), p.Key)
+			details, _ := op.Value.(yaml.MapSlice)
+			if summary := valueOr(mapValue(details, "summary"), valueOr(mapValue(details, "operationId"), "")); summary != "" {
+				line += " - " + summary
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	schemas, _ := mapValue(doc, "definitions").(yaml.MapSlice)
+	if components, ok := mapValue(doc, "components").(yaml.MapSlice); ok {
+		schemas, _ = mapValue(components, "schemas").(yaml.MapSlice)
+	}
+	if len(schemas) > 0 {
+		names := make([]string, 0, len(schemas))
+		for _, s := range schemas {
+			names = append(names, fmt.Sprint(s.Key))
+		}
+		b.WriteString("\nSchemas: " + strings.Join(names, ", ") + "\n")
+	}
+	return b.String(), true
+}
+
+func mapValue(m yaml.MapSlice, key string) interface{} {
+	for _, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+func valueOr(v interface{}, fallback string) string {
+	if v == nil {
+		return fallback
+	}
+	return strings.TrimSpace(fmt.Sprint(v))
+}
+
+var protoComments = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+
+// condenseProto keeps the package and the signatures of a .proto file:
+// messages with their field names, enums with their values and services
+// with their rpc methods
+func condenseProto(content string) string {
+	content = protoComments.ReplaceAllString(content, "")
+
+	type block struct {
+		kind, name string
+		members    []string
+	}
+	var stack []*block
+	var done []*block
+	var header []string
+
+	// Walk the statements, which end at ';', '{' or '}'
+	var stmt strings.Builder
+	for _, r := range content {
+		if r != ';' && r != '{' && r != '}' {
+			stmt.WriteRune(r)
+			continue
+		}
+		text := strings.Join(strings.Fields(stmt.String()), " ")
+		stmt.Reset()
+		fields := strings.Fields(text)
+
+		switch r {
+		case '{':
+			kind, name := "", ""
+			if len(fields) >= 2 {
+				kind, name = fields[0], fields[1]
+			}
+			if kind == "rpc" {
+				// An rpc with an options body; record it on the service
+				if len(stack) > 0 {
+					stack[len(stack)-1].members = append(stack[len(stack)-1].members, text)
+				}
+			}
+			if len(stack) > 0 && (kind == "message" || kind == "enum") {
+				name = stack[len(stack)-1].name + "." + name
+			}
+			blk := &block{kind: kind, name: name}
+			stack = append(stack, blk)
+			// Keep declaration order, so outer messages come before nested ones
+			if kind == "message" || kind == "enum" || kind == "service" {
+				done = append(done, blk)
+			}
+		case '}':
+			if len(stack) == 0 {
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			// oneof fields belong to the enclosing message
+			if top.kind == "oneof" && len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.members = append(parent.members, top.members...)
+			}
+		case ';':
+			if len(fields) == 0 {
+				continue
+			}
+			if len(stack) == 0 {
+				if fields[0] == "syntax" || fields[0] == "package" || fields[0] == "edition" {
+					header = append(header, text+";")
+				}
+				continue
+			}
+			top := stack[len(stack)-1]
+			switch {
+			case fields[0] == "option" || fields[0] == "reserved" || fields[0] == "extensions":
+			case top.kind == "service" && fields[0] == "rpc":
+				top.members = append(top.members, text)
+			case top.kind == "enum" || top.kind == "message" || top.kind == "oneof":
+				// The member name is the word before '='
+				if i := strings.Index(text, "="); i > 0 {
+					words := strings.Fields(text[:i])
+					top.members = append(top.members, words[len(words)-1])
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range header {
+		b.WriteString(line + "\n")
+	}
+	if len(header) > 0 {
+		b.WriteString("\n")
+	}
+	for _, blk := range done {
+		if blk.kind == "service" {
+			b.WriteString(fmt.Sprintf("service %s {\n", blk.name))
+			for _, rpc := range blk.members {
+				b.WriteString("    " + rpc + "\n")
+			}
+			b.WriteString("}\n")
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s %s { %s }\n", blk.kind, blk.name, strings.Join(blk.members, ", ")))
+	}
+	return b.String()
+}
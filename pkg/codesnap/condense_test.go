@@ -0,0 +1,93 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+const shopProto = `syntax = "proto3";
+package shop.v1;
+option go_package = "example.com/shop";
+
+// A customer
+message User {
+  string name = 1; /* the key */ int64 id = 2;
+  oneof contact { string email = 3; string phone = 4; }
+  message Address { string city = 1; }
+  reserved 5;
+}
+enum Status { STATUS_UNKNOWN = 0; STATUS_OK = 1; }
+service Shop {
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc Watch(WatchRequest) returns (stream User) { option deprecated = true; }
+}
+`
+
+func TestCondense(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, content string
+		want                string
+		ok                  bool
+	}{
+		{"proto", "api/shop.proto", shopProto, `syntax = "proto3";
+package shop.v1;
+
+message User { name, id, email, phone }
+message User.Address { city }
+enum Status { STATUS_UNKNOWN, STATUS_OK }
+service Shop {
+    rpc GetUser(GetUserRequest) returns (User)
+    rpc Watch(WatchRequest) returns (stream User)
+}
+`, true},
+		{"openapi", "api/openapi.yaml", `openapi: 3.0.0
+info:
+  title: Shop
+  version: 1.2
+paths:
+  /users:
+    parameters: []
+    get:
+      summary: List users
+    post:
+      operationId: createUser
+components:
+  schemas:
+    User: {}
+    Order: {}
+`, "OpenAPI 3.0.0: Shop 1.2\n\nOperations:\nGET /users - List users\nPOST /users - createUser\n\nSchemas: User, Order\n", true},
+		{"swagger json", "api/swagger.JSON", `{"swagger": "2.0", "info": {"title": "Old"}, "paths": {"/a": {"delete": {}}}, "definitions": {"A": {}}}`,
+			"Swagger 2.0: Old\n\nOperations:\nDELETE /a\n\nSchemas: A\n", true},
+		{"other yaml", "deploy.yaml", "replicas: 3\n", "", false},
+		{"invalid yaml", "broken.yml", "a: [\n", "", false},
+		{"other file", "main.go", "package main\n", "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := condense(tc.file, tc.content)
+			if ok != tc.ok || got != tc.want {
+				t.Errorf("condense(%s) = %q, %v; want %q, %v", tc.file, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestCondenseOption(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":    "folders:\n  - .\nignore:\n  - codesnap.yml\ncondense:\n  - \"api/**\"\n",
+		"api/shop.proto":  shopProto,
+		"api/values.yaml": "replicas: 3\n",
+		"shop.proto":      shopProto,
+	})
+	r := runCodesnap(t, dir, "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, want := range []string{"File: api/shop.proto (condensed)", "message User { name, id, email, phone }", "File: api/values.yaml\n", "replicas: 3", "File: shop.proto\n"} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("snapshot lacks %q, got:\n%s", want, r.stdout)
+		}
+	}
+	if n := strings.Count(r.stdout, "option go_package"); n != 1 {
+		t.Errorf("the full proto file is in the snapshot %d times, want once outside api/", n)
+	}
+}
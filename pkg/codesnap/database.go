@@ -0,0 +1,305 @@
+package codesnap
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DatabaseConfig is a database whose schema is included in the snapshot:
+//
+//	databases:
+//	  - name: app
+//	    dialect: postgres
+//	    dsn: ${DATABASE_URL}
+//
+// The dsn may reference environment variables, so credentials do not have
+// to be stored in the config. Only the schema is read, never any rows.
+type DatabaseConfig struct {
+	Name    string `yaml:"name"`
+	Dialect string `yaml:"dialect"` // postgres, mysql or sqlite
+	DSN     string `yaml:"dsn"`
+	Schema  string `yaml:"schema"` // default: public (postgres), the connected database (mysql)
+}
+
+// databaseDrivers maps dialects to their database/sql driver names. The
+// drivers are registered by the program, see package drivers.
+var databaseDrivers = map[string]string{
+	"postgres": "pgx",
+	"mysql":    "mysql",
+	"sqlite":   "sqlite",
+}
+
+// introspectTimeout bounds the time spent reading one database's schema
+const introspectTimeout = 30 * time.Second
+
+// databaseSchema is the generated DDL summary of one database
+type databaseSchema struct {
+	Name    string `json:"name"`
+	Dialect string `json:"dialect"`
+	DDL     string `json:"ddl"`
+}
+
+func (d DatabaseConfig) validate() error {
+	if _, ok := databaseDrivers[d.Dialect]; !ok {
+		return fmt.Errorf(T("invalid dialect %q for database %q (expected postgres, mysql or sqlite)"), d.Dialect, d.Name)
+	}
+	if d.DSN == "" {
+		return fmt.Errorf(T("database %q needs a dsn"), d.Name)
+	}
+	return nil
+}
+
+// introspectDatabases reads the schema of every configured database.
+// Databases that cannot be read are reported and left out.
+func (cs *CodeSnap) introspectDatabases() []databaseSchema {
+	var schemas []databaseSchema
+	for _, db := range cs.config.Databases {
+		ddl, err := cs.introspect(db)
+		if err != nil {
+			if !cs.quiet {
+				fmt.Printf(T("Warning: skipping database %s: %v\n"), db.Name, err)
+			}
+			cs.logf("Skipping database %s: %v", db.Name, err)
+			continue
+		}
+		schemas = append(schemas, databaseSchema{Name: db.Name, Dialect: db.Dialect, DDL: ddl})
+	}
+	return schemas
+}
+
+func (cs *CodeSnap) introspect(db DatabaseConfig) (string, error) {
+	dsn := os.ExpandEnv(db.DSN)
+	if db.Dialect == "sqlite" {
+		// Open SQLite files read-only; relative paths are relative to the config
+		if !strings.HasPrefix(dsn, "file:") {
+			dsn = "file:" + cs.resolvePath(dsn)
+		}
+		if !strings.Contains(dsn, "mode=") {
+			if strings.Contains(dsn, "?") {
+				dsn += "&mode=ro"
+			} else {
+				dsn += "?mode=ro"
+			}
+		}
+	}
+
+	driver := databaseDrivers[db.Dialect]
+	if !slices.Contains(sql.Drivers(), driver) {
+		return "", fmt.Errorf(T("no %s driver is registered (import %s)"), driver, "github.com/SomaRe/codesnap/pkg/codesnap/drivers")
+	}
+	conn, err := sql.Open(driver, dsn)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), introspectTimeout)
+	defer cancel()
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	switch db.Dialect {
+	case "sqlite":
+		return sqliteDDL(ctx, tx)
+	case "postgres":
+		schema := db.Schema
+		if schema == "" {
+			schema = "public"
+		}
+		return informationSchemaDDL(ctx, tx, db.Dialect, schema)
+	default:
+		schema := db.Schema
+		if schema == "" {
+			if err := tx.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&schema); err != nil {
+				return "", err
+			}
+		}
+		return informationSchemaDDL(ctx, tx, db.Dialect, schema)
+	}
+}
+
+// sqliteDDL returns the stored CREATE statements, which SQLite keeps verbatim
+func sqliteDDL(ctx context.Context, tx *sql.Tx) (string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY type = 'index', tbl_name, name`)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var b strings.Builder
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			return "", err
+		}
+		b.WriteString(stmt + ";\n\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), rows.Err()
+}
+
+type schemaColumn struct {
+	name, dataType, nullable string
+	def                      sql.NullString
+}
+
+// informationSchemaDDL generates CREATE TABLE and CREATE INDEX statements
+// from information_schema for PostgreSQL and MySQL
+func informationSchemaDDL(ctx context.Context, tx *sql.Tx, dialect, schema string) (string, error) {
+	param := "?"
+	typeColumn := "column_type"
+	if dialect == "postgres" {
+		param = "$1"
+		typeColumn = "data_type"
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT table_name, column_name, `+typeColumn+`, is_nullable, column_default
+		FROM information_schema.columns WHERE table_schema = `+param+`
+		ORDER BY table_name, ordinal_position`, schema)
+	if err != nil {
+		return "", err
+	}
+	columns := make(map[string][]schemaColumn)
+	var tables []string
+	for rows.Next() {
+		var table string
+		var c schemaColumn
+		if err := rows.Scan(&table, &c.name, &c.dataType, &c.nullable, &c.def); err != nil {
+			rows.Close()
+			return "", err
+		}
+		if _, ok := columns[table]; !ok {
+			tables = append(tables, table)
+		}
+		columns[table] = append(columns[table], c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	rows, err = tx.QueryContext(ctx, `SELECT k.table_name, k.column_name
+		FROM information_schema.table_constraints t
+		JOIN information_schema.key_column_usage k
+		  ON k.constraint_name = t.constraint_name AND k.table_schema = t.table_schema AND k.table_name = t.table_name
+		WHERE t.constraint_type = 'PRIMARY KEY' AND t.table_schema = `+param+`
+		ORDER BY k.table_name, k.ordinal_position`, schema)
+	if err != nil {
+		return "", err
+	}
+	primaryKeys := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			rows.Close()
+			return "", err
+		}
+		primaryKeys[table] = append(primaryKeys[table], column)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	indexes, err := indexDDL(ctx, tx, dialect, schema)
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(tables)
+	var b strings.Builder
+	for _, table := range tables {
+		b.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", table))
+		var lines []string
+		for _, c := range columns[table] {
+			line := fmt.Sprintf("    %s %s", c.name, c.dataType)
+			if c.nullable == "NO" {
+				line += " NOT NULL"
+			}
+			if c.def.Valid {
+				line += " DEFAULT " + c.def.String
+			}
+			lines = append(lines, line)
+		}
+		if pk := primaryKeys[table]; len(pk) > 0 {
+			lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", strings.Join(pk, ", ")))
+		}
+		b.WriteString(strings.Join(lines, ",\n") + "\n);\n\n")
+	}
+	for _, stmt := range indexes {
+		b.WriteString(stmt + ";\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// indexDDL returns a CREATE INDEX statement for every secondary index
+func indexDDL(ctx context.Context, tx *sql.Tx, dialect, schema string) ([]string, error) {
+	var stmts []string
+	if dialect == "postgres" {
+		rows, err := tx.QueryContext(ctx, `SELECT indexdef FROM pg_indexes
+			WHERE schemaname = $1 AND indexname NOT IN (
+				SELECT constraint_name FROM information_schema.table_constraints
+				WHERE constraint_type = 'PRIMARY KEY' AND table_schema = $1)
+			ORDER BY tablename, indexname`, schema)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var stmt string
+			if err := rows.Scan(&stmt); err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, stmt)
+		}
+		return stmts, rows.Err()
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT table_name, index_name, non_unique, column_name
+		FROM information_schema.statistics
+		WHERE table_schema = ? AND index_name <> 'PRIMARY'
+		ORDER BY table_name, index_name, seq_in_index`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type index struct {
+		table, name string
+		unique      bool
+		columns     []string
+	}
+	var ordered []*index
+	byName := make(map[string]*index)
+	for rows.Next() {
+		var table, name, column string
+		var nonUnique int
+		if err := rows.Scan(&table, &name, &nonUnique, &column); err != nil {
+			return nil, err
+		}
+		key := table + "." + name
+		if byName[key] == nil {
+			byName[key] = &index{table: table, name: name, unique: nonUnique == 0}
+			ordered = append(ordered, byName[key])
+		}
+		byName[key].columns = append(byName[key].columns, column)
+	}
+	for _, idx := range ordered {
+		unique := ""
+		if idx.unique {
+			unique = "UNIQUE "
+		}
+		stmts = append(stmts, fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, idx.name, idx.table, strings.Join(idx.columns, ", ")))
+	}
+	return stmts, rows.Err()
+}
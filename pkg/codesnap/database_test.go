@@ -0,0 +1,65 @@
+package codesnap
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/SomaRe/codesnap/pkg/codesnap/drivers"
+)
+
+func TestDatabaseSchema(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"src/a.go": "package a\n"})
+	db, err := sql.Open("sqlite", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL)",
+		"CREATE INDEX users_email ON users (email)",
+		"INSERT INTO users (email) VALUES ('alice@example.com')",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	for _, tc := range []struct {
+		name, databases string
+		args            []string
+		want, unwanted  []string
+	}{
+		{"sqlite", "  - name: app\n    dialect: sqlite\n    dsn: app.db\n", nil,
+			[]string{"Database: app (sqlite)", "CREATE TABLE users", "CREATE INDEX users_email"}, []string{"alice@example.com"}},
+		{"unreachable", "  - name: gone\n    dialect: sqlite\n    dsn: missing/gone.db\n", nil,
+			[]string{"Warning: skipping database gone"}, []string{"Database: gone"}},
+		{"unreachable and quiet", "  - name: gone\n    dialect: sqlite\n    dsn: missing/gone.db\n", []string{"-q"},
+			nil, []string{"Warning: skipping database gone"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := "folders:\n  - path: src\ndatabases:\n" + tc.databases
+			if err := os.WriteFile(filepath.Join(dir, "codesnap.yml"), []byte(config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			r := runCodesnap(t, dir, append(tc.args, "--stdout")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
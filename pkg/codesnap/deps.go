@@ -0,0 +1,86 @@
+package codesnap
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const defaultDependencyMaxEntries = 200
+
+// dependencyDirNames are directory names used by package managers and tool
+// caches. Such a directory is only treated as a dependency tree when it also
+// holds more than dependency_max_entries entries, so a small hand-written
+// vendor/ folder is still included.
+var dependencyDirNames = map[string]bool{
+	"node_modules":      true,
+	"bower_components":  true,
+	"jspm_packages":     true,
+	".terraform":        true,
+	".terragrunt-cache": true,
+	"site-packages":     true,
+	"dist-packages":     true,
+	"__pypackages__":    true,
+	".tox":              true,
+	".nox":              true,
+	"vendor":            true,
+	"Pods":              true,
+	".gradle":           true,
+	".m2":               true,
+}
+
+// countEntries returns the number of entries in dir, reading at most
+// limit+1 names so huge directories are not listed in full
+func countEntries(dir string, limit int) int {
+	f, err := os.Open(dir)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	names, _ := f.Readdirnames(limit + 1)
+	return len(names)
+}
+
+// skipDependencyDir reports whether the traversal should skip dir because it
+// looks like an installed dependency tree that was not ignored explicitly.
+// Depending on dependency_dirs it asks on a terminal, skips with a warning or
+// never skips. Decisions are remembered for the rest of the run.
+func (cs *CodeSnap) skipDependencyDir(dir string) bool {
+	if cs.config.DependencyDirs == "include" || !dependencyDirNames[filepath.Base(dir)] {
+		return false
+	}
+	if skip, ok := cs.dependencyDecisions[dir]; ok {
+		return skip
+	}
+
+	limit := cs.config.DependencyMaxEntries
+	entries := countEntries(dir, limit)
+	skip := false
+	if entries > limit {
+		skip = true
+		name := cs.displayPath(dir)
+		if cs.config.DependencyDirs == "prompt" && isTerminal(os.Stdin) {
+			fmt.Printf(T("%s looks like a dependency directory with more than %d entries. Include it? [y/N] "), name, limit)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			skip = answer != "y" && answer != "yes"
+		} else {
+			fmt.Printf(T("Warning: skipping %s, it looks like a dependency directory with more than %d entries (add it to ignore or set dependency_dirs: include)\n"), name, limit)
+		}
+	}
+
+	if cs.dependencyDecisions == nil {
+		cs.dependencyDecisions = make(map[string]bool)
+	}
+	cs.dependencyDecisions[dir] = skip
+	return skip
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
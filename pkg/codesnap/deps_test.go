@@ -0,0 +1,47 @@
+package codesnap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDependencyDirs(t *testing.T) {
+	for _, tc := range []struct {
+		name, options  string
+		want, unwanted []string
+	}{
+		{"prompt without a terminal", "",
+			[]string{"File: vendor/v.go", "File: lib/m4.js", "Warning: skipping node_modules"}, []string{"File: node_modules/"}},
+		{"skip", "dependency_dirs: skip\n", []string{"File: vendor/v.go", "Warning: skipping node_modules"}, []string{"File: node_modules/"}},
+		{"include", "dependency_dirs: include\n", []string{"File: node_modules/m0.js", "File: node_modules/m4.js"}, []string{"Warning: skipping"}},
+		{"larger limit", "dependency_max_entries: 10\n", []string{"File: node_modules/m4.js"}, []string{"Warning: skipping"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\ndependency_max_entries: 3\n" + tc.options,
+				"vendor/v.go":  "package v\n",
+			}
+			for i := 0; i < 5; i++ {
+				files[fmt.Sprintf("node_modules/m%d.js", i)] = "module.exports = 1\n"
+				files[fmt.Sprintf("lib/m%d.js", i)] = "module.exports = 1\n"
+			}
+			dir := writeFiles(t, files)
+			r := runCodesnap(t, dir, "--stdout")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+// Package drivers registers the database/sql drivers of the dialects whose
+// schema codesnap can include: pgx for postgres, mysql and sqlite. Package
+// codesnap registers none, so programs that embed it only link the drivers
+// they need; the codesnap command imports this package for all of them:
+//
+//	import _ "github.com/SomaRe/codesnap/pkg/codesnap/drivers"
+package drivers
+
+import (
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
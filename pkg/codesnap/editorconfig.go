@@ -0,0 +1,178 @@
+package codesnap
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const defaultTabWidth = 4
+
+// editorConfigSection is one [glob] section of an .editorconfig file
+type editorConfigSection struct {
+	pattern    string
+	properties map[string]string
+}
+
+// editorConfigFile is a parsed .editorconfig file
+type editorConfigFile struct {
+	root     bool
+	sections []editorConfigSection
+}
+
+// parseEditorConfig reads an .editorconfig file. Missing or unreadable files
+// yield nil, which callers treat as "no settings here".
+func parseEditorConfig(file string) *editorConfigFile {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	ec := &editorConfigFile{}
+	var current *editorConfigSection
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			ec.sections = append(ec.sections, editorConfigSection{
+				pattern:    line[1 : len(line)-1],
+				properties: make(map[string]string),
+			})
+			current = &ec.sections[len(ec.sections)-1]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if current == nil {
+			if key == "root" {
+				ec.root = value == "true"
+			}
+			continue
+		}
+		current.properties[key] = value
+	}
+	return ec
+}
+
+// editorConfigProperties returns the properties that apply to file,
+// merging .editorconfig files from the file's directory upwards until one
+// declares root = true
+func (cs *CodeSnap) editorConfigProperties(file string) map[string]string {
+	if cs.editorConfigs == nil {
+		cs.editorConfigs = make(map[string]*editorConfigFile)
+	}
+
+	// Collect the files from the nearest directory outwards
+	type located struct {
+		dir string
+		ec  *editorConfigFile
+	}
+	var found []located
+	dir := filepath.Dir(file)
+	for {
+		ec, ok := cs.editorConfigs[dir]
+		if !ok {
+			ec = parseEditorConfig(filepath.Join(dir, ".editorconfig"))
+			cs.editorConfigs[dir] = ec
+		}
+		if ec != nil {
+			found = append(found, located{dir, ec})
+			if ec.root {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	// Apply the outermost file first so closer files and later sections win
+	props := make(map[string]string)
+	for i := len(found) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(found[i].dir, file)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, section := range found[i].ec.sections {
+			if editorConfigMatch(section.pattern, rel) {
+				for k, v := range section.properties {
+					props[k] = v
+				}
+			}
+		}
+	}
+	return props
+}
+
+// editorConfigMatch matches an .editorconfig section glob against a path
+// relative to the .editorconfig's directory. Globs without a slash match the
+// file name at any depth.
+func editorConfigMatch(pattern, rel string) bool {
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		pattern = "**/" + pattern
+	}
+	matched, err := doublestar.Match(pattern, rel)
+	return err == nil && matched
+}
+
+// tabWidth returns the tab width for file from .editorconfig, falling back to
+// indent_size and finally to defaultTabWidth
+func (cs *CodeSnap) tabWidth(file string) int {
+	props := cs.editorConfigProperties(file)
+	for _, key := range []string{"tab_width", "indent_size"} {
+		if n, err := strconv.Atoi(props[key]); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultTabWidth
+}
+
+// isMakefile reports whether tabs in file are syntactically significant
+func isMakefile(file string) bool {
+	name := strings.ToLower(path.Base(filepath.ToSlash(file)))
+	return name == "makefile" || name == "gnumakefile" || strings.HasSuffix(name, ".mk")
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop, so columns
+// line up regardless of how the reader renders tabs
+func expandTabs(content string, width int) string {
+	if !strings.Contains(content, "\t") {
+		return content
+	}
+	var b strings.Builder
+	b.Grow(len(content))
+	column := 0
+	for _, r := range content {
+		switch r {
+		case '\t':
+			spaces := width - column%width
+			b.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case '\n':
+			b.WriteRune(r)
+			column = 0
+		default:
+			b.WriteRune(r)
+			column++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package codesnap
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandTabs(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"no tabs", 4, "no tabs"},
+		{"\tx", 4, "    x"},
+		{"ab\tx", 4, "ab  x"},
+		{"abcd\tx", 4, "abcd    x"},
+		{"\t\tx\n\ty", 2, "    x\n  y"},
+		{"é\tx", 4, "é   x"},
+	} {
+		if got := expandTabs(tc.in, tc.width); got != tc.want {
+			t.Errorf("expandTabs(%q, %d) = %q, want %q", tc.in, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestTabWidth(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".editorconfig":     "root = true\n\n[*]\nindent_size = 2\n\n[*.go]\ntab_width = 8\n\n[docs/**]\nindent_size = tab\n",
+		"sub/.editorconfig": "[*.py]\nindent_size = 3\n",
+	})
+	for _, tc := range []struct {
+		file string
+		want int
+	}{
+		{"a.go", 8},
+		{"deep/b.go", 8},
+		{"a.txt", 2},
+		{"sub/c.py", 3},
+		{"sub/c.go", 8},
+		{"docs/d.md", defaultTabWidth},
+	} {
+		cs := &CodeSnap{}
+		if got := cs.tabWidth(filepath.Join(dir, tc.file)); got != tc.want {
+			t.Errorf("tabWidth(%s) = %d, want %d", tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestExpandTabsOption(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":  "folders:\n  - .\nignore:\n  - codesnap.yml\n  - .editorconfig\nexpand_tabs: true\n",
+		".editorconfig": "root = true\n\n[*]\ntab_width = 2\n",
+		"a.go":          "func a() {\n\treturn\n}\n",
+		"Makefile":      "all:\n\tgo build\n",
+	})
+	r := runCodesnap(t, dir, "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, want := range []string{"\n  return\n", "\n\tgo build\n"} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("snapshot lacks %q, got:\n%s", want, r.stdout)
+		}
+	}
+}
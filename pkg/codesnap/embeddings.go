@@ -0,0 +1,90 @@
+package codesnap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// defaultEmbeddingChunkTokens is the chunk size of --format chunks without
+// --chunk-tokens
+const defaultEmbeddingChunkTokens = 512
+
+// embeddingChunk is a line of --format chunks. Lines count from 1 and the
+// end line is inclusive.
+type embeddingChunk struct {
+	Path      string `json:"path"`
+	Language  string `json:"language,omitempty"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Tokens    int    `json:"tokens"`
+	Text      string `json:"text"`
+}
+
+// renderChunks writes every included file as JSON lines of at most
+// cs.chunkTokens estimated tokens each, for vector databases to ingest.
+// Consecutive chunks of a file share about cs.chunkOverlap tokens of whole
+// lines, so a passage cut at a boundary is still found in one of them.
+func (cs *CodeSnap) renderChunks(w io.Writer, results []fileResult) error {
+	budget := cs.chunkTokens
+	if budget <= 0 {
+		budget = defaultEmbeddingChunkTokens
+	}
+	b := bufio.NewWriter(w)
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	for _, r := range results {
+		if r.err != nil || r.empty || r.duplicateOf != "" {
+			continue
+		}
+		for _, c := range chunkLines(r.content, r.language, budget, cs.chunkOverlap) {
+			c.Path, c.Language = r.relPath, r.language
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+	}
+	return b.Flush()
+}
+
+// chunkLines cuts content into chunks of whole lines of at most budget
+// estimated tokens, each starting overlap tokens before the end of the
+// previous one. A single line larger than the budget is a chunk of its own.
+func chunkLines(content, language string, budget, overlap int) []embeddingChunk {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	tokens := make([]int, len(lines))
+	for i, line := range lines {
+		tokens[i] = estimateTokensFor(line, language)
+	}
+
+	var chunks []embeddingChunk
+	for start := 0; start < len(lines); {
+		end, sum := start, 0
+		for end < len(lines) && (end == start || sum+tokens[end] <= budget) {
+			sum += tokens[end]
+			end++
+		}
+		chunks = append(chunks, embeddingChunk{
+			StartLine: start + 1,
+			EndLine:   end,
+			Tokens:    sum,
+			Text:      strings.Join(lines[start:end], ""),
+		})
+		if end == len(lines) {
+			break
+		}
+		// Step back over whole lines that fit in the overlap, but always
+		// move forward
+		next, shared := end, 0
+		for next-1 > start && shared+tokens[next-1] <= overlap {
+			next--
+			shared += tokens[next]
+		}
+		start = next
+	}
+	return chunks
+}
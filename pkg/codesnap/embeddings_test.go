@@ -0,0 +1,112 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestChunkLines(t *testing.T) {
+	line := "a line of some length\n"
+	n := estimateTokensFor(line, "")
+	ten := strings.Repeat(line, 10)
+	long := strings.Repeat("x", 40*n) + "\n"
+
+	for _, tc := range []struct {
+		name            string
+		content         string
+		budget, overlap int
+		want            string // the start-end lines of each chunk
+	}{
+		{"one chunk", ten, 100 * n, 0, "1-10"},
+		{"no overlap", ten, 3 * n, 0, "1-3 4-6 7-9 10-10"},
+		{"one line of overlap", ten, 3 * n, n, "1-3 3-5 5-7 7-9 9-10"},
+		{"overlap of a whole chunk", ten, 3 * n, 3 * n, "1-3 2-4 3-5 4-6 5-7 6-8 7-9 8-10"},
+		{"a line over the budget", line + long + line, 2 * n, 0, "1-1 2-2 3-3"},
+		{"no trailing newline", strings.TrimSuffix(ten, "\n"), 5 * n, 0, "1-5 6-10"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			chunks := chunkLines(tc.content, "", tc.budget, tc.overlap)
+			var got []string
+			text := ""
+			for i, c := range chunks {
+				got = append(got, fmt.Sprintf("%d-%d", c.StartLine, c.EndLine))
+				if c.Tokens > tc.budget && c.StartLine != c.EndLine {
+					t.Errorf("chunk %d has %d tokens, over the budget of %d", i+1, c.Tokens, tc.budget)
+				}
+				if lines := strings.SplitAfter(tc.content, "\n"); c.Text != strings.Join(lines[c.StartLine-1:c.EndLine], "") {
+					t.Errorf("chunk %d (%d-%d) has text %q", i+1, c.StartLine, c.EndLine, c.Text)
+				}
+				if i == 0 || chunks[i-1].EndLine < c.StartLine {
+					text += c.Text
+				}
+			}
+			if strings.Join(got, " ") != tc.want {
+				t.Errorf("chunks %s, want %s", strings.Join(got, " "), tc.want)
+			}
+			if tc.overlap == 0 && text != tc.content {
+				t.Errorf("the chunks do not add up to the content:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestChunksFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		code   int
+		chunks int // of big.go
+		output string
+	}{
+		{"default size", nil, 0, 1, ""},
+		{"small chunks", []string{"--chunk-tokens", "60"}, 0, 3, ""},
+		{"overlap", []string{"--chunk-tokens", "60", "--chunk-overlap", "20"}, 0, 4, ""},
+		{"overlap too large", []string{"--chunk-tokens", "60", "--chunk-overlap", "60"}, exitError, 0,
+			"--chunk-overlap must be at least 0 and less than --chunk-tokens"},
+		{"overlap without chunks", []string{"--format", "text", "--chunk-overlap", "5"}, exitError, 0, "--chunk-overlap needs --format chunks"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var big strings.Builder
+			for i := 1; i <= 40; i++ {
+				fmt.Fprintf(&big, "var v%02d = %d\n", i, i)
+			}
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"big.go":       big.String(),
+				"empty.txt":    "",
+				"small.py":     "print(1)\n",
+			})
+			r := runCodesnap(t, dir, append([]string{"--format", "chunks"}, append(tc.args, "--stdout", "-q")...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if tc.code != 0 {
+				if !strings.Contains(r.stdout+r.stderr, tc.output) {
+					t.Errorf("output lacks %q, got:\n%s%s", tc.output, r.stdout, r.stderr)
+				}
+				return
+			}
+
+			counts := make(map[string]int)
+			end := 0
+			for _, line := range strings.Split(strings.TrimSuffix(r.stdout, "\n"), "\n") {
+				var c embeddingChunk
+				if err := json.Unmarshal([]byte(line), &c); err != nil {
+					t.Fatalf("line %q is not a chunk: %v", line, err)
+				}
+				counts[c.Path]++
+				if c.Path == "big.go" {
+					if c.Language != "go" || c.StartLine > end+1 || c.EndLine <= end || !strings.HasPrefix(c.Text, fmt.Sprintf("var v%02d ", c.StartLine)) {
+						t.Errorf("chunk %+v does not follow line %d", c, end)
+					}
+					end = c.EndLine
+				}
+			}
+			if counts["big.go"] != tc.chunks || end != 40 || counts["small.py"] != 1 || counts["empty.txt"] != 0 {
+				t.Errorf("chunks per file %v, want %d of big.go up to line 40, got to %d", counts, tc.chunks, end)
+			}
+		})
+	}
+}
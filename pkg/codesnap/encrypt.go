@@ -0,0 +1,75 @@
+package codesnap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// encryption is a parsed --encrypt value: the tool (age or gpg) and the
+// recipient the saved snapshot is encrypted to
+type encryption struct {
+	tool      string
+	recipient string
+}
+
+func parseEncryption(value string) (*encryption, error) {
+	tool, recipient, ok := strings.Cut(value, ":")
+	if !ok || recipient == "" || (tool != "age" && tool != "gpg") {
+		return nil, fmt.Errorf(T("invalid --encrypt value %q (expected age:RECIPIENT or gpg:RECIPIENT)"), value)
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf(T("--encrypt %s: %s is not installed"), value, tool)
+	}
+	return &encryption{tool: tool, recipient: recipient}, nil
+}
+
+// ext is the file extension of the encrypted snapshot
+func (e *encryption) ext() string {
+	return "." + e.tool
+}
+
+// encrypt runs age or gpg on content. An age recipient that names an
+// existing file is read as a recipients file, e.g. ~/.ssh/id_ed25519.pub.
+func (e *encryption) encrypt(content []byte) ([]byte, error) {
+	var cmd *exec.Cmd
+	switch e.tool {
+	case "age":
+		flag := "-r"
+		if _, err := os.Stat(e.recipient); err == nil {
+			flag = "-R"
+		}
+		cmd = exec.Command("age", flag, e.recipient)
+	default:
+		cmd = exec.Command("gpg", "--batch", "--yes", "--encrypt", "--recipient", e.recipient, "--output", "-")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return nil, fmt.Errorf(T("%s failed to encrypt the snapshot: %v"), e.tool, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// writeSnapshotFile saves content to path, encrypted with --encrypt. The
+// plain snapshot is never written to disk.
+func (cs *CodeSnap) writeSnapshotFile(path, content string) error {
+	data := []byte(content)
+	if cs.encryption != nil {
+		var err error
+		if data, err = cs.encryption.encrypt(data); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEncryption(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	for _, tc := range []struct {
+		value, err string
+	}{
+		{"age", `invalid --encrypt value "age" (expected age:RECIPIENT or gpg:RECIPIENT)`},
+		{"age:", `invalid --encrypt value "age:"`},
+		{"rsa:key", `invalid --encrypt value "rsa:key"`},
+		{"gpg:me@example.com", "--encrypt gpg:me@example.com: gpg is not installed"},
+	} {
+		if _, err := parseEncryption(tc.value); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("parseEncryption(%s) = %v, want %q", tc.value, err, tc.err)
+		}
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	for _, tc := range []struct {
+		name, tool, script string
+		recipient          string // "FILE" is replaced by a recipients file
+		args               []string
+		code               int
+		want               string // the tool's command line in the saved file, or the output on errors
+	}{
+		{"age", "age", "echo \"age $*\"; tr a-z A-Z\n", "age1abc", nil, 0, "age -r age1abc\n"},
+		{"age recipients file", "age", "echo \"age $*\"; tr a-z A-Z\n", "FILE", nil, 0, "age -R "},
+		{"gpg", "gpg", "echo \"gpg $*\"; tr a-z A-Z\n", "KEY1", nil, 0,
+			"gpg --batch --yes --encrypt --recipient KEY1 --output -\n"},
+		{"failure", "age", "echo 'unknown recipient' >&2; exit 1\n", "age1abc", nil, exitError,
+			"age failed to encrypt the snapshot: unknown recipient"},
+		{"stdout", "age", "cat\n", "age1abc", []string{"--stdout"}, exitError, "--encrypt needs -O FILE, -o or --safe"},
+		{"chunks", "age", "cat\n", "age1abc", []string{"--chunk-tokens", "1000"}, exitError, "cannot be combined with --chunk-tokens"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeCommand(t, tc.tool, tc.script)
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n  - \"*.pub\"\n",
+				"a.go":         "package a\n",
+				"key.pub":      "age1abc\n",
+			})
+			recipient := tc.recipient
+			if recipient == "FILE" {
+				recipient = filepath.Join(dir, "key.pub")
+			}
+			out := filepath.Join(t.TempDir(), "snap.txt")
+			args := []string{"--encrypt", tc.tool + ":" + recipient, "-q"}
+			if tc.args == nil {
+				args = append(args, "-O", out)
+			}
+			r := runCodesnap(t, dir, append(args, tc.args...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+
+			saved, err := os.ReadFile(out)
+			if tc.code != 0 {
+				if err == nil {
+					t.Errorf("a snapshot was saved:\n%s", saved)
+				}
+				if !strings.Contains(r.stdout+r.stderr, tc.want) {
+					t.Errorf("output lacks %q, got:\n%s%s", tc.want, r.stdout, r.stderr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(saved), tc.want) || !strings.Contains(string(saved), "FILE: A.GO\n") || strings.Contains(string(saved), "package a") {
+				t.Errorf("saved file starts with %q, want %q encrypted", saved[:min(len(saved), 80)], tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package codesnap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// environmentTimeout bounds each version command of the --env section
+const environmentTimeout = 5 * time.Second
+
+// envFact is a line of the --env section
+type envFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// environmentTools are the version commands run without a configured
+// environment list, for the tools whose marker file is in the config
+// directory
+var environmentTools = []struct {
+	markers []string
+	name    string
+	command string
+}{
+	{[]string{"go.mod"}, "Go", "go version"},
+	{[]string{"package.json"}, "Node.js", "node --version"},
+	{[]string{"package.json"}, "npm", "npm --version"},
+	{[]string{"pyproject.toml", "requirements.txt", "setup.py"}, "Python", "python3 --version"},
+	{[]string{"Cargo.toml"}, "Rust", "rustc --version"},
+	{[]string{"Gemfile"}, "Ruby", "ruby --version"},
+	{[]string{"pom.xml", "build.gradle", "build.gradle.kts"}, "Java", "java -version"},
+	{[]string{"composer.json"}, "PHP", "php --version"},
+	{[]string{"Dockerfile", "docker-compose.yml", "compose.yaml"}, "Docker", "docker --version"},
+}
+
+// detectEnvironment describes the machine codesnap runs on: the OS and
+// architecture, then the first line of each configured environment
+// command, or of the version commands of the tools the project uses
+func (cs *CodeSnap) detectEnvironment() []envFact {
+	facts := []envFact{{"OS", osDescription()}}
+
+	type check struct{ name, command string }
+	var checks []check
+	if len(cs.config.Environment) > 0 {
+		for _, command := range cs.config.Environment {
+			checks = append(checks, check{command, command})
+		}
+	} else {
+		for _, tool := range environmentTools {
+			for _, marker := range tool.markers {
+				if _, err := os.Stat(filepath.Join(cs.configDir, marker)); err == nil {
+					checks = append(checks, check{tool.name, tool.command})
+					break
+				}
+			}
+		}
+	}
+
+	for _, c := range checks {
+		facts = append(facts, envFact{c.name, cs.commandVersion(c.command)})
+	}
+	return facts
+}
+
+// commandVersion runs command in the config directory and returns the
+// first line it prints. Many tools, e.g. java -version, print their version
+// on stderr, so both are read.
+func (cs *CodeSnap) commandVersion(command string) string {
+	cmd := shellCommand(command)
+	cmd.Dir = cs.configDir
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	// Background processes of a killed shell must not keep Wait waiting
+	cmd.WaitDelay = time.Second
+	if err := cmd.Start(); err != nil {
+		return fmt.Sprintf("not available (%v)", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(environmentTimeout):
+		cmd.Process.Kill()
+		<-done
+		return fmt.Sprintf("no answer within %v", environmentTimeout)
+	}
+
+	line := ""
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		if line = strings.TrimSpace(scanner.Text()); line != "" {
+			break
+		}
+	}
+	line = strings.ToValidUTF8(line, "\ufffd")
+	if err != nil {
+		if line == "" {
+			return fmt.Sprintf("not available (%v)", err)
+		}
+		return fmt.Sprintf("not available (%s)", line)
+	}
+	return line
+}
+
+// osDescription is GOOS/GOARCH, with the distribution's name on Linux
+func osDescription() string {
+	desc := runtime.GOOS + "/" + runtime.GOARCH
+	if data, err := os.ReadFile("/etc/os-release"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if name, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+				desc += " (" + strings.Trim(name, `"'`) + ")"
+				break
+			}
+		}
+	}
+	return desc
+}
+
+// renderEnvironment lists the facts as "- name: value" lines
+func renderEnvironment(facts []envFact) string {
+	var b strings.Builder
+	for _, f := range facts {
+		b.WriteString(fmt.Sprintf("- %s: %s\n", f.Name, f.Value))
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandVersion(t *testing.T) {
+	cs := &CodeSnap{configDir: t.TempDir()}
+	for _, tc := range []struct {
+		command, want string
+	}{
+		{"echo v1.2.3", "v1.2.3"},
+		{"printf '\\n\\n  tool 2.0  \\nmore\\n'", "tool 2.0"},
+		{"echo 'java 21' >&2", "java 21"},
+		{"echo 'unknown flag' >&2; exit 2", "not available (unknown flag)"},
+		{"exit 3", "not available (exit status 3)"},
+		{"printf 'v\\377'", "v�"},
+	} {
+		if got := cs.commandVersion(tc.command); got != tc.want {
+			t.Errorf("commandVersion(%s) = %q, want %q", tc.command, got, tc.want)
+		}
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	for _, tc := range []struct {
+		name, options  string
+		files          map[string]string
+		args           []string
+		want, unwanted []string
+	}{
+		{"detected tools", "", map[string]string{"go.mod": "module x\n", "Cargo.toml": "[package]\n"}, nil,
+			[]string{"\nEnvironment:\n" + strings.Repeat("=", 50) + "\n- OS: ", "\n- Go: go version go1.99 fake\n- Rust: rustc 9.9\n"},
+			[]string{"Node.js", "Python"}},
+		{"configured commands", "environment:\n  - echo custom 1.0\n", map[string]string{"go.mod": "module x\n"}, nil,
+			[]string{"\n- echo custom 1.0: custom 1.0\n"}, []string{"- Go:"}},
+		{"markdown", "", map[string]string{"go.mod": "module x\n"}, []string{"--format", "markdown"},
+			[]string{"## Environment\n\n- OS: ", "- Go: go version go1.99 fake\n"}, nil},
+		{"json", "", map[string]string{"go.mod": "module x\n"}, []string{"--format", "json"},
+			[]string{`"environment": [`, `"name": "Go",`, `"value": "go version go1.99 fake"`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeCommand(t, "go", "echo 'go version go1.99 fake'\n")
+			fakeCommand(t, "rustc", "echo 'rustc 9.9'\n")
+			files := map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"a.txt":        "a\n",
+			}
+			for name, content := range tc.files {
+				files[name] = content
+			}
+			dir := writeFiles(t, files)
+			r := runCodesnap(t, dir, append(tc.args, "--env", "--stdout", "-q")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("output has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envVariable matches $VAR and ${VAR}
+var envVariable = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+// expandPath replaces a leading ~ with the home directory and $VAR or
+// ${VAR} with the environment variable, so a config can be shared by
+// machines with different checkout locations. Variables that are not set
+// are left as written: a pattern such as **/$RECYCLE.BIN/** keeps its
+// meaning, and ${ROOT}/src is reported as missing instead of becoming /src.
+func expandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf(T("cannot expand ~ in %q: %v"), path, err)
+		}
+		path = home + path[1:]
+	}
+	return envVariable.ReplaceAllStringFunc(path, func(v string) string {
+		name := strings.Trim(v, "${}")
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return v
+	}), nil
+}
+
+// expandConfigPaths expands the folders, files and ignore patterns of the
+// config with expandPath. Ignore patterns are matched against paths
+// relative to the config, so an absolute pattern is made relative too.
+func (cs *CodeSnap) expandConfigPaths() error {
+	var err error
+	for i := range cs.config.Folders {
+		if cs.config.Folders[i].Path, err = expandPath(cs.config.Folders[i].Path); err != nil {
+			return fmt.Errorf("folders: %v", err)
+		}
+	}
+	for i := range cs.config.Files {
+		if cs.config.Files[i], err = expandPath(cs.config.Files[i]); err != nil {
+			return fmt.Errorf("files: %v", err)
+		}
+	}
+	for i := range cs.config.Ignore {
+		rule := &cs.config.Ignore[i]
+		pattern, err := expandPath(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("ignore: %v", err)
+		}
+		if pattern != rule.Pattern && filepath.IsAbs(pattern) {
+			if rel, err := filepath.Rel(cs.configDir, pattern); err == nil {
+				pattern = filepath.ToSlash(rel)
+			}
+		}
+		rule.Pattern = pattern
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package codesnap
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("PROJECT_ROOT", "/work/app")
+	t.Setenv("EMPTY", "")
+	for _, tc := range []struct {
+		path, want string
+	}{
+		{"~", "/home/dev"},
+		{"~/src", "/home/dev/src"},
+		{"~other/src", "~other/src"},
+		{"$PROJECT_ROOT/lib", "/work/app/lib"},
+		{"${PROJECT_ROOT}/lib", "/work/app/lib"},
+		{"${PROJECT_ROOT}lib", "/work/applib"},
+		{"a${EMPTY}b", "ab"},
+		{"**/$RECYCLE.BIN/**", "**/$RECYCLE.BIN/**"},
+		{"${UNSET_VARIABLE}/src", "${UNSET_VARIABLE}/src"},
+		{"src/*.go", "src/*.go"},
+	} {
+		if got, err := expandPath(tc.path); err != nil || got != tc.want {
+			t.Errorf("expandPath(%s) = %q, %v; want %q", tc.path, got, err, tc.want)
+		}
+	}
+}
+
+func TestExpandConfigPaths(t *testing.T) {
+	lib := writeFiles(t, map[string]string{"util.go": "package lib\n", "gen.go": "package lib // generated\n"})
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":  "folders:\n  - .\n  - $SHARED_LIB\nfiles:\n  - ${NOTES}\nignore:\n  - codesnap.yml\n  - ${SKIPPED}\n  - $SHARED_LIB/gen.go\n",
+		"a.go":          "package a\n",
+		"b.go":          "package a\n",
+		"notes/todo.md": "- test\n",
+	})
+	t.Setenv("SHARED_LIB", lib)
+	t.Setenv("NOTES", filepath.Join(dir, "notes", "todo.md"))
+	t.Setenv("SKIPPED", filepath.Join(dir, "b.go"))
+
+	r := runCodesnap(t, dir, "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, want := range []string{"File: a.go\n", "util.go\n", "File: notes/todo.md\n"} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("snapshot lacks %q, got:\n%s", want, r.stdout)
+		}
+	}
+	for _, unwanted := range []string{"File: b.go", "gen.go", "$SHARED_LIB"} {
+		if strings.Contains(r.stdout, unwanted) {
+			t.Errorf("snapshot has %q, got:\n%s", unwanted, r.stdout)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// estimateLargest is how many files codesnap estimate lists as the largest
+const estimateLargest = 10
+
+// estimatedFile is one file of codesnap estimate
+type estimatedFile struct {
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	Tokens int    `json:"estimated_tokens"`
+}
+
+// estimateReport is the output of codesnap estimate --json
+type estimateReport struct {
+	Files   int             `json:"files"`
+	Bytes   int64           `json:"bytes"`
+	Tokens  int             `json:"estimated_tokens"`
+	Largest []estimatedFile `json:"largest"`
+	// Budget is max_tokens or max_bytes, when one is set
+	Budget *estimateBudget `json:"budget,omitempty"`
+}
+
+type estimateBudget struct {
+	Key   string `json:"key"`
+	Limit int64  `json:"limit"`
+	Fits  bool   `json:"fits"`
+}
+
+// estimateSize sizes the configured files from their metadata alone: the
+// files are found as for a snapshot but only stat'ed, and their tokens are
+// estimated from the size with the ratio of their language. Nothing is
+// read, so binary files are counted too and the totals are an upper bound.
+func (cs *CodeSnap) estimateSize() (estimateReport, error) {
+	paths := cs.gatherFiles()
+	if cs.changedSince != "" || cs.diffHunks != "" || cs.staged {
+		var err error
+		if paths, err = cs.filterChanged(paths); err != nil {
+			return estimateReport{}, err
+		}
+	}
+
+	var report estimateReport
+	var files []estimatedFile
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		size := info.Size()
+		if size > cs.maxFileSize {
+			if cs.config.LargeFiles == "skip" {
+				continue
+			}
+			size = cs.maxFileSize
+		}
+		ratio, ok := charsPerTokenByLanguage[languageFor(path)]
+		if !ok {
+			ratio = defaultCharsPerToken
+		}
+		file := estimatedFile{Path: cs.displayPath(path), Bytes: size, Tokens: int(math.Ceil(float64(size) / ratio))}
+		files = append(files, file)
+		report.Files++
+		report.Bytes += file.Bytes
+		report.Tokens += file.Tokens
+	}
+
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Tokens > files[j].Tokens })
+	report.Largest = files[:min(len(files), estimateLargest)]
+	switch {
+	case cs.maxBytes > 0:
+		report.Budget = &estimateBudget{"max_bytes", cs.maxBytes, report.Bytes <= cs.maxBytes}
+	case cs.maxTokens > 0:
+		report.Budget = &estimateBudget{"max_tokens", int64(cs.maxTokens), report.Tokens <= cs.maxTokens}
+	}
+	return report, nil
+}
+
+// showEstimate prints the estimate, or writes it as JSON
+func (cs *CodeSnap) showEstimate(asJSON bool) error {
+	report, err := cs.estimateSize()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		if report.Largest == nil {
+			report.Largest = []estimatedFile{}
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf(T("Files: %d\n"), report.Files)
+	fmt.Printf(T("Size: %s\n"), formatSize(report.Bytes))
+	fmt.Printf(T("Estimated tokens: ~%s (from the file sizes, without reading them)\n"), formatCount(report.Tokens))
+	if len(report.Largest) > 0 {
+		fmt.Println("\n" + T("Largest files:"))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range report.Largest {
+			fmt.Fprintf(w, "  ~%s\t%s\t%s\n", formatCount(f.Tokens), formatSize(f.Bytes), f.Path)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if b := report.Budget; b != nil {
+		limit := formatCount(int(b.Limit))
+		if b.Key == "max_bytes" {
+			limit = formatSize(b.Limit)
+		}
+		fmt.Println()
+		if b.Fits {
+			fmt.Printf(T("Fits within %s (%s)\n"), b.Key, limit)
+		} else {
+			fmt.Printf(T("Over %s (%s): a snapshot would leave out files, see drop_policy\n"), b.Key, limit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	// a.go is 720 bytes and 200 tokens at 3.6 characters per token, b.json
+	// 300 bytes at 3.0, notes.md 84 at 4.2 and blob.bin 37 at the default
+	// 3.7; big.log is estimated up to max_file_size
+	files := map[string]string{
+		"a.go":        "package a\n" + strings.Repeat("x", 709) + "\n",
+		"b.json":      "{" + strings.Repeat(" ", 297) + "}\n",
+		"notes.md":    "# Notes\n" + strings.Repeat("n", 75) + "\n",
+		"blob.bin":    "\x00" + strings.Repeat("\x01", 36),
+		"big.log":     strings.Repeat("log line\n", 444) + "log\n",
+		"ignored.txt": "left out\n",
+	}
+	for _, tc := range []struct {
+		name, options  string
+		args           []string
+		report         estimateReport
+		want, unwanted []string
+	}{
+		{"default", "", nil, estimateReport{Files: 5, Bytes: 3189, Tokens: 884},
+			[]string{"Files: 5\nSize: 3.1 KB\nEstimated tokens: ~884 (from the file sizes, without reading them)\n", "Largest files:\n", "big.log\n"},
+			[]string{"ignored.txt", "Fits", "Over"}},
+		{"large_files: skip", "large_files: skip\n", nil, estimateReport{Files: 4, Bytes: 1141, Tokens: 330},
+			[]string{"Files: 4\n"}, []string{"big.log"}},
+		{"fits max_tokens", "max_tokens: 1000\n", nil,
+			estimateReport{Files: 5, Bytes: 3189, Tokens: 884, Budget: &estimateBudget{"max_tokens", 1000, true}},
+			[]string{"Fits within max_tokens (1,000)\n"}, nil},
+		{"over --max-tokens", "", []string{"--max-tokens", "500"},
+			estimateReport{Files: 5, Bytes: 3189, Tokens: 884, Budget: &estimateBudget{"max_tokens", 500, false}},
+			[]string{"Over max_tokens (500): a snapshot would leave out files, see drop_policy\n"}, nil},
+		{"fits --max-bytes", "max_tokens: 10\n", []string{"--max-bytes", "4KB"},
+			estimateReport{Files: 5, Bytes: 3189, Tokens: 884, Budget: &estimateBudget{"max_bytes", 4096, true}},
+			[]string{"Fits within max_bytes (4 KB)\n"}, []string{"max_tokens"}},
+		{"quiet", "", []string{"-q"}, estimateReport{Files: 5, Bytes: 3189, Tokens: 884}, []string{"Files: 5\n"}, []string{"Processing"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			all := map[string]string{"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n  - ignored.txt\nmax_file_size: 2KB\n" + tc.options}
+			for name, content := range files {
+				all[name] = content
+			}
+			dir := writeFiles(t, all)
+			r := runCodesnap(t, dir, append([]string{"estimate"}, tc.args...)...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("estimate lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("estimate has %q, got:\n%s", s, r.stdout)
+				}
+			}
+
+			r = runCodesnap(t, dir, append([]string{"estimate", "--json"}, tc.args...)...)
+			var report estimateReport
+			if err := json.Unmarshal([]byte(r.stdout), &report); err != nil {
+				t.Fatalf("estimate --json is not JSON: %v\n%s%s", err, r.stdout, r.stderr)
+			}
+			if report.Files != tc.report.Files || report.Bytes != tc.report.Bytes || report.Tokens != tc.report.Tokens ||
+				!reflect.DeepEqual(report.Budget, tc.report.Budget) {
+				t.Errorf("report %+v (budget %+v), want %+v (budget %+v)", report, report.Budget, tc.report, tc.report.Budget)
+			}
+			var largest []string
+			for _, f := range report.Largest {
+				largest = append(largest, fmt.Sprintf("%s:%d", f.Path, f.Tokens))
+			}
+			want := "big.log:554 a.go:200 b.json:100 notes.md:20 blob.bin:10"
+			if tc.options == "large_files: skip\n" {
+				want = strings.TrimPrefix(want, "big.log:554 ")
+			}
+			if got := strings.Join(largest, " "); got != want {
+				t.Errorf("largest %s, want %s", got, want)
+			}
+		})
+	}
+
+	many := map[string]string{"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n"}
+	for i := 1; i <= 12; i++ {
+		many[fmt.Sprintf("f%02d.txt", i)] = strings.Repeat("x", i*10)
+	}
+	r := runCodesnap(t, writeFiles(t, many), "estimate", "--json")
+	var report estimateReport
+	if err := json.Unmarshal([]byte(r.stdout), &report); err != nil {
+		t.Fatalf("estimate --json is not JSON: %v\n%s", err, r.stdout)
+	}
+	if report.Files != 12 || len(report.Largest) != estimateLargest || report.Largest[0].Path != "f12.txt" || report.Largest[9].Path != "f03.txt" {
+		t.Errorf("files %d, largest %+v; want 12 files and the largest ten from f12.txt to f03.txt", report.Files, report.Largest)
+	}
+
+	r = runCodesnap(t, writeFiles(t, map[string]string{"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n"}), "estimate", "--json")
+	if r.code != 0 || !strings.Contains(r.stdout, `"largest": []`) {
+		t.Errorf("exit code %d, want an empty largest array:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+}
@@ -0,0 +1,69 @@
+package codesnap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// maxCommandOutput caps the output kept per --exec command. The end is
+// kept, since that is where failures and summaries usually are.
+const maxCommandOutput = 64 * 1024
+
+// commandOutput is the captured output of an --exec command
+type commandOutput struct {
+	Command   string `json:"command"`
+	ExitCode  int    `json:"exit_code"`
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// runCommands runs each command in the config directory and captures its
+// combined stdout and stderr. A failing command is expected (failing tests
+// are the point) and only recorded with its exit code.
+func (cs *CodeSnap) runCommands(commands []string) []commandOutput {
+	var outputs []commandOutput
+	for _, command := range commands {
+		if !cs.quiet {
+			fmt.Printf(T("Running: %s\n"), command)
+		}
+		cmd := shellCommand(command)
+		cmd.Dir = cs.configDir
+		var out bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &out, &out
+
+		result := commandOutput{Command: command}
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				result.ExitCode = exitErr.ExitCode()
+			} else {
+				result.ExitCode = -1
+				out.WriteString(err.Error() + "\n")
+			}
+		}
+
+		output := out.String()
+		if len(output) > maxCommandOutput {
+			output = output[len(output)-maxCommandOutput:]
+			if i := strings.IndexByte(output, '\n'); i >= 0 {
+				output = output[i+1:]
+			}
+			result.Truncated = true
+		}
+		result.Output = strings.ToValidUTF8(output, "\ufffd")
+		outputs = append(outputs, result)
+	}
+	return outputs
+}
+
+// title is the heading of the command's section in the snapshot
+func (c commandOutput) title() string {
+	status := fmt.Sprintf("exit status %d", c.ExitCode)
+	if c.Truncated {
+		status += ", earlier output truncated"
+	}
+	return fmt.Sprintf("%s (%s)", c.Command, status)
+}
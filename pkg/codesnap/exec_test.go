@@ -0,0 +1,74 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunCommands(t *testing.T) {
+	dir := t.TempDir()
+	cs := &CodeSnap{configDir: dir, quiet: true}
+	for _, tc := range []struct {
+		command   string
+		want      commandOutput
+		truncated string // the start of the output kept, if truncated
+	}{
+		{"echo ok", commandOutput{Command: "echo ok", Output: "ok\n"}, ""},
+		{"echo out; echo err >&2; exit 3", commandOutput{Command: "echo out; echo err >&2; exit 3", ExitCode: 3, Output: "out\nerr\n"}, ""},
+		{"pwd", commandOutput{Command: "pwd", Output: dir + "\n"}, ""},
+		{"printf 'a\\377b'", commandOutput{Command: "printf 'a\\377b'", Output: "a�b"}, ""},
+		{"yes line | head -n 20000; echo last", commandOutput{}, "line\n"},
+	} {
+		got := cs.runCommands([]string{tc.command})[0]
+		if tc.truncated != "" {
+			if !got.Truncated || len(got.Output) > maxCommandOutput || !strings.HasPrefix(got.Output, tc.truncated) || !strings.HasSuffix(got.Output, "line\nlast\n") {
+				t.Errorf("%s: kept %d bytes, truncated %v; want the end at whole lines", tc.command, len(got.Output), got.Truncated)
+			}
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s = %+v, want %+v", tc.command, got, tc.want)
+		}
+	}
+}
+
+func TestCommandTitle(t *testing.T) {
+	for _, tc := range []struct {
+		output commandOutput
+		want   string
+	}{
+		{commandOutput{Command: "go test ./...", ExitCode: 1}, "go test ./... (exit status 1)"},
+		{commandOutput{Command: "make", Truncated: true}, "make (exit status 0, earlier output truncated)"},
+	} {
+		if got := tc.output.title(); got != tc.want {
+			t.Errorf("title = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestExecFlag(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{"\nCommand: echo hello (exit status 0)\n" + strings.Repeat("=", 50) + "\n\nhello\n", "\nCommand: exit 2 (exit status 2)\n"}},
+		{"markdown", []string{"## Command: echo hello (exit status 0)\n\n```\nhello\n```\n"}},
+		{"json", []string{`"command": "exit 2"`, `"exit_code": 2`}},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+			})
+			r := runCodesnap(t, dir, "--exec", "echo hello", "--exec", "exit 2", "--format", tc.format, "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package codesnap
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FolderEntry is an entry of the folders list. It is written either as a
+// plain path or as a mapping that labels the folder or takes it from another
+// git worktree of the same repository:
+//
+//	folders:
+//	  - src
+//	  - path: src
+//	    worktree: feature/retry   # branch checked out in another worktree
+//	    label: feature
+//
+// Files of labeled folders are shown as label/path/inside/folder, so the same
+// file from two worktrees can be told apart in one snapshot.
+type FolderEntry struct {
+	Path     string `yaml:"path"`
+	Label    string `yaml:"label"`
+	Worktree string `yaml:"worktree"`
+	Repo     string `yaml:"repo"`
+	Ref      string `yaml:"ref"`
+
+	resolved string
+}
+
+// UnmarshalYAML accepts both the plain string and the mapping form
+func (f *FolderEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*f = FolderEntry{Path: path}
+		return nil
+	}
+
+	type plain FolderEntry
+	var entry plain
+	if err := unmarshal(&entry); err != nil {
+		return err
+	}
+	*f = FolderEntry(entry)
+	return nil
+}
+
+// String returns the folder as written in the config
+func (f FolderEntry) String() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	if f.Worktree != "" {
+		return filepath.Join(f.Worktree, f.Path)
+	}
+	if f.Repo != "" {
+		return filepath.Join(repoName(f.Repo), f.Path)
+	}
+	return f.Path
+}
+
+// folderPath returns the absolute path of a configured folder
+func (cs *CodeSnap) folderPath(f FolderEntry) string {
+	if f.resolved != "" {
+		return f.resolved
+	}
+	return cs.resolvePath(f.Path)
+}
+
+// resolveFolders locates the worktrees referenced by folder entries,
+// fetches remote repositories and records the labeled folder roots used
+// for display paths
+func (cs *CodeSnap) resolveFolders() error {
+	var worktrees map[string]string
+	cs.labels = make(map[string]string)
+
+	for i := range cs.config.Folders {
+		f := &cs.config.Folders[i]
+		// A local directory wins over the short remote form of the same name
+		if repo, ref, path, ok := parseRepoFolder(f.Path); ok && f.Repo == "" && f.Worktree == "" {
+			if _, err := os.Stat(cs.resolvePath(f.Path)); os.IsNotExist(err) {
+				f.Repo, f.Ref, f.Path = repo, ref, path
+			}
+		}
+		if f.Repo != "" {
+			if f.Worktree != "" {
+				return fmt.Errorf(T("folder %s cannot have both repo and worktree"), f.String())
+			}
+			root, err := cs.fetchRepo(f.Repo, f.Ref)
+			if err != nil {
+				return err
+			}
+			f.resolved = filepath.Join(root, filepath.FromSlash(f.Path))
+			if f.Label == "" {
+				f.Label = repoName(f.Repo)
+				if f.Ref != "" {
+					f.Label += "@" + f.Ref
+				}
+				f.Label = path.Join(f.Label, filepath.ToSlash(f.Path))
+			}
+		}
+		if f.Worktree != "" {
+			if worktrees == nil {
+				var err error
+				if worktrees, err = listWorktrees(cs.configDir); err != nil {
+					return err
+				}
+			}
+			root, ok := worktrees[f.Worktree]
+			if !ok {
+				return fmt.Errorf(T("no git worktree found for %q"), f.Worktree)
+			}
+			f.resolved = filepath.Join(root, filepath.FromSlash(f.Path))
+			if f.Label == "" {
+				f.Label = f.Worktree
+			}
+		}
+		if f.Label != "" {
+			cs.labels[cs.folderPath(*f)] = filepath.ToSlash(f.Label)
+		}
+	}
+	return nil
+}
+
+// listWorktrees returns the worktrees of the repository containing dir,
+// keyed by both their checked out branch and their directory name
+func listWorktrees(dir string) (map[string]string, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf(T("git worktree list failed: %s"), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf(T("git worktree list failed: %v"), err)
+	}
+
+	worktrees := make(map[string]string)
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			current = strings.TrimPrefix(line, "worktree ")
+			worktrees[filepath.Base(current)] = current
+		case strings.HasPrefix(line, "branch "):
+			branch := strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+			worktrees[branch] = current
+		}
+	}
+	return worktrees, nil
+}
+
+// displayPath returns the path shown for a file in the snapshot: its
+// virtual path when renamed, label/... for files inside a labeled folder, or
+// else the path relative to the config directory
+func (cs *CodeSnap) displayPath(path string) string {
+	if virtual, ok := cs.renamed(filepath.ToSlash(cs.relPath(path))); ok {
+		return cs.formatPath(virtual)
+	}
+	var root, label string
+	for dir, l := range cs.labels {
+		if len(dir) > len(root) && (path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))) {
+			root, label = dir, l
+		}
+	}
+	if label == "" {
+		return cs.formatPath(cs.relPath(path))
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return cs.formatPath(filepath.FromSlash(label))
+	}
+	return cs.formatPath(filepath.Join(filepath.FromSlash(label), rel))
+}
@@ -0,0 +1,61 @@
+package codesnap
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorktreeFolders(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	for _, tc := range []struct {
+		name, folder string
+		code         int
+		want         []string
+	}{
+		{"by branch", "  - path: src\n    worktree: feature/retry\n", 0,
+			[]string{"File: src/a.go", "File: feature/retry/a.go", "package retry"}},
+		{"by directory", "  - path: src\n    worktree: retry\n", 0,
+			[]string{"File: src/a.go", "File: retry/a.go", "package retry"}},
+		{"labeled", "  - path: src\n    worktree: feature/retry\n    label: new\n", 0,
+			[]string{"File: src/a.go", "File: new/a.go", "package retry"}},
+		{"unknown", "  - path: src\n    worktree: feature/none\n", exitError,
+			[]string{`no git worktree found for "feature/none"`}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "main")
+			if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "src", "a.go"), []byte("package a\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			git(t, dir, "init", "-q")
+			git(t, dir, "add", ".")
+			git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "a")
+			git(t, dir, "worktree", "add", "-q", "-b", "feature/retry", filepath.Join(root, "retry"))
+			if err := os.WriteFile(filepath.Join(root, "retry", "src", "a.go"), []byte("package retry\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "codesnap.yml"), []byte("folders:\n  - src\n"+tc.folder), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			r := runCodesnap(t, dir, "--stdout", "-q")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package codesnap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// renderText renders the results in the default plain text layout, with a
+// banner before each file and a summary at the end
+func (cs *CodeSnap) renderText(w io.Writer, results []fileResult) error {
+	allContent := bufio.NewWriter(w)
+	var emptyFiles []string
+	var included []graphFile
+	section := ""
+
+	summary := fmt.Sprintf("\n\n%s\nSummary:\n", strings.Repeat("=", 50)) +
+		cs.summaryList() + strings.Repeat("=", 50)
+	if cs.summaryFirst {
+		allContent.WriteString(strings.TrimPrefix(summary, "\n\n"))
+	}
+	if cs.tree != "" {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nFolder structure:\n%s\n\n%s",
+			strings.Repeat("=", 50), strings.Repeat("=", 50), strings.TrimRight(cs.tree, "\n")))
+	}
+
+	for _, r := range results {
+		if (r.err != nil && cs.config.SkippedFiles != "annotate") || (r.empty && cs.config.EmptyFiles != "include") {
+			if r.empty && cs.config.EmptyFiles == "list" {
+				emptyFiles = append(emptyFiles, r.relPath)
+			}
+			continue
+		}
+		if r.section != section {
+			section = r.section
+			allContent.WriteString(fmt.Sprintf("\n\n%s\nSection: %s\n%s",
+				strings.Repeat("#", 50), section, strings.Repeat("#", 50)))
+		}
+
+		switch {
+		case r.err != nil:
+			allContent.WriteString(fmt.Sprintf("\n\n%s\nFile: %s (skipped: %s)\n%s",
+				strings.Repeat("=", 50), r.relPath, skipReason(r.err), strings.Repeat("=", 50)))
+		case r.empty:
+			allContent.WriteString(fmt.Sprintf("\n\n%s\nFile: %s (empty)\n%s",
+				strings.Repeat("=", 50), r.relPath, strings.Repeat("=", 50)))
+		case r.duplicateOf != "":
+			allContent.WriteString(fmt.Sprintf("\n\n%s\nFile: %s (duplicate of %s)\n%s",
+				strings.Repeat("=", 50), r.relPath, r.duplicateOf, strings.Repeat("=", 50)))
+		default:
+			name := r.relPath
+			if r.condensed {
+				name += " (condensed)"
+			} else if r.diff {
+				name += " (diff)"
+			}
+			if r.truncatedFrom > 0 {
+				name += r.truncationNote()
+			}
+			allContent.WriteString(fmt.Sprintf("\n\n%s\nFile: %s\n%s\n\n%s",
+				strings.Repeat("=", 50), name, strings.Repeat("=", 50), cs.shownContent(r)))
+			included = append(included, graphFile{path: r.path, relPath: r.relPath, content: r.content})
+		}
+	}
+
+	for _, schema := range cs.schemas {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nDatabase: %s (%s)\n%s\n\n%s",
+			strings.Repeat("=", 50), schema.Name, schema.Dialect, strings.Repeat("=", 50), schema.DDL))
+	}
+
+	for _, c := range cs.commands {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nCommand: %s\n%s\n\n%s",
+			strings.Repeat("=", 50), c.title(), strings.Repeat("=", 50), c.Output))
+	}
+
+	// List empty files in a single appendix instead of one banner each
+	if len(emptyFiles) > 0 {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nEmpty files:\n%s\n",
+			strings.Repeat("=", 50), strings.Repeat("=", 50)))
+		for _, name := range emptyFiles {
+			allContent.WriteString(fmt.Sprintf("- %s\n", name))
+		}
+	}
+
+	if cs.listBinaries {
+		if binaries := binaryFiles(results); len(binaries) > 0 {
+			allContent.WriteString(fmt.Sprintf("\n\n%s\nBinary files:\n%s\n%s",
+				strings.Repeat("=", 50), strings.Repeat("=", 50), renderBinaries(binaries)))
+		}
+	}
+
+	if cs.graphFormat != "" {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nDependency graph:\n%s\n\n%s",
+			strings.Repeat("=", 50), strings.Repeat("=", 50), renderGraph(buildGraph(included), cs.graphFormat)))
+	}
+
+	if cs.symbolIndex {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nSymbol index:\n%s\n\n%s",
+			strings.Repeat("=", 50), strings.Repeat("=", 50), renderSymbols(buildSymbolIndex(included))))
+	}
+
+	if len(cs.environment) > 0 {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nEnvironment:\n%s\n%s",
+			strings.Repeat("=", 50), strings.Repeat("=", 50), renderEnvironment(cs.environment)))
+	}
+
+	if len(cs.notes) > 0 {
+		allContent.WriteString(fmt.Sprintf("\n\n%s\nNotes:\n%s\n",
+			strings.Repeat("=", 50), strings.Repeat("=", 50)))
+		for _, note := range cs.notes {
+			allContent.WriteString(fmt.Sprintf("- %s\n", note))
+		}
+	}
+
+	// Add summary
+	if !cs.summaryFirst {
+		allContent.WriteString(summary)
+	}
+	return allContent.Flush()
+}
+
+// summaryList returns the run statistics as "- name: value" lines, shared
+// by the text and Markdown formats
+func (cs *CodeSnap) summaryList() string {
+	stats := cs.stats
+	summary := fmt.Sprintf("- Files processed: %d\n", stats.processed) +
+		fmt.Sprintf("- Empty files: %d\n", stats.empty) +
+		fmt.Sprintf("- Files skipped: %d\n", stats.skipped)
+	if cs.config.Dedupe {
+		summary += fmt.Sprintf("- Duplicate files: %d\n", stats.duplicates)
+	}
+	if cs.incremental {
+		summary += fmt.Sprintf("- Unchanged files: %d\n", stats.unchanged)
+	}
+	if stats.redacted > 0 {
+		summary += fmt.Sprintf("- Secrets redacted: %d\n", stats.redacted)
+	}
+	summary += fmt.Sprintf("- Estimated tokens: ~%s\n", formatCount(stats.tokens))
+	for _, ft := range cs.fileTokens {
+		summary += fmt.Sprintf("    %s: ~%s\n", ft.Path, formatCount(ft.Tokens))
+	}
+	if len(cs.sectionTokens) > 0 {
+		summary += "- Estimated tokens by section:\n"
+		for _, st := range cs.sectionTokens {
+			summary += fmt.Sprintf("    %s: ~%s (%d files)\n", st.Name, formatCount(st.Tokens), st.Files)
+		}
+	}
+	if len(cs.inconsistencies) > 0 {
+		summary += fmt.Sprintf("- Line ending/encoding outliers: %d\n", len(cs.inconsistencies))
+		for _, inc := range cs.inconsistencies {
+			summary += fmt.Sprintf("    %s: %s\n", inc.Path, inc.Issue)
+		}
+	}
+	if len(cs.syntaxErrors) > 0 {
+		summary += fmt.Sprintf("- Files with syntax errors: %d\n", len(cs.syntaxErrors))
+		for _, se := range cs.syntaxErrors {
+			summary += fmt.Sprintf("    %s: %s\n", se.Path, se.Error)
+		}
+	}
+	return summary
+}
+
+// renderMarkdown renders the results as Markdown: a heading per file and
+// its content in a fenced code block tagged with the file's language, so
+// chat interfaces keep the syntax highlighting
+func (cs *CodeSnap) renderMarkdown(w io.Writer, results []fileResult) error {
+	b := bufio.NewWriter(w)
+	var emptyFiles []string
+	var included []graphFile
+	section := ""
+
+	summary := fmt.Sprintf("## Summary\n\n%s", cs.summaryList())
+	if cs.summaryFirst {
+		b.WriteString(summary + "\n")
+	}
+	if cs.tree != "" {
+		b.WriteString(fmt.Sprintf("## Folder structure\n\n%s\n", fenced(cs.tree, "")))
+	}
+
+	for _, r := range results {
+		if (r.err != nil && cs.config.SkippedFiles != "annotate") || (r.empty && cs.config.EmptyFiles != "include") {
+			if r.empty && cs.config.EmptyFiles == "list" {
+				emptyFiles = append(emptyFiles, r.relPath)
+			}
+			continue
+		}
+		if r.section != section {
+			section = r.section
+			b.WriteString(fmt.Sprintf("# %s\n\n", section))
+		}
+
+		switch {
+		case r.err != nil:
+			b.WriteString(fmt.Sprintf("## %s\n\n_Skipped: %s_\n\n", r.relPath, skipReason(r.err)))
+		case r.empty:
+			b.WriteString(fmt.Sprintf("## %s\n\n_(empty)_\n\n", r.relPath))
+		case r.duplicateOf != "":
+			b.WriteString(fmt.Sprintf("## %s\n\n_Duplicate of %s_\n\n", r.relPath, r.duplicateOf))
+		default:
+			heading := r.relPath
+			language := r.language
+			if r.condensed {
+				heading += " (condensed)"
+				language = ""
+			} else if r.diff {
+				heading += " (diff)"
+				language = "diff"
+			}
+			if r.truncatedFrom > 0 {
+				heading += r.truncationNote()
+			}
+			b.WriteString(fmt.Sprintf("## %s\n\n%s\n", heading, fenced(cs.shownContent(r), language)))
+			included = append(included, graphFile{path: r.path, relPath: r.relPath, content: r.content})
+		}
+	}
+
+	for _, schema := range cs.schemas {
+		b.WriteString(fmt.Sprintf("## Database: %s (%s)\n\n%s\n", schema.Name, schema.Dialect, fenced(schema.DDL, "sql")))
+	}
+
+	for _, c := range cs.commands {
+		b.WriteString(fmt.Sprintf("## Command: %s\n\n%s\n", c.title(), fenced(c.Output, "")))
+	}
+
+	if len(emptyFiles) > 0 {
+		b.WriteString("## Empty files\n\n")
+		for _, name := range emptyFiles {
+			b.WriteString(fmt.Sprintf("- %s\n", name))
+		}
+		b.WriteString("\n")
+	}
+
+	if cs.listBinaries {
+		if binaries := binaryFiles(results); len(binaries) > 0 {
+			b.WriteString("## Binary files\n\n" + renderBinaries(binaries) + "\n")
+		}
+	}
+
+	if cs.graphFormat != "" {
+		b.WriteString("## Dependency graph\n\n" + renderGraph(buildGraph(included), cs.graphFormat) + "\n")
+	}
+
+	if cs.symbolIndex {
+		b.WriteString("## Symbol index\n\n")
+		for _, sym := range buildSymbolIndex(included) {
+			b.WriteString(fmt.Sprintf("- `%s` (%s): %s:%d\n", sym.Name, sym.Kind, sym.Path, sym.Line))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(cs.environment) > 0 {
+		b.WriteString(fmt.Sprintf("## Environment\n\n%s\n", renderEnvironment(cs.environment)))
+	}
+
+	if len(cs.notes) > 0 {
+		b.WriteString("## Notes\n\n")
+		for _, note := range cs.notes {
+			b.WriteString(fmt.Sprintf("- %s\n", note))
+		}
+		b.WriteString("\n")
+	}
+
+	if !cs.summaryFirst {
+		b.WriteString(summary)
+	}
+	return b.Flush()
+}
+
+// fenced wraps content in a code fence longer than any backtick run inside
+// it, so files that contain fences themselves stay intact
+func fenced(content, language string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return fence + language + "\n" + content + fence + "\n"
+}
+
+// jsonFile is a file entry of the JSON format. The classification fields
+// let downstream tools filter without re-implementing the heuristics.
+type jsonFile struct {
+	Path        string `json:"path"`
+	Section     string `json:"section,omitempty"`
+	Size        int64  `json:"size"`
+	Language    string `json:"language,omitempty"`
+	License     string `json:"license,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	LineEndings string `json:"line_endings,omitempty"`
+	IsGenerated bool   `json:"is_generated"`
+	IsTest      bool   `json:"is_test"`
+	Empty       bool   `json:"empty,omitempty"`
+	Condensed   bool   `json:"condensed,omitempty"`
+	Diff        bool   `json:"diff,omitempty"`
+	Truncated   int64  `json:"truncated_from,omitempty"` // size on disk, when cut at max_file_size
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	Tokens      int    `json:"estimated_tokens,omitempty"`
+	SkipReason  string `json:"skip_reason,omitempty"`
+	Content     string `json:"content,omitempty"`
+}
+
+type jsonSummary struct {
+	Processed  int `json:"processed"`
+	Empty      int `json:"empty"`
+	Skipped    int `json:"skipped"`
+	Duplicates int `json:"duplicates"`
+	Unchanged  int `json:"unchanged,omitempty"`
+	// Redacted counts the secrets replaced with [REDACTED]
+	Redacted int `json:"redacted,omitempty"`
+	// EstimatedTokens is a heuristic count of the file contents' tokens
+	EstimatedTokens int `json:"estimated_tokens"`
+	// Sections totals the included files per configured section
+	Sections []sectionTokens `json:"sections,omitempty"`
+	// Inconsistencies lists files whose line endings or encoding differ
+	// from the majority
+	Inconsistencies []inconsistency `json:"inconsistencies,omitempty"`
+	// SyntaxErrors lists the files that failed check_syntax
+	SyntaxErrors []syntaxError `json:"syntax_errors,omitempty"`
+}
+
+type jsonSnapshot struct {
+	Files        []jsonFile          `json:"files"`
+	Dependencies map[string][]string `json:"dependencies,omitempty"`
+	Symbols      []symbol            `json:"symbols,omitempty"`
+	Binaries     []binaryFile        `json:"binaries,omitempty"`
+	Environment  []envFact           `json:"environment,omitempty"`
+	Tree         string              `json:"tree,omitempty"`
+	Databases    []databaseSchema    `json:"databases,omitempty"`
+	Commands     []commandOutput     `json:"commands,omitempty"`
+	Notes        []string            `json:"notes,omitempty"`
+	Summary      jsonSummary         `json:"summary"`
+}
+
+// renderJSON renders the results as a single JSON document. Skipped files
+// are listed with their skip_reason instead of being dropped.
+func (cs *CodeSnap) renderJSON(w io.Writer, results []fileResult) error {
+	snapshot := jsonSnapshot{
+		Files:       make([]jsonFile, 0, len(results)),
+		Databases:   cs.schemas,
+		Commands:    cs.commands,
+		Notes:       cs.notes,
+		Environment: cs.environment,
+		Tree:        cs.tree,
+		Summary: jsonSummary{
+			Processed:       cs.stats.processed,
+			Empty:           cs.stats.empty,
+			Skipped:         cs.stats.skipped,
+			Duplicates:      cs.stats.duplicates,
+			Unchanged:       cs.stats.unchanged,
+			Redacted:        cs.stats.redacted,
+			EstimatedTokens: cs.stats.tokens,
+			Inconsistencies: cs.inconsistencies,
+			SyntaxErrors:    cs.syntaxErrors,
+			Sections:        cs.sectionTokens,
+		},
+	}
+
+	var included []graphFile
+	for _, r := range results {
+		if r.empty && cs.config.EmptyFiles == "omit" {
+			continue
+		}
+		entry := jsonFile{
+			Path:        r.relPath,
+			Section:     r.section,
+			Size:        r.size,
+			Language:    r.languageOrExt(),
+			License:     r.license,
+			IsGenerated: isGenerated(r.path, r.content),
+			IsTest:      isTestFile(r.path),
+			Empty:       r.empty,
+			Condensed:   r.condensed,
+			Diff:        r.diff,
+			Truncated:   r.truncatedFrom,
+			DuplicateOf: r.duplicateOf,
+			Tokens:      r.tokens,
+		}
+		switch {
+		case r.err != nil:
+			entry.SkipReason = skipReason(r.err)
+		case r.duplicateOf == "":
+			entry.Encoding = r.encoding
+			entry.LineEndings = r.lineEndings
+			entry.Content = cs.shownContent(r)
+			if !r.empty {
+				included = append(included, graphFile{path: r.path, relPath: r.relPath, content: r.content})
+			}
+		}
+		snapshot.Files = append(snapshot.Files, entry)
+	}
+
+	if cs.graphFormat != "" {
+		snapshot.Dependencies = buildGraph(included)
+	}
+	if cs.symbolIndex {
+		snapshot.Symbols = buildSymbolIndex(included)
+	}
+	if cs.listBinaries {
+		snapshot.Binaries = binaryFiles(results)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snapshot); err != nil {
+		return fmt.Errorf(T("failed to encode JSON: %v"), err)
+	}
+	return nil
+}
+
+// skipReason maps a validation error to a stable, machine-readable reason
+func skipReason(err error) string {
+	switch {
+	case errors.Is(err, errBinaryFile):
+		return "binary"
+	case errors.Is(err, errInvalidUTF8):
+		return "invalid_utf8"
+	case errors.Is(err, errExcludedLicense):
+		return "license"
+	case errors.Is(err, errFileTooLarge):
+		return "too_large"
+	case errors.Is(err, errCredentialFile):
+		return "credentials"
+	case errors.Is(err, errOverBudget):
+		return "token_budget"
+	case errors.Is(err, errOverByteBudget):
+		return "byte_budget"
+	default:
+		return "unreadable"
+	}
+}
@@ -0,0 +1,264 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEmptyFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name, value, format string
+		want, unwanted      []string
+	}{
+		{"include", "", "text", []string{"File: empty.go (empty)"}, []string{"\nEmpty files:\n"}},
+		{"omit", "omit", "text", nil, []string{"empty.go"}},
+		{"list", "list", "text", []string{"Empty files:\n" + strings.Repeat("=", 50) + "\n- empty.go\n"}, []string{"File: empty.go"}},
+		{"list in markdown", "list", "markdown", []string{"- empty.go\n"}, []string{"## empty.go"}},
+		{"omit in json", "omit", "json", []string{`"path": "a.go"`}, []string{"empty.go"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := "folders:\n  - .\nignore:\n  - codesnap.yml\n"
+			if tc.value != "" {
+				config += "empty_files: " + tc.value + "\n"
+			}
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": config,
+				"a.go":         "package a\n",
+				"empty.go":     "",
+			})
+			r := runCodesnap(t, dir, "--format", tc.format, "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+
+	dir := writeFiles(t, map[string]string{"codesnap.yml": "folders:\n  - .\nempty_files: hide\n"})
+	if r := runCodesnap(t, dir, "--stdout"); r.code != exitError || !strings.Contains(r.stdout+r.stderr, `invalid empty_files value "hide"`) {
+		t.Errorf("exit code %d for an invalid value, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+}
+
+func TestSkippedFiles(t *testing.T) {
+	banner := strings.Repeat("=", 50)
+	for _, tc := range []struct {
+		name, value, format string
+		want, unwanted      []string
+	}{
+		{"omit", "", "text", []string{"File: a.go\n"}, []string{"logo.png", "big.txt"}},
+		{"annotate", "annotate", "text", []string{
+			"\n" + banner + "\nFile: big.txt (skipped: too_large)\n" + banner + "\n\n" + banner + "\nFile: logo.png (skipped: binary)\n" + banner + "\n",
+		}, nil},
+		{"annotate in markdown", "annotate", "markdown", []string{"## big.txt\n\n_Skipped: too_large_\n\n## logo.png\n\n_Skipped: binary_\n"}, nil},
+		{"annotate in json", "annotate", "json", []string{`"skip_reason": "binary"`, `"skip_reason": "too_large"`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := "folders:\n  - .\nignore:\n  - codesnap.yml\nmax_file_size: 1KB\nlarge_files: skip\n"
+			if tc.value != "" {
+				config += "skipped_files: " + tc.value + "\n"
+			}
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": config,
+				"a.go":         "package a\n",
+				"big.txt":      strings.Repeat("line\n", 500),
+				"logo.png":     "\x89PNG\x00\x00",
+			})
+			r := runCodesnap(t, dir, "--format", tc.format, "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+
+	dir := writeFiles(t, map[string]string{"codesnap.yml": "folders:\n  - .\nskipped_files: list\n"})
+	if r := runCodesnap(t, dir, "--stdout"); r.code != exitError || !strings.Contains(r.stdout+r.stderr, `invalid skipped_files value "list" (expected omit or annotate)`) {
+		t.Errorf("exit code %d for an invalid value, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":   "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"a.go":           "package a\n",
+		"a_test.go":      "package a\n\nimport \"testing\"\n",
+		"api.pb.go":      "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage a\n",
+		"image.png":      "\x89PNG\x00\x00",
+		"scripts/deploy": "#!/bin/sh\necho deploy\n",
+	})
+	r := runCodesnap(t, dir, "--format", "json", "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	var snapshot jsonSnapshot
+	if err := json.Unmarshal([]byte(r.stdout), &snapshot); err != nil {
+		t.Fatalf("the snapshot is not JSON: %v\n%s", err, r.stdout)
+	}
+	files := map[string]jsonFile{}
+	for _, f := range snapshot.Files {
+		files[f.Path] = f
+	}
+
+	for _, tc := range []struct {
+		path, language, skipReason string
+		generated, test            bool
+	}{
+		{"a.go", "go", "", false, false},
+		{"a_test.go", "go", "", false, true},
+		{"api.pb.go", "go", "", true, false},
+		{"image.png", "", "binary", false, false},
+		{"scripts/deploy", "bash", "", false, false},
+	} {
+		f, ok := files[tc.path]
+		if !ok {
+			t.Errorf("%s is not in the snapshot:\n%s", tc.path, r.stdout)
+			continue
+		}
+		if f.Language != tc.language || f.SkipReason != tc.skipReason || f.IsGenerated != tc.generated || f.IsTest != tc.test {
+			t.Errorf("%s = %+v, want language %q, skip reason %q, generated %v, test %v",
+				tc.path, f, tc.language, tc.skipReason, tc.generated, tc.test)
+		}
+		if (f.Content == "") != (tc.skipReason != "") {
+			t.Errorf("%s has content %q with skip reason %q", tc.path, f.Content, f.SkipReason)
+		}
+	}
+	if snapshot.Summary.Processed != 4 || snapshot.Summary.Skipped != 1 {
+		t.Errorf("summary = %+v, want 4 processed and 1 skipped", snapshot.Summary)
+	}
+}
+
+func TestNotes(t *testing.T) {
+	for _, tc := range []struct {
+		name, format string
+		notes        []string
+		want         string
+	}{
+		{"text", "text", []string{"Read a.go first", "Ignore the tests"},
+			"\nNotes:\n" + strings.Repeat("=", 50) + "\n- Read a.go first\n- Ignore the tests\n"},
+		{"markdown", "markdown", []string{"Read a.go first", "Ignore the tests"}, "## Notes\n\n- Read a.go first\n- Ignore the tests\n"},
+		{"json", "json", []string{"Read a.go first"}, "\"notes\": [\n    \"Read a.go first\"\n  ]"},
+		{"none", "text", nil, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+			})
+			args := []string{"--format", tc.format, "--stdout", "-q"}
+			for _, note := range tc.notes {
+				args = append(args, "--note", note)
+			}
+			r := runCodesnap(t, dir, args...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			if tc.want == "" {
+				if strings.Contains(r.stdout, "Notes") {
+					t.Errorf("snapshot has notes without --note:\n%s", r.stdout)
+				}
+			} else if !strings.Contains(r.stdout, tc.want) {
+				t.Errorf("snapshot lacks %q, got:\n%s", tc.want, r.stdout)
+			}
+		})
+	}
+}
+
+func TestFenced(t *testing.T) {
+	for _, tc := range []struct {
+		content, language, want string
+	}{
+		{"package a\n", "go", "```go\npackage a\n```\n"},
+		{"no newline", "", "```\nno newline\n```\n"},
+		{"Use ```go blocks\n", "markdown", "````markdown\nUse ```go blocks\n````\n"},
+		{"````\n", "", "`````\n````\n`````\n"},
+	} {
+		if got := fenced(tc.content, tc.language); got != tc.want {
+			t.Errorf("fenced(%q, %q) = %q, want %q", tc.content, tc.language, got, tc.want)
+		}
+	}
+}
+
+func TestMarkdownFormat(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"a.go":         "package a\n",
+		"README.md":    "# Title\n\n```sh\nmake\n```\n",
+		"run":          "#!/usr/bin/env python3\nprint(1)\n",
+	})
+	r := runCodesnap(t, dir, "--format", "markdown", "--with-tree", "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, want := range []string{
+		"## Folder structure\n\n```\n",
+		"## a.go\n\n```go\npackage a\n```\n",
+		"## README.md\n\n````markdown\n# Title\n\n```sh\nmake\n```\n````\n",
+		"## run\n\n```python\n",
+		"## Summary\n\n- Files processed: 3\n",
+	} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("snapshot lacks %q, got:\n%s", want, r.stdout)
+		}
+	}
+}
+
+func TestWithTree(t *testing.T) {
+	banner := strings.Repeat("=", 50)
+	for _, tc := range []struct {
+		name, options  string
+		args           []string
+		want, unwanted []string
+	}{
+		{"text", "", []string{"--with-tree"},
+			[]string{"\n" + banner + "\nFolder structure:\n" + banner + "\n\n", "── src/\n", "── a.go\n", "File: src/a.go\n"}, nil},
+		{"include_tree", "include_tree: true\n", nil, []string{"Folder structure:\n", "File: src/a.go\n"}, nil},
+		{"no tree", "include_tree: true\n", []string{"--no-tree"}, []string{"File: src/a.go\n"}, []string{"Folder structure"}},
+		{"json", "", []string{"--with-tree", "--format", "json"}, []string{`"tree": "`, `src/`}, nil},
+		{"off", "", nil, []string{"File: src/a.go\n"}, []string{"Folder structure", "── "}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"src/a.go":     "package a\n",
+				"src/b.go":     "package a\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot has %q, got:\n%s", s, r.stdout)
+				}
+			}
+			if tree := strings.Index(r.stdout, "Folder structure"); tree > strings.Index(r.stdout, "File: src/a.go") {
+				t.Errorf("the tree does not come before the files:\n%s", r.stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package codesnap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadGoPackages resolves Go package patterns such as ./internal/auth/...
+// in the config directory and returns the source files of the matched
+// packages and of the packages of the same module they import directly.
+// Test files and the files a build constraint excludes are left out.
+func (cs *CodeSnap) loadGoPackages(patterns []string) ([]string, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedEmbedFiles | packages.NeedImports | packages.NeedModule,
+		Dir:  cs.configDir,
+	}
+	roots, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to load Go packages: %v"), err)
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	add := func(pkg *packages.Package) {
+		for _, list := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.EmbedFiles} {
+			for _, file := range list {
+				if !seen[file] {
+					seen[file] = true
+					files = append(files, file)
+				}
+			}
+		}
+	}
+	for _, pkg := range roots {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf(T("Go package %s: %v"), pkg.PkgPath, pkg.Errors[0])
+		}
+		add(pkg)
+		if pkg.Module == nil {
+			continue
+		}
+		for _, imp := range pkg.Imports {
+			if imp.Module != nil && imp.Module.Path == pkg.Module.Path {
+				add(imp)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, nothingCollected{fmt.Errorf(T("no Go package matches %s"), strings.Join(patterns, " "))}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,63 @@
+package codesnap
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestGoPackage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOTOOLCHAIN", "local")
+	for _, tc := range []struct {
+		name, ignore   string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"a package and its imports", "", []string{"--go-package", "./internal/auth"}, 0,
+			[]string{"File: internal/auth/auth.go\n", "File: internal/db/db.go\n"},
+			[]string{"File: cmd/", "File: internal/log/", "auth_test.go", "tool.go", "README.md"}},
+		{"a pattern", "", []string{"--go-package", "./internal/..."}, 0,
+			[]string{"File: internal/auth/auth.go\n", "File: internal/db/db.go\n", "File: internal/log/log.go\n"}, []string{"File: cmd/"}},
+		{"repeated", "", []string{"--go-package", "./cmd", "--go-package", "./internal/log"}, 0,
+			[]string{"File: cmd/main.go\n", "File: internal/auth/auth.go\n", "File: internal/log/log.go\n"}, []string{"File: internal/db/"}},
+		{"ignore still applies", "  - \"**/db.go\"\n", []string{"--go-package", "./internal/auth"}, 0,
+			[]string{"File: internal/auth/auth.go\n"}, []string{"db.go"}},
+		{"no package", "", []string{"--go-package", "./docs/..."}, exitNothingCollected, []string{"no Go package matches ./docs/..."}, nil},
+		{"broken package", "", []string{"--go-package", "./missing"}, exitError, []string{"Go package "}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml":               "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.ignore,
+				"go.mod":                     "module example.com/app\n\ngo 1.21\n",
+				"README.md":                  "# app\n",
+				"docs/guide.md":              "# guide\n",
+				"cmd/main.go":                "package main\n\nimport \"example.com/app/internal/auth\"\n\nfunc main() { auth.Check() }\n",
+				"internal/auth/auth.go":      "package auth\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/internal/db\"\n)\n\nfunc Check() { fmt.Println(db.Open()) }\n",
+				"internal/auth/auth_test.go": "package auth\n",
+				"internal/auth/tool.go":      "//go:build ignore\n\npackage main\n",
+				"internal/db/db.go":          "package db\n\nimport \"example.com/app/internal/log\"\n\nfunc Open() string { log.Print(); return \"ok\" }\n",
+				"internal/log/log.go":        "package log\n\nfunc Print() {}\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
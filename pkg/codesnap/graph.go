@@ -0,0 +1,268 @@
+package codesnap
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// graphFile is an included file considered for the dependency graph
+type graphFile struct {
+	path    string
+	relPath string
+	content string
+}
+
+var (
+	jsImportPattern = regexp.MustCompile(`(?m)(?:import|export)\s[^'"]*?from\s*['"]([^'"]+)['"]|import\s*\(?\s*['"]([^'"]+)['"]|require\(\s*['"]([^'"]+)['"]\s*\)`)
+	pyImportPattern = regexp.MustCompile(`(?m)^\s*(?:from\s+(\.*)([\w.]*)\s+import|import\s+([\w.]+(?:\s*,\s*[\w.]+)*))`)
+	jsExtensions    = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+)
+
+// buildGraph parses the imports of Go, JavaScript/TypeScript and Python
+// files and returns the edges between included files. Go packages are
+// represented by their directory, other languages by their file path.
+func buildGraph(files []graphFile) map[string][]string {
+	// Index the included files by slash-separated relative path
+	byPath := make(map[string]bool, len(files))
+	goDirs := make(map[string]bool)
+	for _, f := range files {
+		rel := filepath.ToSlash(f.relPath)
+		byPath[rel] = true
+		if strings.HasSuffix(rel, ".go") {
+			goDirs[path.Dir(rel)] = true
+		}
+	}
+
+	edges := make(map[string]map[string]bool)
+	addEdge := func(from, to string) {
+		if from == to {
+			return
+		}
+		if edges[from] == nil {
+			edges[from] = make(map[string]bool)
+		}
+		edges[from][to] = true
+	}
+
+	modules := make(map[string]goModule)
+	for _, f := range files {
+		rel := filepath.ToSlash(f.relPath)
+		switch ext := strings.ToLower(path.Ext(rel)); {
+		case ext == ".go":
+			mod, ok := modules[filepath.Dir(f.path)]
+			if !ok {
+				mod = findGoModule(f.path, f.relPath)
+				modules[filepath.Dir(f.path)] = mod
+			}
+			for _, imp := range goImports(f) {
+				if mod.path == "" || (imp != mod.path && !strings.HasPrefix(imp, mod.path+"/")) {
+					continue
+				}
+				dir := path.Clean(path.Join(mod.relDir, strings.TrimPrefix(imp, mod.path)))
+				if goDirs[dir] {
+					addEdge(goNode(path.Dir(rel)), goNode(dir))
+				}
+			}
+		case contains(jsExtensions, ext):
+			for _, m := range jsImportPattern.FindAllStringSubmatch(f.content, -1) {
+				spec := m[1] + m[2] + m[3]
+				if !strings.HasPrefix(spec, ".") {
+					continue
+				}
+				if target := resolveJSImport(byPath, path.Join(path.Dir(rel), spec)); target != "" {
+					addEdge(rel, target)
+				}
+			}
+		case ext == ".py":
+			for _, m := range pyImportPattern.FindAllStringSubmatch(f.content, -1) {
+				var names []string
+				if m[3] != "" {
+					for _, name := range strings.Split(m[3], ",") {
+						names = append(names, strings.TrimSpace(name))
+					}
+				} else {
+					names = []string{m[2]}
+				}
+				for _, name := range names {
+					if target := resolvePyImport(byPath, rel, len(m[1]), name); target != "" {
+						addEdge(rel, target)
+					}
+				}
+			}
+		}
+	}
+
+	graph := make(map[string][]string, len(edges))
+	for from, targets := range edges {
+		for to := range targets {
+			graph[from] = append(graph[from], to)
+		}
+		sort.Strings(graph[from])
+	}
+	return graph
+}
+
+// goModule describes the module a Go file belongs to
+type goModule struct {
+	path   string // module path declared in go.mod
+	relDir string // module root relative to the config directory
+}
+
+// findGoModule walks up from a Go file to the nearest go.mod. relPath is the
+// file's path relative to the config directory, used to express the module
+// root in the same terms.
+func findGoModule(file, relPath string) goModule {
+	dir := filepath.Dir(file)
+	relDir := path.Dir(filepath.ToSlash(relPath))
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+					return goModule{path: strings.Trim(fields[1], `"`), relDir: relDir}
+				}
+			}
+			return goModule{}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return goModule{}
+		}
+		dir = parent
+		relDir = path.Join(relDir, "..")
+	}
+}
+
+func goImports(f graphFile) []string {
+	parsed, err := parser.ParseFile(token.NewFileSet(), f.path, f.content, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+	var imports []string
+	for _, spec := range parsed.Imports {
+		if imp, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+func goNode(dir string) string {
+	return dir + "/"
+}
+
+// resolveJSImport maps a relative import specifier to an included file,
+// trying the usual extension and index file conventions
+func resolveJSImport(byPath map[string]bool, target string) string {
+	if byPath[target] {
+		return target
+	}
+	for _, ext := range jsExtensions {
+		if byPath[target+ext] {
+			return target + ext
+		}
+	}
+	for _, ext := range jsExtensions {
+		if index := path.Join(target, "index"+ext); byPath[index] {
+			return index
+		}
+	}
+	return ""
+}
+
+// resolvePyImport maps a module name to an included file. Relative imports
+// (level > 0) are resolved from the importing file, absolute imports match
+// any included file with the corresponding path suffix.
+func resolvePyImport(byPath map[string]bool, from string, level int, module string) string {
+	modPath := strings.ReplaceAll(module, ".", "/")
+	candidates := []string{modPath + ".py", path.Join(modPath, "__init__.py")}
+
+	if level > 0 {
+		base := path.Dir(from)
+		for i := 1; i < level; i++ {
+			base = path.Dir(base)
+		}
+		for _, c := range candidates {
+			if target := path.Join(base, c); byPath[target] {
+				return target
+			}
+		}
+		return ""
+	}
+
+	if module == "" {
+		return ""
+	}
+	var matches []string
+	for p := range byPath {
+		for _, c := range candidates {
+			if p == c || strings.HasSuffix(p, "/"+c) {
+				matches = append(matches, p)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	// Prefer the shortest match, i.e. the one closest to a source root
+	sort.Slice(matches, func(i, j int) bool { return len(matches[i]) < len(matches[j]) })
+	return matches[0]
+}
+
+// renderGraph formats the graph as a Mermaid flowchart or a Graphviz digraph
+func renderGraph(graph map[string][]string, format string) string {
+	var from []string
+	for node := range graph {
+		from = append(from, node)
+	}
+	sort.Strings(from)
+
+	var b strings.Builder
+	if format == "dot" {
+		b.WriteString("```dot\ndigraph dependencies {\n    rankdir=LR;\n")
+		for _, node := range from {
+			for _, to := range graph[node] {
+				b.WriteString(fmt.Sprintf("    %q -> %q;\n", node, to))
+			}
+		}
+		b.WriteString("}\n```\n")
+		return b.String()
+	}
+
+	// Mermaid node ids must be plain identifiers, so label them separately
+	ids := make(map[string]string)
+	id := func(node string) string {
+		if _, ok := ids[node]; !ok {
+			ids[node] = fmt.Sprintf("n%d", len(ids))
+			return fmt.Sprintf("%s[\"%s\"]", ids[node], strings.ReplaceAll(node, `"`, "#quot;"))
+		}
+		return ids[node]
+	}
+	b.WriteString("```mermaid\ngraph LR\n")
+	for _, node := range from {
+		for _, to := range graph[node] {
+			b.WriteString(fmt.Sprintf("    %s --> %s\n", id(node), id(to)))
+		}
+	}
+	if len(from) == 0 {
+		b.WriteString("    %% no dependencies between the included files\n")
+	}
+	b.WriteString("```\n")
+	return b.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package codesnap
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildGraph(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		want  map[string][]string
+	}{
+		{"javascript", map[string]string{
+			"src/app.ts":        "import { a } from './lib/a'\nimport b from \"./b.js\"\nimport React from 'react'\n",
+			"src/b.js":          "const c = require('./c')\nexport * from './lib'\n",
+			"src/c.jsx":         "import('./app')\n",
+			"src/lib/a.ts":      "export const a = 1\n",
+			"src/lib/index.tsx": "export {}\n",
+		}, map[string][]string{
+			"src/app.ts": {"src/b.js", "src/lib/a.ts"},
+			"src/b.js":   {"src/c.jsx", "src/lib/index.tsx"},
+			"src/c.jsx":  {"src/app.ts"},
+		}},
+		{"python", map[string]string{
+			"app/main.py":           "from .models import User\nfrom ..shared import util\nimport os, app.views\n",
+			"app/models.py":         "import json\n",
+			"app/views/__init__.py": "from app.models import User\n",
+			"shared/util.py":        "",
+		}, map[string][]string{
+			"app/main.py":           {"app/models.py", "app/views/__init__.py"},
+			"app/views/__init__.py": {"app/models.py"},
+		}},
+		{"go packages", map[string]string{
+			"go.mod":            "module example.com/app\n",
+			"main.go":           "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/internal/db\"\n)\n",
+			"internal/db/db.go": "package db\n\nimport \"example.com/app/internal/log\"\n",
+			"internal/log/a.go": "package log\n\nimport \"example.com/app/missing\"\n",
+		}, map[string][]string{
+			"./":           {"internal/db/"},
+			"internal/db/": {"internal/log/"},
+		}},
+		{"no dependencies", map[string]string{"a.js": "console.log(1)\n"}, map[string][]string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			var files []graphFile
+			for rel, content := range tc.files {
+				if strings.HasSuffix(rel, ".mod") {
+					continue
+				}
+				files = append(files, graphFile{path: filepath.Join(dir, rel), relPath: filepath.FromSlash(rel), content: content})
+			}
+			if got := buildGraph(files); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("buildGraph = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderGraph(t *testing.T) {
+	graph := map[string][]string{"a.ts": {"b.ts", "c.ts"}, "b.ts": {"c.ts"}}
+	for _, tc := range []struct {
+		name, format string
+		graph        map[string][]string
+		want         string
+	}{
+		{"mermaid", "mermaid", graph, "```mermaid\ngraph LR\n" +
+			"    n0[\"a.ts\"] --> n1[\"b.ts\"]\n    n0 --> n2[\"c.ts\"]\n    n1 --> n2\n```\n"},
+		{"dot", "dot", graph, "```dot\ndigraph dependencies {\n    rankdir=LR;\n" +
+			"    \"a.ts\" -> \"b.ts\";\n    \"a.ts\" -> \"c.ts\";\n    \"b.ts\" -> \"c.ts\";\n}\n```\n"},
+		{"empty mermaid", "mermaid", nil, "```mermaid\ngraph LR\n    %% no dependencies between the included files\n```\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := renderGraph(tc.graph, tc.format); got != tc.want {
+				t.Errorf("renderGraph =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGraphFlag(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\n",
+		"a.js":         "import './b'\n",
+		"b.js":         "export default 1\n",
+	})
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"mermaid", []string{"--graph"}, 0, `n0["a.js"] --> n1["b.js"]`},
+		{"dot", []string{"--graph", "--graph-format", "dot"}, 0, `"a.js" -> "b.js";`},
+		{"json", []string{"--graph", "--format", "json"}, 0, `"dependencies": {`},
+		{"invalid format", []string{"--graph", "--graph-format", "svg"}, exitError, `invalid graph format "svg"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := runCodesnap(t, dir, append(tc.args, "--stdout")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if out := r.stdout + r.stderr; !strings.Contains(out, tc.want) {
+				t.Errorf("output lacks %q, got:\n%s", tc.want, out)
+			}
+		})
+	}
+}
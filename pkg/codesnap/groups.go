@@ -0,0 +1,111 @@
+package codesnap
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// fileGroups are the @name patterns of include and ignore. An entry
+// starting with a dot is a file name suffix such as .png or .tar.gz, any
+// other entry a whole file name; both are compared case-insensitively.
+// The media groups follow the extensions of the image/, video/, audio/
+// and font/ MIME types.
+var fileGroups = map[string][]string{
+	"images": {
+		".png", ".jpg", ".jpeg", ".gif", ".bmp", ".webp", ".tif", ".tiff", ".ico",
+		".icns", ".svg", ".heic", ".heif", ".avif", ".psd", ".raw", ".cr2", ".nef",
+	},
+	"video": {
+		".mp4", ".m4v", ".mov", ".avi", ".mkv", ".webm", ".wmv", ".flv", ".mpg",
+		".mpeg", ".3gp", ".ogv",
+	},
+	"audio": {
+		".mp3", ".wav", ".flac", ".aac", ".ogg", ".oga", ".opus", ".m4a", ".wma",
+		".aiff", ".mid", ".midi",
+	},
+	"fonts": {".ttf", ".otf", ".woff", ".woff2", ".eot", ".fon", ".pfb"},
+	"archives": {
+		".zip", ".tar", ".gz", ".tgz", ".bz2", ".tbz2", ".xz", ".txz", ".zst",
+		".7z", ".rar", ".lz", ".lzma", ".z", ".cab", ".jar", ".war", ".ear",
+		".whl", ".egg", ".deb", ".rpm", ".apk", ".dmg", ".iso",
+	},
+	"binaries": {
+		".exe", ".dll", ".so", ".dylib", ".a", ".lib", ".o", ".obj", ".class",
+		".pyc", ".pyo", ".wasm", ".bin", ".dat",
+	},
+	"documents": {
+		".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".odt",
+		".ods", ".odp", ".rtf", ".epub",
+	},
+	"docs": {".md", ".markdown", ".rst", ".adoc", ".txt", ".org", ".tex", "license", "copying"},
+	"config": {
+		".json", ".jsonc", ".yaml", ".yml", ".toml", ".ini", ".xml", ".cfg",
+		".conf", ".properties", ".env", ".editorconfig", ".gitignore",
+		".gitattributes", ".dockerignore", ".npmrc", ".nvmrc", ".prettierrc",
+		".eslintrc",
+	},
+	"lockfiles": {
+		"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "cargo.lock",
+		"poetry.lock", "pipfile.lock", "composer.lock", "gemfile.lock", "bun.lockb",
+	},
+	"data": {
+		".csv", ".tsv", ".parquet", ".avro", ".orc", ".arrow", ".feather",
+		".sqlite", ".db", ".h5", ".npy", ".pkl",
+	},
+}
+
+func init() {
+	// Source files are the languages codesnap knows, minus the config and
+	// documentation formats, which have their own groups
+	var source []string
+	for ext, lang := range languagesByExtension {
+		switch lang {
+		case "json", "yaml", "toml", "ini", "xml", "markdown", "rst", "latex":
+			continue
+		}
+		source = append(source, ext)
+	}
+	sort.Strings(source)
+	fileGroups["source"] = append(source, "makefile", "dockerfile", "rakefile", "gemfile")
+}
+
+// checkGroup reports an error if pattern names an unknown @group; other
+// patterns are left to doublestar
+func checkGroup(pattern string) error {
+	if name, ok := strings.CutPrefix(pattern, "@"); ok {
+		if _, known := fileGroups[name]; !known {
+			return fmt.Errorf(T("unknown file group %q (expected one of %s)"), pattern, groupNames())
+		}
+	}
+	return nil
+}
+
+// matchPattern reports whether the slash-separated rel matches a glob, or
+// for @group patterns, whether its file name belongs to the group
+func matchPattern(pattern, rel string) bool {
+	name, ok := strings.CutPrefix(pattern, "@")
+	if !ok {
+		matched, _ := doublestar.Match(pattern, rel)
+		return matched
+	}
+	base := strings.ToLower(path.Base(rel))
+	for _, entry := range fileGroups[name] {
+		if base == entry || (entry[0] == '.' && strings.HasSuffix(base, entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+func groupNames() string {
+	names := make([]string, 0, len(fileGroups))
+	for name := range fileGroups {
+		names = append(names, "@"+name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
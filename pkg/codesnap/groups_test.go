@@ -0,0 +1,83 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"@images", "assets/logo.PNG", true},
+		{"@images", "assets/png", false},
+		{"@archives", "dist/app.tar.gz", true},
+		{"@lockfiles", "web/yarn.lock", true},
+		{"@lockfiles", "web/yarn.lock.bak", false},
+		{"@docs", "LICENSE", true},
+		{"@source", "cmd/main.go", true},
+		{"@source", "Makefile", true},
+		{"@source", "config.yaml", false},
+		{"@config", "config.yaml", true},
+		{"**/*.go", "cmd/main.go", true},
+		{"*.go", "cmd/main.go", false},
+	} {
+		if got := matchPattern(tc.pattern, tc.rel); got != tc.want {
+			t.Errorf("matchPattern(%s, %s) = %v, want %v", tc.pattern, tc.rel, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		pattern, err string
+	}{
+		{"@images", ""},
+		{"**/*.go", ""},
+		{"@pictures", `unknown file group "@pictures" (expected one of @archives, @audio, @binaries, @config, `},
+	} {
+		err := checkGroup(tc.pattern)
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("checkGroup(%s) = %v, want %q", tc.pattern, err, tc.err)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	for _, tc := range []struct {
+		name, options  string
+		code           int
+		want, unwanted []string
+	}{
+		{"ignore", "  - \"@images\"\n  - \"@lockfiles\"\n", 0,
+			[]string{"File: main.go\n", "File: README.md\n"}, []string{"File: logo.png", "File: go.sum"}},
+		{"include", "include:\n  - \"@source\"\n", 0,
+			[]string{"File: main.go\n"}, []string{"File: README.md", "File: go.sum"}},
+		{"unknown ignore group", "  - \"@pictures\"\n", exitError, []string{`unknown file group "@pictures"`}, nil},
+		{"unknown include group", "include:\n  - \"@code\"\n", exitError, []string{`unknown file group "@code"`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"main.go":      "package main\n",
+				"README.md":    "# Project\n",
+				"go.sum":       "example.com/x v1.0.0 h1:abc=\n",
+				"logo.png":     "<svg/>\n",
+			})
+			r := runCodesnap(t, dir, "--stdout", "-q")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package codesnap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/blake3"
+)
+
+// hashAlgorithms maps the names accepted by the hash config key to their
+// constructors. xxhash is the default: change detection only needs a fast,
+// well-distributed digest, not a cryptographic one.
+var hashAlgorithms = map[string]func() hash.Hash{
+	"xxhash": func() hash.Hash { return xxhash.New() },
+	"blake3": func() hash.Hash { return blake3.New() },
+	"sha256": sha256.New,
+}
+
+// contentHash returns the hex digest of content using the configured algorithm
+func (cs *CodeSnap) contentHash(content []byte) string {
+	h := hashAlgorithms[cs.config.Hash]()
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validateHashAlgorithm(name string) error {
+	if _, ok := hashAlgorithms[name]; !ok {
+		return fmt.Errorf(T("invalid hash value %q (expected xxhash, blake3 or sha256)"), name)
+	}
+	return nil
+}
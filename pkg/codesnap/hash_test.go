@@ -0,0 +1,58 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentHash(t *testing.T) {
+	for _, tc := range []struct {
+		algorithm, want string
+	}{
+		{"xxhash", "ef46db3751d8e999"},
+		{"sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"blake3", "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+	} {
+		cs := &CodeSnap{config: &Config{Hash: tc.algorithm}}
+		if got := cs.contentHash(nil); got != tc.want {
+			t.Errorf("%s of nothing = %s, want %s", tc.algorithm, got, tc.want)
+		}
+	}
+	if err := validateHashAlgorithm("md5"); err == nil {
+		t.Error("md5 is accepted as a hash")
+	}
+}
+
+func TestDuplicateFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name, hash, format string
+		want               string
+	}{
+		{"default hash", "", "text", "File: b/a.go (duplicate of a.go)"},
+		{"sha256", "sha256", "text", "File: b/a.go (duplicate of a.go)"},
+		{"markdown", "blake3", "markdown", "## b/a.go\n\n_Duplicate of a.go_"},
+		{"json", "", "json", `"duplicate_of": "a.go"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := "folders:\n  - .\nignore:\n  - codesnap.yml\ndedupe: true\n"
+			if tc.hash != "" {
+				config += "hash: " + tc.hash + "\n"
+			}
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": config,
+				"a.go":         "package a\n",
+				"b/a.go":       "package a\n",
+			})
+			r := runCodesnap(t, dir, "--format", tc.format, "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			if !strings.Contains(r.stdout, tc.want) {
+				t.Errorf("snapshot lacks %q, got:\n%s", tc.want, r.stdout)
+			}
+			if n := strings.Count(r.stdout, "package a"); n != 1 {
+				t.Errorf("the content is in the snapshot %d times, want once", n)
+			}
+		})
+	}
+}
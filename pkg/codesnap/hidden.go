@@ -0,0 +1,32 @@
+package codesnap
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// toolDirNames are the hidden directories of version control systems and
+// editors, which are skipped unless include_hidden is true
+var toolDirNames = map[string]bool{
+	".git":    true,
+	".hg":     true,
+	".svn":    true,
+	".idea":   true,
+	".vscode": true,
+}
+
+// hiddenExcluded reports whether a file or directory found below a
+// configured folder is left out for its dot name. By default only the
+// tool directories are; include_hidden: false leaves out every dotfile
+// and dot-directory, and include_hidden: true (or --hidden) none. Folders
+// and files named in the config are always used.
+func (cs *CodeSnap) hiddenExcluded(path string, isDir bool) bool {
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, ".") {
+		return false
+	}
+	if cs.config.IncludeHidden == nil {
+		return isDir && toolDirNames[name]
+	}
+	return !*cs.config.IncludeHidden
+}
@@ -0,0 +1,41 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTreeLeavesOutHidden(t *testing.T) {
+	for _, tc := range []struct {
+		name, config string
+		want, hidden []string
+	}{
+		{"default", "", []string{".env", "a.go"}, []string{".git"}},
+		{"include_hidden false", "include_hidden: false\n", []string{"a.go"}, []string{".git", ".env"}},
+		{"include_hidden true", "include_hidden: true\n", []string{".git", ".env", "a.go"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - path: .\n" + tc.config,
+				".git/HEAD":    "ref: refs/heads/main\n",
+				".env":         "KEY=value\n",
+				"a.go":         "package a\n",
+			})
+
+			r := runCodesnap(t, dir, "-t", "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, name := range tc.want {
+				if !strings.Contains(r.stdout, "── "+name) {
+					t.Errorf("tree lacks %s, got:\n%s", name, r.stdout)
+				}
+			}
+			for _, name := range tc.hidden {
+				if strings.Contains(r.stdout, "── "+name) {
+					t.Errorf("tree shows %s, got:\n%s", name, r.stdout)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package codesnap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Hooks are shell commands run in the config directory around a snapshot:
+//
+//	hooks:
+//	  pre: make generate
+//	  post: ./notify.sh {{.Output}}
+//
+// The commands are Go templates over hookData, with the strings quoted for
+// the shell; the same values are set as CODESNAP_OUTPUT, CODESNAP_FILES
+// etc. in their environment. A failing pre hook aborts the snapshot.
+type Hooks struct {
+	Pre  string `yaml:"pre"`
+	Post string `yaml:"post"`
+}
+
+// hookData describes the snapshot to the hook commands
+type hookData struct {
+	Config string // path of the config file
+	Format string // text, markdown or json
+	Output string // file or directory the snapshot was written to, if any
+	Files  int    // files included
+	Bytes  int
+	Tokens int // estimated
+}
+
+// parseHook compiles a hook command; an empty command yields nil
+func parseHook(name, command string) (*template.Template, error) {
+	if command == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Option("missingkey=error").Parse(command)
+	if err == nil {
+		// Catch unknown fields now rather than after the snapshot was taken
+		err = t.Execute(io.Discard, hookData{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("invalid %s hook: %v"), name, err)
+	}
+	return t, nil
+}
+
+// runHook runs a compiled hook command with data; a nil hook does nothing
+func (cs *CodeSnap) runHook(hook *template.Template, data hookData) error {
+	if hook == nil {
+		return nil
+	}
+	var command strings.Builder
+	if err := hook.Execute(&command, data.quoted()); err != nil {
+		return fmt.Errorf(T("invalid %s hook: %v"), hook.Name(), err)
+	}
+
+	cmd := shellCommand(command.String())
+	cmd.Dir = cs.configDir
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(),
+		"CODESNAP_CONFIG="+data.Config,
+		"CODESNAP_FORMAT="+data.Format,
+		"CODESNAP_OUTPUT="+data.Output,
+		"CODESNAP_FILES="+strconv.Itoa(data.Files),
+		"CODESNAP_BYTES="+strconv.Itoa(data.Bytes),
+		"CODESNAP_TOKENS="+strconv.Itoa(data.Tokens),
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(T("%s hook %q failed: %v"), hook.Name(), command.String(), err)
+	}
+	return nil
+}
+
+// quoted returns data with its strings quoted for the shell, so an output
+// path with spaces, quotes or $ stays a single word
+func (d hookData) quoted() hookData {
+	d.Config, d.Format, d.Output = shellQuote(d.Config), shellQuote(d.Format), shellQuote(d.Output)
+	return d
+}
+
+// shellQuote quotes s as one word for the shell of shellCommand
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellCommand runs command through the platform's shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// runPostHook runs the post hook for a finished snapshot. The snapshot has
+// already been delivered, so a failure is only reported.
+func (cs *CodeSnap) runPostHook(size, tokens int) {
+	data := hookData{
+		Config: cs.configPath,
+		Format: cs.format,
+		Output: cs.outputPath,
+		Files:  cs.stats.processed,
+		Bytes:  size,
+		Tokens: tokens,
+	}
+	if err := cs.runHook(cs.postHook, data); err != nil {
+		fmt.Printf(T("Warning: %v\n"), err)
+	}
+}
@@ -0,0 +1,63 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("printf is not a cmd command")
+	}
+	for _, tc := range []struct {
+		name, value string
+	}{
+		{"plain", "out.txt"},
+		{"spaces", "my snapshot.txt"},
+		{"quotes", `it's "here".txt`},
+		{"command substitution", "$(touch pwned)`touch pwned`.txt"},
+		{"empty", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cmd := shellCommand("printf '%s' " + shellQuote(tc.value))
+			cmd.Dir = dir
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tc.value {
+				t.Errorf("the shell got %q, want %q", out, tc.value)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+				t.Error("the value was run as a command")
+			}
+		})
+	}
+}
+
+func TestPostHookOutputIsQuoted(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: src\nhooks:\n  post: printf '%s' {{.Output}} > hook.txt\n",
+		"src/a.go":     "package a\n",
+	})
+	output := "snap $(touch pwned).txt"
+
+	r := runCodesnap(t, dir, "-O", output, "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "hook.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(got), output) {
+		t.Errorf("the hook got %q, want the path %q", got, output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Error("the output path was run as a command")
+	}
+}
@@ -0,0 +1,560 @@
+package codesnap
+
+import (
+	"os"
+	"strings"
+)
+
+// language is the active message catalog; empty means English
+var language string
+
+// catalogs holds translations keyed by the English message, which doubles
+// as the fallback. Only diagnostics are translated; snapshots stay in English
+// so they do not depend on the locale. Scripts should rely on exit codes
+// rather than on these strings.
+var catalogs = map[string]map[string]string{
+	"de": {
+		"    ... and %d more\n":                   "    ... und %d weitere\n",
+		"%d entering, %d leaving, %d unchanged\n": "%d neu, %d entfernt, %d unverändert\n",
+		"%d files were skipped (binary, not text or unreadable) and are not counted\n": "%d Dateien wurden übersprungen (binär, kein Text oder nicht lesbar) und werden nicht gezählt\n",
+		"%s already exists; remove it or pass another -c PATH":                         "%s existiert bereits; entferne die Datei oder gib einen anderen -c PATH an",
+		"%s failed to encrypt the snapshot: %v":                                        "%s konnte den Snapshot nicht verschlüsseln: %v",
+		"%s hook %q failed: %v":                                                        "%s-Hook %q fehlgeschlagen: %v",
+		"%s is invalid: %d errors, %d warnings\n":                                      "%s ist ungültig: %d Fehler, %d Warnungen\n",
+		"%s is valid\n":                   "%s ist gültig\n",
+		"%s is valid, with %d warnings\n": "%s ist gültig, mit %d Warnungen\n",
+		"%s looks like a dependency directory with more than %d entries. Include it? [y/N] ": "%s sieht wie ein Abhängigkeitsverzeichnis mit mehr als %d Einträgen aus. Aufnehmen? [y/N] ",
+		"- Average duration: %v\n":                              "- Durchschnittliche Dauer: %v\n",
+		"- Average files per run: %d\n":                         "- Durchschnittliche Dateien pro Lauf: %d\n",
+		"- Average size per run: %d bytes (~%d tokens)\n":       "- Durchschnittliche Größe pro Lauf: %d Bytes (~%d Tokens)\n",
+		"- Runs: %d (%s to %s)\n":                               "- Läufe: %d (%s bis %s)\n",
+		"--anonymize requires anonymize.patterns in the config": "--anonymize benötigt anonymize.patterns in der Konfiguration",
+		"--encrypt %s: %s is not installed":                     "--encrypt %s: %s ist nicht installiert",
+		"--to %s needs a value, e.g. %s=%s":                     "--to %s benötigt einen Wert, z. B. %s=%s",
+		"--to %s takes no value":                                "--to %s nimmt keinen Wert an",
+		"Audit failed: %d findings in %d of %d files\n":         "Prüfung fehlgeschlagen: %d Funde in %d von %d Dateien\n",
+		"Audit passed: nothing forbidden in %d files\n":         "Prüfung bestanden: nichts Verbotenes in %d Dateien\n",
+		"Changed: %s\n":                                         "Geändert: %s\n",
+		"CodeSnap version %s\n":                                 "CodeSnap Version %s\n",
+		"Collected %s\n":                                        "Gesammelt: %s\n",
+		"Commands:":                                             "Befehle:",
+		"Configs:":                                              "Konfigurationen:",
+		"Content saved in %d parts of at most ~%s tokens:\n":    "Inhalt in %d Teilen zu höchstens ~%s Tokens gespeichert:\n",
+		"Content saved to: %s\n":                                "Inhalt gespeichert unter: %s\n",
+		"Content:":                                              "Inhalt:",
+		"Created %s from %s\n":                                  "%s aus %s erstellt\n",
+		"Created configuration at: %s\n":                        "Konfiguration erstellt unter: %s\n",
+		"Created template configuration at: %s\n":               "Vorlage der Konfiguration erstellt unter: %s\n",
+		"Detected project type: %s\n":                           "Erkannter Projekttyp: %s\n",
+		"Error copying to clipboard: %v\n":                      "Fehler beim Kopieren in die Zwischenablage: %v\n",
+		"Error: %s\n":                                           "Fehler: %s\n",
+		"Error: %s already exists\n":                            "Fehler: %s existiert bereits\n",
+		"Error: %v\n":                                           "Fehler: %v\n",
+		"Error: --all-configs cannot be combined with -c, --watch, --list or --format chunks":                                 "Fehler: --all-configs kann nicht mit -c, --watch, --list oder --format chunks kombiniert werden",
+		"Error: --chunk-overlap must be at least 0 and less than --chunk-tokens":                                              "Fehler: --chunk-overlap muss mindestens 0 und kleiner als --chunk-tokens sein",
+		"Error: --chunk-overlap needs --format chunks":                                                                        "Fehler: --chunk-overlap benötigt --format chunks",
+		"Error: --chunk-tokens cannot be combined with --format json":                                                         "Fehler: --chunk-tokens kann nicht mit --format json kombiniert werden",
+		"Error: --diff-context must not be negative":                                                                          "Fehler: --diff-context darf nicht negativ sein",
+		"Error: --encrypt needs -O FILE, -o or --safe and cannot be combined with --chunk-tokens, --split-by or a named pipe": "Fehler: --encrypt benötigt -O FILE, -o oder --safe und kann nicht mit --chunk-tokens, --split-by oder einer Named Pipe kombiniert werden",
+		"Error: --max-tokens and --max-bytes cannot be combined":                                                              "Fehler: --max-tokens und --max-bytes können nicht kombiniert werden",
+		"Error: --max-tokens must not be negative":                                                                            "Fehler: --max-tokens darf nicht negativ sein",
+		"Error: --safe always redacts secrets and cannot be combined with --no-redact":                                        "Fehler: --safe schwärzt immer Geheimnisse und kann nicht mit --no-redact kombiniert werden",
+		"Error: --safe writes into a private directory and cannot be combined with -O, -o or --to":                            "Fehler: --safe schreibt in ein privates Verzeichnis und kann nicht mit -O, -o oder --to kombiniert werden",
+		"Error: --sink cannot be combined with -O, --chunk-tokens or --split-by":                                              "Fehler: --sink kann nicht mit -O, --chunk-tokens oder --split-by kombiniert werden",
+		"Error: --split-by cannot be combined with -t, --with-tree, --incremental or --format chunks":                         "Fehler: --split-by kann nicht mit -t, --with-tree, --incremental oder --format chunks kombiniert werden",
+		"Error: --stdout cannot be combined with -O, --sink, --chunk-tokens or --split-by":                                    "Fehler: --stdout kann nicht mit -O, --sink, --chunk-tokens oder --split-by kombiniert werden",
+		"Error: --to cannot be combined with -O, --stdout, --sink, --chunk-tokens or --split-by":                              "Fehler: --to kann nicht mit -O, --stdout, --sink, --chunk-tokens oder --split-by kombiniert werden",
+		"Error: --workers must not be negative":                                                                               "Fehler: --workers darf nicht negativ sein",
+		"Error: -O with a named pipe cannot be combined with -p, -o or --chunk-tokens":                                        "Fehler: -O mit einer Named Pipe kann nicht mit -p, -o oder --chunk-tokens kombiniert werden",
+		"Error: config requires an action, e.g. codesnap config validate":                                                     "Fehler: config benötigt eine Aktion, z. B. codesnap config validate",
+		"Error: failed to write to stdout: %v\n":                                                                              "Fehler: Schreiben auf stdout fehlgeschlagen: %v\n",
+		"Error: flags: %v\n":                                                                                                  "Fehler: flags: %v\n",
+		"Error: import requires a config to convert, e.g. codesnap import repomix.config.json":                                "Fehler: import benötigt eine zu konvertierende Konfiguration, z. B. codesnap import repomix.config.json",
+		"Error: invalid --max-bytes: %v\n":                                                                                    "Fehler: ungültiges --max-bytes: %v\n",
+		"Error: invalid --max-file-size: %v\n":                                                                                "Fehler: ungültiges --max-file-size: %v\n",
+		"Error: invalid drop policy %q (expected even, largest-first, oldest-first or by-weight)\n":                           "Fehler: ungültige Drop-Policy %q (erwartet even, largest-first, oldest-first oder by-weight)\n",
+		"Error: invalid format %q (expected text, markdown, json or chunks)\n":                                                "Fehler: ungültiges Format %q (erwartet text, markdown, json oder chunks)\n",
+		"Error: invalid graph format %q (expected mermaid or dot)\n":                                                          "Fehler: ungültiges Graphformat %q (erwartet mermaid oder dot)\n",
+		"Error: invalid paths value %q (expected posix or native)\n":                                                          "Fehler: ungültiger paths-Wert %q (erwartet posix oder native)\n",
+		"Error: invalid split-by value %q (expected folder)\n":                                                                "Fehler: ungültiger split-by-Wert %q (erwartet folder)\n",
+		"Error: invalid split-by value %q with --all-configs (expected config)\n":                                             "Fehler: ungültiger split-by-Wert %q mit --all-configs (erwartet config)\n",
+		"Error: pipelines.%s: %v\n":                                                                                           "Fehler: pipelines.%s: %v\n",
+		"Error: run requires a pipeline name, e.g. codesnap run review":                                                       "Fehler: run benötigt den Namen einer Pipeline, z. B. codesnap run review",
+		"Error: unknown command %q\n":                                                                                         "Fehler: unbekannter Befehl %q\n",
+		"Error: unknown pipeline %q (available: %s)\n":                                                                        "Fehler: unbekannte Pipeline %q (verfügbar: %s)\n",
+		"Estimated tokens: ~%s (from the file sizes, without reading them)\n":                                                 "Geschätzte Tokens: ~%s (aus den Dateigrößen, ohne sie zu lesen)\n",
+		"Estimated tokens: ~%s for the snapshot, ~%s of them file contents\n":                                                 "Geschätzte Tokens: ~%s für den Snapshot, davon ~%s Dateiinhalte\n",
+		"Excluded (%d):\n":                               "Ausgeschlossen (%d):\n",
+		"Fetching %s@%s...\n":                            "Lade %s@%s...\n",
+		"Files in the project root: %s\n":                "Dateien im Projektverzeichnis: %s\n",
+		"Files: %d\n":                                    "Dateien: %d\n",
+		"Fits within %s (%s)\n":                          "Passt in %s (%s)\n",
+		"Go package %s: %v":                              "Go-Paket %s: %v",
+		"Ignoring file: %s\n":                            "Ignoriere Datei: %s\n",
+		"Included (%d files, %s):\n":                     "Aufgenommen (%d Dateien, %s):\n",
+		"Language\tFiles\tCode\tComments\tBlank\tSize\t": "Sprache\tDateien\tCode\tKommentare\tLeer\tGröße\t",
+		"Largest files:":                                 "Größte Dateien:",
+		"No codesnap.yml found. Creating template configuration file...": "Keine codesnap.yml gefunden. Erstelle eine Vorlage der Konfiguration...",
+		"No codesnap.yml found. Setting one up for %s\n":                 "Keine codesnap.yml gefunden. Richte eine für %s ein\n",
+		"No folder %s\n": "Kein Ordner %s\n",
+		"No metrics recorded in %s (enable them with metrics: true in the config)\n": "Keine Metriken in %s aufgezeichnet (aktiviere sie mit metrics: true in der Konfiguration)\n",
+		"Nothing selected.": "Nichts ausgewählt.",
+		"Over %s (%s): a snapshot would leave out files, see drop_policy\n": "Über %s (%s): ein Snapshot würde Dateien auslassen, siehe drop_policy\n",
+		"Pipelines:": "Pipelines:",
+		"Please edit the file and run codesnap again.":                    "Bitte bearbeite die Datei und starte codesnap erneut.",
+		"Processing folder: %s\n":                                         "Verarbeite Ordner: %s\n",
+		"Running: %s\n":                                                   "Führe aus: %s\n",
+		"Selected: %s, ~%s tokens\n\n":                                    "Ausgewählt: %s, ~%s Tokens\n\n",
+		"Sent content to the terminal's clipboard (OSC 52)":               "Inhalt an die Zwischenablage des Terminals gesendet (OSC 52)",
+		"Serving snapshot at http://%s/v1/files (press Ctrl+C to stop)\n": "Stelle Snapshot unter http://%s/v1/files bereit (Strg+C zum Beenden)\n",
+		"Size: %s\n":                                                        "Größe: %s\n",
+		"Snapshot sent to %s":                                               "Snapshot an %s gesendet",
+		"Snapshot streamed to: %s\n":                                        "Snapshot gestreamt nach: %s\n",
+		"Split snapshot saved to: %s\n":                                     "Aufgeteilter Snapshot gespeichert unter: %s\n",
+		"Successfully copied content to clipboard with %s\n":                "Inhalt erfolgreich mit %s in die Zwischenablage kopiert\n",
+		"Successfully copied content to clipboard!":                         "Inhalt erfolgreich in die Zwischenablage kopiert!",
+		"Toggle folders by number (e.g. 2 3), or press Enter to continue: ": "Ordner per Nummer umschalten (z. B. 2 3) oder Enter zum Fortfahren: ",
+		"Total":                      "Gesamt",
+		"Total execution time: %v\n": "Gesamte Ausführungszeit: %v\n",
+		"Usage metrics (%s)\n":       "Nutzungsmetriken (%s)\n",
+		"Warning: %d files (%s) were left out to stay within max_bytes (%s)\n":          "Warnung: %d Dateien (%s) wurden ausgelassen, um max_bytes (%s) einzuhalten\n",
+		"Warning: %d files (~%d tokens) were left out to stay within max_tokens (%d)\n": "Warnung: %d Dateien (~%d Tokens) wurden ausgelassen, um max_tokens (%d) einzuhalten\n",
+		"Warning: %s\n": "Warnung: %s\n",
+		"Warning: %s is larger than max_file_size, only its first %s are included\n": "Warnung: %s ist größer als max_file_size, nur die ersten %s werden aufgenommen\n",
+		"Warning: %s is licensed under %s, which is on the deny_licenses list\n":     "Warnung: %s steht unter %s, die auf der deny_licenses-Liste steht\n",
+		"Warning: %v\n":                              "Warnung: %v\n",
+		"Warning: cannot watch %s: %v\n":             "Warnung: %s kann nicht überwacht werden: %v\n",
+		"Warning: clipboard backend %s failed: %v\n": "Warnung: Zwischenablage-Backend %s fehlgeschlagen: %v\n",
+		"Warning: copying to clipboard failed, the snapshot is only saved to a file: %v\n":                                                          "Warnung: Kopieren in die Zwischenablage fehlgeschlagen, der Snapshot wird nur in einer Datei gespeichert: %v\n",
+		"Warning: failed to save the read cache: %v\n":                                                                                              "Warnung: Lese-Cache konnte nicht gespeichert werden: %v\n",
+		"Warning: pattern %q has no codesnap equivalent and was left out\n":                                                                         "Warnung: Muster %q hat keine Entsprechung in codesnap und wurde ausgelassen\n",
+		"Warning: skipping %s, it looks like a dependency directory with more than %d entries (add it to ignore or set dependency_dirs: include)\n": "Warnung: überspringe %s, es sieht wie ein Abhängigkeitsverzeichnis mit mehr als %d Einträgen aus (füge es zu ignore hinzu oder setze dependency_dirs: include)\n",
+		"Warning: skipping %s: %v\n":          "Warnung: überspringe %s: %v\n",
+		"Warning: skipping database %s: %v\n": "Warnung: überspringe Datenbank %s: %v\n",
+		"Warning: tree_depth %d hides %d files in %d directories that a snapshot would include (deepest: %s)\n": "Warnung: tree_depth %d verbirgt %d Dateien in %d Verzeichnissen, die ein Snapshot enthalten würde (am tiefsten: %s)\n",
+		"Watching %d directories for changes (press Ctrl+C to stop)\n":                                          "Überwache %d Verzeichnisse auf Änderungen (Strg+C zum Beenden)\n",
+		"Write %s? [Y/n] ":          "%s schreiben? [Y/n] ",
+		"cannot expand ~ in %q: %v": "~ in %q kann nicht erweitert werden: %v",
+		"cannot import %s (expected a repomix .json config or a .gitingest file)": "%s kann nicht importiert werden (erwartet eine repomix-.json-Konfiguration oder eine .gitingest-Datei)",
+		"codesnap state": "codesnap-Zustand",
+		"configuration must specify at least one file or folder to process": "die Konfiguration muss mindestens eine Datei oder einen Ordner angeben",
+		"credential file":                                                         "Zugangsdatendatei",
+		"database %q needs a dsn":                                                 "Datenbank %q benötigt einen dsn",
+		"dependency directory":                                                    "Abhängigkeitsverzeichnis",
+		"error processing folder %s: %v":                                          "Fehler beim Verarbeiten des Ordners %s: %v",
+		"failed to close named pipe: %v":                                          "Named Pipe konnte nicht geschlossen werden: %v",
+		"failed to combine the snapshots: %v":                                     "Snapshots konnten nicht zusammengeführt werden: %v",
+		"failed to create configuration: %v":                                      "Konfiguration konnte nicht erstellt werden: %v",
+		"failed to create output directory: %v":                                   "Ausgabeverzeichnis konnte nicht erstellt werden: %v",
+		"failed to create private output directory: %v":                           "privates Ausgabeverzeichnis konnte nicht erstellt werden: %v",
+		"failed to create template configuration: %v":                             "Vorlage der Konfiguration konnte nicht erstellt werden: %v",
+		"failed to encode JSON: %v":                                               "JSON konnte nicht kodiert werden: %v",
+		"failed to find the codesnap executable: %v":                              "die codesnap-Programmdatei wurde nicht gefunden: %v",
+		"failed to generate anonymization seed: %v":                               "Anonymisierungs-Seed konnte nicht erzeugt werden: %v",
+		"failed to get working directory: %v":                                     "Arbeitsverzeichnis konnte nicht ermittelt werden: %v",
+		"failed to load Go packages: %v":                                          "Go-Pakete konnten nicht geladen werden: %v",
+		"failed to open named pipe: %v":                                           "Named Pipe konnte nicht geöffnet werden: %v",
+		"failed to parse %s: %v":                                                  "%s konnte nicht geparst werden: %v",
+		"failed to prepare checkout of %s: %v":                                    "Checkout von %s konnte nicht vorbereitet werden: %v",
+		"failed to read .gitignore: %v":                                           ".gitignore konnte nicht gelesen werden: %v",
+		"failed to read config file: %v":                                          "Konfigurationsdatei konnte nicht gelesen werden: %v",
+		"failed to read config to import: %v":                                     "zu importierende Konfiguration konnte nicht gelesen werden: %v",
+		"failed to read directory: %v":                                            "Verzeichnis konnte nicht gelesen werden: %v",
+		"failed to read folder %s: %v":                                            "Ordner %s konnte nicht gelesen werden: %v",
+		"failed to read results of the last run: %v":                              "Ergebnisse des letzten Laufs konnten nicht gelesen werden: %v",
+		"failed to read template: %v":                                             "Vorlage konnte nicht gelesen werden: %v",
+		"failed to render template: %v":                                           "Vorlage konnte nicht gerendert werden: %v",
+		"failed to resolve config directory: %v":                                  "Konfigurationsverzeichnis konnte nicht aufgelöst werden: %v",
+		"failed to save cache: %v":                                                "Cache konnte nicht gespeichert werden: %v",
+		"failed to save content to file: %v":                                      "Inhalt konnte nicht gespeichert werden: %v",
+		"failed to save results of this run: %v":                                  "Ergebnisse dieses Laufs konnten nicht gespeichert werden: %v",
+		"failed to search for configs: %v":                                        "Suche nach Konfigurationen fehlgeschlagen: %v",
+		"failed to start watching: %v":                                            "Überwachung konnte nicht gestartet werden: %v",
+		"failed to write %s: %v":                                                  "%s konnte nicht geschrieben werden: %v",
+		"failed to write anonymization mapping: %v":                               "Anonymisierungszuordnung konnte nicht geschrieben werden: %v",
+		"failed to write metrics: %v":                                             "Metriken konnten nicht geschrieben werden: %v",
+		"failed to write to named pipe: %v":                                       "Schreiben in die Named Pipe fehlgeschlagen: %v",
+		"failed to write to stdout: %v":                                           "Schreiben auf stdout fehlgeschlagen: %v",
+		"file %s does not exist":                                                  "Datei %s existiert nicht",
+		"file %s is a directory; list it under folders":                           "Datei %s ist ein Verzeichnis; führe sie unter folders auf",
+		"file not found":                                                          "Datei nicht gefunden",
+		"folder %s cannot have both repo and worktree":                            "Ordner %s kann nicht zugleich repo und worktree haben",
+		"folder %s does not exist":                                                "Ordner %s existiert nicht",
+		"folder %s is not a directory":                                            "Ordner %s ist kein Verzeichnis",
+		"folder not found":                                                        "Ordner nicht gefunden",
+		"fzf failed: %v":                                                          "fzf fehlgeschlagen: %v",
+		"fzf not found in PATH (install it from https://github.com/junegunn/fzf)": "fzf nicht im PATH gefunden (Installation: https://github.com/junegunn/fzf)",
+		"git %s failed: %s":                                                       "git %s fehlgeschlagen: %s",
+		"git %s failed: %v":                                                       "git %s fehlgeschlagen: %v",
+		"git show %s failed: %s":                                                  "git show %s fehlgeschlagen: %s",
+		"git show %s failed: %v":                                                  "git show %s fehlgeschlagen: %v",
+		"git worktree list failed: %s":                                            "git worktree list fehlgeschlagen: %s",
+		"git worktree list failed: %v":                                            "git worktree list fehlgeschlagen: %v",
+		"hidden directory":                                                        "verstecktes Verzeichnis",
+		"hidden file":                                                             "versteckte Datei",
+		"ignore pattern %q is not a valid glob and never matches":                 "Ignoriermuster %q ist kein gültiger Glob und passt nie",
+		"ignore rule %q matches no file":                                          "Ignorierregel %q passt auf keine Datei",
+		"ignore rule needs a pattern, older_than or owner":                        "Ignorierregel benötigt pattern, older_than oder owner",
+		"invalid %s hook: %v":                                                     "ungültiger %s-Hook: %v",
+		"invalid --encrypt value %q (expected age:RECIPIENT or gpg:RECIPIENT)":    "ungültiger --encrypt-Wert %q (erwartet age:RECIPIENT oder gpg:RECIPIENT)",
+		"invalid --only pattern %q":                                               "ungültiges --only-Muster %q",
+		"invalid --to target %q (expected clipboard, stdout, file=PATH or sink=NAME)": "ungültiges --to-Ziel %q (erwartet clipboard, stdout, file=PATH oder sink=NAME)",
+		"invalid YAML format: %v":                                                                "ungültiges YAML-Format: %v",
+		"invalid anonymize pattern %q: %v":                                                       "ungültiges anonymize-Muster %q: %v",
+		"invalid audit.allow pattern %q: %v":                                                     "ungültiges audit.allow-Muster %q: %v",
+		"invalid audit.patterns pattern %q: %v":                                                  "ungültiges audit.patterns-Muster %q: %v",
+		"invalid condense pattern %q":                                                            "ungültiges condense-Muster %q",
+		"invalid dependency_dirs value %q (expected prompt, skip or include)":                    "ungültiger dependency_dirs-Wert %q (erwartet prompt, skip oder include)",
+		"invalid dialect %q for database %q (expected postgres, mysql or sqlite)":                "ungültiger Dialekt %q für Datenbank %q (erwartet postgres, mysql oder sqlite)",
+		"invalid drop_policy value %q (expected even, largest-first, oldest-first or by-weight)": "ungültiger drop_policy-Wert %q (erwartet even, largest-first, oldest-first oder by-weight)",
+		"invalid drop_weights pattern %q":                                                        "ungültiges drop_weights-Muster %q",
+		"invalid empty_files value %q (expected include, omit or list)":                          "ungültiger empty_files-Wert %q (erwartet include, omit oder list)",
+		"invalid glob %q in section %q":                                                          "ungültiger Glob %q in Abschnitt %q",
+		"invalid glob %q in transform %q":                                                        "ungültiger Glob %q in Transformation %q",
+		"invalid hash value %q (expected xxhash, blake3 or sha256)":                              "ungültiger hash-Wert %q (erwartet xxhash, blake3 oder sha256)",
+		"invalid include pattern %q":                                                             "ungültiges include-Muster %q",
+		"invalid large_files value %q (expected truncate or skip)":                               "ungültiger large_files-Wert %q (erwartet truncate oder skip)",
+		"invalid max_bytes: %v":                                                                  "ungültiges max_bytes: %v",
+		"invalid max_file_size: %v":                                                              "ungültiges max_file_size: %v",
+		"invalid metrics file %s: %v":                                                            "ungültige Metrikdatei %s: %v",
+		"invalid older_than value %q: %v":                                                        "ungültiger older_than-Wert %q: %v",
+		"invalid order value %q (expected %s, optionally prefixed with -)":                       "ungültiger order-Wert %q (erwartet %s, optional mit vorangestelltem -)",
+		"invalid rename path %q (expected a path relative to the config)":                        "ungültiger rename-Pfad %q (erwartet einen Pfad relativ zur Konfiguration)",
+		"invalid size %q (expected e.g. 512KB, 10MB or 1GB)":                                     "ungültige Größe %q (erwartet z. B. 512KB, 10MB oder 1GB)",
+		"invalid skipped_files value %q (expected omit or annotate)":                             "ungültiger skipped_files-Wert %q (erwartet omit oder annotate)",
+		"invalid template: %v":                                                                   "ungültige Vorlage: %v",
+		"invalid text_detection value %q (expected probe or extension)":                          "ungültiger text_detection-Wert %q (erwartet probe oder extension)",
+		"invalid timeout %q in transform %q":                                                     "ungültiges Timeout %q in Transformation %q",
+		"invalid transform_cmd pattern %q":                                                       "ungültiges transform_cmd-Muster %q",
+		"invalid value for %s: %v":                                                               "ungültiger Wert für %s: %v",
+		"invalid workers value %d (expected a positive number)":                                  "ungültiger workers-Wert %d (erwartet eine positive Zahl)",
+		"match needs a glob or a list of globs":                                                  "match benötigt einen Glob oder eine Liste von Globs",
+		"max_tokens and max_bytes cannot be combined":                                            "max_tokens und max_bytes können nicht kombiniert werden",
+		"max_tokens must not be negative":                                                        "max_tokens darf nicht negativ sein",
+		"no %s driver is registered (import %s)":                                                 "kein %s-Treiber registriert (importiere %s)",
+		"no Go package matches %s":                                                               "kein Go-Paket passt auf %s",
+		"no codesnap.yml found below the current directory":                                      "keine codesnap.yml unterhalb des aktuellen Verzeichnisses gefunden",
+		"no config produced a snapshot":                                                          "keine Konfiguration hat einen Snapshot erzeugt",
+		"no files changed since the last incremental run":                                        "seit dem letzten inkrementellen Lauf wurden keine Dateien geändert",
+		"no files of the last run match %s":                                                      "keine Dateien des letzten Laufs passen auf %s",
+		"no files selected":                                                                      "keine Dateien ausgewählt",
+		"no files to pick from":                                                                  "keine Dateien zur Auswahl",
+		"no folders to watch":                                                                    "keine Ordner zum Überwachen",
+		"no git worktree found for %q":                                                           "kein git-Worktree für %q gefunden",
+		"no results to render yet; run codesnap first":                                           "noch keine Ergebnisse zum Rendern; führe zuerst codesnap aus",
+		"no selected files are staged":                                                           "keine ausgewählten Dateien sind vorgemerkt",
+		"no selected files changed since %s":                                                     "seit %s wurden keine ausgewählten Dateien geändert",
+		"no valid files were processed":                                                          "es wurden keine gültigen Dateien verarbeitet",
+		"no valid folders or files were found":                                                   "es wurden keine gültigen Ordner oder Dateien gefunden",
+		"not changed":                                                                            "nicht geändert",
+		"not matched by include":                                                                 "nicht von include erfasst",
+		"not supported on Windows":                                                               "unter Windows nicht unterstützt",
+		"other":                                                                                  "sonstige",
+		"pager %s failed: %v":                                                                    "Pager %s fehlgeschlagen: %v",
+		"profiles cannot be nested":                                                              "Profile können nicht verschachtelt werden",
+		"rename of %q needs a non-empty virtual path":                                            "rename von %q benötigt einen nicht leeren virtuellen Pfad",
+		"section %q needs a glob or a list of globs":                                             "Abschnitt %q benötigt einen Glob oder eine Liste von Globs",
+		"server failed: %v":                                                                      "Server fehlgeschlagen: %v",
+		"sink %q failed: %v":                                                                     "Sink %q fehlgeschlagen: %v",
+		"sink %q needs a cmd":                                                                    "Sink %q benötigt ein cmd",
+		"snapshot was not collected by a Collector":                                              "Snapshot wurde nicht von einem Collector erstellt",
+		"transform %q failed: %s":                                                                "Transformation %q fehlgeschlagen: %s",
+		"transform %q failed: %v":                                                                "Transformation %q fehlgeschlagen: %v",
+		"transform %q needs a match glob":                                                        "Transformation %q benötigt einen match-Glob",
+		"transform %q timed out after %s":                                                        "Zeitüberschreitung der Transformation %q nach %s",
+		"transform_cmd entry needs a run command":                                                "transform_cmd-Eintrag benötigt einen run-Befehl",
+		"transforms entry needs a cmd":                                                           "transforms-Eintrag benötigt ein cmd",
+		"unknown audit check %q (expected secrets, pii, network or markers)":                     "unbekannte Prüfung %q (erwartet secrets, pii, network oder markers)",
+		"unknown file group %q (expected one of %s)":                                             "unbekannte Dateigruppe %q (erwartet eine von %s)",
+		"unknown option %q":                                                                      "unbekannte Option %q",
+		"unknown profile %q (available: %s)":                                                     "unbekanntes Profil %q (verfügbar: %s)",
+		"unknown sink %q (available: %s)":                                                        "unbekannter Sink %q (verfügbar: %s)",
+	},
+	"es": {
+		"    ... and %d more\n":                   "    ... y %d más\n",
+		"%d entering, %d leaving, %d unchanged\n": "%d nuevos, %d eliminados, %d sin cambios\n",
+		"%d files were skipped (binary, not text or unreadable) and are not counted\n": "se omitieron %d archivos (binarios, no de texto o ilegibles), que no se cuentan\n",
+		"%s already exists; remove it or pass another -c PATH":                         "%s ya existe; elimínalo o indica otro -c PATH",
+		"%s failed to encrypt the snapshot: %v":                                        "%s no pudo cifrar la instantánea: %v",
+		"%s hook %q failed: %v":                                                        "el hook %s %q falló: %v",
+		"%s is invalid: %d errors, %d warnings\n":                                      "%s no es válido: %d errores, %d advertencias\n",
+		"%s is valid\n":                   "%s es válido\n",
+		"%s is valid, with %d warnings\n": "%s es válido, con %d advertencias\n",
+		"%s looks like a dependency directory with more than %d entries. Include it? [y/N] ": "%s parece un directorio de dependencias con más de %d entradas. ¿Incluirlo? [y/N] ",
+		"- Average duration: %v\n":                              "- Duración media: %v\n",
+		"- Average files per run: %d\n":                         "- Archivos por ejecución de media: %d\n",
+		"- Average size per run: %d bytes (~%d tokens)\n":       "- Tamaño medio por ejecución: %d bytes (~%d tokens)\n",
+		"- Runs: %d (%s to %s)\n":                               "- Ejecuciones: %d (de %s a %s)\n",
+		"--anonymize requires anonymize.patterns in the config": "--anonymize necesita anonymize.patterns en la configuración",
+		"--encrypt %s: %s is not installed":                     "--encrypt %s: %s no está instalado",
+		"--to %s needs a value, e.g. %s=%s":                     "--to %s necesita un valor, p. ej. %s=%s",
+		"--to %s takes no value":                                "--to %s no admite un valor",
+		"Audit failed: %d findings in %d of %d files\n":         "Auditoría fallida: %d hallazgos en %d de %d archivos\n",
+		"Audit passed: nothing forbidden in %d files\n":         "Auditoría superada: nada prohibido en %d archivos\n",
+		"Changed: %s\n":                                         "Cambiado: %s\n",
+		"CodeSnap version %s\n":                                 "CodeSnap versión %s\n",
+		"Collected %s\n":                                        "Recopilado: %s\n",
+		"Commands:":                                             "Comandos:",
+		"Configs:":                                              "Configuraciones:",
+		"Content saved in %d parts of at most ~%s tokens:\n":    "Contenido guardado en %d partes de como máximo ~%s tokens:\n",
+		"Content saved to: %s\n":                                "Contenido guardado en: %s\n",
+		"Content:":                                              "Contenido:",
+		"Created %s from %s\n":                                  "Se creó %s a partir de %s\n",
+		"Created configuration at: %s\n":                        "Configuración creada en: %s\n",
+		"Created template configuration at: %s\n":               "Plantilla de configuración creada en: %s\n",
+		"Detected project type: %s\n":                           "Tipo de proyecto detectado: %s\n",
+		"Error copying to clipboard: %v\n":                      "Error al copiar al portapapeles: %v\n",
+		"Error: %s\n":                                           "Error: %s\n",
+		"Error: %s already exists\n":                            "Error: %s ya existe\n",
+		"Error: %v\n":                                           "Error: %v\n",
+		"Error: --all-configs cannot be combined with -c, --watch, --list or --format chunks":                                 "Error: --all-configs no se puede combinar con -c, --watch, --list ni --format chunks",
+		"Error: --chunk-overlap must be at least 0 and less than --chunk-tokens":                                              "Error: --chunk-overlap debe ser al menos 0 y menor que --chunk-tokens",
+		"Error: --chunk-overlap needs --format chunks":                                                                        "Error: --chunk-overlap necesita --format chunks",
+		"Error: --chunk-tokens cannot be combined with --format json":                                                         "Error: --chunk-tokens no se puede combinar con --format json",
+		"Error: --diff-context must not be negative":                                                                          "Error: --diff-context no puede ser negativo",
+		"Error: --encrypt needs -O FILE, -o or --safe and cannot be combined with --chunk-tokens, --split-by or a named pipe": "Error: --encrypt necesita -O FILE, -o o --safe y no se puede combinar con --chunk-tokens, --split-by ni una tubería con nombre",
+		"Error: --max-tokens and --max-bytes cannot be combined":                                                              "Error: --max-tokens y --max-bytes no se pueden combinar",
+		"Error: --max-tokens must not be negative":                                                                            "Error: --max-tokens no puede ser negativo",
+		"Error: --safe always redacts secrets and cannot be combined with --no-redact":                                        "Error: --safe siempre oculta secretos y no se puede combinar con --no-redact",
+		"Error: --safe writes into a private directory and cannot be combined with -O, -o or --to":                            "Error: --safe escribe en un directorio privado y no se puede combinar con -O, -o ni --to",
+		"Error: --sink cannot be combined with -O, --chunk-tokens or --split-by":                                              "Error: --sink no se puede combinar con -O, --chunk-tokens ni --split-by",
+		"Error: --split-by cannot be combined with -t, --with-tree, --incremental or --format chunks":                         "Error: --split-by no se puede combinar con -t, --with-tree, --incremental ni --format chunks",
+		"Error: --stdout cannot be combined with -O, --sink, --chunk-tokens or --split-by":                                    "Error: --stdout no se puede combinar con -O, --sink, --chunk-tokens ni --split-by",
+		"Error: --to cannot be combined with -O, --stdout, --sink, --chunk-tokens or --split-by":                              "Error: --to no se puede combinar con -O, --stdout, --sink, --chunk-tokens ni --split-by",
+		"Error: --workers must not be negative":                                                                               "Error: --workers no puede ser negativo",
+		"Error: -O with a named pipe cannot be combined with -p, -o or --chunk-tokens":                                        "Error: -O con una tubería con nombre no se puede combinar con -p, -o ni --chunk-tokens",
+		"Error: config requires an action, e.g. codesnap config validate":                                                     "Error: config necesita una acción, p. ej. codesnap config validate",
+		"Error: failed to write to stdout: %v\n":                                                                              "Error: no se pudo escribir en stdout: %v\n",
+		"Error: flags: %v\n":                                                                                                  "Error: flags: %v\n",
+		"Error: import requires a config to convert, e.g. codesnap import repomix.config.json":                                "Error: import necesita una configuración que convertir, p. ej. codesnap import repomix.config.json",
+		"Error: invalid --max-bytes: %v\n":                                                                                    "Error: --max-bytes no válido: %v\n",
+		"Error: invalid --max-file-size: %v\n":                                                                                "Error: --max-file-size no válido: %v\n",
+		"Error: invalid drop policy %q (expected even, largest-first, oldest-first or by-weight)\n":                           "Error: política de descarte no válida %q (se esperaba even, largest-first, oldest-first o by-weight)\n",
+		"Error: invalid format %q (expected text, markdown, json or chunks)\n":                                                "Error: formato no válido %q (se esperaba text, markdown, json o chunks)\n",
+		"Error: invalid graph format %q (expected mermaid or dot)\n":                                                          "Error: formato de grafo no válido %q (se esperaba mermaid o dot)\n",
+		"Error: invalid paths value %q (expected posix or native)\n":                                                          "Error: valor de paths no válido %q (se esperaba posix o native)\n",
+		"Error: invalid split-by value %q (expected folder)\n":                                                                "Error: valor de split-by no válido %q (se esperaba folder)\n",
+		"Error: invalid split-by value %q with --all-configs (expected config)\n":                                             "Error: valor de split-by no válido %q con --all-configs (se esperaba config)\n",
+		"Error: pipelines.%s: %v\n":                                                                                           "Error: pipelines.%s: %v\n",
+		"Error: run requires a pipeline name, e.g. codesnap run review":                                                       "Error: run necesita el nombre de un pipeline, p. ej. codesnap run review",
+		"Error: unknown command %q\n":                                                                                         "Error: comando desconocido %q\n",
+		"Error: unknown pipeline %q (available: %s)\n":                                                                        "Error: pipeline desconocido %q (disponibles: %s)\n",
+		"Estimated tokens: ~%s (from the file sizes, without reading them)\n":                                                 "Tokens estimados: ~%s (a partir del tamaño de los archivos, sin leerlos)\n",
+		"Estimated tokens: ~%s for the snapshot, ~%s of them file contents\n":                                                 "Tokens estimados: ~%s para la instantánea, ~%s de ellos contenido de archivos\n",
+		"Excluded (%d):\n":                               "Excluidos (%d):\n",
+		"Fetching %s@%s...\n":                            "Descargando %s@%s...\n",
+		"Files in the project root: %s\n":                "Archivos en la raíz del proyecto: %s\n",
+		"Files: %d\n":                                    "Archivos: %d\n",
+		"Fits within %s (%s)\n":                          "Cabe en %s (%s)\n",
+		"Go package %s: %v":                              "paquete Go %s: %v",
+		"Ignoring file: %s\n":                            "Ignorando archivo: %s\n",
+		"Included (%d files, %s):\n":                     "Incluidos (%d archivos, %s):\n",
+		"Language\tFiles\tCode\tComments\tBlank\tSize\t": "Lenguaje\tArchivos\tCódigo\tComentarios\tVacías\tTamaño\t",
+		"Largest files:":                                 "Archivos más grandes:",
+		"No codesnap.yml found. Creating template configuration file...": "No se encontró codesnap.yml. Creando plantilla de configuración...",
+		"No codesnap.yml found. Setting one up for %s\n":                 "No se encontró codesnap.yml. Configurando uno para %s\n",
+		"No folder %s\n": "No hay carpeta %s\n",
+		"No metrics recorded in %s (enable them with metrics: true in the config)\n": "No hay métricas registradas en %s (actívalas con metrics: true en la configuración)\n",
+		"Nothing selected.": "No se seleccionó nada.",
+		"Over %s (%s): a snapshot would leave out files, see drop_policy\n": "Supera %s (%s): una instantánea omitiría archivos, ver drop_policy\n",
+		"Pipelines:": "Pipelines:",
+		"Please edit the file and run codesnap again.":                    "Edita el archivo y vuelve a ejecutar codesnap.",
+		"Processing folder: %s\n":                                         "Procesando carpeta: %s\n",
+		"Running: %s\n":                                                   "Ejecutando: %s\n",
+		"Selected: %s, ~%s tokens\n\n":                                    "Seleccionado: %s, ~%s tokens\n\n",
+		"Sent content to the terminal's clipboard (OSC 52)":               "Contenido enviado al portapapeles del terminal (OSC 52)",
+		"Serving snapshot at http://%s/v1/files (press Ctrl+C to stop)\n": "Sirviendo la instantánea en http://%s/v1/files (pulsa Ctrl+C para detener)\n",
+		"Size: %s\n":                                                        "Tamaño: %s\n",
+		"Snapshot sent to %s":                                               "Instantánea enviada a %s",
+		"Snapshot streamed to: %s\n":                                        "Instantánea transmitida a: %s\n",
+		"Split snapshot saved to: %s\n":                                     "Instantánea dividida guardada en: %s\n",
+		"Successfully copied content to clipboard with %s\n":                "Contenido copiado al portapapeles con %s\n",
+		"Successfully copied content to clipboard!":                         "¡Contenido copiado al portapapeles!",
+		"Toggle folders by number (e.g. 2 3), or press Enter to continue: ": "Alterna carpetas por número (p. ej. 2 3) o pulsa Enter para continuar: ",
+		"Total":                      "Total",
+		"Total execution time: %v\n": "Tiempo total de ejecución: %v\n",
+		"Usage metrics (%s)\n":       "Métricas de uso (%s)\n",
+		"Warning: %d files (%s) were left out to stay within max_bytes (%s)\n":          "Advertencia: se omitieron %d archivos (%s) para no superar max_bytes (%s)\n",
+		"Warning: %d files (~%d tokens) were left out to stay within max_tokens (%d)\n": "Advertencia: se omitieron %d archivos (~%d tokens) para no superar max_tokens (%d)\n",
+		"Warning: %s\n": "Advertencia: %s\n",
+		"Warning: %s is larger than max_file_size, only its first %s are included\n": "Advertencia: %s supera max_file_size, solo se incluyen sus primeros %s\n",
+		"Warning: %s is licensed under %s, which is on the deny_licenses list\n":     "Advertencia: %s tiene la licencia %s, que está en la lista deny_licenses\n",
+		"Warning: %v\n":                              "Advertencia: %v\n",
+		"Warning: cannot watch %s: %v\n":             "Advertencia: no se puede vigilar %s: %v\n",
+		"Warning: clipboard backend %s failed: %v\n": "Advertencia: el método de portapapeles %s falló: %v\n",
+		"Warning: copying to clipboard failed, the snapshot is only saved to a file: %v\n":                                                          "Advertencia: no se pudo copiar al portapapeles, la instantánea solo se guarda en un archivo: %v\n",
+		"Warning: failed to save the read cache: %v\n":                                                                                              "Advertencia: no se pudo guardar la caché de lectura: %v\n",
+		"Warning: pattern %q has no codesnap equivalent and was left out\n":                                                                         "Advertencia: el patrón %q no tiene equivalente en codesnap y se omitió\n",
+		"Warning: skipping %s, it looks like a dependency directory with more than %d entries (add it to ignore or set dependency_dirs: include)\n": "Advertencia: se omite %s, parece un directorio de dependencias con más de %d entradas (añádelo a ignore o usa dependency_dirs: include)\n",
+		"Warning: skipping %s: %v\n":          "Advertencia: se omite %s: %v\n",
+		"Warning: skipping database %s: %v\n": "Advertencia: se omite la base de datos %s: %v\n",
+		"Warning: tree_depth %d hides %d files in %d directories that a snapshot would include (deepest: %s)\n": "Advertencia: tree_depth %d oculta %d archivos en %d directorios que una instantánea incluiría (el más profundo: %s)\n",
+		"Watching %d directories for changes (press Ctrl+C to stop)\n":                                          "Vigilando %d directorios en busca de cambios (pulsa Ctrl+C para detener)\n",
+		"Write %s? [Y/n] ":          "¿Escribir %s? [Y/n] ",
+		"cannot expand ~ in %q: %v": "no se puede expandir ~ en %q: %v",
+		"cannot import %s (expected a repomix .json config or a .gitingest file)": "no se puede importar %s (se esperaba una configuración .json de repomix o un archivo .gitingest)",
+		"codesnap state": "estado de codesnap",
+		"configuration must specify at least one file or folder to process": "la configuración debe indicar al menos un archivo o carpeta",
+		"credential file":                                                         "archivo de credenciales",
+		"database %q needs a dsn":                                                 "la base de datos %q necesita un dsn",
+		"dependency directory":                                                    "directorio de dependencias",
+		"error processing folder %s: %v":                                          "error al procesar la carpeta %s: %v",
+		"failed to close named pipe: %v":                                          "no se pudo cerrar la tubería con nombre: %v",
+		"failed to combine the snapshots: %v":                                     "no se pudieron combinar las instantáneas: %v",
+		"failed to create configuration: %v":                                      "no se pudo crear la configuración: %v",
+		"failed to create output directory: %v":                                   "no se pudo crear el directorio de salida: %v",
+		"failed to create private output directory: %v":                           "no se pudo crear el directorio de salida privado: %v",
+		"failed to create template configuration: %v":                             "no se pudo crear la plantilla de configuración: %v",
+		"failed to encode JSON: %v":                                               "no se pudo codificar el JSON: %v",
+		"failed to find the codesnap executable: %v":                              "no se encontró el ejecutable de codesnap: %v",
+		"failed to generate anonymization seed: %v":                               "no se pudo generar la semilla de anonimización: %v",
+		"failed to get working directory: %v":                                     "no se pudo obtener el directorio de trabajo: %v",
+		"failed to load Go packages: %v":                                          "no se pudieron cargar los paquetes Go: %v",
+		"failed to open named pipe: %v":                                           "no se pudo abrir la tubería con nombre: %v",
+		"failed to parse %s: %v":                                                  "no se pudo analizar %s: %v",
+		"failed to prepare checkout of %s: %v":                                    "no se pudo preparar el checkout de %s: %v",
+		"failed to read .gitignore: %v":                                           "no se pudo leer .gitignore: %v",
+		"failed to read config file: %v":                                          "no se pudo leer el archivo de configuración: %v",
+		"failed to read config to import: %v":                                     "no se pudo leer la configuración a importar: %v",
+		"failed to read directory: %v":                                            "no se pudo leer el directorio: %v",
+		"failed to read folder %s: %v":                                            "no se pudo leer la carpeta %s: %v",
+		"failed to read results of the last run: %v":                              "no se pudieron leer los resultados de la última ejecución: %v",
+		"failed to read template: %v":                                             "no se pudo leer la plantilla: %v",
+		"failed to render template: %v":                                           "no se pudo renderizar la plantilla: %v",
+		"failed to resolve config directory: %v":                                  "no se pudo resolver el directorio de configuración: %v",
+		"failed to save cache: %v":                                                "no se pudo guardar la caché: %v",
+		"failed to save content to file: %v":                                      "no se pudo guardar el contenido: %v",
+		"failed to save results of this run: %v":                                  "no se pudieron guardar los resultados de esta ejecución: %v",
+		"failed to search for configs: %v":                                        "no se pudieron buscar configuraciones: %v",
+		"failed to start watching: %v":                                            "no se pudo iniciar la vigilancia: %v",
+		"failed to write %s: %v":                                                  "no se pudo escribir %s: %v",
+		"failed to write anonymization mapping: %v":                               "no se pudo escribir la correspondencia de anonimización: %v",
+		"failed to write metrics: %v":                                             "no se pudieron escribir las métricas: %v",
+		"failed to write to named pipe: %v":                                       "no se pudo escribir en la tubería con nombre: %v",
+		"failed to write to stdout: %v":                                           "no se pudo escribir en stdout: %v",
+		"file %s does not exist":                                                  "el archivo %s no existe",
+		"file %s is a directory; list it under folders":                           "el archivo %s es un directorio; inclúyelo en folders",
+		"file not found":                                                          "archivo no encontrado",
+		"folder %s cannot have both repo and worktree":                            "la carpeta %s no puede tener repo y worktree a la vez",
+		"folder %s does not exist":                                                "la carpeta %s no existe",
+		"folder %s is not a directory":                                            "la carpeta %s no es un directorio",
+		"folder not found":                                                        "carpeta no encontrada",
+		"fzf failed: %v":                                                          "fzf falló: %v",
+		"fzf not found in PATH (install it from https://github.com/junegunn/fzf)": "fzf no está en el PATH (instálalo desde https://github.com/junegunn/fzf)",
+		"git %s failed: %s":                                                       "git %s falló: %s",
+		"git %s failed: %v":                                                       "git %s falló: %v",
+		"git show %s failed: %s":                                                  "git show %s falló: %s",
+		"git show %s failed: %v":                                                  "git show %s falló: %v",
+		"git worktree list failed: %s":                                            "git worktree list falló: %s",
+		"git worktree list failed: %v":                                            "git worktree list falló: %v",
+		"hidden directory":                                                        "directorio oculto",
+		"hidden file":                                                             "archivo oculto",
+		"ignore pattern %q is not a valid glob and never matches":                 "el patrón de ignorar %q no es un glob válido y nunca coincide",
+		"ignore rule %q matches no file":                                          "la regla de ignorar %q no coincide con ningún archivo",
+		"ignore rule needs a pattern, older_than or owner":                        "la regla de ignorar necesita pattern, older_than u owner",
+		"invalid %s hook: %v":                                                     "hook %s no válido: %v",
+		"invalid --encrypt value %q (expected age:RECIPIENT or gpg:RECIPIENT)":    "valor de --encrypt no válido %q (se esperaba age:RECIPIENT o gpg:RECIPIENT)",
+		"invalid --only pattern %q":                                               "patrón de --only no válido %q",
+		"invalid --to target %q (expected clipboard, stdout, file=PATH or sink=NAME)": "destino de --to no válido %q (se esperaba clipboard, stdout, file=PATH o sink=NAME)",
+		"invalid YAML format: %v":                                                                "formato YAML no válido: %v",
+		"invalid anonymize pattern %q: %v":                                                       "patrón de anonymize no válido %q: %v",
+		"invalid audit.allow pattern %q: %v":                                                     "patrón de audit.allow no válido %q: %v",
+		"invalid audit.patterns pattern %q: %v":                                                  "patrón de audit.patterns no válido %q: %v",
+		"invalid condense pattern %q":                                                            "patrón de condense no válido %q",
+		"invalid dependency_dirs value %q (expected prompt, skip or include)":                    "valor de dependency_dirs no válido %q (se esperaba prompt, skip o include)",
+		"invalid dialect %q for database %q (expected postgres, mysql or sqlite)":                "dialecto no válido %q para la base de datos %q (se esperaba postgres, mysql o sqlite)",
+		"invalid drop_policy value %q (expected even, largest-first, oldest-first or by-weight)": "valor de drop_policy no válido %q (se esperaba even, largest-first, oldest-first o by-weight)",
+		"invalid drop_weights pattern %q":                                                        "patrón de drop_weights no válido %q",
+		"invalid empty_files value %q (expected include, omit or list)":                          "valor de empty_files no válido %q (se esperaba include, omit o list)",
+		"invalid glob %q in section %q":                                                          "glob no válido %q en la sección %q",
+		"invalid glob %q in transform %q":                                                        "glob no válido %q en la transformación %q",
+		"invalid hash value %q (expected xxhash, blake3 or sha256)":                              "valor de hash no válido %q (se esperaba xxhash, blake3 o sha256)",
+		"invalid include pattern %q":                                                             "patrón de include no válido %q",
+		"invalid large_files value %q (expected truncate or skip)":                               "valor de large_files no válido %q (se esperaba truncate o skip)",
+		"invalid max_bytes: %v":                                                                  "max_bytes no válido: %v",
+		"invalid max_file_size: %v":                                                              "max_file_size no válido: %v",
+		"invalid metrics file %s: %v":                                                            "archivo de métricas no válido %s: %v",
+		"invalid older_than value %q: %v":                                                        "valor de older_than no válido %q: %v",
+		"invalid order value %q (expected %s, optionally prefixed with -)":                       "valor de order no válido %q (se esperaba %s, opcionalmente precedido de -)",
+		"invalid rename path %q (expected a path relative to the config)":                        "ruta de rename no válida %q (se esperaba una ruta relativa a la configuración)",
+		"invalid size %q (expected e.g. 512KB, 10MB or 1GB)":                                     "tamaño no válido %q (se esperaba p. ej. 512KB, 10MB o 1GB)",
+		"invalid skipped_files value %q (expected omit or annotate)":                             "valor de skipped_files no válido %q (se esperaba omit o annotate)",
+		"invalid template: %v":                                                                   "plantilla no válida: %v",
+		"invalid text_detection value %q (expected probe or extension)":                          "valor de text_detection no válido %q (se esperaba probe o extension)",
+		"invalid timeout %q in transform %q":                                                     "tiempo límite no válido %q en la transformación %q",
+		"invalid transform_cmd pattern %q":                                                       "patrón de transform_cmd no válido %q",
+		"invalid value for %s: %v":                                                               "valor no válido para %s: %v",
+		"invalid workers value %d (expected a positive number)":                                  "valor de workers no válido %d (se esperaba un número positivo)",
+		"match needs a glob or a list of globs":                                                  "match necesita un glob o una lista de globs",
+		"max_tokens and max_bytes cannot be combined":                                            "max_tokens y max_bytes no se pueden combinar",
+		"max_tokens must not be negative":                                                        "max_tokens no puede ser negativo",
+		"no %s driver is registered (import %s)":                                                 "no hay ningún controlador %s registrado (importa %s)",
+		"no Go package matches %s":                                                               "ningún paquete Go coincide con %s",
+		"no codesnap.yml found below the current directory":                                      "no se encontró ningún codesnap.yml bajo el directorio actual",
+		"no config produced a snapshot":                                                          "ninguna configuración produjo una instantánea",
+		"no files changed since the last incremental run":                                        "no cambió ningún archivo desde la última ejecución incremental",
+		"no files of the last run match %s":                                                      "ningún archivo de la última ejecución coincide con %s",
+		"no files selected":                                                                      "no se seleccionaron archivos",
+		"no files to pick from":                                                                  "no hay archivos para elegir",
+		"no folders to watch":                                                                    "no hay carpetas que vigilar",
+		"no git worktree found for %q":                                                           "no se encontró ningún worktree de git para %q",
+		"no results to render yet; run codesnap first":                                           "aún no hay resultados que renderizar; ejecuta codesnap primero",
+		"no selected files are staged":                                                           "ningún archivo seleccionado está preparado (staged)",
+		"no selected files changed since %s":                                                     "ningún archivo seleccionado cambió desde %s",
+		"no valid files were processed":                                                          "no se procesó ningún archivo válido",
+		"no valid folders or files were found":                                                   "no se encontraron carpetas ni archivos válidos",
+		"not changed":                                                                            "sin cambios",
+		"not matched by include":                                                                 "no coincide con include",
+		"not supported on Windows":                                                               "no compatible con Windows",
+		"other":                                                                                  "otros",
+		"pager %s failed: %v":                                                                    "el paginador %s falló: %v",
+		"profiles cannot be nested":                                                              "los perfiles no se pueden anidar",
+		"rename of %q needs a non-empty virtual path":                                            "el rename de %q necesita una ruta virtual no vacía",
+		"section %q needs a glob or a list of globs":                                             "la sección %q necesita un glob o una lista de globs",
+		"server failed: %v":                                                                      "el servidor falló: %v",
+		"sink %q failed: %v":                                                                     "el sink %q falló: %v",
+		"sink %q needs a cmd":                                                                    "el sink %q necesita un cmd",
+		"snapshot was not collected by a Collector":                                              "la instantánea no la recopiló un Collector",
+		"transform %q failed: %s":                                                                "la transformación %q falló: %s",
+		"transform %q failed: %v":                                                                "la transformación %q falló: %v",
+		"transform %q needs a match glob":                                                        "la transformación %q necesita un glob en match",
+		"transform %q timed out after %s":                                                        "la transformación %q superó el tiempo límite de %s",
+		"transform_cmd entry needs a run command":                                                "la entrada de transform_cmd necesita un comando run",
+		"transforms entry needs a cmd":                                                           "la entrada de transforms necesita un cmd",
+		"unknown audit check %q (expected secrets, pii, network or markers)":                     "comprobación de auditoría desconocida %q (se esperaba secrets, pii, network o markers)",
+		"unknown file group %q (expected one of %s)":                                             "grupo de archivos desconocido %q (se esperaba uno de %s)",
+		"unknown option %q":                                                                      "opción desconocida %q",
+		"unknown profile %q (available: %s)":                                                     "perfil desconocido %q (disponibles: %s)",
+		"unknown sink %q (available: %s)":                                                        "sink desconocido %q (disponibles: %s)",
+	},
+}
+
+// T returns the translation of msg for the active language, or msg itself
+func T(msg string) string {
+	if translated, ok := catalogs[language][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// setLanguage selects the message catalog from the --lang flag or, when it
+// is empty, from the usual locale environment variables
+func setLanguage(flagValue string) {
+	candidates := []string{flagValue, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, value := range candidates {
+		if value == "" {
+			continue
+		}
+		// Reduce locales like de_DE.UTF-8 or es-MX to the language code
+		code := strings.ToLower(value)
+		if i := strings.IndexAny(code, "_-.@"); i >= 0 {
+			code = code[:i]
+		}
+		if _, ok := catalogs[code]; ok {
+			language = code
+		} else {
+			language = ""
+		}
+		return
+	}
+}
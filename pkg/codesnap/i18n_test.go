@@ -0,0 +1,100 @@
+package codesnap
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return true
+			}
+			if fn, ok := call.Fun.(*ast.Ident); !ok || fn.Name != "T" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("%s: T needs a string literal", fset.Position(call.Pos()))
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages[msg] = true
+			return true
+		})
+	}
+
+	verbs := regexp.MustCompile(`%[a-z]`)
+	for lang, catalog := range catalogs {
+		for msg := range messages {
+			translated, ok := catalog[msg]
+			if !ok {
+				t.Errorf("%s: no translation of %q", lang, msg)
+				continue
+			}
+			if got, want := verbs.FindAllString(translated, -1), verbs.FindAllString(msg, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q has the verbs %v, want %v", lang, translated, got, want)
+			}
+		}
+		for msg := range catalog {
+			if !messages[msg] {
+				t.Errorf("%s: %q is translated but never used", lang, msg)
+			}
+		}
+	}
+}
+
+func TestSnapshotIgnoresLanguage(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: .\nnotes:\n  - Read a.go first\n",
+		"a.go":         "package a\n",
+		"b/b.go":       "package b\n",
+	})
+	out := filepath.Join(t.TempDir(), "codesnap.txt")
+	snapshot := func(lang string) string {
+		r := runCodesnap(t, dir, "--lang", lang, "--with-tree", "-O", out)
+		if r.code != 0 {
+			t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+		}
+		content, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	// The first run creates the .codesnap state directory, which the tree shows
+	snapshot("en")
+	want := snapshot("en")
+	for _, lang := range []string{"de", "es"} {
+		if got := snapshot(lang); got != want {
+			t.Errorf("snapshot with --lang %s differs from English:\n%s", lang, got)
+		}
+	}
+}
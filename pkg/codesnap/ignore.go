@@ -0,0 +1,138 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IgnoreRule is an entry of the ignore list. It is written either as a plain
+// glob pattern or as a mapping that adds predicates on the file's metadata:
+//
+//	ignore:
+//	  - "**/*.log"
+//	  - pattern: "vendor/**"
+//	    older_than: 2y
+//	  - owner: root
+//
+// A file is ignored when it matches the pattern (all files if omitted) and
+// every predicate given.
+type IgnoreRule struct {
+	Pattern   string `yaml:"pattern"`
+	OlderThan string `yaml:"older_than"`
+	Owner     string `yaml:"owner"`
+
+	maxAge time.Duration
+}
+
+// UnmarshalYAML accepts both the plain string and the mapping form
+func (r *IgnoreRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var pattern string
+	if err := unmarshal(&pattern); err == nil {
+		*r = IgnoreRule{Pattern: pattern}
+		return nil
+	}
+
+	type plain IgnoreRule
+	var rule plain
+	if err := unmarshal(&rule); err != nil {
+		return err
+	}
+	*r = IgnoreRule(rule)
+	return nil
+}
+
+// prepare validates the rule and parses its age predicate
+func (r *IgnoreRule) prepare() error {
+	if r.Pattern == "" && r.OlderThan == "" && r.Owner == "" {
+		return errors.New(T("ignore rule needs a pattern, older_than or owner"))
+	}
+	if err := checkGroup(r.Pattern); err != nil {
+		return err
+	}
+	if r.OlderThan != "" {
+		age, err := parseAge(r.OlderThan)
+		if err != nil {
+			return fmt.Errorf(T("invalid older_than value %q: %v"), r.OlderThan, err)
+		}
+		r.maxAge = age
+	}
+	return nil
+}
+
+// matches reports whether the rule ignores the file at path, whose
+// slash-separated path relative to the config directory is relPath
+func (r *IgnoreRule) matches(path, relPath string) bool {
+	if r.Pattern != "" && !matchPattern(filepath.ToSlash(r.Pattern), relPath) {
+		return false
+	}
+
+	if r.maxAge == 0 && r.Owner == "" {
+		return true
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	if r.maxAge > 0 && time.Since(info.ModTime()) < r.maxAge {
+		return false
+	}
+	if r.Owner != "" {
+		owner, uid, err := fileOwner(info)
+		if err != nil || (owner != r.Owner && uid != r.Owner) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the rule for log messages
+func (r *IgnoreRule) String() string {
+	var parts []string
+	if r.Pattern != "" {
+		parts = append(parts, r.Pattern)
+	}
+	if r.OlderThan != "" {
+		parts = append(parts, "older_than: "+r.OlderThan)
+	}
+	if r.Owner != "" {
+		parts = append(parts, "owner: "+r.Owner)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ageUnits are the suffixes accepted by parseAge in addition to the units
+// understood by time.ParseDuration
+var ageUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"mo", 30 * 24 * time.Hour},
+	{"y", 365 * 24 * time.Hour},
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+}
+
+// parseAge parses ages like 2y, 6mo, 3w, 10d or any Go duration such as 12h
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for _, u := range ageUnits {
+		if number, ok := strings.CutSuffix(s, u.suffix); ok {
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("expected a positive number before %q", u.suffix)
+			}
+			return time.Duration(n * float64(u.unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("expected an age like 2y, 6mo, 3w, 10d or 12h")
+	}
+	return d, nil
+}
@@ -0,0 +1,89 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"2y", 2 * 365 * 24 * time.Hour, true},
+		{"6mo", 6 * 30 * 24 * time.Hour, true},
+		{"3w", 3 * 7 * 24 * time.Hour, true},
+		{"1.5d", 36 * time.Hour, true},
+		{"12h", 12 * time.Hour, true},
+		{" 10d ", 10 * 24 * time.Hour, true},
+		{"0d", 0, false},
+		{"-1y", 0, false},
+		{"y", 0, false},
+		{"soon", 0, false},
+	} {
+		got, err := parseAge(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v, ok %v", tc.in, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	uid := strconv.Itoa(os.Getuid())
+	for _, tc := range []struct {
+		name, rule    string
+		kept, ignored []string
+	}{
+		{"pattern", `"old/**"`, []string{"new.go"}, []string{"old/old.go"}},
+		{"older than", "older_than: 1y", []string{"new.go"}, []string{"old/old.go"}},
+		{"pattern and age", "pattern: new.go\n    older_than: 1y", []string{"new.go", "old/old.go"}, nil},
+		{"owner by uid", "owner: \"" + uid + "\"", nil, []string{"new.go", "old/old.go"}},
+		{"other owner", "owner: nobody-" + uid, []string{"new.go", "old/old.go"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if strings.HasPrefix(tc.rule, "owner") && runtime.GOOS == "windows" {
+				t.Skip("owner rules are not supported on Windows")
+			}
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n  - " + tc.rule + "\n",
+				"new.go":       "package new\n",
+				"old/old.go":   "package old\n",
+			})
+			twoYearsAgo := time.Now().AddDate(-2, 0, 0)
+			if err := os.Chtimes(filepath.Join(dir, "old", "old.go"), twoYearsAgo, twoYearsAgo); err != nil {
+				t.Fatal(err)
+			}
+			r := runCodesnap(t, dir, "--stdout", "-q")
+			if len(tc.kept) == 0 {
+				if r.code != exitNothingCollected {
+					t.Fatalf("exit code %d with every file ignored, want %d", r.code, exitNothingCollected)
+				}
+				return
+			}
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, file := range tc.kept {
+				if !strings.Contains(r.stdout, "File: "+file) {
+					t.Errorf("%s is missing from the snapshot:\n%s", file, r.stdout)
+				}
+			}
+			for _, file := range tc.ignored {
+				if strings.Contains(r.stdout, "File: "+file) {
+					t.Errorf("%s is not ignored:\n%s", file, r.stdout)
+				}
+			}
+		})
+	}
+
+	dir := writeFiles(t, map[string]string{"codesnap.yml": "folders:\n  - .\nignore:\n  - older_than: someday\n"})
+	if r := runCodesnap(t, dir, "--stdout"); r.code != exitError || !strings.Contains(r.stdout+r.stderr, `invalid older_than value "someday"`) {
+		t.Errorf("exit code %d for an invalid age, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+}
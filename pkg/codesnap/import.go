@@ -0,0 +1,199 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// importedConfig is the part of a codesnap.yml that codesnap import can
+// fill in from another tool's config
+type importedConfig struct {
+	Folders     []string `yaml:"folders"`
+	Include     []string `yaml:"include,omitempty"`
+	Ignore      []string `yaml:"ignore,omitempty"`
+	MaxFileSize string   `yaml:"max_file_size,omitempty"`
+}
+
+// repomixConfig is the subset of repomix.config.json that selects files
+type repomixConfig struct {
+	Include []string `json:"include"`
+	Ignore  struct {
+		UseGitignore       *bool    `json:"useGitignore"`
+		UseDefaultPatterns *bool    `json:"useDefaultPatterns"`
+		CustomPatterns     []string `json:"customPatterns"`
+	} `json:"ignore"`
+	Input struct {
+		MaxFileSize int64 `json:"maxFileSize"`
+	} `json:"input"`
+}
+
+// gitingestConfig is a .gitingest file
+type gitingestConfig struct {
+	Config struct {
+		IgnorePatterns []string `toml:"ignore_patterns"`
+	} `toml:"config"`
+}
+
+// importDefaultPatterns stand in for the default ignore patterns of
+// repomix, which also leaves out version control and dependency folders
+var importDefaultPatterns = []string{
+	"**/.git/**", "**/node_modules/**", "**/vendor/**", "**/dist/**", "**/build/**",
+	"**/__pycache__/**", "**/.venv/**", "**/*.log", "**/*.lock", "**/package-lock.json",
+}
+
+// importConfig converts the file selection of a repomix.config.json or a
+// .gitingest file into a new codesnap config at configPath. Patterns that
+// cannot be expressed, such as gitignore negations, are returned so they
+// can be reported.
+func importConfig(source, configPath string) ([]string, error) {
+	if _, err := os.Stat(configPath); err == nil {
+		return nil, fmt.Errorf(T("%s already exists; remove it or pass another -c PATH"), configPath)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to read config to import: %v"), err)
+	}
+
+	sourceDir, err := filepath.Abs(filepath.Dir(source))
+	if err != nil {
+		return nil, err
+	}
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Rel(configDir, sourceDir)
+	if err != nil {
+		root = sourceDir
+	}
+	root = filepath.ToSlash(root)
+
+	var patterns, include []string
+	var maxFileSize int64
+	useGitignore := false
+	tool := "repomix"
+	switch name := filepath.Base(source); {
+	case name == ".gitingest" || strings.HasSuffix(name, ".toml"):
+		tool = "gitingest"
+		var cfg gitingestConfig
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf(T("failed to parse %s: %v"), source, err)
+		}
+		patterns = cfg.Config.IgnorePatterns
+	case strings.HasSuffix(name, ".json"):
+		var cfg repomixConfig
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf(T("failed to parse %s: %v"), source, err)
+		}
+		for _, p := range cfg.Include {
+			if p != "**/*" && p != "**" {
+				include = append(include, p)
+			}
+		}
+		patterns = cfg.Ignore.CustomPatterns
+		useGitignore = cfg.Ignore.UseGitignore == nil || *cfg.Ignore.UseGitignore
+		if cfg.Ignore.UseDefaultPatterns == nil || *cfg.Ignore.UseDefaultPatterns {
+			patterns = append(append([]string{}, importDefaultPatterns...), patterns...)
+		}
+		maxFileSize = cfg.Input.MaxFileSize
+	default:
+		return nil, fmt.Errorf(T("cannot import %s (expected a repomix .json config or a .gitingest file)"), source)
+	}
+	if useGitignore {
+		gitignore, err := os.ReadFile(filepath.Join(sourceDir, ".gitignore"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf(T("failed to read .gitignore: %v"), err)
+		}
+		patterns = append(patterns, strings.Split(string(gitignore), "\n")...)
+	}
+
+	cfg := importedConfig{Folders: []string{root}}
+	var unsupported []string
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		glob, ok := gitignoreGlob(p)
+		if !ok {
+			if p = strings.TrimSpace(p); p != "" && !strings.HasPrefix(p, "#") {
+				unsupported = append(unsupported, p)
+			}
+			continue
+		}
+		if glob = underRoot(root, glob); !seen[glob] {
+			seen[glob] = true
+			cfg.Ignore = append(cfg.Ignore, glob)
+		}
+	}
+	for _, p := range include {
+		cfg.Include = append(cfg.Include, underRoot(root, strings.TrimPrefix(p, "/")))
+	}
+	if maxFileSize > 0 {
+		cfg.MaxFileSize = configSize(maxFileSize)
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("# Converted from %s (%s) by codesnap import\n", filepath.Base(source), tool)
+	if err := os.WriteFile(configPath, append([]byte(header), out...), 0644); err != nil {
+		return nil, fmt.Errorf(T("failed to write %s: %v"), configPath, err)
+	}
+	return unsupported, nil
+}
+
+// gitignoreGlob converts a gitignore-style pattern, as repomix and
+// gitingest use them, to an ignore glob. A pattern without a slash matches
+// at any depth, and one that names a directory ignores everything in it.
+// Comments, blank lines and negations report false.
+func gitignoreGlob(pattern string) (string, bool) {
+	p := strings.TrimSpace(pattern)
+	if p == "" || strings.HasPrefix(p, "#") || strings.HasPrefix(p, "!") {
+		return "", false
+	}
+	dir := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return "", false
+	}
+	last := p[strings.LastIndex(p, "/")+1:]
+	if !strings.ContainsAny(last, "*?[.") {
+		// A plain name such as node_modules or build is most likely a
+		// directory
+		dir = true
+	}
+	if !anchored && !strings.HasPrefix(p, "**/") {
+		p = "**/" + p
+	}
+	if dir && !strings.HasSuffix(p, "/**") {
+		p += "/**"
+	}
+	return p, true
+}
+
+// underRoot makes a glob relative to the imported tool's directory
+// relative to the new config instead
+func underRoot(root, glob string) string {
+	if root == "." || strings.HasPrefix(glob, "**/") {
+		return glob
+	}
+	return root + "/" + glob
+}
+
+// configSize writes n in the largest unit of max_file_size that is exact
+func configSize(n int64) string {
+	for _, unit := range sizeUnits {
+		if n%unit.bytes == 0 {
+			return fmt.Sprintf("%d%s", n/unit.bytes, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
@@ -0,0 +1,100 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitignoreGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, want string
+		ok            bool
+	}{
+		{"*.log", "**/*.log", true},
+		{"node_modules", "**/node_modules/**", true},
+		{"build/", "**/build/**", true},
+		{"/dist", "dist/**", true},
+		{"docs/*.md", "docs/*.md", true},
+		{"**/tmp/", "**/tmp/**", true},
+		{"  .env  ", "**/.env", true},
+		{"# comment", "", false},
+		{"", "", false},
+		{"!keep.log", "", false},
+		{"/", "", false},
+	} {
+		if got, ok := gitignoreGlob(tc.pattern); got != tc.want || ok != tc.ok {
+			t.Errorf("gitignoreGlob(%q) = %q, %v; want %q, %v", tc.pattern, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		files  map[string]string
+		args   []string
+		code   int
+		output []string
+		path   string // where the config is written, if not codesnap.yml
+		config string // the start of the config written, if any
+	}{
+		{"repomix", map[string]string{
+			"repomix.config.json": `{"include": ["src/**", "**/*"], "ignore": {"useDefaultPatterns": false, "customPatterns": ["*.snap", "!keep.snap"]}, "input": {"maxFileSize": 1048576}}`,
+			".gitignore":          "# build output\nbin/\n",
+		}, []string{"import", "repomix.config.json"}, 0,
+			[]string{"Created codesnap.yml from repomix.config.json\n", `Warning: pattern "!keep.snap" has no codesnap equivalent and was left out`}, "",
+			"# Converted from repomix.config.json (repomix) by codesnap import\nfolders:\n- .\ninclude:\n- src/**\nignore:\n- '**/*.snap'\n- '**/bin/**'\nmax_file_size: 1MB\n"},
+		{"repomix defaults", map[string]string{
+			"repomix.config.json": `{"ignore": {"useGitignore": false}}`,
+		}, []string{"import", "repomix.config.json"}, 0, nil, "",
+			"# Converted from repomix.config.json (repomix) by codesnap import\nfolders:\n- .\nignore:\n- '**/.git/**'\n- '**/node_modules/**'\n"},
+		{"gitingest in a subdirectory", map[string]string{
+			"app/.gitingest": "[config]\nignore_patterns = [\"/generated\", \"*.pyc\"]\n",
+		}, []string{"import", "app/.gitingest"}, 0, []string{"Created codesnap.yml from app/.gitingest\n"}, "",
+			"# Converted from .gitingest (gitingest) by codesnap import\nfolders:\n- app\nignore:\n- app/generated/**\n- '**/*.pyc'\n"},
+		{"another config path", map[string]string{
+			"repomix.config.json": `{"ignore": {"useGitignore": false, "useDefaultPatterns": false}}`,
+			"codesnap.yml":        "folders:\n  - .\n",
+			"out/notes.txt":       "",
+		}, []string{"import", "repomix.config.json", "-c", "out/snap.yml"}, 0, []string{"Created out/snap.yml"}, "out/snap.yml",
+			"# Converted from repomix.config.json (repomix) by codesnap import\nfolders:\n- ..\n"},
+		{"existing config", map[string]string{
+			"repomix.config.json": `{}`,
+			"codesnap.yml":        "folders:\n  - .\n",
+		}, []string{"import", "repomix.config.json"}, exitError, []string{"codesnap.yml already exists; remove it or pass another -c PATH"}, "", ""},
+		{"unknown format", map[string]string{"settings.ini": "[x]\n"}, []string{"import", "settings.ini"}, exitError,
+			[]string{"cannot import settings.ini (expected a repomix .json config or a .gitingest file)"}, "", ""},
+		{"invalid json", map[string]string{"repomix.config.json": "{"}, []string{"import", "repomix.config.json"}, exitError,
+			[]string{"failed to parse repomix.config.json"}, "", ""},
+		{"no file", nil, []string{"import"}, exitError, []string{"import requires a config to convert"}, "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			r := runCodesnap(t, dir, tc.args...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			for _, s := range tc.output {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			if tc.config == "" {
+				return
+			}
+			path := "codesnap.yml"
+			if tc.path != "" {
+				path = tc.path
+			}
+			config, err := os.ReadFile(filepath.Join(dir, path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(config), tc.config) {
+				t.Errorf("config =\n%s\nwant it to start with\n%s", config, tc.config)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cacheFile keeps the state of the last incremental run, relative to the
+// config directory
+const cacheFile = stateDir + "/cache.json"
+
+// cacheEntry is what an incremental run remembers about a file
+type cacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Hash    string `json:"hash,omitempty"` // empty for files that were skipped
+}
+
+type snapshotCache struct {
+	Hash  string                `json:"hash"` // algorithm the hashes were computed with
+	Files map[string]cacheEntry `json:"files"`
+}
+
+// loadCache reads the cache, treating a missing or unreadable one as empty
+// so the next run simply includes everything
+func loadCache(path, algorithm string) *snapshotCache {
+	cache := &snapshotCache{}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, cache)
+	}
+	if cache.Hash != algorithm || cache.Files == nil {
+		cache = &snapshotCache{Files: make(map[string]cacheEntry)}
+	}
+	cache.Hash = algorithm
+	return cache
+}
+
+func (c *snapshotCache) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(T("failed to save cache: %v"), err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf(T("failed to save cache: %v"), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf(T("failed to save cache: %v"), err)
+	}
+	return nil
+}
+
+// collectIncremental snapshots only the files that changed since the last
+// incremental run. A parallel metadata pass compares size and mtime with
+// the cache, so only candidates are read and hashed; candidates whose hash
+// is unchanged (e.g. touched files) are left out as well.
+func (cs *CodeSnap) collectIncremental(paths []string) (string, error) {
+	cachePath := filepath.Join(cs.configDir, filepath.FromSlash(cacheFile))
+	cache := loadCache(cachePath, cs.config.Hash)
+
+	metadata := make([]cacheEntry, len(paths))
+	parallelFor(len(paths), cs.workers, func(i int) {
+		if info, err := os.Stat(paths[i]); err == nil {
+			metadata[i] = cacheEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		}
+	})
+
+	next := &snapshotCache{Hash: cache.Hash, Files: make(map[string]cacheEntry, len(paths))}
+	candidates := make([]string, 0, len(paths))
+	current := make(map[string]cacheEntry, len(paths))
+	unchanged := 0
+	for i, path := range paths {
+		old, ok := cache.Files[path]
+		if ok && old.Size == metadata[i].Size && old.ModTime == metadata[i].ModTime {
+			next.Files[path] = old
+			unchanged++
+			continue
+		}
+		candidates = append(candidates, path)
+		current[path] = metadata[i]
+	}
+
+	var changed []fileResult
+	for _, r := range cs.readAll(candidates) {
+		entry := current[r.path]
+		entry.Hash = r.hash
+		next.Files[r.path] = entry
+		if old, ok := cache.Files[r.path]; ok && r.err == nil && old.Hash == r.hash {
+			unchanged++
+			continue
+		}
+		changed = append(changed, r)
+	}
+
+	if err := next.save(cachePath); err != nil {
+		return "", err
+	}
+
+	if len(changed) == 0 {
+		return "", nothingCollected{errors.New(T("no files changed since the last incremental run"))}
+	}
+	if err := cs.saveLastRun(changed); err != nil {
+		fmt.Printf(T("Warning: %v\n"), err)
+	}
+	cs.processResults(changed)
+	cs.stats.unchanged = unchanged
+	return cs.render(changed)
+}
@@ -0,0 +1,70 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIncremental(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"a.go":         "package a\n",
+		"b.go":         "package b\n",
+	})
+	later := time.Now().Add(time.Hour)
+	for _, tc := range []struct {
+		name           string
+		change         func() error
+		code           int
+		want, unwanted []string
+	}{
+		{"first run", nil, 0, []string{"File: a.go", "File: b.go"}, nil},
+		{"nothing changed", nil, exitNothingCollected, []string{"no files changed since the last incremental run"}, nil},
+		{"touched", func() error { return os.Chtimes(filepath.Join(dir, "a.go"), later, later) },
+			exitNothingCollected, []string{"no files changed"}, nil},
+		{"modified", func() error { return os.WriteFile(filepath.Join(dir, "b.go"), []byte("package b // changed\n"), 0644) },
+			0, []string{"File: b.go", "- Unchanged files: 1"}, []string{"File: a.go"}},
+		{"added", func() error { return os.WriteFile(filepath.Join(dir, "c.go"), []byte("package c\n"), 0644) },
+			0, []string{"File: c.go"}, []string{"File: a.go", "File: b.go"}},
+	} {
+		if tc.change != nil {
+			if err := tc.change(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r := runCodesnap(t, dir, "--incremental", "--stdout")
+		if r.code != tc.code {
+			t.Fatalf("%s: exit code %d, want %d; output: %s%s", tc.name, r.code, tc.code, r.stdout, r.stderr)
+		}
+		out := r.stdout + r.stderr
+		for _, s := range tc.want {
+			if !strings.Contains(out, s) {
+				t.Errorf("%s: output lacks %q, got:\n%s", tc.name, s, out)
+			}
+		}
+		for _, s := range tc.unwanted {
+			if strings.Contains(out, s) {
+				t.Errorf("%s: output has %q, got:\n%s", tc.name, s, out)
+			}
+		}
+	}
+}
+
+func TestLoadCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache := loadCache(path, "xxhash")
+	cache.Files["a.go"] = cacheEntry{Size: 10, ModTime: 1, Hash: "abc"}
+	if err := cache.save(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadCache(path, "xxhash"); got.Files["a.go"] != cache.Files["a.go"] {
+		t.Errorf("the cache holds %+v, want %+v", got.Files["a.go"], cache.Files["a.go"])
+	}
+	// Hashes of another algorithm cannot be compared
+	if got := loadCache(path, "sha256"); len(got.Files) != 0 || got.Hash != "sha256" {
+		t.Errorf("the cache for sha256 = %+v, want an empty one", got)
+	}
+}
@@ -0,0 +1,92 @@
+package codesnap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxFileSize is the part of a file included when max_file_size is
+// not configured. It keeps a stray multi-GB log from exhausting memory.
+const defaultMaxFileSize = 10 << 20
+
+// errFileTooLarge marks files skipped with large_files: skip
+var errFileTooLarge = errors.New("file is larger than max_file_size")
+
+// sizeUnits are the suffixes accepted by parseSize, largest first
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+// parseSize parses a size such as 512KB, 10MB or 2GB (powers of 1024)
+func parseSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(upper, unit.suffix); ok {
+			n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil || n <= 0 {
+				break
+			}
+			return int64(n * float64(unit.bytes)), nil
+		}
+	}
+	return 0, fmt.Errorf(T("invalid size %q (expected e.g. 512KB, 10MB or 1GB)"), s)
+}
+
+// formatSize formats a byte count with the largest unit that fits
+func formatSize(n int64) string {
+	for _, unit := range sizeUnits[:len(sizeUnits)-1] {
+		if n >= unit.bytes {
+			rounded := math.Round(float64(n)*10/float64(unit.bytes)) / 10
+			return strconv.FormatFloat(rounded, 'f', -1, 64) + " " + unit.suffix
+		}
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// readLimited appends at most limit bytes from r to head and reports
+// whether r had more. Reading stops at the limit, so only that much of a
+// huge file is ever held in memory.
+func readLimited(r io.Reader, head []byte, limit int64) ([]byte, bool, error) {
+	var buf bytes.Buffer
+	buf.Write(head)
+	if rest := limit - int64(len(head)); rest > 0 {
+		if _, err := io.CopyN(&buf, r, rest); err != nil && err != io.EOF {
+			return nil, false, err
+		}
+	}
+	content := buf.Bytes()
+	if int64(len(content)) > limit {
+		return cutAtLine(content[:limit]), true, nil
+	}
+	var probe [1]byte
+	if n, _ := r.Read(probe[:]); n > 0 {
+		return cutAtLine(content), true, nil
+	}
+	return content, false, nil
+}
+
+// cutAtLine shortens truncated content to its last complete line, or to
+// its last complete character when it has no line break
+func cutAtLine(content []byte) []byte {
+	if i := bytes.LastIndexByte(content, '\n'); i >= 0 {
+		return content[:i+1]
+	}
+	for end := len(content); end > 0 && end > len(content)-utf8.UTFMax; end-- {
+		if utf8.Valid(content[:end]) {
+			return content[:end]
+		}
+	}
+	return content
+}
+
+// truncationNote is the header suffix of a file cut at max_file_size
+func (r fileResult) truncationNote() string {
+	return fmt.Sprintf(" (truncated: first %s of %s)", formatSize(int64(len(r.content))), formatSize(r.truncatedFrom))
+}
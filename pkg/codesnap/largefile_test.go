@@ -0,0 +1,169 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want int64
+		err  bool
+	}{
+		{"512KB", 512 << 10, false},
+		{"10MB", 10 << 20, false},
+		{" 1.5 gb ", 3 << 29, false},
+		{"100B", 100, false},
+		{"10", 0, true},
+		{"0MB", 0, true},
+		{"-1KB", 0, true},
+		{"tenMB", 0, true},
+	} {
+		got, err := parseSize(tc.s)
+		if got != tc.want || (err != nil) != tc.err {
+			t.Errorf("parseSize(%q) = %d, %v; want %d, error %v", tc.s, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, tc := range []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1 KB"},
+		{1536, "1.5 KB"},
+		{10 << 20, "10 MB"},
+		{8804682957, "8.2 GB"},
+	} {
+		if got := formatSize(tc.n); got != tc.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestReadLimited(t *testing.T) {
+	for _, tc := range []struct {
+		name, head, rest string
+		limit            int64
+		want             string
+		truncated        bool
+	}{
+		{"within the limit", "a\n", "b\n", 10, "a\nb\n", false},
+		{"exactly the limit", "a\n", "b\n", 4, "a\nb\n", false},
+		{"cut at the last line", "a\n", "b\nc\n", 5, "a\nb\n", true},
+		{"a head over the limit", "abcdef", "", 3, "abc", true},
+		{"no line break within a character", "h", "éllo", 2, "h", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, truncated, err := readLimited(strings.NewReader(tc.rest), []byte(tc.head), tc.limit)
+			if err != nil || string(got) != tc.want || truncated != tc.truncated {
+				t.Errorf("readLimited = %q, %v, %v; want %q, %v", got, truncated, err, tc.want, tc.truncated)
+			}
+		})
+	}
+}
+
+func TestMaxFileSize(t *testing.T) {
+	big := strings.Repeat("0123456789abcde\n", 200)
+	for _, tc := range []struct {
+		name, config   string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"the default limit", "", nil, 0, []string{"File: big.txt\n"}, []string{"truncated"}},
+		{"max_file_size", "max_file_size: 1KB\n", nil, 0,
+			[]string{"File: big.txt (truncated: first 1 KB of 3.1 KB)\n", "Warning: big.txt is larger than max_file_size, only its first 1 KB are included", "File: small.txt\n"},
+			[]string{"File: small.txt (truncated"}},
+		{"--max-file-size", "max_file_size: 1MB\n", []string{"--max-file-size", "2KB"}, 0,
+			[]string{"File: big.txt (truncated: first 2 KB of 3.1 KB)\n"}, nil},
+		{"json", "max_file_size: 1KB\n", []string{"--format", "json"}, 0, []string{`"truncated_from": 3200`}, nil},
+		{"quiet", "max_file_size: 1KB\n", []string{"-q"}, 0, []string{"(truncated: first 1 KB of 3.1 KB)"}, []string{"Warning"}},
+		{"invalid max_file_size", "max_file_size: big\n", nil, exitError, []string{`invalid max_file_size: invalid size "big"`}, nil},
+		{"invalid --max-file-size", "", []string{"--max-file-size", "-1KB"}, exitError, []string{"invalid --max-file-size"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.config,
+				"big.txt":      big,
+				"small.txt":    "small\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+			if tc.code == 0 && tc.config != "" && len(tc.args) == 0 && !strings.Contains(r.stdout, big[:1024]) {
+				t.Errorf("snapshot lacks the first KB of big.txt")
+			}
+		})
+	}
+}
+
+func TestLargeFiles(t *testing.T) {
+	big := strings.Repeat("0123456789abcde\n", 200)
+	for _, tc := range []struct {
+		name, config   string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"truncate", "large_files: truncate\n", nil, 0, []string{"File: big.txt (truncated: first 1 KB of 3.1 KB)\n"}, nil},
+		{"skip", "large_files: skip\n", nil, 0,
+			[]string{"File: small.txt\n", "- Files skipped: 1\n", "Skipping big.txt: file is larger than max_file_size (3.1 KB)"}, []string{"File: big.txt", "0123456789abcde"}},
+		{"skip in JSON", "large_files: skip\n", []string{"--format", "json"}, 0,
+			[]string{`"path": "big.txt"`, `"skip_reason": "too_large"`}, []string{"0123456789abcde"}},
+		{"skip with --max-file-size", "large_files: skip\n", []string{"--max-file-size", "4KB"}, 0,
+			[]string{"File: big.txt\n", "- Files skipped: 0\n"}, nil},
+		{"invalid", "large_files: drop\n", nil, exitError,
+			[]string{`invalid large_files value "drop" (expected truncate or skip)`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\nmax_file_size: 1KB\n" + tc.config,
+				"big.txt":      big,
+				"small.txt":    "small\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q", "-l")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			// Skipped files are explained in the log
+			out := r.stdout + r.stderr
+			logs, _ := filepath.Glob(filepath.Join(dir, "codesnap_log_*.txt"))
+			for _, path := range logs {
+				log, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out += string(log)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// lastRunFile keeps the files read by the last snapshot, relative to the
+// config directory, so codesnap render can re-render them
+const lastRunFile = stateDir + "/last-run.json"
+
+// cachedResult is a file as it was read, before expanding tabs, condensing
+// or any other processing, which render applies again
+type cachedResult struct {
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+	Size    int64  `json:"size"`
+	Hash    string `json:"hash,omitempty"`
+	Empty   bool   `json:"empty,omitempty"`
+	// TruncatedFrom is the size on disk of a file cut at max_file_size
+	TruncatedFrom int64  `json:"truncated_from,omitempty"`
+	License       string `json:"license,omitempty"`
+	Encoding      string `json:"encoding,omitempty"` // of transcoded files
+	// Error and SkipReason describe why a file was skipped
+	Error      string `json:"error,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+// saveLastRun stores the read results for codesnap render
+func (cs *CodeSnap) saveLastRun(results []fileResult) error {
+	cached := make([]cachedResult, len(results))
+	for i, r := range results {
+		cached[i] = cachedResult{Path: r.path, Content: r.content, Size: r.size, Hash: r.hash, Empty: r.empty, TruncatedFrom: r.truncatedFrom, License: r.license, Encoding: r.encoding}
+		if r.err != nil {
+			cached[i].Error, cached[i].SkipReason = r.err.Error(), skipReason(r.err)
+		}
+	}
+
+	path := filepath.Join(cs.configDir, filepath.FromSlash(lastRunFile))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(T("failed to save results of this run: %v"), err)
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf(T("failed to save results of this run: %v"), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf(T("failed to save results of this run: %v"), err)
+	}
+	return nil
+}
+
+// renderLastRun re-renders the files of the last run whose path relative
+// to the config matches one of the only patterns (all files when only is
+// empty), without reading the files again
+func (cs *CodeSnap) renderLastRun(only []string) (string, error) {
+	for _, pattern := range only {
+		if !doublestar.ValidatePattern(pattern) {
+			return "", fmt.Errorf(T("invalid --only pattern %q"), pattern)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(cs.configDir, filepath.FromSlash(lastRunFile)))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", errors.New(T("no results to render yet; run codesnap first"))
+	} else if err != nil {
+		return "", fmt.Errorf(T("failed to read results of the last run: %v"), err)
+	}
+	var cached []cachedResult
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return "", fmt.Errorf(T("failed to read results of the last run: %v"), err)
+	}
+
+	var results []fileResult
+	for _, c := range cached {
+		if !matchesAny(only, filepath.ToSlash(cs.relPath(c.Path))) {
+			continue
+		}
+		r := fileResult{path: c.Path, relPath: cs.displayPath(c.Path), content: c.Content, size: c.Size, hash: c.Hash, empty: c.Empty, truncatedFrom: c.TruncatedFrom, license: c.License, encoding: c.Encoding}
+		if c.Error != "" {
+			r.err = cachedError(c)
+		} else if r.hash == "" && cs.config.Dedupe {
+			r.hash = cs.contentHash([]byte(c.Content))
+		}
+		results = append(results, r)
+	}
+	if len(results) == 0 {
+		return "", nothingCollected{fmt.Errorf(T("no files of the last run match %s"), strings.Join(only, ", "))}
+	}
+
+	cs.processResults(results)
+	return cs.render(results)
+}
+
+// matchesAny reports whether rel matches one of patterns (globs or
+// @groups), or patterns is empty
+func matchesAny(patterns []string, rel string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matchPattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedError restores the error of a skipped file, keeping the sentinel
+// errors so the JSON skip_reason stays the same
+func cachedError(c cachedResult) error {
+	switch c.SkipReason {
+	case "binary":
+		return errBinaryFile
+	case "invalid_utf8":
+		return errInvalidUTF8
+	case "license":
+		return fmt.Errorf("%w%s", errExcludedLicense, strings.TrimPrefix(c.Error, errExcludedLicense.Error()))
+	case "credentials":
+		return errCredentialFile
+	case "too_large":
+		return fmt.Errorf("%w%s", errFileTooLarge, strings.TrimPrefix(c.Error, errFileTooLarge.Error()))
+	}
+	return errors.New(c.Error)
+}
@@ -0,0 +1,85 @@
+package codesnap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"a.go":         "package a\n",
+		"b/b.go":       "package b\n",
+		"image.png":    "\x89PNG\x00\x00",
+	})
+	if r := runCodesnap(t, dir, "render", "--stdout"); r.code != exitError || !strings.Contains(r.stdout+r.stderr, "no results to render yet") {
+		t.Fatalf("render before a run: exit code %d, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+	if r := runCodesnap(t, dir, "--stdout"); r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	// render shows the files as they were read, not as they are now
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"all files", nil, 0, []string{"File: a.go", "package a\n", "File: b/b.go", "- Files skipped: 1"}, []string{"package changed"}},
+		{"another format", []string{"--format", "markdown"}, 0, []string{"## a.go\n\n```go\npackage a\n"}, nil},
+		{"only", []string{"--only", "b/**"}, 0, []string{"File: b/b.go"}, []string{"File: a.go"}},
+		{"skip reasons", []string{"--format", "json"}, 0, []string{`"skip_reason": "binary"`}, nil},
+		{"no match", []string{"--only", "c/**"}, exitNothingCollected, []string{"no files of the last run match c/**"}, nil},
+		{"invalid pattern", []string{"--only", "["}, exitError, []string{`invalid --only pattern "["`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := runCodesnap(t, dir, append([]string{"render", "--stdout"}, tc.args...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCachedError(t *testing.T) {
+	for _, tc := range []struct {
+		cached cachedResult
+		is     error
+	}{
+		{cachedResult{Error: errBinaryFile.Error(), SkipReason: "binary"}, errBinaryFile},
+		{cachedResult{Error: errInvalidUTF8.Error(), SkipReason: "invalid_utf8"}, errInvalidUTF8},
+		{cachedResult{Error: errCredentialFile.Error(), SkipReason: "credentials"}, errCredentialFile},
+		{cachedResult{Error: errFileTooLarge.Error() + " (2.0 MB)", SkipReason: "too_large"}, errFileTooLarge},
+		{cachedResult{Error: errExcludedLicense.Error() + " GPL-3.0", SkipReason: "license"}, errExcludedLicense},
+		{cachedResult{Error: "cannot open file: permission denied", SkipReason: "unreadable"}, nil},
+	} {
+		err := cachedError(tc.cached)
+		if err.Error() != tc.cached.Error {
+			t.Errorf("cachedError(%s) = %q, want %q", tc.cached.SkipReason, err, tc.cached.Error)
+		}
+		if tc.is != nil && !errors.Is(err, tc.is) {
+			t.Errorf("cachedError(%s) = %v, not %v", tc.cached.SkipReason, err, tc.is)
+		}
+		if got := skipReason(err); got != tc.cached.SkipReason {
+			t.Errorf("skip reason of cachedError(%s) = %s", tc.cached.SkipReason, got)
+		}
+	}
+}
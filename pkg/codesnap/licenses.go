@@ -0,0 +1,109 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// errExcludedLicense marks files skipped by --exclude-licenses
+var errExcludedLicense = errors.New("file is under an excluded license")
+
+// licenseHeaderSize is how much of the start of a file is searched for a
+// license header
+const licenseHeaderSize = 4096
+
+// spdxIdentifier matches SPDX-License-Identifier comments
+var spdxIdentifier = regexp.MustCompile(`SPDX-License-Identifier:\s*([^\r\n*]+)`)
+
+// spdxID matches a single license identifier of an SPDX expression
+var spdxID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+-]*$`)
+
+// licenseTexts recognize the usual license notices of files without an
+// SPDX identifier, most specific first
+var licenseTexts = []struct {
+	id      string
+	pattern *regexp.Regexp
+}{
+	{"AGPL-3.0", regexp.MustCompile(`(?i)GNU\s+Affero\s+General\s+Public\s+License`)},
+	{"LGPL-2.1", regexp.MustCompile(`(?i)GNU\s+Lesser\s+General\s+Public\s+License[\s\S]{0,200}?version\s+2\.1`)},
+	{"LGPL-3.0", regexp.MustCompile(`(?i)GNU\s+Lesser\s+General\s+Public\s+License`)},
+	{"GPL-2.0", regexp.MustCompile(`(?i)GNU\s+General\s+Public\s+License[\s\S]{0,200}?version\s+2\b`)},
+	{"GPL-3.0", regexp.MustCompile(`(?i)GNU\s+General\s+Public\s+License`)},
+	{"SSPL-1.0", regexp.MustCompile(`(?i)Server\s+Side\s+Public\s+License`)},
+	{"BUSL-1.1", regexp.MustCompile(`(?i)Business\s+Source\s+License`)},
+	{"MPL-2.0", regexp.MustCompile(`(?i)Mozilla\s+Public\s+License,?\s+v(ersion|\.)\s*2\.0`)},
+	{"EPL-2.0", regexp.MustCompile(`(?i)Eclipse\s+Public\s+License\s+v?2\.0`)},
+	{"Apache-2.0", regexp.MustCompile(`(?i)Apache\s+License,?\s+Version\s+2\.0`)},
+	{"MIT", regexp.MustCompile(`(?i)Permission\s+is\s+hereby\s+granted,\s+free\s+of\s+charge`)},
+	{"BSD-3-Clause", regexp.MustCompile(`(?i)Neither\s+the\s+name\s+of\s+[\s\S]{0,100}?\s+nor\s+the\s+names\s+of\s+its\s+contributors`)},
+}
+
+// detectLicenses returns the licenses named in the header of content: the
+// identifiers of an SPDX expression, or the license its notice matches
+func detectLicenses(content string) []string {
+	if len(content) > licenseHeaderSize {
+		content = content[:licenseHeaderSize]
+	}
+	if m := spdxIdentifier.FindStringSubmatch(content); m != nil {
+		var ids []string
+		for _, field := range strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(m[1])) {
+			switch strings.ToUpper(field) {
+			case "OR", "AND", "WITH":
+				continue
+			}
+			if spdxID.MatchString(field) {
+				ids = append(ids, field)
+			}
+		}
+		return ids
+	}
+	for _, text := range licenseTexts {
+		if text.pattern.MatchString(content) {
+			return []string{text.id}
+		}
+	}
+	return nil
+}
+
+// matchLicense returns the first of ids covered by a listed license. An
+// entry covers the identifiers it is a prefix of, case-insensitively, so
+// GPL-3.0 covers GPL-3.0-only and GPL-3.0-or-later but not LGPL-3.0.
+func matchLicense(listed, ids []string) string {
+	for _, id := range ids {
+		for _, entry := range listed {
+			if len(id) >= len(entry) && strings.EqualFold(id[:len(entry)], entry) {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// checkLicense records the license of a read file and returns an error if
+// it is one of cs.excludeLicenses
+func (cs *CodeSnap) checkLicense(result *fileResult) error {
+	if len(cs.excludeLicenses) == 0 && len(cs.config.DenyLicenses) == 0 {
+		return nil
+	}
+	ids := detectLicenses(result.content)
+	result.license = strings.Join(ids, ", ")
+	if id := matchLicense(cs.excludeLicenses, ids); id != "" {
+		return fmt.Errorf("%w: %s", errExcludedLicense, id)
+	}
+	return nil
+}
+
+// splitLicenses splits the comma separated values of --exclude-licenses
+func splitLicenses(values []string) []string {
+	var licenses []string
+	for _, value := range values {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				licenses = append(licenses, id)
+			}
+		}
+	}
+	return licenses
+}
@@ -0,0 +1,97 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLicenses(t *testing.T) {
+	for _, tc := range []struct {
+		name, content string
+		want          []string
+	}{
+		{"SPDX identifier", "// SPDX-License-Identifier: MIT\npackage a\n", []string{"MIT"}},
+		{"SPDX expression", "/* SPDX-License-Identifier: (GPL-2.0-only OR Apache-2.0) AND BSD-3-Clause */\n", []string{"GPL-2.0-only", "Apache-2.0", "BSD-3-Clause"}},
+		{"SPDX exception", "# SPDX-License-Identifier: GPL-2.0-or-later WITH Classpath-exception-2.0\n", []string{"GPL-2.0-or-later", "Classpath-exception-2.0"}},
+		{"SPDX before a notice", "// SPDX-License-Identifier: MIT\n// GNU General Public License\n", []string{"MIT"}},
+		{"GPL version 2", "// under the terms of the GNU General Public License as published by\n// the Free Software Foundation; either version 2 of the License\n", []string{"GPL-2.0"}},
+		{"GPL version 3", "// GNU General Public License version 3\n", []string{"GPL-3.0"}},
+		{"LGPL 2.1", "// GNU Lesser General Public License, version 2.1\n", []string{"LGPL-2.1"}},
+		{"AGPL", "// GNU Affero General Public License\n", []string{"AGPL-3.0"}},
+		{"Apache", "// Licensed under the Apache License, Version 2.0 (the \"License\");\n", []string{"Apache-2.0"}},
+		{"MIT notice", "/* Permission is hereby granted, free of charge, to any person */\n", []string{"MIT"}},
+		{"beyond the header", strings.Repeat("x", licenseHeaderSize) + "// SPDX-License-Identifier: MIT\n", nil},
+		{"no license", "package a\n", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := detectLicenses(tc.content); strings.Join(got, " ") != strings.Join(tc.want, " ") {
+				t.Errorf("detectLicenses = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchLicense(t *testing.T) {
+	for _, tc := range []struct {
+		listed, ids []string
+		want        string
+	}{
+		{[]string{"GPL-3.0"}, []string{"GPL-3.0-or-later"}, "GPL-3.0-or-later"},
+		{[]string{"gpl-3.0"}, []string{"GPL-3.0-only"}, "GPL-3.0-only"},
+		{[]string{"GPL-3.0"}, []string{"LGPL-3.0"}, ""},
+		{[]string{"GPL"}, []string{"MIT", "GPL-2.0"}, "GPL-2.0"},
+		{[]string{"AGPL-3.0"}, []string{"AGPL"}, ""},
+		{nil, []string{"MIT"}, ""},
+	} {
+		if got := matchLicense(tc.listed, tc.ids); got != tc.want {
+			t.Errorf("matchLicense(%q, %q) = %q, want %q", tc.listed, tc.ids, got, tc.want)
+		}
+	}
+}
+
+func TestSplitLicenses(t *testing.T) {
+	got := splitLicenses([]string{"GPL-3.0, AGPL-3.0", "", "SSPL-1.0,"})
+	if want := "GPL-3.0 AGPL-3.0 SSPL-1.0"; strings.Join(got, " ") != want {
+		t.Errorf("splitLicenses = %q, want %q", got, want)
+	}
+}
+
+func TestLicenses(t *testing.T) {
+	for _, tc := range []struct {
+		name, config   string
+		args           []string
+		want, unwanted []string
+	}{
+		{"no checks", "", nil, []string{"File: gpl.go", "File: mit.go", "File: plain.go"}, []string{"Warning"}},
+		{"deny_licenses", "deny_licenses:\n  - GPL-3.0\n", nil,
+			[]string{"File: gpl.go", "Warning: gpl.go is licensed under GPL-3.0-or-later, which is on the deny_licenses list"}, []string{"mit.go is licensed"}},
+		{"--exclude-licenses", "", []string{"--exclude-licenses", "GPL-3.0,MIT"},
+			[]string{"File: plain.go"}, []string{"File: gpl.go", "File: mit.go"}},
+		{"json", "deny_licenses:\n  - BUSL\n", []string{"--format", "json"},
+			[]string{`"license": "GPL-3.0-or-later"`, `"license": "MIT"`}, []string{"Warning"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.config,
+				"gpl.go":       "// SPDX-License-Identifier: GPL-3.0-or-later\npackage a\n",
+				"mit.go":       "// Permission is hereby granted, free of charge, to any person\npackage a\n",
+				"plain.go":     "package a\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
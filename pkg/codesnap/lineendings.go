@@ -0,0 +1,98 @@
+package codesnap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// lineEndingsOf describes the line endings of content: "lf", "crlf", "cr",
+// "mixed", or "" when it has no line breaks
+func lineEndingsOf(content string) string {
+	crlf := strings.Count(content, "\r\n")
+	cr := strings.Count(content, "\r") - crlf
+	lf := strings.Count(content, "\n") - crlf
+	kinds := 0
+	style := ""
+	for _, k := range []struct {
+		name  string
+		count int
+	}{{"lf", lf}, {"crlf", crlf}, {"cr", cr}} {
+		if k.count > 0 {
+			kinds++
+			style = k.name
+		}
+	}
+	if kinds > 1 {
+		return "mixed"
+	}
+	return style
+}
+
+// normalizeText converts all line endings to LF and drops a UTF-8 byte
+// order mark
+func normalizeText(content string) string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.ReplaceAll(content, "\r", "\n")
+}
+
+// inconsistency is a file whose line endings or encoding differ from the
+// majority of the snapshot
+type inconsistency struct {
+	Path  string `json:"path"`
+	Issue string `json:"issue"`
+}
+
+// findInconsistencies compares each included file's line endings and
+// encoding with the most common ones
+func findInconsistencies(results []fileResult) []inconsistency {
+	endings := make(map[string]int)
+	encodings := make(map[string]int)
+	for _, r := range results {
+		if r.err != nil || r.empty {
+			continue
+		}
+		if r.lineEndings != "" && r.lineEndings != "mixed" {
+			endings[r.lineEndings]++
+		}
+		encodings[r.encoding]++
+	}
+	majorityEnding := majority(endings)
+	majorityEncoding := majority(encodings)
+
+	var found []inconsistency
+	for _, r := range results {
+		if r.err != nil || r.empty {
+			continue
+		}
+		if r.lineEndings == "mixed" {
+			found = append(found, inconsistency{r.relPath, "mixed line endings"})
+		} else if r.lineEndings != "" && r.lineEndings != majorityEnding {
+			found = append(found, inconsistency{r.relPath, fmt.Sprintf("%s line endings (most files use %s)",
+				strings.ToUpper(r.lineEndings), strings.ToUpper(majorityEnding))})
+		}
+		if r.encoding != majorityEncoding {
+			found = append(found, inconsistency{r.relPath, fmt.Sprintf("%s encoding (most files use %s)",
+				strings.ToUpper(r.encoding), strings.ToUpper(majorityEncoding))})
+		}
+	}
+	return found
+}
+
+// majority returns the most frequent key, preferring the first in sorted
+// order on ties so the result is stable
+func majority(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	best := ""
+	for _, k := range keys {
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+	return best
+}
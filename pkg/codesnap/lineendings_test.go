@@ -0,0 +1,82 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineEndingsOf(t *testing.T) {
+	for _, tc := range []struct {
+		content, want string
+	}{
+		{"", ""},
+		{"one line", ""},
+		{"a\nb\n", "lf"},
+		{"a\r\nb\r\n", "crlf"},
+		{"a\rb\r", "cr"},
+		{"a\r\nb\n", "mixed"},
+		{"a\rb\n", "mixed"},
+	} {
+		if got := lineEndingsOf(tc.content); got != tc.want {
+			t.Errorf("lineEndingsOf(%q) = %q, want %q", tc.content, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	for _, tc := range []struct {
+		content, want string
+	}{
+		{"a\nb\n", "a\nb\n"},
+		{"a\r\nb\r\n", "a\nb\n"},
+		{"a\rb\r\n\n", "a\nb\n\n"},
+		{"\ufeffa\r\n", "a\n"},
+		{"a\ufeff", "a\ufeff"},
+	} {
+		if got := normalizeText(tc.content); got != tc.want {
+			t.Errorf("normalizeText(%q) = %q, want %q", tc.content, got, tc.want)
+		}
+	}
+}
+
+func TestLineEndingOutliers(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package a\n\nvar a = 1\n",
+		"b.go": "package a\n\nvar b = 1\n",
+		"c.go": "package a\r\n\r\nvar c = 1\r\n",
+		"d.go": "package a\r\n\nvar d = 1\n",
+		"e.go": "\ufeffpackage a\n",
+	}
+	for _, tc := range []struct {
+		name, options  string
+		want, unwanted []string
+	}{
+		{"reported", "", []string{
+			"- Line ending/encoding outliers: 3\n",
+			"    c.go: CRLF line endings (most files use LF)\n",
+			"    d.go: mixed line endings\n",
+			"    e.go: UTF-8-BOM encoding (most files use UTF-8)\n",
+			"package a\r\n",
+		}, nil},
+		{"normalized", "normalize_line_endings: true\n", []string{"- Line ending/encoding outliers: 3\n"}, []string{"\r", "\ufeff"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files["codesnap.yml"] = "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options
+			dir := writeFiles(t, files)
+			r := runCodesnap(t, dir, "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot has %q, got:\n%q", s, r.stdout)
+				}
+			}
+		})
+	}
+}
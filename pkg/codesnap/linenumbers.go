@@ -0,0 +1,33 @@
+package codesnap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// numberLines prefixes every line of content with its number, right-aligned
+// to the width of the largest one and followed by " | "
+func numberLines(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	width := len(strconv.Itoa(len(lines)))
+	var b strings.Builder
+	b.Grow(len(content) + len(lines)*(width+3))
+	for i, line := range lines {
+		fmt.Fprintf(&b, "%*d | %s", width, i+1, line)
+	}
+	return b.String()
+}
+
+// shownContent is the content of r as it appears in the snapshot. With
+// --line-numbers every line is numbered, except in condensed files and
+// diff hunks whose lines do not map to the file's.
+func (cs *CodeSnap) shownContent(r fileResult) string {
+	if !cs.lineNumbers || r.condensed || r.diff {
+		return r.content
+	}
+	return numberLines(r.content)
+}
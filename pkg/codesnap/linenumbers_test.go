@@ -0,0 +1,57 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNumberLines(t *testing.T) {
+	for _, tc := range []struct {
+		content, want string
+	}{
+		{"", ""},
+		{"one\n", "1 | one\n"},
+		{"one\ntwo", "1 | one\n2 | two"},
+		{"a\n\nb\n", "1 | a\n2 | \n3 | b\n"},
+		{strings.Repeat("x\n", 10), " 1 | x\n 2 | x\n 3 | x\n 4 | x\n 5 | x\n 6 | x\n 7 | x\n 8 | x\n 9 | x\n10 | x\n"},
+	} {
+		if got := numberLines(tc.content); got != tc.want {
+			t.Errorf("numberLines(%q) = %q, want %q", tc.content, got, tc.want)
+		}
+	}
+}
+
+func TestLineNumbersFlag(t *testing.T) {
+	for _, tc := range []struct {
+		name, config   string
+		args           []string
+		want, unwanted []string
+	}{
+		{"text", "", nil, []string{"1 | package a\n2 | \n3 | func A() {}\n"}, nil},
+		{"markdown", "", []string{"--format", "markdown"}, []string{"```go\n1 | package a\n"}, nil},
+		{"json", "", []string{"--format", "json"}, []string{`"content": "1 | package a\n2 | \n3 | func A() {}\n"`}, nil},
+		{"condensed files", "condense:\n  - \"*.proto\"\n", nil, []string{"File: shop.proto (condensed)\n", "1 | package a\n"}, []string{"1 | syntax", "| message"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.config,
+				"a.go":         "package a\n\nfunc A() {}\n",
+				"shop.proto":   "syntax = \"proto3\";\n\nmessage User {\n  string name = 1;\n}\n",
+			})
+			r := runCodesnap(t, dir, append([]string{"--line-numbers", "--stdout", "-q"}, tc.args...)...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
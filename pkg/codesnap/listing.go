@@ -0,0 +1,133 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+)
+
+// listedFile is a file found by --list, with the reason it is left out
+// (empty for included files)
+type listedFile struct {
+	path   string
+	size   int64
+	reason string
+}
+
+// listFiles walks the configured folders and files like gatherFiles, but
+// keeps the files it rejects as well, with the rule that rejected them.
+// Dependency directories that are skipped are listed as one entry.
+func (cs *CodeSnap) listFiles() ([]listedFile, error) {
+	var listed []listedFile
+	add := func(path, reason string) {
+		f := listedFile{path: path, reason: reason}
+		if info, err := os.Stat(path); err == nil {
+			f.size = info.Size()
+		}
+		listed = append(listed, f)
+	}
+
+	for _, folder := range cs.config.Folders {
+		folderPath := cs.folderPath(folder)
+		if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+			listed = append(listed, listedFile{path: folderPath, reason: T("folder not found")})
+			continue
+		}
+		skipDir := func(dir string) bool {
+			if cs.hiddenExcluded(dir, true) {
+				listed = append(listed, listedFile{path: dir, reason: T("hidden directory")})
+				return true
+			}
+			if cs.skipDependencyDir(dir) {
+				listed = append(listed, listedFile{path: dir, reason: T("dependency directory")})
+				return true
+			}
+			return false
+		}
+		err := cs.walkFolder(folderPath, skipDir, func(full string) {
+			reason := cs.exclusionOf(full)
+			if reason == "" && cs.hiddenExcluded(full, false) {
+				reason = T("hidden file")
+			}
+			if reason == "" && !cs.matchesInclude(full) {
+				reason = T("not matched by include")
+			}
+			add(full, reason)
+		})
+		if err != nil {
+			return nil, fmt.Errorf(T("failed to read folder %s: %v"), folderPath, err)
+		}
+	}
+	// A listed file is not subject to include, also where a folder has it
+	found := make(map[string]int)
+	for i, f := range listed {
+		found[f.path] = i
+	}
+	for _, file := range cs.config.Files {
+		filePath := cs.resolvePath(file)
+		if _, err := os.Stat(filePath); err != nil {
+			listed = append(listed, listedFile{path: filePath, reason: T("file not found")})
+			continue
+		}
+		if i, ok := found[filePath]; ok {
+			listed[i].reason = cs.exclusionOf(filePath)
+			continue
+		}
+		add(filePath, cs.exclusionOf(filePath))
+	}
+
+	if cs.changedSince != "" || cs.diffHunks != "" || cs.staged {
+		var candidates []string
+		for _, f := range listed {
+			if f.reason == "" {
+				candidates = append(candidates, f.path)
+			}
+		}
+		kept, err := cs.filterChanged(candidates)
+		if err != nil {
+			return nil, err
+		}
+		keep := make(map[string]bool)
+		for _, path := range kept {
+			keep[path] = true
+		}
+		for i, f := range listed {
+			if f.reason == "" && !keep[f.path] {
+				listed[i].reason = T("not changed")
+			}
+		}
+	}
+	return listed, nil
+}
+
+// showFileList prints the files a snapshot would include, with their
+// sizes, and then the rejected ones with the reason for each, without
+// reading any file. Files the snapshot skips for their content, such as
+// binaries, are only known once they are read and show as included.
+func (cs *CodeSnap) showFileList() error {
+	listed, err := cs.listFiles()
+	if err != nil {
+		return err
+	}
+	var included, excluded []listedFile
+	var total int64
+	for _, f := range listed {
+		if f.reason == "" {
+			included = append(included, f)
+			total += f.size
+		} else {
+			excluded = append(excluded, f)
+		}
+	}
+
+	fmt.Printf(T("Included (%d files, %s):\n"), len(included), formatSize(total))
+	for _, f := range included {
+		fmt.Printf("  %10s  %s\n", formatSize(f.size), cs.displayPath(f.path))
+	}
+	if len(excluded) > 0 {
+		fmt.Printf("\n"+T("Excluded (%d):\n"), len(excluded))
+		for _, f := range excluded {
+			fmt.Printf("  %s  (%s)\n", cs.displayPath(f.path), f.reason)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	files := map[string]string{
+		"codesnap.yml":        "folders:\n  - .\n  - missing\nfiles:\n  - extra/x.md\n  - gone.md\nignore:\n  - codesnap.yml\n  - \"**/*.log\"\ninclude:\n  - \"**/*.go\"\ninclude_hidden: false\ndependency_max_entries: 1\n",
+		"src/a.go":            "package a\n",
+		"app.log":             "started\n",
+		"README.md":           "# project\n",
+		".env":                "KEY=1\n",
+		".hidden/h.go":        "package h\n",
+		"node_modules/m/i.js": "module.exports = 1\n",
+		"node_modules/n/i.js": "module.exports = 2\n",
+		"extra/x.md":          "# extra\n",
+	}
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		code           int
+		want, unwanted []string
+	}{
+		{"list", []string{"--list"}, 0, []string{
+			"Included (2 files, ",
+			"  src/a.go\n",
+			"  extra/x.md\n",
+			"\nExcluded (8):\n",
+			"  app.log  (ignore: **/*.log)\n",
+			"  codesnap.yml  (ignore: codesnap.yml)\n",
+			"  README.md  (not matched by include)\n",
+			"  .env  (hidden file)\n",
+			"  .hidden  (hidden directory)\n",
+			"  node_modules  (dependency directory)\n",
+			"  missing  (folder not found)\n",
+			"  gone.md  (file not found)\n",
+		}, []string{"File: ", "h.go", "i.js", "extra/x.md  ("}},
+		{"dry run", []string{"--dry-run"}, 0, []string{"Included (2 files, ", "  src/a.go\n"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, files)
+			r := runCodesnap(t, dir, tc.args...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("output has %q, got:\n%s", s, r.stdout)
+				}
+			}
+			if r.clipboard != "" {
+				t.Errorf("--list copied to the clipboard:\n%s", r.clipboard)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package codesnap
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isLockedError reports whether err signals a transiently busy file
+func isLockedError(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.ETXTBSY)
+}
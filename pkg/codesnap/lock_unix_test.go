@@ -0,0 +1,48 @@
+//go:build !windows
+
+package codesnap
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsLockedError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{syscall.EBUSY, true},
+		{&fs.PathError{Op: "open", Path: "a.go", Err: syscall.ETXTBSY}, true},
+		{fmt.Errorf("reading: %w", syscall.EBUSY), true},
+		{&fs.PathError{Op: "open", Path: "a.go", Err: syscall.ENOENT}, false},
+		{syscall.EACCES, false},
+	} {
+		if got := isLockedError(tc.err); got != tc.want {
+			t.Errorf("isLockedError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestOpenWithRetry(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.go": "package a\n"})
+	file, err := openWithRetry(filepath.Join(dir, "a.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// Only locked files are retried, a missing one fails at once
+	start := time.Now()
+	if _, err := openWithRetry(filepath.Join(dir, "missing.go")); !os.IsNotExist(err) {
+		t.Errorf("opening a missing file = %v, want not exist", err)
+	}
+	if elapsed := time.Since(start); elapsed >= openRetryBackoff {
+		t.Errorf("a missing file took %v, it was retried", elapsed)
+	}
+}
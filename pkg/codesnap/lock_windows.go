@@ -0,0 +1,19 @@
+//go:build windows
+
+package codesnap
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// isLockedError reports whether err signals a file held open by another
+// process, typically an editor or antivirus scanner
+func isLockedError(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation)
+}
@@ -0,0 +1,93 @@
+package codesnap
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// mainEnv makes the test binary run Main instead of the tests, so the CLI
+// can be tested as a separate process with its own flags and exit code
+const mainEnv = "CODESNAP_TEST_MAIN"
+
+// clipboardEnv is the file the fake clipboard of runCodesnap writes to
+const clipboardEnv = "CODESNAP_TEST_CLIPBOARD"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(mainEnv) == "1" {
+		Main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runResult is the outcome of one codesnap process
+type runResult struct {
+	stdout, stderr string
+	// clipboard is what the run copied to the clipboard
+	clipboard string
+	code      int
+}
+
+// runCodesnap runs codesnap with args in dir. The clipboard is a fake
+// xclip, first in PATH, that keeps what is copied for the result.
+func runCodesnap(t *testing.T, dir string, args ...string) runResult {
+	t.Helper()
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("the fake clipboard is an xclip shell script")
+	}
+	bin := t.TempDir()
+	clipboard := filepath.Join(bin, "clipboard")
+	xclip := "#!/bin/sh\ncat > \"$" + clipboardEnv + "\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "xclip"), []byte(xclip), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), mainEnv+"=1", clipboardEnv+"="+clipboard, "WAYLAND_DISPLAY=",
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatalf("running codesnap: %v", err)
+	}
+	copied, _ := os.ReadFile(clipboard)
+	return runResult{stdout.String(), stderr.String(), string(copied), cmd.ProcessState.ExitCode()}
+}
+
+// writeFiles creates a directory with the given files, by relative path
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// fakeCommand puts a shell script called name first in PATH for the rest
+// of the test, standing in for a tool codesnap runs
+func fakeCommand(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake commands are shell scripts")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
@@ -0,0 +1,147 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// metricsFile is where opt-in usage metrics are kept, relative to the config
+// directory. Nothing is ever sent anywhere; the file is only read by
+// codesnap metrics.
+const metricsFile = stateDir + "/metrics.json"
+
+// usageMetrics accumulates statistics over all recorded runs
+type usageMetrics struct {
+	Runs       int            `json:"runs"`
+	Files      int            `json:"files"`
+	Bytes      int64          `json:"bytes"`
+	Tokens     int64          `json:"tokens"`
+	DurationMs int64          `json:"duration_ms"`
+	Commands   map[string]int `json:"commands"`
+	Configs    map[string]int `json:"configs"`
+	Pipelines  map[string]int `json:"pipelines,omitempty"`
+	FirstRun   time.Time      `json:"first_run"`
+	LastRun    time.Time      `json:"last_run"`
+}
+
+func loadMetrics(path string) (*usageMetrics, error) {
+	m := &usageMetrics{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf(T("invalid metrics file %s: %v"), path, err)
+	}
+	return m, nil
+}
+
+// recordMetrics adds the finished run to the metrics file when the config
+// opts in with metrics: true
+func (cs *CodeSnap) recordMetrics(command, pipeline string, size, tokens int, elapsed time.Duration) error {
+	if !cs.config.Metrics {
+		return nil
+	}
+
+	path := filepath.Join(cs.configDir, filepath.FromSlash(metricsFile))
+	m, err := loadMetrics(path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if m.Runs == 0 {
+		m.FirstRun = now
+	}
+	m.Runs++
+	m.LastRun = now
+	m.Files += cs.stats.processed
+	m.Bytes += int64(size)
+	m.Tokens += int64(tokens)
+	m.DurationMs += elapsed.Milliseconds()
+
+	if command == "" {
+		command = "snap"
+	}
+	m.Commands = increment(m.Commands, command)
+	m.Configs = increment(m.Configs, filepath.ToSlash(cs.relPath(cs.configPath)))
+	if pipeline != "" {
+		m.Pipelines = increment(m.Pipelines, pipeline)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(T("failed to write metrics: %v"), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf(T("failed to write metrics: %v"), err)
+	}
+	return nil
+}
+
+func increment(counts map[string]int, key string) map[string]int {
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	counts[key]++
+	return counts
+}
+
+// showMetrics prints the metrics stored next to the given config file
+func showMetrics(configPath string) error {
+	if configPath == "" {
+		configPath = "codesnap.yml"
+	}
+	path := filepath.Join(filepath.Dir(configPath), filepath.FromSlash(metricsFile))
+	m, err := loadMetrics(path)
+	if err != nil {
+		return err
+	}
+	if m.Runs == 0 {
+		fmt.Printf(T("No metrics recorded in %s (enable them with metrics: true in the config)\n"), path)
+		return nil
+	}
+
+	fmt.Printf(T("Usage metrics (%s)\n"), path)
+	fmt.Printf(T("- Runs: %d (%s to %s)\n"), m.Runs,
+		m.FirstRun.Format("2006-01-02"), m.LastRun.Format("2006-01-02"))
+	fmt.Printf(T("- Average files per run: %d\n"), m.Files/m.Runs)
+	fmt.Printf(T("- Average size per run: %d bytes (~%d tokens)\n"), m.Bytes/int64(m.Runs), m.Tokens/int64(m.Runs))
+	fmt.Printf(T("- Average duration: %v\n"), time.Duration(m.DurationMs/int64(m.Runs))*time.Millisecond)
+	printCounts(T("Commands:"), m.Commands)
+	printCounts(T("Configs:"), m.Configs)
+	printCounts(T("Pipelines:"), m.Pipelines)
+	return nil
+}
+
+// printCounts prints a heading and the counts sorted from most used
+func printCounts(heading string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Println(heading)
+	for _, key := range keys {
+		fmt.Printf("  %-30s %d\n", key, counts[key])
+	}
+}
@@ -0,0 +1,46 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	for _, tc := range []struct {
+		name, options string
+		want          []string
+	}{
+		{"off", "", []string{"No metrics recorded"}},
+		{"on", "metrics: true\n", []string{"- Runs: 3 (", "- Average files per run: 1\n",
+			fmt.Sprintf("Commands:\n  %-30s 2\n  %-30s 1\n", "snap", "tree"), fmt.Sprintf("Configs:\n  %-30s 3\n", "codesnap.yml")}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"a.go":         "package a\n",
+			})
+			for _, args := range [][]string{{"--stdout"}, {"--stdout"}, {"tree", "--stdout"}} {
+				if r := runCodesnap(t, dir, args...); r.code != 0 {
+					t.Fatalf("codesnap %v: exit code %d, stderr: %s", args, r.code, r.stderr)
+				}
+			}
+			_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(metricsFile)))
+			if recorded := err == nil; recorded != (tc.options != "") {
+				t.Errorf("metrics file written: %v", recorded)
+			}
+
+			r := runCodesnap(t, dir, "metrics")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(r.stdout, want) {
+					t.Errorf("metrics lack %q, got:\n%s", want, r.stdout)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+//go:build !windows
+
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// minMappedSize is the smallest region mapped for an output file
+const minMappedSize = 1 << 16
+
+// mappedFile renders a snapshot into a file through a shared memory map,
+// so a large snapshot is neither held in memory nor written in small
+// chunks. The file is sized up front and grown when the estimate was too
+// small, then truncated to the written length on Close.
+type mappedFile struct {
+	outputCounter
+	file *os.File
+	data []byte
+	n    int
+}
+
+func createMappedFile(path string) (*mappedFile, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return &mappedFile{file: file}, nil
+}
+
+// reserve makes room for at least size bytes in total
+func (m *mappedFile) reserve(size int64) error {
+	if size <= int64(len(m.data)) {
+		return nil
+	}
+	if size < minMappedSize {
+		size = minMappedSize
+	}
+	if m.data != nil {
+		if err := syscall.Munmap(m.data); err != nil {
+			return fmt.Errorf(T("failed to save content to file: %v"), err)
+		}
+		m.data = nil
+	}
+	if err := m.file.Truncate(size); err != nil {
+		return fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	data, err := syscall.Mmap(int(m.file.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	m.data = data
+	return nil
+}
+
+func (m *mappedFile) Write(p []byte) (int, error) {
+	if need := int64(m.n + len(p)); need > int64(len(m.data)) {
+		// Grow geometrically so a bad estimate costs few remaps
+		if err := m.reserve(max(need, 2*int64(len(m.data)))); err != nil {
+			return 0, err
+		}
+	}
+	m.n += copy(m.data[m.n:], p)
+	m.count(p)
+	return len(p), nil
+}
+
+// Close unmaps the file and cuts it to the written length
+func (m *mappedFile) Close() error {
+	var err error
+	if m.data != nil {
+		err = syscall.Munmap(m.data)
+		m.data = nil
+	}
+	if terr := m.file.Truncate(int64(m.n)); err == nil {
+		err = terr
+	}
+	if cerr := m.file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return nil
+}
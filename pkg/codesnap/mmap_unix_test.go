@@ -0,0 +1,64 @@
+//go:build !windows
+
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMappedFile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		reserve int64
+		writes  []string
+	}{
+		{"nothing written", 0, nil},
+		{"within the reservation", 100, []string{"package a\n", "var a = 1\n"}},
+		{"grows past a small estimate", 10, []string{strings.Repeat("x", minMappedSize), strings.Repeat("y", minMappedSize+1)}},
+		{"without a reservation", 0, []string{strings.Repeat("z", 3*minMappedSize)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapshot.txt")
+			m, err := createMappedFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.reserve > 0 {
+				if err := m.reserve(tc.reserve); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := strings.Join(tc.writes, "")
+			for _, w := range tc.writes {
+				if n, err := m.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write = %d, %v; want %d", n, err, len(w))
+				}
+			}
+			if n, tokens := m.written(); n != len(want) || tokens != sumTokens(tc.writes) {
+				t.Errorf("written = %d bytes, %d tokens; want %d, %d", n, tokens, len(want), sumTokens(tc.writes))
+			}
+			if err := m.Close(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("the file has %d bytes, want %d written", len(got), len(want))
+			}
+		})
+	}
+}
+
+// sumTokens is the token estimate of writes counted one write at a time
+func sumTokens(writes []string) int {
+	n := 0
+	for _, w := range writes {
+		n += estimateTokens(w)
+	}
+	return n
+}
@@ -0,0 +1,54 @@
+//go:build windows
+
+package codesnap
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// mappedFile writes a snapshot through a buffered file on Windows, where
+// resizing a mapped file requires closing all views of it first
+type mappedFile struct {
+	outputCounter
+	file *os.File
+	w    *bufio.Writer
+}
+
+func createMappedFile(path string) (*mappedFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return &mappedFile{file: file, w: bufio.NewWriterSize(file, 1<<20)}, nil
+}
+
+// reserve preallocates the file for size bytes
+func (m *mappedFile) reserve(size int64) error {
+	if err := m.file.Truncate(size); err != nil {
+		return fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return nil
+}
+
+func (m *mappedFile) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	m.count(p[:n])
+	return n, err
+}
+
+// Close flushes the file and cuts it to the written length
+func (m *mappedFile) Close() error {
+	err := m.w.Flush()
+	if terr := m.file.Truncate(int64(m.bytes)); err == nil {
+		err = terr
+	}
+	if cerr := m.file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return nil
+}
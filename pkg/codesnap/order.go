@@ -0,0 +1,68 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// orderKeys are the values of order, each optionally prefixed with - to
+// reverse it
+var orderKeys = []string{"config-order", "path", "size", "mtime", "tokens"}
+
+// validOrder checks an order value
+func validOrder(order string) error {
+	key := strings.TrimPrefix(order, "-")
+	for _, k := range orderKeys {
+		if key == k {
+			return nil
+		}
+	}
+	return fmt.Errorf(T("invalid order value %q (expected %s, optionally prefixed with -)"), order, strings.Join(orderKeys, ", "))
+}
+
+// orderResults sorts the files of the snapshot by the configured order:
+// config-order (default) keeps the order of the folders and files in the
+// config, path sorts by the path shown, size by the size on disk, mtime
+// by the modification time (oldest first) and tokens by the estimated
+// tokens of the content as read. Ties keep the path order. A leading -
+// reverses the order.
+func (cs *CodeSnap) orderResults(results []fileResult) {
+	order := cs.config.Order
+	key := strings.TrimPrefix(order, "-")
+	if key == "" || key == "config-order" {
+		if order == "-config-order" {
+			for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+				results[i], results[j] = results[j], results[i]
+			}
+		}
+		return
+	}
+
+	values := make(map[string]int64, len(results))
+	for _, r := range results {
+		switch key {
+		case "size":
+			values[r.path] = r.size
+		case "mtime":
+			if info, err := os.Stat(r.path); err == nil {
+				values[r.path] = info.ModTime().UnixNano()
+			}
+		case "tokens":
+			values[r.path] = int64(estimateTokensFor(r.content, languageOf(r.path, r.content)))
+		}
+	}
+	less := func(a, b fileResult) bool {
+		if values[a.path] != values[b.path] {
+			return values[a.path] < values[b.path]
+		}
+		return a.relPath < b.relPath
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if strings.HasPrefix(order, "-") {
+			return less(results[j], results[i])
+		}
+		return less(results[i], results[j])
+	})
+}
@@ -0,0 +1,67 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name, options string
+		args          []string
+		code          int
+		want          string // the files in snapshot order, or the error
+	}{
+		{"config order", "", nil, 0, "z/small.go a/big.go a/mid.go top.md"},
+		{"reversed config order", "order: -config-order\n", nil, 0, "top.md a/mid.go a/big.go z/small.go"},
+		{"path", "order: path\n", nil, 0, "a/big.go a/mid.go top.md z/small.go"},
+		{"size", "order: size\n", nil, 0, "z/small.go top.md a/mid.go a/big.go"},
+		{"largest first", "order: -size\n", nil, 0, "a/big.go a/mid.go top.md z/small.go"},
+		{"mtime", "order: mtime\n", nil, 0, "a/big.go a/mid.go top.md z/small.go"},
+		{"newest first", "order: -mtime\n", nil, 0, "z/small.go top.md a/mid.go a/big.go"},
+		{"tokens", "order: tokens\n", nil, 0, "z/small.go top.md a/mid.go a/big.go"},
+		{"--order overrides order", "order: size\n", []string{"--order", "path"}, 0, "a/big.go a/mid.go top.md z/small.go"},
+		{"invalid order", "order: name\n", nil, exitError,
+			`invalid order value "name" (expected config-order, path, size, mtime, tokens, optionally prefixed with -)`},
+		{"invalid --order", "", []string{"--order", "--size"}, exitError, `invalid order value "--size"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - z\n  - a\nfiles:\n  - top.md\n" + tc.options,
+				"z/small.go":   "package z\n",
+				"a/big.go":     "package a\n\n" + strings.Repeat("var big = 1\n", 100),
+				"a/mid.go":     "package a\n\n" + strings.Repeat("var mid = 1\n", 10),
+				"top.md":       "# Top\n\n" + strings.Repeat("word ", 10) + "\n",
+			})
+			now := time.Now()
+			for i, name := range []string{"a/big.go", "a/mid.go", "top.md", "z/small.go"} {
+				at := now.Add(time.Duration(i-4) * time.Hour)
+				if err := os.Chtimes(filepath.Join(dir, name), at, at); err != nil {
+					t.Fatal(err)
+				}
+			}
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if tc.code != 0 {
+				if !strings.Contains(r.stderr, tc.want) {
+					t.Errorf("stderr lacks %q, got:\n%s", tc.want, r.stderr)
+				}
+				return
+			}
+			var files []string
+			for _, line := range strings.Split(r.stdout, "\n") {
+				if name, ok := strings.CutPrefix(line, "File: "); ok {
+					files = append(files, name)
+				}
+			}
+			if got := strings.Join(files, " "); got != tc.want {
+				t.Errorf("files in order %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// fileOwner returns the user name and numeric uid owning the file
+func fileOwner(info os.FileInfo) (string, string, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", fmt.Errorf("file owner not available")
+	}
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return "", uid, nil
+	}
+	return u.Username, uid, nil
+}
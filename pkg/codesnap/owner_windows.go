@@ -0,0 +1,14 @@
+//go:build windows
+
+package codesnap
+
+import (
+	"fmt"
+	"os"
+)
+
+// fileOwner is not supported on Windows, where ownership is expressed
+// through security descriptors rather than a single uid
+func fileOwner(info os.FileInfo) (string, string, error) {
+	return "", "", fmt.Errorf("owner rules are not supported on Windows")
+}
@@ -0,0 +1,72 @@
+package codesnap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// pickFiles pipes the candidate files through fzf and returns the ones the
+// user selected. Paths are shown relative to the config directory.
+func (cs *CodeSnap) pickFiles(candidates []string) ([]string, error) {
+	fzf, err := exec.LookPath("fzf")
+	if err != nil {
+		return nil, errors.New(T("fzf not found in PATH (install it from https://github.com/junegunn/fzf)"))
+	}
+	if len(candidates) == 0 {
+		return nil, nothingCollected{errors.New(T("no files to pick from"))}
+	}
+
+	// Map the displayed names back to the full paths
+	byName := make(map[string]string, len(candidates))
+	var input bytes.Buffer
+	for _, path := range candidates {
+		name := cs.displayPath(path)
+		byName[name] = path
+		input.WriteString(name + "\n")
+	}
+
+	preview := "cat {}"
+	if runtime.GOOS == "windows" {
+		preview = "type {}"
+	}
+
+	cmd := exec.Command(fzf, "--multi", "--preview", preview,
+		"--header", "TAB to select, ENTER to snapshot the selection")
+	cmd.Dir = cs.configDir
+	cmd.Stdin = &input
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// fzf exits with 1 when nothing matched and 130 when cancelled
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return nil, errors.New(T("no files selected"))
+		}
+		return nil, fmt.Errorf(T("fzf failed: %v"), err)
+	}
+
+	var selected []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if path, ok := byName[line]; ok {
+			selected = append(selected, path)
+		} else {
+			selected = append(selected, filepath.Join(cs.configDir, line))
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New(T("no files selected"))
+	}
+	return selected, nil
+}
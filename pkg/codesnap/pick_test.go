@@ -0,0 +1,44 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPick(t *testing.T) {
+	for _, tc := range []struct {
+		name, fzf      string
+		code           int
+		want, unwanted []string
+	}{
+		{"selection", `grep -e b.go -e c.go`, 0,
+			[]string{"File: b.go", "File: sub/c.go"}, []string{"File: a.go"}},
+		{"cancelled", "exit 130", exitError, []string{"no files selected"}, nil},
+		{"fzf fails", "exit 2", exitError, []string{"fzf failed"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeCommand(t, "fzf", tc.fzf+"\n")
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+				"b.go":         "package b\n",
+				"sub/c.go":     "package c\n",
+			})
+			r := runCodesnap(t, dir, "pick", "--stdout")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPipelineStdout(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: .\npipelines:\n  review:\n    stdout: true\n    format: markdown\n",
+		"a.go":         "package a\n",
+	})
+
+	r := runCodesnap(t, dir, "run", "review")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	if !strings.Contains(r.stdout, "## a.go") {
+		t.Errorf("snapshot not written to stdout, got:\n%s", r.stdout)
+	}
+}
+
+func TestPipelineUnknown(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"codesnap.yml": "pipelines:\n  review:\n    stdout: true\n"})
+
+	r := runCodesnap(t, dir, "run", "nope")
+	if r.code != 1 || !strings.Contains(r.stdout+r.stderr, `unknown pipeline "nope" (available: review)`) {
+		t.Errorf("exit code %d, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+}
+
+func TestPipelineQuietStdout(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: .\npipelines:\n  pipe:\n    quiet: true\n    stdout: true\n",
+		"a.go":         "package a\n",
+	})
+
+	r := runCodesnap(t, dir, "run", "pipe")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	if !strings.Contains(r.stdout, "File: a.go") {
+		t.Errorf("snapshot not written to stdout, got:\n%s", r.stdout)
+	}
+	if out := r.stdout + r.stderr; strings.Contains(out, "Processing folder") {
+		t.Errorf("quiet from the pipeline not honored, got:\n%s", out)
+	}
+}
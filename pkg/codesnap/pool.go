@@ -0,0 +1,148 @@
+package codesnap
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Without a configured worker count, the read pool starts with one worker
+// per CPU and adapts to the storage: when files take long to read, as on
+// network mounts, it grows while that raises the throughput; when they are
+// read fast, as from local SSDs, the work is CPU-bound and it stays at one
+// worker per CPU.
+const (
+	maxReadWorkers = 64
+	// Mean per-file latencies above highReadLatency are waits on storage,
+	// those below lowReadLatency are mostly validation and hashing
+	highReadLatency = 2 * time.Millisecond
+	lowReadLatency  = 200 * time.Microsecond
+	// minAdaptWindow is the least number of files measured per decision
+	minAdaptWindow = 16
+)
+
+// defaultReadWorkers is the initial size of the adaptive pool
+func defaultReadWorkers() int {
+	return max(runtime.NumCPU(), 1)
+}
+
+// parallelFor calls fn for every index in [0, n) and waits for all calls to
+// return. With workers > 0 it uses that many goroutines; otherwise their
+// number adapts to the latency of fn, see readPool. Goroutines are only
+// started once there is work for them, so a handful of files never spins up
+// a full pool.
+func parallelFor(n, workers int, fn func(i int)) {
+	pool := newReadPool(n, workers, fn)
+	pool.mu.Lock()
+	pool.spawn()
+	pool.mu.Unlock()
+	pool.wg.Wait()
+}
+
+// readPool hands out indexes to at most limit workers at a time and
+// adjusts limit from the latency of the finished calls
+type readPool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	wg   sync.WaitGroup
+	fn   func(i int)
+
+	n, next, running, started, limit, maxLimit int
+
+	// The current measurement window
+	windowStart   time.Time
+	windowDone    int
+	windowLatency time.Duration
+
+	// The limit before the last increase and its throughput, to undo an
+	// increase that did not pay off
+	prevLimit      int
+	prevThroughput float64
+	settled        bool
+}
+
+func newReadPool(n, workers int, fn func(i int)) *readPool {
+	p := &readPool{n: n, fn: fn, limit: defaultReadWorkers(), maxLimit: maxReadWorkers, windowStart: time.Now()}
+	if workers > 0 {
+		p.limit, p.maxLimit, p.settled = workers, workers, true
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// spawn starts goroutines up to the limit, but no more than there are
+// indexes left. p.mu must be held.
+func (p *readPool) spawn() {
+	for ; p.started < min(p.limit, p.running+p.n-p.next); p.started++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+}
+
+func (p *readPool) work() {
+	defer p.wg.Done()
+	for {
+		i, ok := p.acquire()
+		if !ok {
+			return
+		}
+		start := time.Now()
+		p.fn(i)
+		p.release(time.Since(start))
+	}
+}
+
+// acquire waits until a worker may run and returns the next index, or
+// false when all were handed out
+func (p *readPool) acquire() (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.running >= p.limit && p.next < p.n {
+		p.cond.Wait()
+	}
+	if p.next >= p.n {
+		return 0, false
+	}
+	i := p.next
+	p.next++
+	p.running++
+	return i, true
+}
+
+// release records a finished call that took latency
+func (p *readPool) release(latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+	p.windowDone++
+	p.windowLatency += latency
+	if p.windowDone >= max(minAdaptWindow, 2*p.limit) {
+		p.adapt()
+		p.spawn()
+	}
+	p.cond.Broadcast()
+}
+
+// adapt sets the limit for the next window from the last one
+func (p *readPool) adapt() {
+	elapsed := time.Since(p.windowStart)
+	throughput := float64(p.windowDone) / elapsed.Seconds()
+	mean := p.windowLatency / time.Duration(p.windowDone)
+
+	switch {
+	case p.settled:
+	case mean < lowReadLatency:
+		p.limit = defaultReadWorkers()
+		p.settled = true
+	case mean > highReadLatency:
+		if p.prevThroughput > 0 && throughput < p.prevThroughput*1.1 {
+			p.limit = p.prevLimit
+			p.settled = true
+		} else if p.limit < p.maxLimit {
+			p.prevLimit, p.prevThroughput = p.limit, throughput
+			p.limit = min(2*p.limit, p.maxLimit)
+		}
+	}
+
+	p.windowStart, p.windowDone, p.windowLatency = time.Now(), 0, 0
+}
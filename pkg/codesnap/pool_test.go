@@ -0,0 +1,128 @@
+package codesnap
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelFor(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		n, workers int
+		sleep      time.Duration
+	}{
+		{"no work", 0, 0, 0},
+		{"fewer files than workers", 3, 8, 0},
+		{"one worker", 50, 1, 0},
+		{"fixed workers", 200, 4, 0},
+		{"adaptive and fast", 500, 0, 0},
+		{"adaptive and slow", 100, 0, 3 * time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := make([]int32, tc.n)
+			var running, peak atomic.Int32
+			parallelFor(tc.n, tc.workers, func(i int) {
+				now := running.Add(1)
+				for p := peak.Load(); now > p && !peak.CompareAndSwap(p, now); p = peak.Load() {
+				}
+				time.Sleep(tc.sleep)
+				atomic.AddInt32(&calls[i], 1)
+				running.Add(-1)
+			})
+			for i, c := range calls {
+				if c != 1 {
+					t.Fatalf("index %d called %d times", i, c)
+				}
+			}
+			limit := tc.workers
+			if limit == 0 {
+				limit = maxReadWorkers
+			}
+			if p := int(peak.Load()); p > min(limit, tc.n) {
+				t.Errorf("%d calls ran at once, want at most %d", p, min(limit, tc.n))
+			}
+		})
+	}
+}
+
+func TestReadPoolAdapt(t *testing.T) {
+	cpus := defaultReadWorkers()
+	for _, tc := range []struct {
+		name           string
+		workers        int
+		limit          int
+		prevThroughput float64 // files per second before the last increase
+		latency        time.Duration
+		wantLimit      int
+		wantSettled    bool
+	}{
+		{"configured workers", 4, 4, 0, 10 * time.Millisecond, 4, true},
+		{"fast storage", 0, cpus, 0, 50 * time.Microsecond, cpus, true},
+		{"slow storage grows", 0, cpus, 0, 10 * time.Millisecond, min(2*cpus, maxReadWorkers), false},
+		{"in between", 0, cpus, 0, time.Millisecond, cpus, false},
+		{"a growth that did not pay off", 0, 2 * cpus, 1e9, 10 * time.Millisecond, cpus, true},
+		{"at the maximum", 0, maxReadWorkers, 0, 10 * time.Millisecond, maxReadWorkers, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newReadPool(100, tc.workers, func(int) {})
+			p.limit = tc.limit
+			if tc.prevThroughput > 0 {
+				p.prevLimit, p.prevThroughput = cpus, tc.prevThroughput
+			}
+			p.windowStart = time.Now().Add(-time.Second)
+			p.windowDone = minAdaptWindow
+			p.windowLatency = tc.latency * minAdaptWindow
+			p.adapt()
+			if p.limit != tc.wantLimit || p.settled != tc.wantSettled {
+				t.Errorf("limit %d, settled %v; want %d, %v", p.limit, p.settled, tc.wantLimit, tc.wantSettled)
+			}
+			if p.windowDone != 0 || p.windowLatency != 0 {
+				t.Errorf("the window was not reset")
+			}
+		})
+	}
+}
+
+func TestReadPoolSpawnsOnDemand(t *testing.T) {
+	p := newReadPool(2, 8, func(int) {})
+	p.mu.Lock()
+	p.spawn()
+	started := p.started
+	p.mu.Unlock()
+	p.wg.Wait()
+	if started != 2 {
+		t.Errorf("started %d workers for 2 files", started)
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	for _, tc := range []struct {
+		name, options string
+		args          []string
+		code          int
+		want          string
+	}{
+		{"flag", "", []string{"--workers", "2"}, 0, "File: b.go\n"},
+		{"config", "workers: 3\n", nil, 0, "File: b.go\n"},
+		{"flag over config", "workers: 3\n", []string{"--workers", "1"}, 0, "File: b.go\n"},
+		{"negative flag", "", []string{"--workers", "-1"}, exitError, "--workers must not be negative"},
+		{"negative config", "workers: -2\n", nil, exitError, "invalid workers value -2 (expected a positive number)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"a.go":         "package a\n",
+				"b.go":         "package a\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if !strings.Contains(r.stdout+r.stderr, tc.want) {
+				t.Errorf("output lacks %q, got:\n%s%s", tc.want, r.stdout, r.stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package codesnap
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Preset is a bundle of command line options stored in the config, as its
+// flags or as a named pipeline:
+//
+//	pipelines:
+//	  review:
+//	    tree: true
+//	    graph: true
+//	    graph_format: dot
+//	    anonymize: true
+//
+// Keys are the long flag names, with underscores instead of dashes. The
+// single-letter flags are available under their long names (print, output,
+// log, tree_only), and tree is short for with_tree.
+type Preset map[string]interface{}
+
+// presetFlagAliases maps preset keys to the flags they stand for
+var presetFlagAliases = map[string]string{
+	"print":     "p",
+	"output":    "o",
+	"log":       "l",
+	"tree_only": "t",
+	"tree":      "with-tree",
+}
+
+// presetExcludedFlags cannot be set from a preset, since they are needed
+// before the config is read or end the program immediately
+var presetExcludedFlags = map[string]bool{"c": true, "profile": true, "h": true, "v": true}
+
+// apply sets the preset's options on fs, leaving flags that were given
+// explicitly on the command line untouched. Options of flags in known that
+// fs lacks are skipped, so one preset can serve several subcommands.
+func (p Preset) apply(fs, known *flag.FlagSet) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name, ok := presetFlagAliases[key]
+		if !ok {
+			name = strings.ReplaceAll(key, "_", "-")
+		}
+		if presetExcludedFlags[name] || known.Lookup(name) == nil {
+			return fmt.Errorf(T("unknown option %q"), key)
+		}
+		if explicit[name] || fs.Lookup(name) == nil {
+			continue
+		}
+
+		// Lists set repeatable flags once per item
+		values, isList := p[key].([]interface{})
+		if !isList {
+			values = []interface{}{p[key]}
+		}
+		for _, value := range values {
+			if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf(T("invalid value for %s: %v"), key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// configOptions are the parts of a config that set command line flags
+type configOptions struct {
+	Flags     Preset            `yaml:"flags"`
+	Pipelines map[string]Preset `yaml:"pipelines"`
+}
+
+// readConfigOptions reads the flags and pipelines of the config at path
+// (default: codesnap.yml) with the named profile applied, so they can be
+// applied before any flag is used. A missing config sets none.
+func readConfigOptions(path, profile string) (configOptions, error) {
+	if path == "" {
+		path = "codesnap.yml"
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return configOptions{}, nil
+	}
+	if err != nil {
+		return configOptions{}, fmt.Errorf(T("failed to read config file: %v"), err)
+	}
+	if profile != "" {
+		if data, err = applyProfile(data, profile); err != nil {
+			return configOptions{}, err
+		}
+	}
+	var opts configOptions
+	if err := yaml.Unmarshal(data, &opts); err != nil {
+		return configOptions{}, fmt.Errorf(T("invalid YAML format: %v"), err)
+	}
+	return opts, nil
+}
+
+// presetNames lists the names of presets for error messages
+func presetNames(presets map[string]Preset) string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,160 @@
+package codesnap
+
+import (
+	"flag"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testList is a repeatable flag
+type testList []string
+
+func (l *testList) String() string     { return strings.Join(*l, ",") }
+func (l *testList) Set(v string) error { *l = append(*l, v); return nil }
+
+// testFlags are the kinds of flags a preset sets
+func testFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("codesnap", flag.ContinueOnError)
+	fs.String("c", "", "")
+	fs.Bool("p", false, "")
+	fs.Bool("t", false, "")
+	fs.Bool("with-tree", false, "")
+	fs.Int("tree-depth", 0, "")
+	fs.Var(&testList{}, "note", "")
+	return fs
+}
+
+func TestPresetApply(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		preset Preset
+		args   []string
+		want   map[string]string
+		err    bool
+	}{
+		{name: "sets the flags not given", preset: Preset{"print": true, "tree_depth": 2},
+			want: map[string]string{"p": "true", "t": "false", "tree-depth": "2"}},
+		{name: "tree adds the tree", preset: Preset{"tree": true},
+			want: map[string]string{"with-tree": "true", "t": "false"}},
+		{name: "tree_only stands for -t", preset: Preset{"tree_only": true},
+			want: map[string]string{"t": "true", "with-tree": "false"}},
+		{name: "explicit flags win", preset: Preset{"tree_only": true}, args: []string{"-t=false"},
+			want: map[string]string{"t": "false"}},
+		{name: "lists set repeatable flags", preset: Preset{"note": []interface{}{"a", "b"}},
+			want: map[string]string{"note": "a,b"}},
+		{name: "skips the options of other commands", preset: Preset{"graph": true, "print": true},
+			want: map[string]string{"p": "true"}},
+		{name: "unknown option", preset: Preset{"colour": true}, err: true},
+		{name: "excluded option", preset: Preset{"c": "other.yml"}, err: true},
+		{name: "invalid value", preset: Preset{"tree_depth": "deep"}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := testFlags()
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			known := testFlags()
+			known.Bool("graph", false, "")
+			err := tc.preset.apply(fs, known)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tc.want {
+				if got := fs.Lookup(name).Value.String(); got != want {
+					t.Errorf("-%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadConfigOptions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nflags:\n  tree: true\nprofiles:\n  review:\n    flags:\n      format: markdown\n",
+		"invalid.yml":  "flags: [\n",
+	})
+	for _, tc := range []struct {
+		name, path, profile string
+		want                Preset
+		err                 bool
+	}{
+		{name: "flags", path: "codesnap.yml", want: Preset{"tree": true}},
+		{name: "profile flags replace the top-level ones", path: "codesnap.yml", profile: "review",
+			want: Preset{"format": "markdown"}},
+		{name: "unknown profile", path: "codesnap.yml", profile: "nope", err: true},
+		{name: "missing config", path: "missing.yml"},
+		{name: "invalid YAML", path: "invalid.yml", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := readConfigOptions(filepath.Join(dir, tc.path), tc.profile)
+			if (err != nil) != tc.err {
+				t.Fatalf("error %v, want error %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(opts.Flags, tc.want) {
+				t.Errorf("flags %v, want %v", opts.Flags, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigFlags(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nflags:\n  tree: true\n",
+		"a.go":         "package a\n",
+	})
+	for _, tc := range []struct {
+		name        string
+		args        []string
+		tree, files bool
+	}{
+		{"flags of the config", nil, true, true},
+		{"explicit flag wins", []string{"--with-tree=false"}, false, true},
+		{"tree only", []string{"-t"}, true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := runCodesnap(t, dir, tc.args...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, output: %s%s", r.code, r.stdout, r.stderr)
+			}
+			tree := strings.Contains(r.clipboard, "── a.go")
+			files := strings.Contains(r.clipboard, "package a")
+			if tree != tc.tree || files != tc.files {
+				t.Errorf("tree %v and files %v, want %v and %v; copied:\n%s", tree, files, tc.tree, tc.files, r.clipboard)
+			}
+		})
+	}
+}
+
+func TestProfileFlags(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - .\nprofiles:\n  review:\n    flags:\n      format: markdown\n",
+		"a.go":         "package a\n",
+	})
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		markdown bool
+	}{
+		{"flags of the profile", []string{"--profile", "review"}, true},
+		{"explicit flag wins", []string{"--profile", "review", "--format", "text"}, false},
+		{"without the profile", nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := runCodesnap(t, dir, tc.args...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, output: %s%s", r.code, r.stdout, r.stderr)
+			}
+			if markdown := strings.Contains(r.clipboard, "```go"); markdown != tc.markdown {
+				t.Errorf("markdown: %v, want %v; copied:\n%s", markdown, tc.markdown, r.clipboard)
+			}
+		})
+	}
+}
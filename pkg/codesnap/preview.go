@@ -0,0 +1,38 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// showInPager writes content to the user's pager ($PAGER, falling back to
+// less or more). Without a usable pager the content is printed directly.
+func showInPager(content string) error {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		if runtime.GOOS == "windows" {
+			pager = []string{"more"}
+		} else {
+			// -F quits for short output, -R keeps colors, -X leaves the screen intact
+			pager = []string{"less", "-FRX"}
+		}
+	}
+
+	path, err := exec.LookPath(pager[0])
+	if err != nil {
+		fmt.Print(content)
+		return nil
+	}
+
+	cmd := exec.Command(path, pager[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf(T("pager %s failed: %v"), pager[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	for _, tc := range []struct {
+		name, pager, script string
+		code                int
+		want                []string
+	}{
+		{"pager", "pager -x", `echo "args: $*"; cat`, 0, []string{"args: -x", "File: a.go", "package a"}},
+		{"no pager", "no-such-pager", "", 0, []string{"File: a.go", "package a"}},
+		{"pager fails", "pager", "cat > /dev/null; exit 3", exitError, []string{"pager pager failed"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeCommand(t, "pager", tc.script+"\n")
+			t.Setenv("PAGER", tc.pager)
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+			})
+			r := runCodesnap(t, dir, "preview")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			if r.clipboard != "" {
+				t.Errorf("preview copied to the clipboard:\n%s", r.clipboard)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// applyProfile overlays the named profile on the top level of a config:
+//
+//	profiles:
+//	  backend:
+//	    folders: [cmd, internal]
+//	  frontend:
+//	    folders: [web]
+//	    include: ["**/*.ts", "**/*.tsx"]
+//
+// Every key the profile sets replaces the top-level value; the others are
+// inherited. The result is the config without its profiles.
+func applyProfile(data []byte, name string) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf(T("invalid YAML format: %v"), err)
+	}
+
+	var profiles yaml.MapSlice
+	base := make(yaml.MapSlice, 0, len(doc))
+	for _, item := range doc {
+		if item.Key == "profiles" {
+			profiles, _ = item.Value.(yaml.MapSlice)
+			continue
+		}
+		base = append(base, item)
+	}
+
+	var profile yaml.MapSlice
+	found := false
+	names := make([]string, 0, len(profiles))
+	for _, item := range profiles {
+		key := fmt.Sprint(item.Key)
+		names = append(names, key)
+		if key == name {
+			profile, _ = item.Value.(yaml.MapSlice)
+			found = true
+		}
+	}
+	if !found {
+		sort.Strings(names)
+		available := strings.Join(names, ", ")
+		if available == "" {
+			available = "none"
+		}
+		return nil, fmt.Errorf(T("unknown profile %q (available: %s)"), name, available)
+	}
+
+	for _, item := range profile {
+		if item.Key == "profiles" {
+			return nil, errors.New(T("profiles cannot be nested"))
+		}
+		replaced := false
+		for i := range base {
+			if base[i].Key == item.Key {
+				base[i].Value, replaced = item.Value, true
+				break
+			}
+		}
+		if !replaced {
+			base = append(base, item)
+		}
+	}
+	return yaml.Marshal(base)
+}
@@ -0,0 +1,75 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	config := "folders:\n- .\nignore:\n- '*.log'\nprofiles:\n  backend:\n    folders:\n    - cmd\n    - internal\n  frontend:\n    folders:\n    - web\n    include:\n    - '**/*.ts'\n"
+	for _, tc := range []struct {
+		name, config, profile, want, err string
+	}{
+		{"replaces and inherits keys", config, "backend",
+			"folders:\n- cmd\n- internal\nignore:\n- '*.log'\n", ""},
+		{"adds keys", config, "frontend",
+			"folders:\n- web\nignore:\n- '*.log'\ninclude:\n- '**/*.ts'\n", ""},
+		{"unknown profile", config, "mobile", "", `unknown profile "mobile" (available: backend, frontend)`},
+		{"no profiles", "folders:\n- .\n", "backend", "", `unknown profile "backend" (available: none)`},
+		{"nested profiles", "profiles:\n  a:\n    profiles:\n      b: {}\n", "a", "", "profiles cannot be nested"},
+		{"invalid YAML", "folders: [\n", "a", "", "invalid YAML format"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := applyProfile([]byte(tc.config), tc.profile)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("applyProfile = %v, want %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil || string(got) != tc.want {
+				t.Errorf("applyProfile = %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestProfileFlag(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":   "folders:\n  - .\nignore:\n  - codesnap.yml\nprofiles:\n  backend:\n    folders:\n      - cmd\n  frontend:\n    folders:\n      - web\n    include:\n      - \"**/*.ts\"\n",
+		"cmd/main.go":    "package main\n",
+		"web/app.ts":     "export {}\n",
+		"web/style.css":  "a {}\n",
+		"docs/README.md": "# Docs\n",
+	})
+	for _, tc := range []struct {
+		profile        string
+		code           int
+		want, unwanted []string
+	}{
+		{"", 0, []string{"File: cmd/main.go", "File: web/app.ts", "File: docs/README.md"}, nil},
+		{"backend", 0, []string{"File: cmd/main.go"}, []string{"web/", "docs/"}},
+		{"frontend", 0, []string{"File: web/app.ts"}, []string{"style.css", "cmd/", "docs/"}},
+		{"mobile", exitError, []string{`unknown profile "mobile" (available: backend, frontend)`}, nil},
+	} {
+		var args []string
+		if tc.profile != "" {
+			args = []string{"--profile", tc.profile}
+		}
+		r := runCodesnap(t, dir, append(args, "--stdout", "-q")...)
+		if r.code != tc.code {
+			t.Fatalf("%s: exit code %d, want %d; output: %s%s", tc.profile, r.code, tc.code, r.stdout, r.stderr)
+		}
+		out := r.stdout + r.stderr
+		for _, s := range tc.want {
+			if !strings.Contains(out, s) {
+				t.Errorf("%s: output lacks %q, got:\n%s", tc.profile, s, out)
+			}
+		}
+		for _, s := range tc.unwanted {
+			if strings.Contains(out, s) {
+				t.Errorf("%s: output has %q, got:\n%s", tc.profile, s, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package codesnap
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// readCacheMaxAge drops cache entries of files no run has read for a month
+const readCacheMaxAge = 30 * 24 * time.Hour
+
+// readCacheVersion changes whenever reading a file gives a different
+// result, so older caches are discarded
+const readCacheVersion = 2
+
+// readCacheEntry is a file as validateFile read it, valid while the file
+// keeps its size and mtime
+type readCacheEntry struct {
+	Size    int64
+	ModTime int64
+	Valid   bool
+	Content string
+	// TruncatedFrom is the size on disk of a file cut at MaxFileSize
+	TruncatedFrom int64
+	Encoding      string
+	// Error and SkipReason describe why the file was skipped
+	Error      string
+	SkipReason string
+	Used       int64 // day of the last run that read the entry
+}
+
+type readCacheData struct {
+	Version     int
+	MaxFileSize int64
+	StrictUTF8  bool
+	// TextDetection and TextExtensions can turn a binary file into text
+	TextDetection  string
+	TextExtensions []string
+	Files          map[string]readCacheEntry
+}
+
+// readCache remembers what unchanged files contained, so repeated runs
+// (and every rerun of --watch) only read and validate the files that
+// changed. It is stored per config in the user cache directory.
+type readCache struct {
+	path  string
+	today int64
+	// start is when the run began; files modified in the same second are
+	// not cached, as a change right after reading could keep the mtime
+	start int64
+
+	mu    sync.Mutex
+	data  readCacheData
+	dirty bool
+	// hits and misses count the lookups, for the metrics of codesnap serve
+	hits, misses int64
+}
+
+// readCachePath is where the cache of the config in configDir is stored
+func readCachePath(configDir string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(configDir))
+	return filepath.Join(dir, "codesnap", "reads", hex.EncodeToString(sum[:8])+".gob"), nil
+}
+
+// openReadCache loads the read cache of cs's config. A missing or
+// unreadable cache, or one written by another version or with another
+// max_file_size or --strict-utf8, starts empty; nil means there is nowhere to store it.
+func (cs *CodeSnap) openReadCache() *readCache {
+	path, err := readCachePath(cs.configDir)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	c := &readCache{path: path, today: now.Unix() / 86400, start: now.Unix()}
+	if file, err := os.Open(path); err == nil {
+		gob.NewDecoder(file).Decode(&c.data)
+		file.Close()
+	}
+	if c.data.Version != readCacheVersion || c.data.MaxFileSize != cs.maxFileSize || c.data.StrictUTF8 != cs.strictUTF8 ||
+		c.data.TextDetection != cs.config.TextDetection || !slices.Equal(c.data.TextExtensions, cs.config.TextExtensions) || c.data.Files == nil {
+		c.data = readCacheData{Version: readCacheVersion, MaxFileSize: cs.maxFileSize, StrictUTF8: cs.strictUTF8,
+			TextDetection: cs.config.TextDetection, TextExtensions: cs.config.TextExtensions, Files: make(map[string]readCacheEntry)}
+		c.dirty = true
+	}
+	return c
+}
+
+// read returns the result of validateFile for path, from the cache if the
+// file did not change since it was stored
+func (c *readCache) read(path string, limit int64, strict, trusted bool) (textFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return validateFile(path, limit, strict, trusted)
+	}
+	size, modTime := info.Size(), info.ModTime().UnixNano()
+
+	c.mu.Lock()
+	entry, ok := c.data.Files[path]
+	if ok && entry.Size == size && entry.ModTime == modTime {
+		c.hits++
+		if entry.Used != c.today {
+			entry.Used = c.today
+			c.data.Files[path] = entry
+			c.dirty = true
+		}
+		c.mu.Unlock()
+		if entry.Error != "" {
+			return textFile{}, cachedError(cachedResult{Error: entry.Error, SkipReason: entry.SkipReason})
+		}
+		return textFile{valid: entry.Valid, content: entry.Content, truncatedFrom: entry.TruncatedFrom, encoding: entry.Encoding}, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	text, err := validateFile(path, limit, strict, trusted)
+	if info.ModTime().Unix() >= c.start {
+		return text, err
+	}
+	entry = readCacheEntry{Size: size, ModTime: modTime, Valid: text.valid, Content: text.content, TruncatedFrom: text.truncatedFrom, Encoding: text.encoding, Used: c.today}
+	if err != nil {
+		// Only errors about the content are cached; a file that could not be
+		// opened is tried again on the next run
+		reason := skipReason(err)
+		if reason == "unreadable" {
+			return text, err
+		}
+		entry.Error, entry.SkipReason = err.Error(), reason
+	}
+	c.mu.Lock()
+	c.data.Files[path] = entry
+	c.dirty = true
+	c.mu.Unlock()
+	return text, err
+}
+
+// counts returns the number of lookups answered from the cache and of
+// those that had to read the file
+func (c *readCache) counts() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// save writes the cache if it changed, leaving out the entries no run
+// used recently. The file is private as it holds the files' contents.
+func (c *readCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	for path, entry := range c.data.Files {
+		if c.today-entry.Used > int64(readCacheMaxAge/(24*time.Hour)) {
+			delete(c.data.Files, path)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".reads-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(&c.data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
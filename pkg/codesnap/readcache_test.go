@@ -0,0 +1,84 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := writeFiles(t, map[string]string{
+		"a.txt":     "hello\n",
+		"bin.dat":   "\x00\x01\x02",
+		"fresh.txt": "just written\n",
+	})
+	old := time.Now().Add(-time.Hour)
+	for _, name := range []string{"a.txt", "bin.dat"} {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cs := &CodeSnap{configDir: dir, config: &Config{}, maxFileSize: defaultMaxFileSize}
+
+	// Each step opens the saved cache again, like a new run
+	for _, tc := range []struct {
+		name   string
+		before func(t *testing.T)
+		reads  []string
+		want   map[string]string // the content, or the error, of each file read
+		hits   int64
+	}{
+		{"first run", nil, []string{"a.txt", "bin.dat", "fresh.txt"},
+			map[string]string{"a.txt": "hello\n", "bin.dat": "binary", "fresh.txt": "just written\n"}, 0},
+		{"unchanged files", nil, []string{"a.txt", "bin.dat", "fresh.txt"},
+			map[string]string{"a.txt": "hello\n", "bin.dat": "binary", "fresh.txt": "just written\n"}, 2},
+		{"a changed file", func(t *testing.T) {
+			path := filepath.Join(dir, "a.txt")
+			if err := os.WriteFile(path, []byte("changed\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, old.Add(time.Minute), old.Add(time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+		}, []string{"a.txt", "bin.dat"}, map[string]string{"a.txt": "changed\n", "bin.dat": "binary"}, 1},
+		{"another max_file_size", func(t *testing.T) { cs.maxFileSize = 1024 }, []string{"a.txt", "bin.dat"},
+			map[string]string{"a.txt": "changed\n", "bin.dat": "binary"}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.before != nil {
+				tc.before(t)
+			}
+			c := cs.openReadCache()
+			if c == nil {
+				t.Fatal("no read cache")
+			}
+			for _, name := range tc.reads {
+				text, err := c.read(filepath.Join(dir, name), cs.maxFileSize, false, false)
+				got := text.content
+				if err != nil {
+					got = err.Error()
+				}
+				if !strings.Contains(got, tc.want[name]) {
+					t.Errorf("%s = %q, want %q", name, got, tc.want[name])
+				}
+			}
+			if hits, misses := c.counts(); hits != tc.hits || misses != int64(len(tc.reads))-tc.hits {
+				t.Errorf("%d hits and %d misses, want %d hits", hits, misses, tc.hits)
+			}
+			if err := c.save(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	info, err := os.Stat(cs.openReadCache().path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("the cache has mode %v, want 0600", perm)
+	}
+}
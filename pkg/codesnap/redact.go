@@ -0,0 +1,93 @@
+package codesnap
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redactedMarker replaces secrets found by redactSecrets
+const redactedMarker = "[REDACTED]"
+
+var (
+	// secretTokens match credentials by their well-known shape
+	secretTokens = []*regexp.Regexp{
+		regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                   // AWS access key IDs
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),                                  // GitHub tokens
+		regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`),                                // GitHub fine-grained tokens
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),                                  // Slack tokens
+		regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{24,}\b`),                             // Stripe keys
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                                         // OpenAI, Anthropic and similar API keys
+		regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),                                       // Google API keys
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), // JWTs
+	}
+
+	// secretAssignment matches quoted values assigned to names that suggest
+	// a secret, e.g. password = "hunter22" or "apiKey": "..."
+	secretAssignment = regexp.MustCompile(`(?i)((?:password|passwd|secret|api[_-]?key|access[_-]?key|auth[_-]?token|access[_-]?token|private[_-]?key|client[_-]?secret)\w*["']?\s*[:=]\s*)("[^"\n]{6,}"|'[^'\n]{6,}')`)
+
+	// secretEnvLine matches unquoted values in .env and shell style, e.g.
+	// DB_PASSWORD=hunter22, also on the lines of a diff hunk
+	secretEnvLine = regexp.MustCompile(`(?m)^([-+ ]?\s*(?:export\s+)?[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_?KEY)[A-Z0-9_]*=)([^\s"'$][^\s"']{5,})`)
+
+	// urlCredentials matches the password of credentials embedded in URLs
+	urlCredentials = regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`)
+
+	// bearerToken matches the token of Authorization headers and curl
+	// commands, e.g. Authorization: Bearer abc...
+	bearerToken = regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/-]{16,}=*`)
+)
+
+// redactSecrets replaces API keys, tokens, private keys and passwords in
+// content with [REDACTED], keeping the names they are assigned to so the
+// code stays readable
+func redactSecrets(content string) string {
+	for _, re := range secretTokens {
+		content = re.ReplaceAllString(content, redactedMarker)
+	}
+	content = secretAssignment.ReplaceAllStringFunc(content, func(m string) string {
+		parts := secretAssignment.FindStringSubmatch(m)
+		quote := parts[2][:1]
+		if strings.Contains(parts[2], redactedMarker) {
+			return m
+		}
+		return parts[1] + quote + redactedMarker + quote
+	})
+	content = secretEnvLine.ReplaceAllString(content, "${1}"+redactedMarker)
+	content = bearerToken.ReplaceAllString(content, "${1}"+redactedMarker)
+	return urlCredentials.ReplaceAllString(content, "${1}"+redactedMarker+"@")
+}
+
+// redactSections is the one redaction pass of a snapshot: it replaces the
+// secrets of every emitted section, so the included files (or their diff
+// hunks), the database DDL, the --exec commands and their output, the --env
+// facts and the notes, and counts them in stats.redacted. It runs once the
+// budget has left out what it drops, and before anything is rendered.
+func (cs *CodeSnap) redactSections(results []fileResult) {
+	if !cs.redact {
+		return
+	}
+	redact := func(s *string) {
+		redacted := redactSecrets(*s)
+		cs.stats.redacted += strings.Count(redacted, redactedMarker) - strings.Count(*s, redactedMarker)
+		*s = redacted
+	}
+	for i := range results {
+		if r := &results[i]; r.err == nil && !r.empty && r.duplicateOf == "" {
+			redact(&r.content)
+		}
+	}
+	for i := range cs.schemas {
+		redact(&cs.schemas[i].DDL)
+	}
+	for i := range cs.commands {
+		redact(&cs.commands[i].Command)
+		redact(&cs.commands[i].Output)
+	}
+	for i := range cs.environment {
+		redact(&cs.environment[i].Value)
+	}
+	for i := range cs.notes {
+		redact(&cs.notes[i])
+	}
+}
@@ -0,0 +1,70 @@
+package codesnap
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// renameRule shows the files under a real path, relative to the config, at
+// a virtual path instead:
+//
+//	rename:
+//	  internal/secretsvc: service-x
+type renameRule struct {
+	from, to string
+}
+
+// parseRenames validates the rename map and orders the rules longest
+// path first, so nested renames take precedence
+func parseRenames(renames map[string]string) ([]renameRule, error) {
+	rules := make([]renameRule, 0, len(renames))
+	for from, to := range renames {
+		from = path.Clean(filepath.ToSlash(from))
+		to = path.Clean(filepath.ToSlash(to))
+		if from == "." || path.IsAbs(from) || strings.HasPrefix(from, "../") {
+			return nil, fmt.Errorf(T("invalid rename path %q (expected a path relative to the config)"), from)
+		}
+		if to == "." || to == "" {
+			return nil, fmt.Errorf(T("rename of %q needs a non-empty virtual path"), from)
+		}
+		rules = append(rules, renameRule{from, to})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if len(rules[i].from) != len(rules[j].from) {
+			return len(rules[i].from) > len(rules[j].from)
+		}
+		return rules[i].from < rules[j].from
+	})
+	return rules, nil
+}
+
+// renamed returns the virtual path for rel, a path relative to the config
+// with forward slashes. ok is false when no rename applies.
+func (cs *CodeSnap) renamed(rel string) (string, bool) {
+	for _, r := range cs.renames {
+		if rel == r.from {
+			return r.to, true
+		}
+		if strings.HasPrefix(rel, r.from+"/") {
+			return r.to + rel[len(r.from):], true
+		}
+	}
+	return rel, false
+}
+
+// treeName returns the name shown in the tree for file below parent, which
+// is its virtual path relative to the parent's, or name if it is not renamed
+func (cs *CodeSnap) treeName(parent, file, name string) string {
+	virtual, ok := cs.renamed(filepath.ToSlash(cs.relPath(file)))
+	if !ok {
+		return name
+	}
+	parentVirtual, _ := cs.renamed(filepath.ToSlash(cs.relPath(parent)))
+	if strings.HasPrefix(virtual, parentVirtual+"/") {
+		return virtual[len(parentVirtual)+1:]
+	}
+	return virtual
+}
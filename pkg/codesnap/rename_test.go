@@ -0,0 +1,67 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenamed(t *testing.T) {
+	rules, err := parseRenames(map[string]string{
+		"internal/secretsvc":        "service-x",
+		"internal/secretsvc/keys/":  "service-x/credentials",
+		"./cmd/../tools/generator/": "tools/gen",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &CodeSnap{renames: rules}
+	for _, tc := range []struct {
+		rel, want string
+		ok        bool
+	}{
+		{"internal/secretsvc", "service-x", true},
+		{"internal/secretsvc/main.go", "service-x/main.go", true},
+		{"internal/secretsvc/keys/store.go", "service-x/credentials/store.go", true},
+		{"internal/secretsvc2/main.go", "internal/secretsvc2/main.go", false},
+		{"tools/generator/gen.go", "tools/gen/gen.go", true},
+		{"main.go", "main.go", false},
+	} {
+		if got, ok := cs.renamed(tc.rel); got != tc.want || ok != tc.ok {
+			t.Errorf("renamed(%s) = %s, %v; want %s, %v", tc.rel, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	for _, tc := range []struct {
+		from, to, err string
+	}{
+		{".", "x", `invalid rename path "."`},
+		{"/etc", "x", `invalid rename path "/etc"`},
+		{"../other", "x", `invalid rename path "../other"`},
+		{"internal", "", `rename of "internal" needs a non-empty virtual path`},
+	} {
+		if _, err := parseRenames(map[string]string{tc.from: tc.to}); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("parseRenames(%s: %s) = %v, want %q", tc.from, tc.to, err, tc.err)
+		}
+	}
+}
+
+func TestRenameOption(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml":                "folders:\n  - .\nignore:\n  - codesnap.yml\nrename:\n  internal/secretsvc: service-x\n",
+		"internal/secretsvc/main.go":  "package svc\n",
+		"internal/secretsvc/db/db.go": "package db\n",
+		"internal/other/other.go":     "package other\n",
+	})
+	r := runCodesnap(t, dir, "--with-tree", "--stdout", "-q")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+	}
+	for _, want := range []string{"File: service-x/main.go", "File: service-x/db/db.go", "File: internal/other/other.go", "── service-x/\n"} {
+		if !strings.Contains(r.stdout, want) {
+			t.Errorf("snapshot lacks %q, got:\n%s", want, r.stdout)
+		}
+	}
+	if strings.Contains(r.stdout, "secretsvc") {
+		t.Errorf("snapshot has the real path:\n%s", r.stdout)
+	}
+}
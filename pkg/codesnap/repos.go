@@ -0,0 +1,104 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// repoHosts are the hosts whose repositories can be given as a folder in
+// the short form host/org/repo[@ref][/path]
+var repoHosts = []string{"github.com/", "gitlab.com/", "bitbucket.org/"}
+
+// parseRepoFolder splits a short remote folder such as
+// github.com/org/repo@main/src into the repository, ref and path within it
+func parseRepoFolder(s string) (repo, ref, path string, ok bool) {
+	slashed := filepath.ToSlash(s)
+	hosted := false
+	for _, host := range repoHosts {
+		hosted = hosted || strings.HasPrefix(slashed, host)
+	}
+	parts := strings.SplitN(slashed, "/", 4)
+	if !hosted || len(parts) < 3 || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	name, ref, _ := strings.Cut(parts[2], "@")
+	repo = strings.Join([]string{parts[0], parts[1], name}, "/")
+	if len(parts) == 4 {
+		path = parts[3]
+	}
+	return repo, ref, path, true
+}
+
+// repoURL returns the URL a remote folder is fetched from
+func repoURL(repo string) string {
+	if strings.Contains(repo, "://") || strings.HasPrefix(repo, "git@") {
+		return repo
+	}
+	return "https://" + strings.TrimSuffix(repo, ".git") + ".git"
+}
+
+// repoName is a repository without its scheme and .git suffix, as shown in
+// the snapshot, e.g. github.com/org/repo
+func repoName(repo string) string {
+	if _, rest, ok := strings.Cut(repo, "://"); ok {
+		repo = rest
+	} else if rest, ok := strings.CutPrefix(repo, "git@"); ok {
+		repo = strings.Replace(rest, ":", "/", 1)
+	}
+	return strings.Trim(strings.TrimSuffix(repo, ".git"), "/")
+}
+
+// fetchRepo makes a shallow checkout of ref (default: the default branch)
+// of a remote repository and returns its directory. Checkouts are kept in
+// the user cache directory and refreshed on every run; their git data is
+// stored beside them, so it is not part of the snapshot.
+func (cs *CodeSnap) fetchRepo(repo, ref string) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = os.TempDir()
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(repoName(repo)+"@"+ref, "-"), "-")
+	dir := filepath.Join(cache, "codesnap", "repos", name)
+	gitDir := dir + ".git"
+
+	fmt.Printf(T("Fetching %s@%s...\n"), repoName(repo), ref)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", fmt.Errorf(T("failed to prepare checkout of %s: %v"), repo, err)
+	}
+	if _, err := gitOutput(repoGit(gitDir, "", "init", "-q", "--bare")); err != nil {
+		return "", err
+	}
+	if _, err := gitOutput(repoGit(gitDir, "", "fetch", "-q", "--depth", "1", repoURL(repo), ref)); err != nil {
+		return "", err
+	}
+
+	// Start from an empty directory so files deleted upstream disappear
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf(T("failed to prepare checkout of %s: %v"), repo, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf(T("failed to prepare checkout of %s: %v"), repo, err)
+	}
+	if _, err := gitOutput(repoGit(gitDir, dir, "checkout", "-q", "-f", "FETCH_HEAD", "--", ".")); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// repoGit prepares a git command on a checkout's separate git directory.
+// Prompts are disabled, so a private repository fails instead of waiting
+// for credentials.
+func repoGit(gitDir, workTree string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_DIR="+gitDir, "GIT_TERMINAL_PROMPT=0")
+	if workTree != "" {
+		cmd.Env = append(cmd.Env, "GIT_WORK_TREE="+workTree)
+	}
+	return cmd
+}
@@ -0,0 +1,96 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRepoFolder(t *testing.T) {
+	for _, tc := range []struct {
+		s, repo, ref, path string
+		ok                 bool
+	}{
+		{"github.com/org/lib", "github.com/org/lib", "", "", true},
+		{"github.com/org/lib@v1.2.0/pkg", "github.com/org/lib", "v1.2.0", "pkg", true},
+		{"gitlab.com/group/tool/internal/api", "gitlab.com/group/tool", "", "internal/api", true},
+		{"bitbucket.org/team/app@main", "bitbucket.org/team/app", "main", "", true},
+		{"github.com/org", "", "", "", false},
+		{"github.com//lib", "", "", "", false},
+		{"example.com/org/lib", "", "", "", false},
+		{"src", "", "", "", false},
+	} {
+		repo, ref, path, ok := parseRepoFolder(tc.s)
+		if repo != tc.repo || ref != tc.ref || path != tc.path || ok != tc.ok {
+			t.Errorf("parseRepoFolder(%s) = %q, %q, %q, %v; want %q, %q, %q, %v", tc.s, repo, ref, path, ok, tc.repo, tc.ref, tc.path, tc.ok)
+		}
+	}
+}
+
+func TestRepoURLAndName(t *testing.T) {
+	for _, tc := range []struct {
+		repo, url, name string
+	}{
+		{"github.com/org/lib", "https://github.com/org/lib.git", "github.com/org/lib"},
+		{"github.com/org/lib.git", "https://github.com/org/lib.git", "github.com/org/lib"},
+		{"https://git.example.com/team/tool.git", "https://git.example.com/team/tool.git", "git.example.com/team/tool"},
+		{"git@github.com:org/lib.git", "git@github.com:org/lib.git", "github.com/org/lib"},
+		{"file:///srv/repos/lib/", "file:///srv/repos/lib/", "srv/repos/lib"},
+	} {
+		if got := repoURL(tc.repo); got != tc.url {
+			t.Errorf("repoURL(%s) = %s, want %s", tc.repo, got, tc.url)
+		}
+		if got := repoName(tc.repo); got != tc.name {
+			t.Errorf("repoName(%s) = %s, want %s", tc.repo, got, tc.name)
+		}
+	}
+}
+
+func TestRepoFolders(t *testing.T) {
+	remote := gitRepo(t, map[string]string{
+		"src/lib.go":  "package lib // v1\n",
+		"src/gone.go": "package lib\n",
+		"README.md":   "# lib\n",
+	})
+	git(t, remote, "tag", "v1")
+	if err := os.WriteFile(filepath.Join(remote, "src", "lib.go"), []byte("package lib // v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, remote, "rm", "-q", "src/gone.go")
+	git(t, remote, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qam", "v2")
+	url := "file://" + filepath.ToSlash(remote)
+
+	for _, tc := range []struct {
+		name, folder   string
+		code           int
+		want, unwanted []string
+	}{
+		{"the default branch", "  - repo: " + url + "\n    label: lib\n", 0,
+			[]string{"Fetching " + repoName(url) + "@HEAD...", "File: lib/src/lib.go\n", "package lib // v2", "File: lib/README.md"}, []string{"gone.go", ".git/"}},
+		{"a tag and a path", "  - repo: " + url + "\n    ref: v1\n    path: src\n", 0,
+			[]string{"File: " + repoName(url) + "@v1/src/lib.go\n", "package lib // v1", "File: " + repoName(url) + "@v1/src/gone.go\n"}, []string{"README.md"}},
+		{"an unknown ref", "  - repo: " + url + "\n    ref: nope\n", exitError, []string{"git fetch failed"}, nil},
+		{"repo and worktree", "  - repo: " + url + "\n    worktree: main\n", exitError, []string{"cannot have both repo and worktree"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			dir := writeFiles(t, map[string]string{"codesnap.yml": "folders:\n" + tc.folder})
+			r := runCodesnap(t, dir, "--stdout")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// errCredentialFile marks files skipped by --safe because they are likely
+// to hold credentials
+var errCredentialFile = errors.New("file looks like it holds credentials")
+
+// credentialNames are file name patterns of credential stores, keys and
+// certificates left out by --safe whether or not they are ignored
+var credentialNames = []string{
+	".env", ".env.*", "*.env", ".envrc", ".netrc", "_netrc", ".npmrc", ".pypirc", ".git-credentials",
+	".pgpass", ".my.cnf", "credentials", "credentials.*", "secrets.*", "*.tfvars", "*.tfstate",
+	"id_rsa*", "id_dsa*", "id_ecdsa*", "id_ed25519*", "*.pem", "*.key", "*.p12", "*.pfx",
+	"*.jks", "*.keystore", "*.kdbx", "*.gpg", "*.asc",
+}
+
+// isCredentialFile reports whether a file's name matches credentialNames,
+// or it lies in a directory that only holds credentials such as ~/.ssh
+func isCredentialFile(file string) bool {
+	slashed := filepath.ToSlash(file)
+	name := strings.ToLower(path.Base(slashed))
+	for _, pattern := range credentialNames {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	for _, dir := range strings.Split(path.Dir(slashed), "/") {
+		if dir == ".ssh" || dir == ".gnupg" || dir == ".aws" {
+			return true
+		}
+	}
+	return false
+}
+
+// safeOutputPath creates a directory only the current user can access and
+// returns the path of a new snapshot file in it
+func safeOutputPath(ext string) (string, error) {
+	dir, err := os.MkdirTemp("", "codesnap-")
+	if err != nil {
+		return "", fmt.Errorf(T("failed to create private output directory: %v"), err)
+	}
+	return filepath.Join(dir, fmt.Sprintf("codesnap_%s%s", time.Now().Format("20060102_150405"), ext)), nil
+}
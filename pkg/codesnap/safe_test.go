@@ -0,0 +1,104 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsCredentialFile(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		want bool
+	}{
+		{".env", true},
+		{"config/.env.production", true},
+		{"deploy/prod.env", true},
+		{"home/.netrc", true},
+		{"CREDENTIALS.json", true},
+		{"infra/main.tfvars", true},
+		{"keys/id_ed25519.pub", true},
+		{"certs/server.pem", true},
+		{"home/.ssh/config", true},
+		{".aws/config", true},
+		{"environment.go", false},
+		{"docs/envelope.md", false},
+		{"src/keyboard.go", false},
+		{"ssh/client.go", false},
+	} {
+		if got := isCredentialFile(tc.file); got != tc.want {
+			t.Errorf("isCredentialFile(%s) = %v, want %v", tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestSafe(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"a private file", nil, 0, ""},
+		{"markdown", []string{"--format", "markdown"}, 0, ""},
+		{"with -O", []string{"-O", "out.txt"}, exitError, "--safe writes into a private directory"},
+		{"with -o", []string{"-o"}, exitError, "--safe writes into a private directory"},
+		{"with --no-redact", []string{"--no-redact"}, exitError, "--safe always redacts secrets"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml":     "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"main.go":          "package main\n",
+				".env":             "DB_PASSWORD=hunter22\n",
+				"keys/id_rsa":      "not a key\n",
+				"certs/server.pem": "not a certificate\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--safe")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if tc.code != 0 {
+				if !strings.Contains(r.stdout+r.stderr, tc.want) {
+					t.Errorf("output lacks %q, got:\n%s%s", tc.want, r.stdout, r.stderr)
+				}
+				return
+			}
+			if r.clipboard != "" {
+				t.Errorf("--safe copied to the clipboard")
+			}
+
+			_, path, ok := strings.Cut(r.stdout, "Content saved to: ")
+			if !ok {
+				t.Fatalf("output does not name the file, got:\n%s", r.stdout)
+			}
+			path, _, _ = strings.Cut(path, "\n")
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.RemoveAll(filepath.Dir(path)) })
+			if !strings.Contains(string(content), "package main") {
+				t.Errorf("the snapshot lacks main.go:\n%s", content)
+			}
+			for _, secret := range []string{"hunter22", "not a key", "not a certificate"} {
+				if strings.Contains(string(content), secret) {
+					t.Errorf("the snapshot has %q:\n%s", secret, content)
+				}
+			}
+			if runtime.GOOS == "windows" {
+				return
+			}
+			for p, want := range map[string]os.FileMode{filepath.Dir(path): 0o700, path: 0o600} {
+				info, err := os.Stat(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Mode().Perm() != want {
+					t.Errorf("%s has mode %v, want %v", p, info.Mode().Perm(), want)
+				}
+			}
+		})
+	}
+}
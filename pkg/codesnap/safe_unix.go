@@ -0,0 +1,11 @@
+//go:build !windows
+
+package codesnap
+
+import "syscall"
+
+// restrictFileModes makes every file and directory the process creates
+// from now on accessible to the current user only (0600 and 0700)
+func restrictFileModes() {
+	syscall.Umask(0o077)
+}
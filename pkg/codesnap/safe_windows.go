@@ -0,0 +1,8 @@
+//go:build windows
+
+package codesnap
+
+// restrictFileModes is a no-op on Windows, where new files inherit the
+// permissions of their directory and the temporary directory is already
+// private to the user
+func restrictFileModes() {}
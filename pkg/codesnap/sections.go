@@ -0,0 +1,89 @@
+package codesnap
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v2"
+)
+
+// otherSection collects the files that match none of the configured sections
+const otherSection = "Other"
+
+// Section groups files of one feature area under a header:
+//
+//	sections:
+//	  Auth: ["internal/auth/**", "pkg/jwt/**"]
+//	  Billing: ["internal/billing/**"]
+type Section struct {
+	Name     string
+	Patterns []string
+}
+
+// Sections keeps the sections in the order they are written in the config,
+// which is the order they appear in the snapshot
+type Sections []Section
+
+func (s *Sections) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw yaml.MapSlice
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	for _, item := range raw {
+		name := fmt.Sprint(item.Key)
+		var patterns []string
+		switch v := item.Value.(type) {
+		case string:
+			patterns = []string{v}
+		case []interface{}:
+			for _, p := range v {
+				patterns = append(patterns, fmt.Sprint(p))
+			}
+		default:
+			return fmt.Errorf(T("section %q needs a glob or a list of globs"), name)
+		}
+		for _, p := range patterns {
+			if !doublestar.ValidatePattern(p) {
+				return fmt.Errorf(T("invalid glob %q in section %q"), p, name)
+			}
+		}
+		*s = append(*s, Section{Name: name, Patterns: patterns})
+	}
+	return nil
+}
+
+// sectionOf returns the name of the first section matching file, or
+// otherSection when none does
+func (cs *CodeSnap) sectionOf(file string) string {
+	rel := filepath.ToSlash(cs.relPath(file))
+	for _, section := range cs.config.Sections {
+		for _, pattern := range section.Patterns {
+			if matched, _ := doublestar.Match(pattern, rel); matched {
+				return section.Name
+			}
+		}
+	}
+	return otherSection
+}
+
+// groupBySection assigns each result its section and orders the results by
+// section, keeping the original order within a section
+func (cs *CodeSnap) groupBySection(results []fileResult) {
+	if len(cs.config.Sections) == 0 {
+		return
+	}
+	order := make(map[string]int, len(cs.config.Sections)+1)
+	for i, section := range cs.config.Sections {
+		order[section.Name] = i
+	}
+	order[otherSection] = len(cs.config.Sections)
+
+	for i := range results {
+		results[i].section = cs.sectionOf(results[i].path)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return order[results[i].section] < order[results[j].section]
+	})
+}
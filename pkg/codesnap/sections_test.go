@@ -0,0 +1,72 @@
+package codesnap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSectionsConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name, yaml string
+		want       Sections
+		err        string
+	}{
+		{"glob and list", "Billing: billing/**\nAuth: [\"auth/**\", \"pkg/jwt/**\"]\n",
+			Sections{{"Billing", []string{"billing/**"}}, {"Auth", []string{"auth/**", "pkg/jwt/**"}}}, ""},
+		{"config order", "Zeta: z/**\nAlpha: a/**\n",
+			Sections{{"Zeta", []string{"z/**"}}, {"Alpha", []string{"a/**"}}}, ""},
+		{"invalid glob", "Auth: [\"auth/[\"]\n", nil, `invalid glob "auth/[" in section "Auth"`},
+		{"not a glob", "Auth:\n  path: auth\n", nil, `section "Auth" needs a glob or a list of globs`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got Sections
+			err := yaml.Unmarshal([]byte(tc.yaml), &got)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("error = %v, want %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("sections = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSections(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{"Section: Billing", "File: billing/b.go", "Section: Auth", "File: auth/a.go", "Section: Other", "File: main.go"}},
+		{"markdown", []string{"# Billing\n", "## billing/b.go", "# Auth\n", "## auth/a.go", "# Other\n", "## main.go"}},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\nsections:\n  Billing: billing/**\n  Auth: [\"auth/**\"]\n",
+				"auth/a.go":    "package auth\n",
+				"billing/b.go": "package billing\n",
+				"main.go":      "package main\n",
+			})
+			r := runCodesnap(t, dir, "--format", tc.format, "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			rest := r.stdout
+			for _, s := range tc.want {
+				i := strings.Index(rest, s)
+				if i < 0 {
+					t.Fatalf("snapshot lacks %q after the earlier sections, got:\n%s", s, r.stdout)
+				}
+				rest = rest[i+len(s):]
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package codesnap
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultServeAddr = "127.0.0.1:8765"
+
+// fileObject mirrors the file object of the OpenAI files API, so clients
+// that can attach files from that API can list and download snapshot chunks
+type fileObject struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int    `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+}
+
+// snapshotChunk is one included file of the snapshot
+type snapshotChunk struct {
+	file    fileObject
+	content string
+}
+
+// snapshotServer serves the current snapshot. Every request rebuilds it, so
+// clients always see the files as they are on disk.
+type snapshotServer struct {
+	mu        sync.Mutex
+	cs        *CodeSnap
+	transform func(string) string
+
+	// statsMu guards stats separately from mu, so /metrics and /healthz
+	// answer while a snapshot is being built
+	statsMu sync.Mutex
+	stats   serverStats
+}
+
+// serverStats describe the snapshots built so far, for /metrics
+type serverStats struct {
+	snapshots    int64
+	lastDuration time.Duration
+	lastSnapshot time.Time
+	files        int // files selected by the last snapshot
+	included     int
+	skipped      int
+	cacheHits    int64
+	cacheMisses  int64
+}
+
+// chunks collects the snapshot and splits it into one chunk per included
+// file. Empty, duplicate and skipped files are left out.
+func (s *snapshotServer) chunks() []snapshotChunk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := time.Now()
+	results := s.cs.readFiles(s.cs.gatherFiles())
+	s.cs.redactSections(results)
+	s.statsMu.Lock()
+	s.stats.snapshots++
+	s.stats.lastDuration = time.Since(start)
+	s.stats.lastSnapshot = start
+	s.stats.files = len(results)
+	s.stats.included = s.cs.stats.processed
+	s.stats.skipped = s.cs.stats.skipped
+	if s.cs.readCache != nil {
+		s.stats.cacheHits, s.stats.cacheMisses = s.cs.readCache.counts()
+	}
+	s.statsMu.Unlock()
+
+	var chunks []snapshotChunk
+	for _, r := range results {
+		if r.err != nil || r.empty || r.duplicateOf != "" {
+			continue
+		}
+		content := r.content
+		if s.transform != nil {
+			content = s.transform(content)
+		}
+		var created int64
+		if info, err := os.Stat(r.path); err == nil {
+			created = info.ModTime().Unix()
+		}
+		chunks = append(chunks, snapshotChunk{
+			file: fileObject{
+				ID:        fmt.Sprintf("file-%x", sha256.Sum256([]byte(r.relPath)))[:29],
+				Object:    "file",
+				Bytes:     len(content),
+				CreatedAt: created,
+				Filename:  r.relPath,
+				Purpose:   "assistants",
+				Status:    "processed",
+			},
+			content: content,
+		})
+	}
+	return chunks
+}
+
+// find returns the chunk with the given id
+func (s *snapshotServer) find(w http.ResponseWriter, id string) (snapshotChunk, bool) {
+	for _, c := range s.chunks() {
+		if c.file.ID == id {
+			return c, true
+		}
+	}
+	writeAPIError(w, http.StatusNotFound, fmt.Sprintf("No such File object: %s", id))
+	return snapshotChunk{}, false
+}
+
+func (s *snapshotServer) handleList(w http.ResponseWriter, r *http.Request) {
+	chunks := s.chunks()
+	files := make([]fileObject, 0, len(chunks))
+	for _, c := range chunks {
+		files = append(files, c.file)
+	}
+	writeJSON(w, struct {
+		Object  string       `json:"object"`
+		Data    []fileObject `json:"data"`
+		HasMore bool         `json:"has_more"`
+	}{"list", files, false})
+}
+
+func (s *snapshotServer) handleRetrieve(w http.ResponseWriter, r *http.Request) {
+	if c, ok := s.find(w, r.PathValue("id")); ok {
+		writeJSON(w, c.file)
+	}
+}
+
+func (s *snapshotServer) handleContent(w http.ResponseWriter, r *http.Request) {
+	if c, ok := s.find(w, r.PathValue("id")); ok {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(c.content))
+	}
+}
+
+func (s *snapshotServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+// handleMetrics writes the server's counters in the Prometheus text format
+func (s *snapshotServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	s.statsMu.Lock()
+	stats := s.stats
+	s.statsMu.Unlock()
+
+	var b strings.Builder
+	metric := func(name, kind, help string, value interface{}) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("codesnap_snapshots_total", "counter", "Snapshots built since the server started.", stats.snapshots)
+	metric("codesnap_last_snapshot_duration_seconds", "gauge", "Time the last snapshot took to build.", stats.lastDuration.Seconds())
+	var last int64
+	if !stats.lastSnapshot.IsZero() {
+		last = stats.lastSnapshot.Unix()
+	}
+	metric("codesnap_last_snapshot_timestamp_seconds", "gauge", "Unix time the last snapshot was started.", last)
+	metric("codesnap_watched_files", "gauge", "Files selected by the config in the last snapshot.", stats.files)
+	metric("codesnap_included_files", "gauge", "Files included in the last snapshot.", stats.included)
+	metric("codesnap_skipped_files", "gauge", "Files skipped by the last snapshot.", stats.skipped)
+	metric("codesnap_read_cache_hits_total", "counter", "Files taken from the read cache.", stats.cacheHits)
+	metric("codesnap_read_cache_misses_total", "counter", "Files read because they were not in the read cache or changed.", stats.cacheMisses)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes an error in the shape OpenAI clients expect
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"message": message, "type": "invalid_request_error"},
+	})
+}
+
+// serve exposes the snapshot through a read-only, files API compatible
+// endpoint, along with /healthz and Prometheus /metrics, until the process
+// is stopped. transform, if set, is applied to
+// every chunk, e.g. to anonymize it.
+func (cs *CodeSnap) serve(addr string, transform func(string) string) error {
+	// Every request collects the snapshot again; its progress would fill
+	// the terminal
+	cs.quiet = true
+	s := &snapshotServer{cs: cs, transform: transform}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/files", s.handleList)
+	mux.HandleFunc("GET /v1/files/{id}", s.handleRetrieve)
+	mux.HandleFunc("GET /v1/files/{id}/content", s.handleContent)
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+
+	fmt.Printf(T("Serving snapshot at http://%s/v1/files (press Ctrl+C to stop)\n"), addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		return fmt.Errorf(T("server failed: %v"), err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package codesnap
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer runs codesnap serve in dir and waits until it answers
+// requests; stdout collects what the server prints
+func startServer(t *testing.T, dir string) (addr string, cmd *exec.Cmd, stdout *bytes.Buffer) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr = l.Addr().String()
+	l.Close()
+
+	cmd = exec.Command(os.Args[0], "serve", "--addr", addr)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), mainEnv+"=1")
+	stdout = &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stdout
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		var resp *http.Response
+		if resp, err = http.Get("http://" + addr + "/healthz"); err == nil {
+			resp.Body.Close()
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("server did not answer: %v", err)
+	}
+	return addr, cmd, stdout
+}
+
+func TestServeRequestsAreQuiet(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"codesnap.yml": "folders:\n  - path: .\n", "a.go": "package a\n"})
+	addr, cmd, stdout := startServer(t, dir)
+
+	resp, err := http.Get("http://" + addr + "/v1/files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	if strings.Contains(stdout.String(), "Processing folder") {
+		t.Errorf("serve logs the folders of every request, got:\n%s", stdout.String())
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"codesnap.yml": "folders:\n  - path: .\nignore:\n  - codesnap.yml\nread_cache: true\n",
+		"a.go":         "package a\n",
+		"b.go":         "package b\n",
+		"logo.png":     "\x89PNG\x00\x00",
+	})
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	addr, _, _ := startServer(t, dir)
+
+	for _, tc := range []struct {
+		path, contentType string
+		files             int // requests of /v1/files before this one
+		want              []string
+	}{
+		{"/healthz", "text/plain; charset=utf-8", 0, []string{"ok\n"}},
+		{"/metrics", "text/plain; version=0.0.4; charset=utf-8", 0, []string{
+			"# HELP codesnap_snapshots_total Snapshots built since the server started.\n# TYPE codesnap_snapshots_total counter\ncodesnap_snapshots_total 0\n",
+			"\ncodesnap_last_snapshot_timestamp_seconds 0\n",
+		}},
+		{"/metrics", "text/plain; version=0.0.4; charset=utf-8", 2, []string{
+			"\ncodesnap_snapshots_total 2\n", "\ncodesnap_watched_files 3\n", "\ncodesnap_included_files 2\n", "\ncodesnap_skipped_files 1\n",
+			"\ncodesnap_read_cache_hits_total ", "\ncodesnap_read_cache_misses_total ",
+		}},
+	} {
+		for i := 0; i < tc.files; i++ {
+			resp, err := http.Get("http://" + addr + "/v1/files")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+		}
+		resp, err := http.Get("http://" + addr + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != tc.contentType {
+			t.Errorf("%s: status %d, content type %q", tc.path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(string(body), s) {
+				t.Errorf("%s lacks %q, got:\n%s", tc.path, s, body)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package codesnap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// sinkProtocol is the version of the handshake with sink commands
+const sinkProtocol = 1
+
+// Sink is a named destination for snapshots, implemented by an external
+// command and selected with --sink:
+//
+//	sinks:
+//	  paste:
+//	    cmd: my-uploader --project foo
+//
+// The command reads a single line of JSON (sinkHeader) followed by the
+// snapshot, exactly header.bytes long, on stdin. It may answer with a
+// JSON sinkResponse on stdout; other output is shown as it is.
+type Sink struct {
+	Cmd string `yaml:"cmd"`
+}
+
+// sinkHeader is the first line a sink command reads
+type sinkHeader struct {
+	Protocol int    `json:"protocol"`
+	Sink     string `json:"sink"`
+	snapshotMetadata
+}
+
+// sinkResponse is what a sink command may print when it is done
+type sinkResponse struct {
+	Location string `json:"location"` // where the snapshot ended up, e.g. a URL
+	Message  string `json:"message"`
+}
+
+func (s Sink) validate(name string) error {
+	if strings.TrimSpace(s.Cmd) == "" {
+		return fmt.Errorf(T("sink %q needs a cmd"), name)
+	}
+	return nil
+}
+
+// sendToSink delivers the snapshot to the named sink and returns its
+// response. A failing command is an error.
+func (cs *CodeSnap) sendToSink(name, content string, meta snapshotMetadata) (sinkResponse, error) {
+	sink, ok := cs.config.Sinks[name]
+	if !ok {
+		names := make([]string, 0, len(cs.config.Sinks))
+		for n := range cs.config.Sinks {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		available := strings.Join(names, ", ")
+		if available == "" {
+			available = "none"
+		}
+		return sinkResponse{}, fmt.Errorf(T("unknown sink %q (available: %s)"), name, available)
+	}
+
+	header, err := json.Marshal(sinkHeader{Protocol: sinkProtocol, Sink: name, snapshotMetadata: meta})
+	if err != nil {
+		return sinkResponse{}, err
+	}
+
+	cmd := shellCommand(sink.Cmd)
+	cmd.Dir = cs.configDir
+	cmd.Env = append(os.Environ(), "CODESNAP_SINK="+name)
+	cmd.Stdin = io.MultiReader(bytes.NewReader(append(header, '\n')), strings.NewReader(content))
+	var stdout bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return sinkResponse{}, fmt.Errorf(T("sink %q failed: %v"), name, err)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	var response sinkResponse
+	if len(out) > 0 && (out[0] != '{' || json.Unmarshal(out, &response) != nil) {
+		response = sinkResponse{Message: string(out)}
+	}
+	return response, nil
+}
@@ -0,0 +1,81 @@
+package codesnap
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSink(t *testing.T) {
+	for _, tc := range []struct {
+		name, sinks, script string
+		ran                 string // the arguments of the command
+		args                []string
+		code                int
+		want                []string
+	}{
+		{"a location and a message", "  paste:\n    cmd: uploader --project foo\n",
+			`echo '{"location": "https://paste.example.com/abc", "message": "expires in 7 days"}'`, "--project foo", nil, 0,
+			[]string{"Snapshot sent to paste: https://paste.example.com/abc\nexpires in 7 days\n"}},
+		{"plain output", "  paste:\n    cmd: uploader\n", "echo uploaded", "", nil, 0,
+			[]string{"Snapshot sent to paste\nuploaded\n"}},
+		{"a failing command", "  paste:\n    cmd: uploader\n", "exit 4", "", nil, exitError,
+			[]string{`sink "paste" failed: exit status 4`}},
+		{"an unknown sink", "  paste:\n    cmd: uploader\n  wiki:\n    cmd: uploader\n", "", "", []string{"--sink", "ticket"}, exitError,
+			[]string{`unknown sink "ticket" (available: paste, wiki)`}},
+		{"no cmd", "  paste:\n    cmd: \" \"\n", "", "", nil, exitError, []string{`sink "paste" needs a cmd`}},
+		{"with -O", "  paste:\n    cmd: uploader\n", "", "", []string{"-O", "out.txt"}, exitError, []string{"--sink"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := t.TempDir()
+			t.Setenv("OUT", out)
+			fakeCommand(t, "uploader", "cat > \"$OUT/stdin\"\necho \"$CODESNAP_SINK $* $(pwd)\" > \"$OUT/env\"\n"+tc.script+"\n")
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\nsinks:\n" + tc.sinks,
+				"a.go":         "package a\n",
+			})
+			args := tc.args
+			if len(args) == 0 || args[0] != "--sink" {
+				args = append([]string{"--sink", "paste"}, args...)
+			}
+			r := runCodesnap(t, dir, args...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout+r.stderr, s) {
+					t.Errorf("output lacks %q, got:\n%s%s", s, r.stdout, r.stderr)
+				}
+			}
+			if tc.code != 0 {
+				return
+			}
+			if r.clipboard != "" {
+				t.Errorf("the snapshot was also copied")
+			}
+
+			stdin, err := os.ReadFile(filepath.Join(out, "stdin"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			line, content, _ := bytes.Cut(stdin, []byte("\n"))
+			var header sinkHeader
+			if err := json.Unmarshal(line, &header); err != nil {
+				t.Fatalf("invalid header %s: %v", line, err)
+			}
+			if header.Protocol != sinkProtocol || header.Sink != "paste" || header.Files != 1 || header.Bytes != len(content) {
+				t.Errorf("header = %+v, want protocol %d, sink paste, 1 file and %d bytes", header, sinkProtocol, len(content))
+			}
+			if !bytes.Contains(content, []byte("File: a.go\n")) {
+				t.Errorf("the sink got no snapshot:\n%s", content)
+			}
+			env, _ := os.ReadFile(filepath.Join(out, "env"))
+			if want := "paste " + tc.ran + " " + dir + "\n"; string(env) != want {
+				t.Errorf("the sink ran as %q, want %q", env, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// splitPart is one output file of a split snapshot
+type splitPart struct {
+	Name   string   `json:"name"`
+	File   string   `json:"file"`
+	Folder string   `json:"folder"`
+	Tokens int      `json:"estimated_tokens"`
+	Files  []string `json:"files"`
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// partName derives a file name from a configured folder
+func (cs *CodeSnap) partName(folder FolderEntry) string {
+	name := strings.Trim(filepath.ToSlash(folder.String()), "./")
+	if name == "" {
+		name = filepath.Base(cs.folderPath(folder))
+	}
+	return strings.Trim(unsafeNameChars.ReplaceAllString(name, "-"), "-")
+}
+
+// splitByFolder writes one snapshot per configured folder, plus one for the
+// individually configured files, and an index of them into dir (default: a
+// new timestamped directory). It returns the directory and the total bytes
+// and estimated tokens written.
+func (cs *CodeSnap) splitByFolder(dir string, anon *anonymizer) (string, int, int, error) {
+	if dir == "" {
+		dir = fmt.Sprintf("codesnap_%s", time.Now().Format("20060102_150405"))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, 0, fmt.Errorf(T("failed to create output directory: %v"), err)
+	}
+
+	ext := map[string]string{"markdown": ".md", "json": ".json"}[cs.format]
+	if ext == "" {
+		ext = ".txt"
+	}
+
+	folders, files := cs.config.Folders, cs.config.Files
+	defer func() { cs.config.Folders, cs.config.Files = folders, files }()
+
+	type group struct {
+		name   string
+		label  string
+		folder []FolderEntry
+		files  []string
+	}
+	var groups []group
+	for _, folder := range folders {
+		groups = append(groups, group{cs.partName(folder), folder.String(), []FolderEntry{folder}, nil})
+	}
+	if len(files) > 0 {
+		groups = append(groups, group{"files", "individual files", nil, files})
+	}
+
+	var parts []splitPart
+	var read []fileResult // as read, for codesnap render
+	var total runStats
+	size, tokens := 0, 0
+	used := map[string]int{"index": 1}
+	for _, g := range groups {
+		cs.config.Folders, cs.config.Files = g.folder, g.files
+		results := cs.readAll(cs.gatherFiles())
+		read = append(read, results...)
+		cs.processResults(results)
+		content, err := cs.render(results)
+		if err != nil {
+			fmt.Printf(T("Warning: skipping %s: %v\n"), g.label, err)
+			continue
+		}
+		if anon != nil {
+			content = anon.apply(content)
+		}
+
+		// Folders with the same name, or named index, get a numeric suffix
+		name := g.name
+		if used[name]++; used[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, used[name])
+		}
+		file := name + ext
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			return "", 0, 0, fmt.Errorf(T("failed to save content to file: %v"), err)
+		}
+
+		part := splitPart{Name: name, File: file, Folder: g.label, Tokens: estimateTokens(content)}
+		for _, r := range results {
+			if r.err == nil {
+				part.Files = append(part.Files, r.relPath)
+			}
+		}
+		parts = append(parts, part)
+		size += len(content)
+		tokens += part.Tokens
+		total.processed += cs.stats.processed
+		total.empty += cs.stats.empty
+		total.skipped += cs.stats.skipped
+		total.duplicates += cs.stats.duplicates
+		total.tokens += cs.stats.tokens
+	}
+	cs.stats = total
+	if err := cs.saveLastRun(read); err != nil {
+		fmt.Printf(T("Warning: %v\n"), err)
+	}
+	if len(parts) == 0 {
+		return "", 0, 0, nothingCollected{errors.New(T("no valid files were processed"))}
+	}
+
+	index := cs.splitIndex(parts)
+	if anon != nil {
+		index = anon.apply(index)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index"+ext), []byte(index), 0644); err != nil {
+		return "", 0, 0, fmt.Errorf(T("failed to save content to file: %v"), err)
+	}
+	return dir, size + len(index), tokens + estimateTokens(index), nil
+}
+
+// splitIndex lists the parts of a split snapshot in the selected format
+func (cs *CodeSnap) splitIndex(parts []splitPart) string {
+	var b strings.Builder
+	switch cs.format {
+	case "json":
+		data, _ := json.MarshalIndent(struct {
+			Parts []splitPart `json:"parts"`
+		}{parts}, "", "  ")
+		return string(data) + "\n"
+	case "markdown":
+		b.WriteString("# Snapshot index\n\n")
+		for _, p := range parts {
+			b.WriteString(fmt.Sprintf("## [%s](%s)\n\n%s, %d files, ~%s tokens\n\n", p.Name, p.File, p.Folder, len(p.Files), formatCount(p.Tokens)))
+			for _, f := range p.Files {
+				b.WriteString(fmt.Sprintf("- `%s`\n", f))
+			}
+			b.WriteString("\n")
+		}
+	default:
+		b.WriteString("Snapshot index\n\n")
+		for _, p := range parts {
+			b.WriteString(fmt.Sprintf("%s: %s, %d files, ~%s tokens\n", p.File, p.Folder, len(p.Files), formatCount(p.Tokens)))
+			for _, f := range p.Files {
+				b.WriteString(fmt.Sprintf("    %s\n", f))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestPartName(t *testing.T) {
+	cs := &CodeSnap{configDir: "/work/project"}
+	for _, tc := range []struct {
+		folder FolderEntry
+		want   string
+	}{
+		{FolderEntry{Path: "src"}, "src"},
+		{FolderEntry{Path: "./internal/api/"}, "internal-api"},
+		{FolderEntry{Path: "."}, "project"},
+		{FolderEntry{Path: "web app (old)"}, "web-app-old"},
+		{FolderEntry{Path: "src", Label: "feature"}, "feature"},
+	} {
+		if got := cs.partName(tc.folder); got != tc.want {
+			t.Errorf("partName(%+v) = %q, want %q", tc.folder, got, tc.want)
+		}
+	}
+}
+
+func TestSplitByFolder(t *testing.T) {
+	for _, tc := range []struct {
+		name, format string
+		parts        []string
+		index        []string
+	}{
+		{"text", "text", []string{"index.txt", "files.txt", "index-2.txt", "src.txt"},
+			[]string{"src.txt: src, 1 files, ~", "    src/a.go\n", "index-2.txt: index, 1 files", "files.txt: individual files, 1 files"}},
+		{"markdown", "markdown", []string{"index.md", "files.md", "index-2.md", "src.md"},
+			[]string{"# Snapshot index\n", "## [src](src.md)\n\nsrc, 1 files, ~", "- `src/a.go`\n"}},
+		{"json", "json", []string{"index.json", "files.json", "index-2.json", "src.json"},
+			[]string{`"file": "src.json"`, `"folder": "src"`, `"files": [`}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - src\n  - index\n  - logs\nfiles:\n  - main.go\nignore:\n  - \"**/*.log\"\n",
+				"src/a.go":     "package a\n",
+				"index/i.go":   "package index\n",
+				"main.go":      "package main\n",
+				"logs/app.log": "started\n",
+			})
+			out := filepath.Join(t.TempDir(), "split")
+			r := runCodesnap(t, dir, "--split-by", "folder", "--format", tc.format, "-O", out)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			if !strings.Contains(r.stdout, "Split snapshot saved to: "+out) || !strings.Contains(r.stdout, "Warning: skipping logs") {
+				t.Errorf("output does not name the directory and the skipped folder, got:\n%s", r.stdout)
+			}
+
+			entries, err := os.ReadDir(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			want := append([]string(nil), tc.parts...)
+			sort.Strings(want)
+			if strings.Join(names, " ") != strings.Join(want, " ") {
+				t.Fatalf("parts = %v, want %v", names, want)
+			}
+			index, err := os.ReadFile(filepath.Join(out, tc.parts[0]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tc.index {
+				if !strings.Contains(string(index), s) {
+					t.Errorf("index lacks %q, got:\n%s", s, index)
+				}
+			}
+			part, err := os.ReadFile(filepath.Join(out, tc.parts[3]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(part), "package a") || strings.Contains(string(part), "package main") {
+				t.Errorf("the src part has other files, got:\n%s", part)
+			}
+		})
+	}
+
+	dir := writeFiles(t, map[string]string{"codesnap.yml": "folders:\n  - .\n", "a.go": "package a\n"})
+	for _, args := range [][]string{{"--split-by", "file"}, {"--split-by", "folder", "--with-tree"}} {
+		if r := runCodesnap(t, dir, args...); r.code != exitError {
+			t.Errorf("%v: exit code %d, want %d; output: %s%s", args, r.code, exitError, r.stdout, r.stderr)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// languageStats are the totals of one language in codesnap stats
+type languageStats struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	Code     int    `json:"code"`
+	Comments int    `json:"comments"`
+	Blank    int    `json:"blank"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// statsReport is the output of codesnap stats --json
+type statsReport struct {
+	Languages []languageStats `json:"languages"`
+	Total     languageStats   `json:"total"`
+	Skipped   int             `json:"skipped"`
+}
+
+// add counts a file of content into s. Comment lines are the non-blank
+// lines that --strip-comments would drop, so languages it does not know
+// count all their lines as code.
+func (s *languageStats) add(content string, size int64) {
+	s.Files++
+	s.Bytes += size
+	blank, nonBlank := countLines(content)
+	s.Blank += blank
+	code := nonBlank
+	if syntax, ok := commentSyntaxes[s.Language]; ok {
+		_, code = countLines(string(stripComments([]byte(content), syntax)))
+	}
+	s.Code += code
+	s.Comments += nonBlank - code
+}
+
+func (s *languageStats) merge(o languageStats) {
+	s.Files += o.Files
+	s.Code += o.Code
+	s.Comments += o.Comments
+	s.Blank += o.Blank
+	s.Bytes += o.Bytes
+}
+
+// countLines returns the number of blank and non-blank lines of content
+func countLines(content string) (blank, nonBlank int) {
+	for content != "" {
+		line, rest, _ := strings.Cut(content, "\n")
+		if strings.TrimSpace(line) == "" {
+			blank++
+		} else {
+			nonBlank++
+		}
+		content = rest
+	}
+	return blank, nonBlank
+}
+
+// codeStats reads the configured files and totals them per language,
+// largest first by lines of code
+func (cs *CodeSnap) codeStats() (statsReport, error) {
+	paths := cs.gatherFiles()
+	if cs.changedSince != "" || cs.diffHunks != "" || cs.staged {
+		var err error
+		if paths, err = cs.filterChanged(paths); err != nil {
+			return statsReport{}, err
+		}
+	}
+
+	var report statsReport
+	byLanguage := make(map[string]*languageStats)
+	for _, r := range cs.readAll(paths) {
+		if r.err != nil {
+			report.Skipped++
+			continue
+		}
+		language := languageOf(r.path, r.content)
+		s, ok := byLanguage[language]
+		if !ok {
+			s = &languageStats{Language: language}
+			byLanguage[language] = s
+		}
+		s.add(r.content, r.size)
+	}
+	for _, s := range byLanguage {
+		report.Languages = append(report.Languages, *s)
+		report.Total.merge(*s)
+	}
+	sort.Slice(report.Languages, func(i, j int) bool {
+		a, b := report.Languages[i], report.Languages[j]
+		if a.Code != b.Code {
+			return a.Code > b.Code
+		}
+		return a.Language < b.Language
+	})
+	report.Total.Language = "total"
+	return report, nil
+}
+
+// showStats prints the per-language totals as a table, or as JSON
+func (cs *CodeSnap) showStats(asJSON bool) error {
+	report, err := cs.codeStats()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		if report.Languages == nil {
+			report.Languages = []languageStats{}
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, T("Language\tFiles\tCode\tComments\tBlank\tSize\t"))
+	row := func(name string, s languageStats) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t\n", name, s.Files, s.Code, s.Comments, s.Blank, formatSize(s.Bytes))
+	}
+	for _, s := range report.Languages {
+		name := s.Language
+		if name == "" {
+			name = T("other")
+		}
+		row(name, s)
+	}
+	row(T("Total"), report.Total)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if report.Skipped > 0 {
+		fmt.Printf(T("%d files were skipped (binary, not text or unreadable) and are not counted\n"), report.Skipped)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package codesnap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLanguageStats(t *testing.T) {
+	for _, tc := range []struct {
+		language, content string
+		want              languageStats
+	}{
+		{"go", "// Package a\npackage a\n\n/* block\ncomment */\nvar x = 1 // trailing\n", languageStats{Files: 1, Code: 2, Comments: 3, Blank: 1}},
+		{"python", "#!/usr/bin/env python3\n# comment\nprint('# not one')\n\n\n", languageStats{Files: 1, Code: 2, Comments: 1, Blank: 2}},
+		{"", "plain\n  \ntext", languageStats{Files: 1, Code: 2, Blank: 1}},
+		{"", "", languageStats{Files: 1}},
+	} {
+		s := languageStats{Language: tc.language}
+		s.add(tc.content, int64(len(tc.content)))
+		tc.want.Language, tc.want.Bytes = tc.language, int64(len(tc.content))
+		if s != tc.want {
+			t.Errorf("stats of %q = %+v, want %+v", tc.content, s, tc.want)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	files := map[string]string{
+		"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+		"a.go":         "package a\n\n// F does nothing\nfunc F() {}\n",
+		"b.go":         "package a\n",
+		"run.py":       "print(1)\nprint(2)\nprint(3)\nprint(4)\n",
+		"notes":        "plain\n",
+		"logo.png":     "\x89PNG\x00\x00",
+	}
+
+	dir := writeFiles(t, files)
+	r := runCodesnap(t, dir, "stats")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(r.stdout), "\n")
+	for i, want := range []string{
+		"Language Files Code Comments Blank Size",
+		"python 1 4 0 0 36 B",
+		"go 2 3 1 1 51 B",
+		"other 1 1 0 0 6 B",
+		"Total 4 8 1 1 93 B",
+		"1 files were skipped (binary, not text or unreadable) and are not counted",
+	} {
+		if i >= len(lines) || strings.Join(strings.Fields(lines[i]), " ") != want {
+			t.Errorf("line %d is not %q, got:\n%s", i+1, want, r.stdout)
+		}
+	}
+
+	r = runCodesnap(t, dir, "stats", "--json")
+	if r.code != 0 {
+		t.Fatalf("exit code %d, output: %s%s", r.code, r.stdout, r.stderr)
+	}
+	var report statsReport
+	if err := json.Unmarshal([]byte(r.stdout), &report); err != nil {
+		t.Fatalf("stats --json is not JSON: %v\n%s", err, r.stdout)
+	}
+	if len(report.Languages) != 3 || report.Languages[0].Language != "python" || report.Total.Code != 8 || report.Total.Files != 4 || report.Skipped != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	empty := writeFiles(t, map[string]string{"codesnap.yml": files["codesnap.yml"]})
+	if r := runCodesnap(t, empty, "stats", "--json"); r.code != 0 || !strings.Contains(r.stdout, `"languages": []`) {
+		t.Errorf("exit code %d, output of an empty project:\n%s%s", r.code, r.stdout, r.stderr)
+	}
+}
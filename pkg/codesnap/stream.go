@@ -0,0 +1,108 @@
+package codesnap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// snapshotOutput is a destination the snapshot is written to while it is
+// rendered, instead of being assembled in memory first
+type snapshotOutput interface {
+	io.WriteCloser
+	// reserve announces the expected total size before rendering starts
+	reserve(size int64) error
+	// written returns the bytes and estimated tokens written so far
+	written() (int, int)
+}
+
+// outputCounter tallies what was written to a snapshotOutput
+type outputCounter struct {
+	bytes, tokens int
+}
+
+func (c *outputCounter) count(p []byte) {
+	c.bytes += len(p)
+	c.tokens += estimateTokens(string(p))
+}
+
+func (c *outputCounter) written() (int, int) {
+	return c.bytes, c.tokens
+}
+
+// isNamedPipe reports whether path exists and is a FIFO
+func isNamedPipe(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeNamedPipe != 0
+}
+
+// snapshotStream writes a snapshot into a named pipe while it is rendered,
+// so a consumer can start reading before the whole snapshot exists
+type snapshotStream struct {
+	outputCounter
+	file *os.File
+}
+
+// openStream opens the named pipe at path for writing. This blocks until a
+// reader has opened the other end.
+func openStream(path string) (*snapshotStream, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to open named pipe: %v"), err)
+	}
+	return &snapshotStream{file: file}, nil
+}
+
+func (s *snapshotStream) reserve(int64) error { return nil }
+
+func (s *snapshotStream) Write(p []byte) (int, error) {
+	n, err := s.file.Write(p)
+	s.count(p[:n])
+	if err != nil {
+		return n, fmt.Errorf(T("failed to write to named pipe: %v"), err)
+	}
+	return n, nil
+}
+
+// Close closes the pipe, which signals the end of the snapshot to the reader
+func (s *snapshotStream) Close() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf(T("failed to close named pipe: %v"), err)
+	}
+	return nil
+}
+
+// anonymizingOutput pseudonymizes a snapshot on its way to the output
+type anonymizingOutput struct {
+	snapshotOutput
+	anon *anonymizer
+	// pending holds the unfinished last line; anonymization is applied to
+	// whole lines so matches are not split between writes
+	pending []byte
+}
+
+func (a *anonymizingOutput) Write(p []byte) (int, error) {
+	a.pending = append(a.pending, p...)
+	if i := bytes.LastIndexByte(a.pending, '\n'); i >= 0 {
+		lines := string(a.pending[:i+1])
+		a.pending = append(a.pending[:0], a.pending[i+1:]...)
+		if _, err := io.WriteString(a.snapshotOutput, a.anon.apply(lines)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close writes any unfinished line and closes the output
+func (a *anonymizingOutput) Close() error {
+	var err error
+	if len(a.pending) > 0 {
+		_, err = io.WriteString(a.snapshotOutput, a.anon.apply(string(a.pending)))
+		a.pending = nil
+	}
+	if cerr := a.snapshotOutput.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+//go:build !windows
+
+package codesnap
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestOutputNamedPipe(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"text", nil, []string{"File: a.go", "package a", "- Files processed: 2"}},
+		{"anonymized", []string{"--anonymize", "--anonymize-seed", "s"}, []string{"File: a.go", "ANON_"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\nanonymize:\n  patterns:\n    - acme-[a-z]+\n",
+				"a.go":         "package a\n\nconst host = \"acme-db\"\n",
+				"b.go":         "package a\n",
+			})
+			fifo := filepath.Join(t.TempDir(), "snapshot.fifo")
+			if err := syscall.Mkfifo(fifo, 0600); err != nil {
+				t.Skipf("cannot create a named pipe: %v", err)
+			}
+
+			// codesnap blocks until the pipe has a reader
+			read := make(chan string)
+			go func() {
+				f, err := os.Open(fifo)
+				if err != nil {
+					read <- err.Error()
+					return
+				}
+				defer f.Close()
+				content, _ := io.ReadAll(f)
+				read <- string(content)
+			}()
+			r := runCodesnap(t, dir, append(tc.args, "-O", fifo)...)
+			content := <-read
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("the pipe got no %q, got:\n%s", want, content)
+				}
+			}
+			if strings.Contains(content, "acme-db") != (len(tc.args) == 0) {
+				t.Errorf("anonymized: %v, got:\n%s", len(tc.args) > 0, content)
+			}
+			if !strings.Contains(r.stdout, "Snapshot streamed to: "+fifo) {
+				t.Errorf("output does not name the pipe, got:\n%s", r.stdout)
+			}
+			if info, err := os.Stat(fifo); err != nil || info.Mode()&os.ModeNamedPipe == 0 {
+				t.Errorf("the named pipe was replaced: %v", err)
+			}
+		})
+	}
+}
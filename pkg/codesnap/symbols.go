@@ -0,0 +1,169 @@
+package codesnap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// symbol is an entry of the symbol index
+type symbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+}
+
+// ctagsKinds are the ctags kinds listed in the symbol index; variables,
+// members and the like would drown the definitions a reader looks up
+var ctagsKinds = map[string]bool{
+	"function": true, "method": true, "class": true, "struct": true, "interface": true,
+	"type": true, "typedef": true, "enum": true, "trait": true, "module": true,
+}
+
+// buildSymbolIndex lists the exported functions, methods and types of the
+// included Go files, parsed with go/ast, and the definitions ctags finds in
+// the other files if it is installed. The index is sorted by name.
+func buildSymbolIndex(files []graphFile) []symbol {
+	var symbols []symbol
+	var others []graphFile
+	for _, f := range files {
+		if strings.HasSuffix(f.path, ".go") {
+			symbols = append(symbols, goSymbols(f)...)
+		} else {
+			others = append(others, f)
+		}
+	}
+	symbols = append(symbols, ctagsSymbols(others)...)
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].Name != symbols[j].Name {
+			return symbols[i].Name < symbols[j].Name
+		}
+		return symbols[i].Path < symbols[j].Path
+	})
+	return symbols
+}
+
+// goSymbols returns the exported top-level declarations of a Go file.
+// Methods are named Type.Method.
+func goSymbols(f graphFile) []symbol {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, f.path, f.content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var symbols []symbol
+	add := func(name, kind string, pos token.Pos) {
+		symbols = append(symbols, symbol{name, kind, f.relPath, fset.Position(pos).Line})
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil {
+				add(d.Name.Name, "func", d.Pos())
+			} else if recv := receiverName(d.Recv.List[0].Type); ast.IsExported(recv) {
+				add(recv+"."+d.Name.Name, "method", d.Pos())
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if !ts.Name.IsExported() {
+					continue
+				}
+				kind := "type"
+				switch ts.Type.(type) {
+				case *ast.StructType:
+					kind = "struct"
+				case *ast.InterfaceType:
+					kind = "interface"
+				}
+				add(ts.Name.Name, kind, ts.Pos())
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverName returns the type name of a method receiver such as *T or
+// T[K, V]
+func receiverName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// ctagsSymbols runs ctags in cross-reference mode (-x) over the files,
+// which both Universal and Exuberant Ctags support, and returns the
+// definitions of the kinds in ctagsKinds. It returns nothing when ctags is
+// not installed or fails.
+func ctagsSymbols(files []graphFile) []symbol {
+	if len(files) == 0 {
+		return nil
+	}
+	ctags, err := exec.LookPath("ctags")
+	if err != nil {
+		return nil
+	}
+	relPaths := make(map[string]string, len(files))
+	args := []string{"-x"}
+	for _, f := range files {
+		relPaths[f.path] = f.relPath
+		args = append(args, f.path)
+	}
+	out, err := exec.Command(ctags, args...).Output()
+	if err != nil {
+		return nil
+	}
+
+	// Lines are "name kind line file source"
+	var symbols []symbol
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !ctagsKinds[fields[1]] || strings.HasPrefix(fields[0], "_") {
+			continue
+		}
+		rel, ok := relPaths[fields[3]]
+		line, err := strconv.Atoi(fields[2])
+		if !ok || err != nil {
+			continue
+		}
+		symbols = append(symbols, symbol{fields[0], fields[1], rel, line})
+	}
+	return symbols
+}
+
+// renderSymbols lists the index one symbol per line, for the text format
+func renderSymbols(symbols []symbol) string {
+	var b strings.Builder
+	for _, s := range symbols {
+		b.WriteString(fmt.Sprintf("%s (%s): %s:%d\n", s.Name, s.Kind, s.Path, s.Line))
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package codesnap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGoSymbols(t *testing.T) {
+	src := `package shop
+
+type Cart struct{ items []Item }
+
+type Store interface{ Load() }
+
+type ID string
+
+type cache map[string]int
+
+func (c *Cart) Add(item Item) {}
+
+func (c Cart) total() int { return 0 }
+
+func (m Map[K, V]) Get(k K) V { var v V; return v }
+
+func (c cache) Reset() {}
+
+func NewCart() *Cart { return nil }
+
+func helper() {}
+`
+	got := goSymbols(graphFile{path: "/src/shop/cart.go", relPath: "shop/cart.go", content: src})
+	want := []symbol{
+		{"Cart", "struct", "shop/cart.go", 3},
+		{"Store", "interface", "shop/cart.go", 5},
+		{"ID", "type", "shop/cart.go", 7},
+		{"Cart.Add", "method", "shop/cart.go", 11},
+		{"Map.Get", "method", "shop/cart.go", 15},
+		{"NewCart", "func", "shop/cart.go", 19},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("goSymbols = %+v, want %+v", got, want)
+	}
+	if got := goSymbols(graphFile{path: "broken.go", relPath: "broken.go", content: "package {"}); got != nil {
+		t.Errorf("symbols of a file that does not parse: %+v", got)
+	}
+}
+
+func TestCtagsSymbols(t *testing.T) {
+	fakeCommand(t, "ctags", `for f in "$@"; do
+  case "$f" in -x) continue ;; esac
+  echo "Widget           class         3 $f class Widget:"
+  echo "render           method        8 $f     def render(self):"
+  echo "_private         function     12 $f def _private():"
+  echo "count            variable      1 $f count = 0"
+done
+echo "stray            function      1 /elsewhere/x.py def stray():"
+`)
+	files := []graphFile{
+		{path: "/src/app/widget.py", relPath: "app/widget.py"},
+		{path: "/src/lib.rb", relPath: "lib.rb"},
+	}
+	want := []symbol{
+		{"Widget", "class", "app/widget.py", 3},
+		{"render", "method", "app/widget.py", 8},
+		{"Widget", "class", "lib.rb", 3},
+		{"render", "method", "lib.rb", 8},
+	}
+	if got := ctagsSymbols(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("ctagsSymbols = %+v, want %+v", got, want)
+	}
+	if got := ctagsSymbols(nil); got != nil {
+		t.Errorf("ctagsSymbols(nil) = %+v", got)
+	}
+
+	fakeCommand(t, "ctags", "exit 1\n")
+	if got := ctagsSymbols(files); got != nil {
+		t.Errorf("symbols from a failing ctags: %+v", got)
+	}
+}
+
+func TestSymbolIndex(t *testing.T) {
+	fakeCommand(t, "ctags", "exit 1\n")
+	for _, tc := range []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{"Symbol index:\n" + strings.Repeat("=", 50) + "\n\nCart (struct): b/cart.go:3\nCart.Add (method): b/cart.go:5\nRun (func): a.go:3\n"}},
+		{"markdown", []string{"## Symbol index\n\n- `Cart` (struct): b/cart.go:3\n- `Cart.Add` (method): b/cart.go:5\n- `Run` (func): a.go:3\n"}},
+		{"json", []string{`"symbols": [`, `"name": "Cart.Add"`, `"kind": "method"`}},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n\nfunc Run() {}\n",
+				"b/cart.go":    "package b\n\ntype Cart struct{}\n\nfunc (c *Cart) Add() {}\n",
+			})
+			r := runCodesnap(t, dir, "--symbols", "--format", tc.format, "--stdout", "-q")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
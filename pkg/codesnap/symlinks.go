@@ -0,0 +1,85 @@
+package codesnap
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// walkFolder calls fn for every file below root, depth first in lexical
+// order, and does not enter the directories for which skipDir is true.
+// Symlinks to files are reported like files; symlinked directories are only
+// entered with follow_symlinks, and never when they lead back to a directory
+// that is being walked, so a link such as sub/up -> .. cannot loop. Broken
+// links are skipped.
+func (cs *CodeSnap) walkFolder(root string, skipDir func(path string) bool, fn func(path string)) error {
+	real, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	walking := map[string]bool{}
+
+	var walk func(dir, realDir string) error
+	walk = func(dir, realDir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		walking[realDir] = true
+		defer delete(walking, realDir)
+
+		for _, entry := range entries {
+			full := filepath.Join(dir, entry.Name())
+			realEntry := filepath.Join(realDir, entry.Name())
+			isDir := entry.IsDir()
+			if entry.Type()&fs.ModeSymlink != 0 {
+				target, ok := cs.followSymlink(full)
+				if !ok {
+					continue
+				}
+				if isDir = target != ""; isDir {
+					realEntry = target
+				}
+			}
+			if !isDir {
+				fn(full)
+				continue
+			}
+			if skipDir(full) {
+				continue
+			}
+			if walking[realEntry] {
+				cs.logf("Not following symlink %s: it leads back to %s", full, realEntry)
+				continue
+			}
+			if err := walk(full, realEntry); err != nil {
+				cs.logf("Error reading directory %s: %v", full, err)
+			}
+		}
+		return nil
+	}
+	return walk(root, real)
+}
+
+// followSymlink resolves a symlink found during a walk. It returns the
+// target's real path for a directory that should be entered, "" for a file,
+// and false for a broken link or, without follow_symlinks, a directory.
+func (cs *CodeSnap) followSymlink(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		cs.logf("Skipping broken symlink %s", path)
+		return "", false
+	}
+	if !info.IsDir() {
+		return "", true
+	}
+	if !cs.config.FollowSymlinks {
+		cs.logf("Not following symlinked directory %s", path)
+		return "", false
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
@@ -0,0 +1,62 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFollowSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	for _, tc := range []struct {
+		name, options  string
+		args           []string
+		want, unwanted []string
+	}{
+		{"snapshot", "", nil,
+			[]string{"File: src/a.go\n", "File: src/alias.go\n"}, []string{"File: src/lib/", "File: src/up/"}},
+		{"snapshot following", "follow_symlinks: true\n", nil,
+			[]string{"File: src/alias.go\n", "File: src/lib/b.go\n"}, []string{"File: src/up/"}},
+		{"tree", "", []string{"tree"},
+			[]string{"alias.go\n", "lib -> ", "broken -> missing\n", "up -> ..\n"}, []string{"b.go"}},
+		{"tree following", "follow_symlinks: true\n", []string{"tree"},
+			[]string{"alias.go\n", "lib/", "b.go\n", "broken -> missing\n", "up -> ..\n"}, []string{"lib -> "}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"src/a.go":     "package src\n",
+			})
+			outside := writeFiles(t, map[string]string{"lib/b.go": "package lib\n"})
+			for link, target := range map[string]string{
+				"src/alias.go": "a.go",
+				"src/lib":      filepath.Join(outside, "lib"),
+				"src/up":       "..",
+				"src/broken":   "missing",
+			} {
+				if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("output has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
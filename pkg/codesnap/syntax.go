@@ -0,0 +1,86 @@
+package codesnap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// syntaxError is a config-like file that does not parse, found with
+// check_syntax
+type syntaxError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// jsoncNames are JSON files that conventionally allow comments and
+// trailing commas, so a strict parse would flag valid files
+var jsoncNames = []string{"tsconfig*.json", "jsconfig*.json", "devcontainer.json", ".eslintrc.json", "*.code-workspace"}
+
+// checkSyntax parses JSON, YAML and TOML files and returns the parse error,
+// or "" when content is valid or not one of those formats
+func checkSyntax(file, content string) string {
+	slashed := filepath.ToSlash(file)
+	var err error
+	switch languageFor(file) {
+	case "json":
+		if strings.Contains(slashed, "/.vscode/") {
+			return ""
+		}
+		for _, pattern := range jsoncNames {
+			if matched, _ := path.Match(pattern, path.Base(slashed)); matched {
+				return ""
+			}
+		}
+		err = checkJSON(content)
+	case "yaml":
+		err = checkYAML(content)
+	case "toml":
+		var v interface{}
+		_, err = toml.Decode(content, &v)
+	}
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// checkJSON parses content as a single JSON value, reporting the line of a
+// syntax error
+func checkJSON(content string) error {
+	dec := json.NewDecoder(strings.NewReader(content))
+	var v interface{}
+	err := dec.Decode(&v)
+	if err == nil {
+		if _, extra := dec.Token(); extra != io.EOF {
+			err = errors.New("unexpected data after the top-level value")
+		}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line := bytes.Count([]byte(content[:syntaxErr.Offset]), []byte("\n")) + 1
+		return fmt.Errorf("line %d: %v", line, err)
+	}
+	return err
+}
+
+// checkYAML parses every document of a YAML stream
+func checkYAML(content string) error {
+	dec := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var v interface{}
+		if err := dec.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSyntax(t *testing.T) {
+	for _, tc := range []struct {
+		file, content, err string
+	}{
+		{"package.json", `{"name": "a"}`, ""},
+		{"package.json", "{\n  \"name\": \"a\",\n}\n", "line 3: invalid character '}'"},
+		{"data.json", `{"a": 1} {"b": 2}`, "unexpected data after the top-level value"},
+		{"tsconfig.json", "{\n  // comment\n  \"strict\": true,\n}\n", ""},
+		{"tsconfig.base.json", "{,}", ""},
+		{"app/.vscode/settings.json", "{,}", ""},
+		{"config.yml", "a: 1\n---\nb: [2, 3]\n", ""},
+		{"config.yaml", "a: 1\n---\nb: [2\n", "yaml:"},
+		{"Cargo.toml", "[package]\nname = \"a\"\n", ""},
+		{"pyproject.toml", "[tool\nname = 1\n", "toml:"},
+		{"main.go", "package {", ""},
+	} {
+		got := checkSyntax(tc.file, tc.content)
+		if (tc.err == "") != (got == "") || !strings.Contains(got, tc.err) {
+			t.Errorf("checkSyntax(%s, %q) = %q, want %q", tc.file, tc.content, got, tc.err)
+		}
+	}
+}
+
+func TestCheckSyntaxOption(t *testing.T) {
+	for _, tc := range []struct {
+		name, config   string
+		args           []string
+		want, unwanted []string
+	}{
+		{"off", "", nil, []string{"File: broken.json"}, []string{"syntax errors"}},
+		{"text", "check_syntax: true\n", nil,
+			[]string{"- Files with syntax errors: 2\n    broken.json: line 1: ", "    broken.yml: yaml:"}, []string{"good.json:"}},
+		{"json", "check_syntax: true\n", []string{"--format", "json"},
+			[]string{`"syntax_errors": [`, `"path": "broken.json"`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.config,
+				"broken.json":  "{\"a\": }\n",
+				"broken.yml":   "a: [1\n",
+				"good.json":    "{\"a\": 1}\n",
+				"empty.json":   "",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot has %q, got:\n%s", s, r.stdout)
+				}
+			}
+		})
+	}
+}
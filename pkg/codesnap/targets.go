@@ -0,0 +1,113 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// outputTarget is one destination of --to: clipboard, stdout, file=PATH
+// or sink=NAME
+type outputTarget struct {
+	kind  string
+	value string
+}
+
+// parseTargets parses the comma-separated destinations of --to
+func parseTargets(spec string) ([]outputTarget, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	var targets []outputTarget
+	for _, field := range strings.Split(spec, ",") {
+		kind, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch kind {
+		case "clipboard", "stdout":
+			if value != "" {
+				return nil, fmt.Errorf(T("--to %s takes no value"), kind)
+			}
+		case "file", "sink":
+			if value == "" {
+				return nil, fmt.Errorf(T("--to %s needs a value, e.g. %s=%s"), kind, kind, map[string]string{"file": "out.md", "sink": "paste"}[kind])
+			}
+		default:
+			return nil, fmt.Errorf(T("invalid --to target %q (expected clipboard, stdout, file=PATH or sink=NAME)"), field)
+		}
+		targets = append(targets, outputTarget{kind, value})
+	}
+	return targets, nil
+}
+
+// hasStdout reports whether one of the targets is stdout
+func hasStdout(targets []outputTarget) bool {
+	for _, t := range targets {
+		if t.kind == "stdout" {
+			return true
+		}
+	}
+	return false
+}
+
+// errClipboardTarget marks the failure of the clipboard target of --to
+var errClipboardTarget = errors.New("no clipboard backend took the snapshot")
+
+// deliverTo sends the snapshot to each target in turn. A target that
+// fails does not keep the others from getting the snapshot; the failures
+// are returned together.
+func (cs *CodeSnap) deliverTo(targets []outputTarget, content string, meta snapshotMetadata, stdout io.Writer) error {
+	var errs []error
+	for _, t := range targets {
+		switch t.kind {
+		case "clipboard":
+			backend, err := cs.copyWithFallback(content, meta)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: %v", errClipboardTarget, err))
+				continue
+			}
+			printCopied(backend)
+		case "stdout":
+			if _, err := io.WriteString(stdout, content); err != nil {
+				errs = append(errs, fmt.Errorf(T("failed to write to stdout: %v"), err))
+			}
+		case "file":
+			if err := cs.writeSnapshotFile(t.value, content); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if cs.outputPath == "" {
+				cs.outputPath = t.value
+			}
+			fmt.Printf("\n"+T("Content saved to: %s\n"), t.value)
+		case "sink":
+			response, err := cs.sendToSink(t.value, content, meta)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			fmt.Printf("\n"+T("Snapshot sent to %s"), t.value)
+			if response.Location != "" {
+				fmt.Printf(": %s", response.Location)
+			}
+			fmt.Println()
+			if response.Message != "" {
+				fmt.Println(response.Message)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// printCopied reports which clipboard backend took the snapshot
+func printCopied(backend string) {
+	switch backend {
+	case "system":
+		fmt.Println("\n" + T("Successfully copied content to clipboard!"))
+	case "osc52":
+		fmt.Println("\n" + T("Sent content to the terminal's clipboard (OSC 52)"))
+	case "file":
+		// saveToFile named the file
+	default:
+		fmt.Printf("\n"+T("Successfully copied content to clipboard with %s\n"), backend)
+	}
+}
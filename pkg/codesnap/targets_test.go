@@ -0,0 +1,95 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want []outputTarget
+		err  string
+	}{
+		{"", nil, ""},
+		{"clipboard", []outputTarget{{"clipboard", ""}}, ""},
+		{"clipboard, file=out.md,stdout,sink=paste", []outputTarget{{"clipboard", ""}, {"file", "out.md"}, {"stdout", ""}, {"sink", "paste"}}, ""},
+		{"file=a=b.md", []outputTarget{{"file", "a=b.md"}}, ""},
+		{"stdout=x", nil, "--to stdout takes no value"},
+		{"file", nil, "--to file needs a value, e.g. file=out.md"},
+		{"sink=", nil, "--to sink needs a value, e.g. sink=paste"},
+		{"clipboard,printer", nil, `invalid --to target "printer"`},
+	} {
+		got, err := parseTargets(tc.spec)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("parseTargets(%q) error = %v, want %q", tc.spec, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseTargets(%q) = %v, %v; want %v", tc.spec, got, err, tc.want)
+		}
+	}
+}
+
+func TestTo(t *testing.T) {
+	for _, tc := range []struct {
+		name, config string
+		args         []string
+		code         int
+		clipboard    bool
+		stdout, file bool // whether the snapshot went there
+		sink         bool
+		want         string
+	}{
+		{"all targets", "", []string{"--to", "clipboard,file=out.md,stdout,sink=paste"}, 0, true, true, true, true, "Content saved to: out.md"},
+		{"only a file", "", []string{"--to", "file=out.md"}, 0, false, false, true, false, "Content saved to: out.md"},
+		{"only a sink", "", []string{"--to", "sink=paste"}, 0, false, false, false, true, "Snapshot sent to paste"},
+		{"a failing clipboard", "clipboard:\n  - \"false\"\n", []string{"--to", "clipboard,file=out.md"}, exitClipboard, false, false, true, false,
+			"no clipboard backend took the snapshot"},
+		{"a failing sink", "", []string{"--to", "sink=missing,file=out.md"}, exitError, false, false, true, false, `unknown sink "missing"`},
+		{"an invalid target", "", []string{"--to", "printer"}, exitError, false, false, false, false, `invalid --to target "printer"`},
+		{"with --stdout", "", []string{"--to", "file=out.md", "--stdout"}, exitError, false, false, false, false,
+			"--to cannot be combined with -O, --stdout, --sink, --chunk-tokens or --split-by"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := t.TempDir()
+			t.Setenv("OUT", out)
+			fakeCommand(t, "uploader", "cat > \"$OUT/stdin\"\n")
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n  - out.md\nsinks:\n  paste:\n    cmd: uploader\n" + tc.config,
+				"a.go":         "package a\n",
+			})
+			r := runCodesnap(t, dir, tc.args...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if !strings.Contains(r.stdout+r.stderr, tc.want) {
+				t.Errorf("output lacks %q, got:\n%s%s", tc.want, r.stdout, r.stderr)
+			}
+			file, _ := os.ReadFile(filepath.Join(dir, "out.md"))
+			sink, _ := os.ReadFile(filepath.Join(out, "stdin"))
+			for _, target := range []struct {
+				name    string
+				content string
+				want    bool
+			}{
+				{"clipboard", r.clipboard, tc.clipboard},
+				{"stdout", r.stdout, tc.stdout},
+				{"file", string(file), tc.file},
+				{"sink", string(sink), tc.sink},
+			} {
+				if got := strings.Contains(target.content, "File: a.go\n"); got != target.want {
+					t.Errorf("snapshot in %s: %v, want %v", target.name, got, target.want)
+				}
+			}
+			if tc.stdout && strings.Contains(r.stdout, "Content saved to") {
+				t.Errorf("status messages went to stdout:\n%s", r.stdout)
+			}
+		})
+	}
+}
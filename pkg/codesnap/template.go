@@ -0,0 +1,69 @@
+package codesnap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are available to output templates in addition to the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"repeat": strings.Repeat,
+	"trim":   strings.TrimSpace,
+	"upper":  strings.ToUpper,
+}
+
+// templateData is what an output template is executed with
+type templateData struct {
+	// Files are the files of the snapshot in order, without skipped ones
+	Files     []FileResult
+	Stats     Stats
+	Databases []databaseSchema
+	Commands  []commandOutput
+	Notes     []string
+	// Tree is the folder structure with --with-tree or include_tree
+	Tree string
+	// Summary is the summary as the text format lists it
+	Summary string
+}
+
+// loadTemplate parses the output template at path
+func loadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(T("failed to read template: %v"), err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf(T("invalid template: %v"), err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplate renders the results with the configured output template
+func (cs *CodeSnap) renderTemplate(w io.Writer, results []fileResult) error {
+	data := templateData{
+		Stats:     cs.exportStats(),
+		Databases: cs.schemas,
+		Commands:  cs.commands,
+		Notes:     cs.notes,
+		Tree:      cs.tree,
+		Summary:   cs.summaryList(),
+	}
+	for _, r := range results {
+		if r.err == nil {
+			data.Files = append(data.Files, exportResult(r))
+		}
+	}
+
+	b := bufio.NewWriter(w)
+	if err := cs.outputTemplate.Execute(b, data); err != nil {
+		return fmt.Errorf(T("failed to render template: %v"), err)
+	}
+	return b.Flush()
+}
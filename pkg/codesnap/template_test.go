@@ -0,0 +1,59 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name, config, template string
+		args                   []string
+		code                   int
+		want, unwanted         []string
+	}{
+		{"files and stats", "",
+			"{{range .Files}}{{if not .Empty}}<file path=\"{{.RelPath}}\" lang=\"{{.Language}}\" tokens=\"{{.Tokens}}\">\n{{.Content}}</file>\n{{end}}{{end}}{{.Stats.Processed}} files\n",
+			[]string{"--template", "snapshot.tmpl"}, 0,
+			[]string{"<file path=\"a.go\" lang=\"go\" tokens=\"3\">\npackage a\n</file>\n", "2 files\n"}, []string{"empty.txt", "logo.png", "File: a.go"}},
+		{"functions and sections", "",
+			"{{upper \"notes\"}} {{repeat \"-\" 3}}\n{{range .Notes}}{{trim .}}\n{{end}}{{range .Commands}}{{.Command}}: {{.Output}}{{end}}{{.Summary}}",
+			[]string{"--template", "snapshot.tmpl", "--note", "  check a.go  ", "--exec", "echo ran"}, 0,
+			[]string{"NOTES ---\ncheck a.go\necho ran: ran\n", "- Files processed: 2\n"}, nil},
+		{"the tree", "", "{{.Tree}}", []string{"--template", "snapshot.tmpl", "--with-tree"}, 0, []string{"├── a.go\n"}, nil},
+		{"template: in the config", "template: config.tmpl\n", "", nil, 0, []string{"from the config"}, []string{"File: a.go"}},
+		{"--template wins", "template: config.tmpl\n", "{{len .Files}} files", []string{"--template", "snapshot.tmpl"}, 0, []string{"2 files"}, []string{"from the config"}},
+		{"a missing template", "", "", []string{"--template", "missing.tmpl"}, exitError, []string{"failed to read template"}, nil},
+		{"an invalid template", "", "{{range .Files}", []string{"--template", "snapshot.tmpl"}, exitError, []string{"invalid template"}, nil},
+		{"a failing template", "", "{{.Missing}}", []string{"--template", "snapshot.tmpl"}, exitError, []string{"failed to render template"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n  - \"*.tmpl\"\n" + tc.config,
+				"a.go":         "package a\n",
+				"empty.txt":    "",
+				"logo.png":     "\x89PNG\x00",
+				"config.tmpl":  "from the config",
+			}
+			if tc.template != "" {
+				files["snapshot.tmpl"] = tc.template
+			}
+			dir := writeFiles(t, files)
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package codesnap
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// trustsExtension reports whether path is read without the binary probe:
+// with text_detection: extension, files with a known source extension or
+// one listed in text_extensions are taken to be text
+func (cs *CodeSnap) trustsExtension(path string) bool {
+	if cs.config == nil || cs.config.TextDetection != "extension" {
+		return false
+	}
+	if languageFor(path) != "" {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, trusted := range cs.config.TextExtensions {
+		if ext != "" && ext == strings.ToLower(trusted) {
+			return true
+		}
+	}
+	return false
+}
+
+// readTrusted reads a file of the given size, up to limit bytes, with a
+// single read instead of the probe and the reads that follow it. A file
+// that grew since it was stat'ed is read on with readLimited.
+func readTrusted(file *os.File, size, limit int64) ([]byte, bool, error) {
+	// One byte more than expected tells whether the file ends there
+	want := min(size, limit) + 1
+	buf := make([]byte, want)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+	if int64(n) < want {
+		return buf[:n], false, nil
+	}
+	return readLimited(file, buf, limit)
+}
@@ -0,0 +1,85 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadTrusted(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		content   string
+		size      int64 // as stat'ed before reading
+		limit     int64
+		want      string
+		truncated bool
+	}{
+		{"whole file", "one\ntwo\n", 8, 100, "one\ntwo\n", false},
+		{"exactly the limit", "one\ntwo\n", 8, 8, "one\ntwo\n", false},
+		{"over the limit", "one\ntwo\nthree\n", 14, 10, "one\ntwo\n", true},
+		{"grew since the stat", "one\ntwo\nthree\n", 4, 100, "one\ntwo\nthree\n", false},
+		{"grew over the limit", "one\ntwo\nthree\n", 4, 10, "one\ntwo\n", true},
+		{"shrank since the stat", "one\n", 8, 100, "one\n", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "a.txt")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			got, truncated, err := readTrusted(file, tc.size, tc.limit)
+			if err != nil || string(got) != tc.want || truncated != tc.truncated {
+				t.Errorf("readTrusted = %q, %v, %v; want %q, %v", got, truncated, err, tc.want, tc.truncated)
+			}
+		})
+	}
+}
+
+func TestTextDetection(t *testing.T) {
+	for _, tc := range []struct {
+		name, options  string
+		code           int
+		want, unwanted []string
+	}{
+		{"probe", "", 0, []string{"File: notes.txt\n"}, []string{"File: main.go", "File: page.tmpl", "File: blob.bin"}},
+		{"extension", "text_detection: extension\n", 0, []string{"File: main.go\n", "File: notes.txt\n"},
+			[]string{"File: page.tmpl", "File: blob.bin"}},
+		{"text_extensions", "text_detection: extension\ntext_extensions: [.TMPL]\n", 0, []string{"File: main.go\n", "File: page.tmpl\n"},
+			[]string{"File: blob.bin"}},
+		{"text_extensions without extension", "text_extensions: [.tmpl]\n", 0, nil, []string{"File: main.go", "File: page.tmpl"}},
+		{"max_file_size", "text_detection: extension\nmax_file_size: 1KB\n", 0, []string{"File: long.md (truncated: first 1020 B of 1.5 KB)\n"}, []string{"line 300\n"}},
+		{"invalid", "text_detection: name\n", exitError, []string{`invalid text_detection value "name" (expected probe or extension)`}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.options,
+				"main.go":      "package main\n\nconst nul = \"\x00\"\n",
+				"page.tmpl":    "{{.Title}}\x00\n",
+				"blob.bin":     "\x00\x01\x02",
+				"notes.txt":    "plain text\n",
+				"long.md":      strings.Repeat("line\n", 299) + "line 300\n",
+			})
+			r := runCodesnap(t, dir, "--stdout", "-q")
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package codesnap
+
+import (
+	"math"
+	"sort"
+	"unicode"
+)
+
+// Token counts are estimated offline from character counts, so budgeting
+// works without downloading tokenizer data. They are labeled as estimates
+// wherever they are shown.
+const defaultCharsPerToken = 3.7
+
+// charsPerTokenByLanguage adjusts the ratio for languages whose token
+// density differs noticeably from the default: punctuation heavy formats
+// split into more tokens, prose into fewer.
+var charsPerTokenByLanguage = map[string]float64{
+	"json":       3.0,
+	"yaml":       3.3,
+	"xml":        3.1,
+	"html":       3.2,
+	"css":        3.3,
+	"scss":       3.3,
+	"javascript": 3.5,
+	"typescript": 3.5,
+	"go":         3.6,
+	"rust":       3.5,
+	"c":          3.6,
+	"cpp":        3.5,
+	"java":       4.0,
+	"csharp":     3.9,
+	"kotlin":     3.9,
+	"python":     3.8,
+	"ruby":       3.8,
+	"markdown":   4.2,
+	"rst":        4.2,
+}
+
+// estimateTokensFor estimates the number of tokens in content written in
+// language (as returned by languageFor; empty for unknown). CJK and other
+// wide characters typically map to about one token each and are counted
+// separately.
+func estimateTokensFor(content, language string) int {
+	ratio, ok := charsPerTokenByLanguage[language]
+	if !ok {
+		ratio = defaultCharsPerToken
+	}
+	narrow, wide := 0, 0
+	for _, r := range content {
+		if r >= 0x2E80 && (unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)) {
+			wide++
+		} else {
+			narrow++
+		}
+	}
+	return int(math.Ceil(float64(narrow)/ratio)) + wide
+}
+
+// estimateTokens estimates the number of tokens in a whole snapshot
+func estimateTokens(content string) int {
+	return estimateTokensFor(content, "")
+}
+
+// fileTokens is the estimated token count of one included file
+type fileTokens struct {
+	Path   string
+	Tokens int
+}
+
+// fileTokensOf lists the included files by estimated tokens, largest
+// first, so the files that use up most of a context window stand out
+func fileTokensOf(results []fileResult) []fileTokens {
+	var counts []fileTokens
+	for _, r := range results {
+		if r.err != nil || r.empty || r.duplicateOf != "" {
+			continue
+		}
+		counts = append(counts, fileTokens{r.relPath, r.tokens})
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Tokens > counts[j].Tokens
+	})
+	return counts
+}
+
+// sectionTokens is the estimated token count of one configured section
+type sectionTokens struct {
+	Name   string `json:"name"`
+	Files  int    `json:"files"`
+	Tokens int    `json:"estimated_tokens"`
+}
+
+// sectionTokensOf totals the included files per section, in snapshot
+// order. results must have been grouped by groupBySection.
+func sectionTokensOf(results []fileResult) []sectionTokens {
+	var counts []sectionTokens
+	for _, r := range results {
+		if r.err != nil || r.empty || r.duplicateOf != "" {
+			continue
+		}
+		if len(counts) == 0 || counts[len(counts)-1].Name != r.section {
+			counts = append(counts, sectionTokens{Name: r.section})
+		}
+		counts[len(counts)-1].Files++
+		counts[len(counts)-1].Tokens += r.tokens
+	}
+	return counts
+}
@@ -0,0 +1,137 @@
+package codesnap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokensFor(t *testing.T) {
+	for _, tc := range []struct {
+		name, content, language string
+		want                    int
+	}{
+		{"empty", "", "", 0},
+		{"default ratio", strings.Repeat("a", 37), "", 10},
+		{"rounds up", strings.Repeat("a", 38), "", 11},
+		{"unknown language", strings.Repeat("a", 37), "cobol", 10},
+		{"denser json", strings.Repeat("a", 30), "json", 10},
+		{"lighter prose", strings.Repeat("a", 42), "markdown", 10},
+		{"wide characters", "你好世界", "", 4},
+		{"hangul and kana", "안녕 カタカナ", "", 7},
+		{"mixed", "hi 世界", "", 3},
+		{"accents are narrow", "café", "", 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := estimateTokensFor(tc.content, tc.language); got != tc.want {
+				t.Errorf("estimateTokensFor(%q, %q) = %d, want %d", tc.content, tc.language, got, tc.want)
+			}
+		})
+	}
+	if got, want := estimateTokens("package main\n"), estimateTokensFor("package main\n", ""); got != want {
+		t.Errorf("estimateTokens = %d, want the default ratio's %d", got, want)
+	}
+}
+
+func TestFileTokensOf(t *testing.T) {
+	results := []fileResult{
+		{relPath: "small.go", tokens: 3},
+		{relPath: "big.go", tokens: 40},
+		{relPath: "skipped.bin", tokens: 90, err: errBinaryFile},
+		{relPath: "empty.go", empty: true},
+		{relPath: "copy.go", tokens: 40, duplicateOf: "big.go"},
+		{relPath: "also-small.go", tokens: 3},
+	}
+	want := []fileTokens{{"big.go", 40}, {"small.go", 3}, {"also-small.go", 3}}
+	if got := fileTokensOf(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("fileTokensOf = %v, want %v", got, want)
+	}
+}
+
+func TestTokensFlag(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		args           []string
+		want, unwanted []string
+	}{
+		{"listed", []string{"--tokens"}, []string{"- Estimated tokens: ~", "    big.go: ~29\n    a.go: ~3\n", "~32 of them file contents"}, nil},
+		{"not listed", nil, []string{"- Estimated tokens: ~"}, []string{"    big.go: ~"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n",
+				"a.go":         "package a\n",
+				"big.go":       "package a\n\n" + strings.Repeat("var x = 1\n", 9),
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSectionTokensOf(t *testing.T) {
+	results := []fileResult{
+		{relPath: "billing/a.go", section: "Billing", tokens: 10},
+		{relPath: "billing/b.go", section: "Billing", tokens: 5},
+		{relPath: "billing/empty.go", section: "Billing", empty: true},
+		{relPath: "billing/copy.go", section: "Billing", tokens: 10, duplicateOf: "billing/a.go"},
+		{relPath: "auth/logo.png", section: "Auth", err: errBinaryFile},
+		{relPath: "main.go", section: "Other", tokens: 3},
+	}
+	want := []sectionTokens{{"Billing", 2, 15}, {"Other", 1, 3}}
+	if got := sectionTokensOf(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("sectionTokensOf = %+v, want %+v", got, want)
+	}
+	if got := sectionTokensOf(nil); got != nil {
+		t.Errorf("sectionTokensOf(nil) = %+v", got)
+	}
+}
+
+func TestSectionTokensSummary(t *testing.T) {
+	for _, tc := range []struct {
+		name, config string
+		args         []string
+		want         []string
+		unwanted     string
+	}{
+		{"text", "sections:\n  Billing: billing/**\n", nil,
+			[]string{"- Estimated tokens by section:\n    Billing: ~", " (2 files)\n    Other: ~", " (1 files)\n"}, ""},
+		{"json", "sections:\n  Billing: billing/**\n", []string{"--format", "json"},
+			[]string{`"sections": [`, `"name": "Billing"`, `"files": 2`}, ""},
+		{"no sections", "", nil, nil, "by section"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\n" + tc.config,
+				"billing/a.go": "package billing\n",
+				"billing/b.go": "package billing\n\nfunc B() {}\n",
+				"main.go":      "package main\n",
+			})
+			r := runCodesnap(t, dir, append(tc.args, "--stdout", "-q")...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(r.stdout, s) {
+					t.Errorf("snapshot lacks %q, got:\n%s", s, r.stdout)
+				}
+			}
+			if tc.unwanted != "" && strings.Contains(r.stdout, tc.unwanted) {
+				t.Errorf("snapshot has %q, got:\n%s", tc.unwanted, r.stdout)
+			}
+		})
+	}
+}
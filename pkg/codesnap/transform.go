@@ -0,0 +1,233 @@
+package codesnap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Transform rewrites the content of a file before it is validated and
+// included, e.g. to scrub secrets or decode a binary format into text.
+// path is the file's absolute path. Transforms run concurrently for
+// different files and must be safe for concurrent use; files they should
+// not touch are returned unchanged.
+type Transform func(path string, content []byte) ([]byte, error)
+
+// AddTransform appends t to the chain of transforms applied to every file,
+// after those configured with transform_cmd and transforms
+func (cs *CodeSnap) AddTransform(t Transform) {
+	cs.transforms = append(cs.transforms, t)
+}
+
+// TransformCmd pipes the files matching Pattern through a shell command:
+//
+//	transform_cmd:
+//	  - pattern: "**/*.pb"
+//	    run: protoc --decode_raw
+//
+// The content is written to the command's stdin and replaced by its
+// stdout. The file's path is available as $CODESNAP_PATH, the path relative
+// to the config as $CODESNAP_REL_PATH. See Transformer for more options.
+type TransformCmd struct {
+	Pattern string `yaml:"pattern"`
+	Run     string `yaml:"run"`
+}
+
+// transform turns the configured command into a Transform
+func (tc TransformCmd) transform(cs *CodeSnap) Transform {
+	return pipeTransform(cs, []string{tc.Pattern}, tc.Run, 0)
+}
+
+func (tc TransformCmd) validate() error {
+	if tc.Run == "" {
+		return errors.New(T("transform_cmd entry needs a run command"))
+	}
+	if tc.Pattern == "" || !doublestar.ValidatePattern(tc.Pattern) {
+		return fmt.Errorf(T("invalid transform_cmd pattern %q"), tc.Pattern)
+	}
+	return nil
+}
+
+// defaultTransformerTimeout bounds one run of a transforms: command
+const defaultTransformerTimeout = time.Minute
+
+// Transformer is an external command in the transforms: pipeline. Unlike
+// transform_cmd entries, it can match several globs and is killed when it
+// runs longer than Timeout (default: 1m):
+//
+//	transforms:
+//	  - match: ["**/*.ipynb"]
+//	    cmd: jupyter nbconvert --to markdown --stdin --stdout
+//	    timeout: 30s
+type Transformer struct {
+	Match   globList `yaml:"match"`
+	Cmd     string   `yaml:"cmd"`
+	Timeout string   `yaml:"timeout"`
+}
+
+// globList is a single glob or a list of globs
+type globList []string
+
+func (g *globList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*g = globList{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return errors.New(T("match needs a glob or a list of globs"))
+	}
+	*g = list
+	return nil
+}
+
+func (t Transformer) validate() error {
+	if t.Cmd == "" {
+		return errors.New(T("transforms entry needs a cmd"))
+	}
+	if len(t.Match) == 0 {
+		return fmt.Errorf(T("transform %q needs a match glob"), t.Cmd)
+	}
+	for _, p := range t.Match {
+		if !doublestar.ValidatePattern(p) {
+			return fmt.Errorf(T("invalid glob %q in transform %q"), p, t.Cmd)
+		}
+	}
+	if t.Timeout != "" {
+		if _, err := time.ParseDuration(t.Timeout); err != nil {
+			return fmt.Errorf(T("invalid timeout %q in transform %q"), t.Timeout, t.Cmd)
+		}
+	}
+	return nil
+}
+
+// transform turns the configured command into a Transform. validate must
+// have accepted t.
+func (t Transformer) transform(cs *CodeSnap) Transform {
+	timeout := defaultTransformerTimeout
+	if t.Timeout != "" {
+		timeout, _ = time.ParseDuration(t.Timeout)
+	}
+	return pipeTransform(cs, t.Match, t.Cmd, timeout)
+}
+
+// pipeTransform pipes the files matching any of patterns through the shell
+// command run, killing it after timeout unless that is 0. The content is
+// written to its stdin and replaced by its stdout.
+func pipeTransform(cs *CodeSnap, patterns []string, run string, timeout time.Duration) Transform {
+	return func(path string, content []byte) ([]byte, error) {
+		rel := filepath.ToSlash(cs.relPath(path))
+		if !matchesAny(patterns, rel) {
+			return content, nil
+		}
+
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		cmd := shellCommand(run)
+		cmd.Dir = cs.configDir
+		cmd.Env = append(os.Environ(), "CODESNAP_PATH="+path, "CODESNAP_REL_PATH="+rel)
+		cmd.Stdin = bytes.NewReader(content)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf(T("transform %q failed: %v"), run, err)
+		}
+		// Children of the killed shell may keep the output open, so do not
+		// wait for Wait after a timeout
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			cmd.Process.Kill()
+			return nil, fmt.Errorf(T("transform %q timed out after %s"), run, timeout)
+		}
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf(T("transform %q failed: %s"), run, msg)
+			}
+			return nil, fmt.Errorf(T("transform %q failed: %v"), run, err)
+		}
+		return stdout.Bytes(), nil
+	}
+}
+
+// readFile reads a file for the snapshot. Without transforms this is
+// validateFile, answered from the read cache for unchanged files; with
+// transforms the raw content, up to cs.maxFileSize, is passed through the
+// chain, and the result is validated as text. With --staged the content
+// comes from the git index instead of the working tree.
+func (cs *CodeSnap) readFile(path string) (textFile, error) {
+	if len(cs.transforms) == 0 && !cs.staged {
+		if cs.readCache != nil {
+			return cs.readCache.read(path, cs.maxFileSize, cs.strictUTF8, cs.trustsExtension(path))
+		}
+		return validateFile(path, cs.maxFileSize, cs.strictUTF8, cs.trustsExtension(path))
+	}
+
+	var content []byte
+	var truncatedFrom int64
+	if cs.staged {
+		var err error
+		if content, err = cs.stagedContent(path); err != nil {
+			return textFile{}, err
+		}
+		if int64(len(content)) > cs.maxFileSize {
+			truncatedFrom = int64(len(content))
+			content = cutAtLine(content[:cs.maxFileSize])
+		}
+	} else {
+		file, err := openWithRetry(path)
+		if err != nil {
+			return textFile{}, fmt.Errorf("cannot open file: %v", err)
+		}
+		var truncated bool
+		content, truncated, err = readLimited(file, nil, cs.maxFileSize)
+		if err == nil && truncated {
+			var info os.FileInfo
+			if info, err = file.Stat(); err == nil {
+				truncatedFrom = info.Size()
+			}
+		}
+		file.Close()
+		if err != nil {
+			return textFile{}, fmt.Errorf("error reading file: %v", err)
+		}
+	}
+
+	var err error
+
+	for _, t := range cs.transforms {
+		if content, err = t(path, content); err != nil {
+			return textFile{}, err
+		}
+	}
+
+	if bytes.Contains(content, []byte{0}) && (cs.strictUTF8 || !hasUTF16BOM(content)) {
+		return textFile{}, errBinaryFile
+	}
+	text := textFile{valid: true, content: string(content), truncatedFrom: truncatedFrom}
+	if cs.strictUTF8 {
+		if !utf8.Valid(content) {
+			return textFile{}, errInvalidUTF8
+		}
+		return text, nil
+	}
+	text.content, text.encoding = decodeText(content)
+	return text, nil
+}
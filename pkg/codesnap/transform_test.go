@@ -0,0 +1,158 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestTransformCmdValidate(t *testing.T) {
+	for _, tc := range []struct {
+		cmd TransformCmd
+		err string
+	}{
+		{TransformCmd{Pattern: "**/*.pb", Run: "protoc --decode_raw"}, ""},
+		{TransformCmd{Pattern: "**/*.pb"}, "transform_cmd entry needs a run command"},
+		{TransformCmd{Run: "cat"}, `invalid transform_cmd pattern ""`},
+		{TransformCmd{Pattern: "[", Run: "cat"}, `invalid transform_cmd pattern "["`},
+	} {
+		err := tc.cmd.validate()
+		if (tc.err == "") != (err == nil) || (err != nil && err.Error() != tc.err) {
+			t.Errorf("validate(%+v) = %v, want %q", tc.cmd, err, tc.err)
+		}
+	}
+}
+
+func TestTransformCmd(t *testing.T) {
+	for _, tc := range []struct {
+		name, run      string
+		want, unwanted []string
+	}{
+		{"rewrites matching files", "tr a-z A-Z", []string{"HELLO\n", "package a\n"}, []string{"hello"}},
+		{"sees the path", `echo "$CODESNAP_REL_PATH"`, []string{"File: notes/b.txt\n" + strings.Repeat("=", 50) + "\n\nnotes/b.txt\n"}, []string{"hello"}},
+		{"replaces the content", "sed s/hello/bye/", []string{"bye\n"}, []string{"hello"}},
+		{"fails", "echo broken >&2; exit 1", []string{`Skipping notes/b.txt: transform "echo broken >&2; exit 1" failed: broken`, "- Files skipped: 1"}, []string{"hello"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": "folders:\n  - .\nignore:\n  - codesnap.yml\ntransform_cmd:\n  - pattern: \"**/*.txt\"\n    run: " + yamlQuote(tc.run) + "\n",
+				"a.go":         "package a\n",
+				"notes/b.txt":  "hello\n",
+			})
+			r := runCodesnap(t, dir, "--stdout", "-q", "-l")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			// Skipped files are explained in the log, written once there is
+			// something to log
+			out := r.stdout
+			logs, _ := filepath.Glob(filepath.Join(dir, "codesnap_log_*.txt"))
+			for _, path := range logs {
+				log, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out += string(log)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+// yamlQuote quotes s as a YAML double-quoted string
+func yamlQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func TestTransformerValidate(t *testing.T) {
+	for _, tc := range []struct {
+		yaml string
+		err  string
+	}{
+		{"match: \"**/*.ipynb\"\ncmd: nbconvert\n", ""},
+		{"match: [\"**/*.ipynb\", \"*.json\"]\ncmd: nbconvert\ntimeout: 30s\n", ""},
+		{"match: \"*.x\"\n", "transforms entry needs a cmd"},
+		{"cmd: cat\n", `transform "cat" needs a match glob`},
+		{"match: [\"[\"]\ncmd: cat\n", `invalid glob "[" in transform "cat"`},
+		{"match: \"*.x\"\ncmd: cat\ntimeout: soon\n", `invalid timeout "soon" in transform "cat"`},
+		{"match: {a: b}\ncmd: cat\n", "match needs a glob or a list of globs"},
+	} {
+		var tr Transformer
+		err := yaml.Unmarshal([]byte(tc.yaml), &tr)
+		if err == nil {
+			err = tr.validate()
+		}
+		if (tc.err == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%q: %v, want %q", tc.yaml, err, tc.err)
+		}
+	}
+}
+
+func TestTransformers(t *testing.T) {
+	for _, tc := range []struct {
+		name, transforms string
+		want, unwanted   []string
+	}{
+		{"a pipeline in order", "  - match: \"**/*.txt\"\n    cmd: tr a-z A-Z\n  - match: [\"*.md\", \"**/*.txt\"]\n    cmd: sed s/HELLO/bye/\n",
+			[]string{"File: notes/b.txt\n" + strings.Repeat("=", 50) + "\n\nbye\n", "# notes\n"}, []string{"hello", "HELLO", "PACKAGE"}},
+		{"several globs", "  - match: [\"*.go\", \"*.md\"]\n    cmd: tr a-z A-Z\n",
+			[]string{"PACKAGE A\n", "# NOTES\n", "hello\n"}, nil},
+		{"a timeout", "  - match: \"**/*.txt\"\n    cmd: sleep 5\n    timeout: 100ms\n",
+			[]string{`Skipping notes/b.txt: transform "sleep 5" timed out after 100ms`, "- Files skipped: 1"}, []string{"hello"}},
+		{"after transform_cmd", "  - match: \"**/*.txt\"\n    cmd: sed s/HELLO/bye/\n",
+			[]string{"bye\n"}, []string{"hello", "HELLO"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := "folders:\n  - .\nignore:\n  - codesnap.yml\ntransforms:\n" + tc.transforms
+			if tc.name == "after transform_cmd" {
+				config += "transform_cmd:\n  - pattern: \"**/*.txt\"\n    run: tr a-z A-Z\n"
+			}
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": config,
+				"a.go":         "package a\n",
+				"README.md":    "# notes\n",
+				"notes/b.txt":  "hello\n",
+			})
+			start := time.Now()
+			r := runCodesnap(t, dir, "--stdout", "-q", "-l")
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			if elapsed := time.Since(start); elapsed > 4*time.Second {
+				t.Errorf("the run took %s", elapsed)
+			}
+			out := r.stdout
+			logs, _ := filepath.Glob(filepath.Join(dir, "codesnap_log_*.txt"))
+			for _, path := range logs {
+				log, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out += string(log)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
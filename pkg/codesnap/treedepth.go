@@ -0,0 +1,61 @@
+package codesnap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hiddenByDepth counts the files below tree_depth that the tree leaves out
+// although a snapshot would collect them
+type hiddenByDepth struct {
+	files   int
+	dirs    map[string]bool
+	deepest string
+}
+
+func (h *hiddenByDepth) add(cs *CodeSnap, path string) {
+	if cs.hiddenExcluded(path, false) || cs.exclusionOf(path) != "" || !cs.matchesInclude(path) {
+		return
+	}
+	if h.dirs == nil {
+		h.dirs = make(map[string]bool)
+	}
+	h.files++
+	h.dirs[filepath.Dir(path)] = true
+	rel := filepath.ToSlash(cs.relPath(path))
+	if h.deepest == "" || strings.Count(rel, "/") > strings.Count(h.deepest, "/") {
+		h.deepest = rel
+	}
+	cs.logf("Not in the tree (below tree_depth): %s", rel)
+}
+
+// countHidden adds the collectable files at or below path to h. Dependency
+// directories are left out as in a snapshot, without asking about them.
+func (cs *CodeSnap) countHidden(path string, h *hiddenByDepth) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if !info.IsDir() {
+		h.add(cs, path)
+		return
+	}
+	skipDir := func(dir string) bool {
+		return cs.hiddenExcluded(dir, true) || (cs.config.DependencyDirs != "include" && dependencyDirNames[filepath.Base(dir)])
+	}
+	if skipDir(path) {
+		return
+	}
+	cs.walkFolder(path, skipDir, func(full string) { h.add(cs, full) })
+}
+
+// warn tells that the tree is not the complete picture of a snapshot
+func (h *hiddenByDepth) warn(cs *CodeSnap) {
+	if h.files == 0 || cs.quiet {
+		return
+	}
+	fmt.Printf(T("Warning: tree_depth %d hides %d files in %d directories that a snapshot would include (deepest: %s)\n"),
+		cs.config.TreeDepth, h.files, len(h.dirs), h.deepest)
+}
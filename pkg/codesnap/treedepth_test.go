@@ -0,0 +1,72 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTreeDepthHidden(t *testing.T) {
+	for _, tc := range []struct {
+		name, options  string
+		args           []string
+		want, unwanted []string
+		logged         []string
+	}{
+		{"files below the limit", "tree_depth: 2\n", nil,
+			[]string{"- Not shown (below tree_depth 2): 2 files in 2 directories\n",
+				"Warning: tree_depth 2 hides 2 files in 2 directories that a snapshot would include (deepest: a/b/c/deep.go)\n"},
+			[]string{"deep.go\n", "skip.log", ".hidden.go", "index.js"}, nil},
+		{"logged", "tree_depth: 2\n", []string{"-l"}, []string{"- Not shown (below tree_depth 2)"}, nil,
+			[]string{"Not in the tree (below tree_depth): a/b/c/deep.go", "Not in the tree (below tree_depth): a/b/d.go"}},
+		{"quiet", "tree_depth: 2\n", []string{"-q"}, []string{"- Not shown (below tree_depth 2)"}, []string{"Warning"}, nil},
+		{"deep enough", "tree_depth: 5\n", nil, []string{"deep.go\n"}, []string{"Not shown", "Warning"}, nil},
+		{"no limit", "", nil, []string{"deep.go\n"}, []string{"Not shown", "Warning"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml":                "folders:\n  - .\nignore:\n  - codesnap.yml\n  - \"**/*.log\"\ninclude_hidden: false\n" + tc.options,
+				"top.go":                      "package top\n",
+				"a/a.go":                      "package a\n",
+				"a/b/d.go":                    "package b\n",
+				"a/b/c/deep.go":               "package c\n",
+				"a/b/c/skip.log":              "started\n",
+				"a/b/c/.hidden.go":            "package c\n",
+				"a/b/.cache/c.go":             "package c\n",
+				"a/b/node_modules/x/index.js": "module.exports = 1\n",
+			})
+			r := runCodesnap(t, dir, append([]string{"tree", "--stdout"}, tc.args...)...)
+			if r.code != 0 {
+				t.Fatalf("exit code %d, stderr: %s", r.code, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+			if tc.logged == nil {
+				return
+			}
+			logs, _ := filepath.Glob(filepath.Join(dir, "codesnap_log_*.txt"))
+			if len(logs) != 1 {
+				t.Fatalf("log files %v, want one", logs)
+			}
+			log, err := os.ReadFile(logs[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tc.logged {
+				if !strings.Contains(string(log), s) {
+					t.Errorf("log lacks %q, got:\n%s", s, log)
+				}
+			}
+		})
+	}
+}
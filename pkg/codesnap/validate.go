@@ -0,0 +1,186 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v2"
+)
+
+// unknownField matches yaml's strict decoding error for a key that is
+// not part of the config
+var unknownField = regexp.MustCompile(`field (\S+) not found in type \S+`)
+
+// yamlLine is the line prefix of yaml's decoding errors
+var yamlLine = regexp.MustCompile(`^line \d+: `)
+
+// configProblems are the findings of codesnap config validate
+type configProblems struct {
+	errors   []string
+	warnings []string
+}
+
+func (p *configProblems) errorf(format string, args ...interface{}) {
+	p.errors = append(p.errors, fmt.Sprintf(format, args...))
+}
+
+func (p *configProblems) warnf(format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// strictErrors decodes data strictly into a Config and returns the keys
+// it does not know and the values of the wrong type
+func strictErrors(data []byte) ([]string, error) {
+	err := yaml.UnmarshalStrict(data, &Config{})
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages := make([]string, len(typeErr.Errors))
+		for i, msg := range typeErr.Errors {
+			messages[i] = unknownField.ReplaceAllString(msg, `unknown key "$1"`)
+		}
+		return messages, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("invalid YAML format: %v"), err)
+	}
+	return nil, nil
+}
+
+// validateConfig checks the config at cs.configPath without collecting
+// anything: keys and types, the settings a run would reject, the folders
+// and files it names and the ignore rules. Problems that would stop a run
+// are errors; ignore rules that are broken or match no file are warnings.
+func (cs *CodeSnap) validateConfig() (configProblems, error) {
+	var problems configProblems
+	data, err := os.ReadFile(cs.configPath)
+	if err != nil {
+		return problems, fmt.Errorf(T("failed to read config file: %v"), err)
+	}
+
+	messages, err := strictErrors(data)
+	if err != nil {
+		problems.errors = append(problems.errors, err.Error())
+		return problems, nil
+	}
+	problems.errors = append(problems.errors, messages...)
+	// Values of the wrong type fail the decoding below the same way
+	wrongTypes := false
+	for _, msg := range messages {
+		wrongTypes = wrongTypes || !strings.Contains(msg, "unknown key")
+	}
+	// Profiles are kept as plain YAML until one is applied, so their keys
+	// are checked one profile at a time
+	var doc struct {
+		Profiles yaml.MapSlice `yaml:"profiles"`
+	}
+	if yaml.Unmarshal(data, &doc) == nil {
+		for _, item := range doc.Profiles {
+			profile, err := yaml.Marshal(item.Value)
+			if err != nil {
+				continue
+			}
+			messages, err := strictErrors(profile)
+			if err != nil {
+				problems.errorf("profiles.%v: %v", item.Key, err)
+				continue
+			}
+			for _, msg := range messages {
+				// Line numbers refer to the re-encoded profile, not the file
+				msg = yamlLine.ReplaceAllString(msg, "")
+				problems.errorf("profiles.%v: %s", item.Key, msg)
+			}
+		}
+	}
+
+	if wrongTypes {
+		return problems, nil
+	}
+	if err := cs.parseConfig(data); err != nil {
+		problems.errors = append(problems.errors, err.Error())
+		return problems, nil
+	}
+
+	for _, folder := range cs.config.Folders {
+		path := cs.folderPath(folder)
+		if info, err := os.Stat(path); err != nil {
+			problems.errorf(T("folder %s does not exist"), folder.String())
+		} else if !info.IsDir() {
+			problems.errorf(T("folder %s is not a directory"), folder.String())
+		}
+	}
+	for _, file := range cs.config.Files {
+		if info, err := os.Stat(cs.resolvePath(file)); err != nil {
+			problems.errorf(T("file %s does not exist"), file)
+		} else if info.IsDir() {
+			problems.errorf(T("file %s is a directory; list it under folders"), file)
+		}
+	}
+
+	cs.checkIgnoreRules(&problems)
+	return problems, nil
+}
+
+// checkIgnoreRules warns about ignore patterns that are not valid globs,
+// and about rules that match none of the files in the configured folders.
+// Each rule is tried on every file, so a rule that only matches files an
+// earlier rule already ignores is not reported.
+func (cs *CodeSnap) checkIgnoreRules(problems *configProblems) {
+	if len(cs.config.Ignore) == 0 {
+		return
+	}
+	listed, err := cs.listFiles()
+	if err != nil {
+		problems.errors = append(problems.errors, err.Error())
+		return
+	}
+	matched := make([]bool, len(cs.config.Ignore))
+	for _, f := range listed {
+		if info, err := os.Stat(f.path); err != nil || info.IsDir() {
+			continue
+		}
+		relPath := filepath.ToSlash(cs.relPath(f.path))
+		for i := range cs.config.Ignore {
+			if !matched[i] && cs.config.Ignore[i].matches(f.path, relPath) {
+				matched[i] = true
+			}
+		}
+	}
+	for i, rule := range cs.config.Ignore {
+		if rule.Pattern != "" && !strings.HasPrefix(rule.Pattern, "@") && !doublestar.ValidatePattern(filepath.ToSlash(rule.Pattern)) {
+			problems.warnf(T("ignore pattern %q is not a valid glob and never matches"), rule.Pattern)
+		} else if !matched[i] {
+			problems.warnf(T("ignore rule %q matches no file"), rule.String())
+		}
+	}
+}
+
+// showValidation prints the problems of the config, the errors to errOut,
+// and reports whether it is valid. Warnings alone leave it valid.
+func (cs *CodeSnap) showValidation(errOut io.Writer) (bool, error) {
+	problems, err := cs.validateConfig()
+	if err != nil {
+		return false, err
+	}
+	for _, msg := range problems.errors {
+		fmt.Fprintf(errOut, T("Error: %s\n"), msg)
+	}
+	for _, msg := range problems.warnings {
+		fmt.Printf(T("Warning: %s\n"), msg)
+	}
+	switch {
+	case len(problems.errors) > 0:
+		fmt.Printf(T("%s is invalid: %d errors, %d warnings\n"), cs.configPath, len(problems.errors), len(problems.warnings))
+		return false, nil
+	case len(problems.warnings) > 0:
+		fmt.Printf(T("%s is valid, with %d warnings\n"), cs.configPath, len(problems.warnings))
+	default:
+		fmt.Printf(T("%s is valid\n"), cs.configPath)
+	}
+	return true, nil
+}
@@ -0,0 +1,59 @@
+package codesnap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name, config string
+		args         []string
+		code         int
+		want         []string
+		unwanted     []string
+	}{
+		{"valid", "folders:\n  - src\nignore:\n  - \"**/*.log\"\n", nil, 0,
+			[]string{"codesnap.yml is valid\n"}, []string{"Warning", "Error"}},
+		{"warnings", "folders:\n  - src\nignore:\n  - \"*.none\"\n  - \"src/[\"\n  - \"**/*.log\"\n", nil, 0,
+			[]string{`Warning: ignore rule "*.none" matches no file`, `Warning: ignore pattern "src/[" is not a valid glob and never matches`,
+				"codesnap.yml is valid, with 2 warnings\n"}, []string{"**/*.log"}},
+		{"unknown keys and wrong types", "foldres:\n  - src\ntree_depth: deep\nprofiles:\n  review:\n    formt: markdown\n", nil, exitError,
+			[]string{`Error: line 1: unknown key "foldres"`, "Error: line 3: cannot unmarshal !!str `deep` into int", `Error: profiles.review: unknown key "formt"`,
+				"codesnap.yml is invalid: 3 errors, 0 warnings\n"}, nil},
+		{"missing paths", "folders:\n  - src\n  - gone\nfiles:\n  - missing.md\n  - src\n", nil, exitError,
+			[]string{"Error: folder gone does not exist", "Error: file missing.md does not exist", "Error: file src is a directory; list it under folders",
+				"is invalid: 3 errors"}, nil},
+		{"invalid value", "folders:\n  - src\nworkers: -1\n", nil, exitError,
+			[]string{"Error: invalid workers value -1 (expected a positive number)"}, nil},
+		{"invalid yaml", "folders: [src\n", nil, exitError, []string{"Error: invalid YAML format: "}, nil},
+		{"no action", "folders:\n  - src\n", []string{"config"}, exitError, []string{"config requires an action, e.g. codesnap config validate"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": tc.config,
+				"src/a.go":     "package a\n",
+				"src/app.log":  "started\n",
+			})
+			args := tc.args
+			if args == nil {
+				args = []string{"config", "validate"}
+			}
+			r := runCodesnap(t, dir, args...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			out := r.stdout + r.stderr
+			for _, s := range tc.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output lacks %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.unwanted {
+				if strings.Contains(out, s) {
+					t.Errorf("output has %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
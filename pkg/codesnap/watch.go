@@ -0,0 +1,199 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the files have to be quiet after a change
+// before the snapshot is regenerated, so saving many files at once (a
+// checkout, a formatter run) triggers a single snapshot
+const watchDebounce = 300 * time.Millisecond
+
+// watchEnv marks the snapshot runs started by --watch, so a pipeline that
+// enables watch does not start another watcher
+const watchEnv = "CODESNAP_WATCHING"
+
+// watch monitors the configured folders and files and the config itself,
+// and runs codesnap with args once at the start and again after every
+// change. Each run is a separate process, so config edits take effect and
+// a failing run does not stop the watcher. ignored are files written by the
+// runs themselves, such as the -O output.
+func (cs *CodeSnap) watch(args []string, ignored []string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf(T("failed to start watching: %v"), err)
+	}
+	defer watcher.Close()
+
+	cs.quiet = true
+	// Events name absolute paths, so the config is compared by its own
+	config, err := filepath.Abs(cs.configPath)
+	if err != nil {
+		return fmt.Errorf(T("failed to start watching: %v"), err)
+	}
+	dirs := 0
+	for _, folder := range cs.config.Folders {
+		dirs += cs.watchTree(watcher, cs.folderPath(folder))
+	}
+	// Individual files and the config are watched through their directory
+	for _, dir := range cs.watchedFileDirs(config) {
+		if err := watcher.Add(dir); err == nil {
+			dirs++
+		}
+	}
+	if dirs == 0 {
+		return errors.New(T("no folders to watch"))
+	}
+
+	exclude := make(map[string]bool, len(ignored))
+	for _, path := range ignored {
+		if abs, err := filepath.Abs(path); err == nil {
+			exclude[abs] = true
+		}
+	}
+
+	fmt.Printf(T("Watching %d directories for changes (press Ctrl+C to stop)\n"), dirs)
+	runWatched(args)
+
+	var debounce <-chan time.Time
+	var changed string
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && cs.inWatchedFolder(event.Name) {
+					cs.watchTree(watcher, event.Name)
+				}
+			}
+			if event.Has(fsnotify.Chmod) || exclude[event.Name] || !cs.watchRelevant(event.Name, config) {
+				continue
+			}
+			changed = event.Name
+			debounce = time.After(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf(T("Warning: %v\n"), err)
+		case <-debounce:
+			debounce = nil
+			fmt.Printf("\n"+T("Changed: %s\n"), cs.displayPath(changed))
+			runWatched(args)
+		}
+	}
+}
+
+// watchTree watches dir and its subdirectories, except hidden directories
+// left out of snapshots (version control metadata by default), codesnap's
+// own state, ignored directories and large dependency trees. It returns the number of directories watched.
+func (cs *CodeSnap) watchTree(watcher *fsnotify.Watcher, dir string) int {
+	dirs := 0
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && (cs.hiddenExcluded(path, true) || !cs.shouldIncludeFile(path) || cs.isDependencyTree(path)) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			fmt.Printf(T("Warning: cannot watch %s: %v\n"), path, err)
+			return filepath.SkipDir
+		}
+		dirs++
+		return nil
+	})
+	return dirs
+}
+
+// isDependencyTree applies the dependency_dirs heuristic without prompting
+func (cs *CodeSnap) isDependencyTree(dir string) bool {
+	return cs.config.DependencyDirs != "include" && dependencyDirNames[filepath.Base(dir)] &&
+		countEntries(dir, cs.config.DependencyMaxEntries) > cs.config.DependencyMaxEntries
+}
+
+// watchedFileDirs returns the directories of the individually configured
+// files and of the config, given by its absolute path
+func (cs *CodeSnap) watchedFileDirs(config string) []string {
+	seen := map[string]bool{}
+	var dirs []string
+	for _, file := range append([]string{config}, cs.config.Files...) {
+		dir := filepath.Dir(cs.resolvePath(file))
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// inWatchedFolder reports whether path lies inside a configured folder
+func (cs *CodeSnap) inWatchedFolder(path string) bool {
+	for _, folder := range cs.config.Folders {
+		root := cs.folderPath(folder)
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchRelevant reports whether a change to path can change the snapshot:
+// it is the config, a configured file, or a file in a configured folder
+// that passes the include and ignore rules. config is the absolute path of
+// the config.
+func (cs *CodeSnap) watchRelevant(path, config string) bool {
+	if path == config {
+		return true
+	}
+	for _, file := range cs.config.Files {
+		if path == cs.resolvePath(file) {
+			return true
+		}
+	}
+	// Snapshots and logs saved with -o and -l
+	if name := filepath.Base(path); strings.HasPrefix(name, "codesnap_") && strings.HasSuffix(name, ".txt") {
+		return false
+	}
+	return cs.inWatchedFolder(path) && cs.matchesInclude(path) && cs.shouldIncludeFile(path)
+}
+
+// runWatched runs one snapshot in a child process with the same options
+func runWatched(args []string) {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Printf(T("Error: %v\n"), err)
+		return
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), watchEnv+"=1")
+	// A failed run has already reported its error; keep watching
+	cmd.Run()
+}
+
+// withoutWatchFlag returns args without the --watch flag
+func withoutWatchFlag(args []string) []string {
+	var kept []string
+	for _, arg := range args {
+		switch strings.TrimLeft(arg, "-") {
+		case "watch", "watch=true", "watch=1":
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
@@ -0,0 +1,35 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWatchConfigInSubdirectory(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	cs, err := newCodeSnap(filepath.Join("sub", "codesnap.yml"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.config = &Config{}
+	config, err := filepath.Abs(cs.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dirs := cs.watchedFileDirs(config); !slices.Equal(dirs, []string{cs.configDir}) {
+		t.Errorf("watched %v, want the config directory %s", dirs, cs.configDir)
+	}
+	if !cs.watchRelevant(filepath.Join(cs.configDir, "codesnap.yml"), config) {
+		t.Error("a change to the config is not relevant")
+	}
+}
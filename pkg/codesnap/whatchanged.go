@@ -0,0 +1,98 @@
+package codesnap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// readConfigVersion reads a previous version of the config. against is
+// either a file or a git revision and path such as HEAD:codesnap.yml; an
+// empty value means the committed version of the current config.
+func (cs *CodeSnap) readConfigVersion(against string) ([]byte, error) {
+	if against == "" {
+		against = "HEAD:./" + filepath.ToSlash(filepath.Base(cs.configPath))
+	}
+	if _, err := os.Stat(against); err == nil {
+		data, err := os.ReadFile(against)
+		if err != nil {
+			return nil, fmt.Errorf(T("failed to read config file: %v"), err)
+		}
+		return data, nil
+	}
+
+	cmd := exec.Command("git", "show", against)
+	cmd.Dir = cs.configDir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf(T("git show %s failed: %s"), against, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf(T("git show %s failed: %v"), against, err)
+	}
+	return out, nil
+}
+
+// whatChanged prints the files that enter and leave the selection when
+// going from the config version against to the current config
+func (cs *CodeSnap) whatChanged(against string) error {
+	data, err := cs.readConfigVersion(against)
+	if err != nil {
+		return err
+	}
+
+	// Evaluate the old config from the same directory, so relative paths
+	// resolve to the same files
+	previous := &CodeSnap{
+		configPath: cs.configPath,
+		configDir:  cs.configDir,
+		baseDir:    cs.baseDir,
+		profile:    cs.profile,
+		pathStyle:  cs.pathStyle,
+		quiet:      true,
+	}
+	if err := previous.parseConfig(data); err != nil {
+		return err
+	}
+	cs.quiet = true
+
+	before := make(map[string]bool)
+	for _, path := range previous.gatherFiles() {
+		before[path] = true
+	}
+	after := make(map[string]bool)
+	for _, path := range cs.gatherFiles() {
+		after[path] = true
+	}
+
+	var entering, leaving []string
+	for path := range after {
+		if !before[path] {
+			entering = append(entering, cs.displayPath(path))
+		}
+	}
+	for path := range before {
+		if !after[path] {
+			leaving = append(leaving, previous.displayPath(path))
+		}
+	}
+	sort.Strings(entering)
+	sort.Strings(leaving)
+
+	for _, name := range entering {
+		fmt.Printf("+ %s\n", name)
+	}
+	for _, name := range leaving {
+		fmt.Printf("- %s\n", name)
+	}
+	if len(entering)+len(leaving) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf(T("%d entering, %d leaving, %d unchanged\n"), len(entering), len(leaving), len(after)-len(entering))
+	return nil
+}
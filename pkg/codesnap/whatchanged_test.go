@@ -0,0 +1,53 @@
+package codesnap
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWhatChanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	const committed = "folders:\n  - .\nignore:\n  - \"*.yml\"\n  - \"*.md\"\n"
+	for _, tc := range []struct {
+		name, config string
+		args         []string
+		code         int
+		want         string
+	}{
+		{"unchanged", committed, nil, 0, "0 entering, 0 leaving, 2 unchanged\n"},
+		{"entering and leaving", "folders:\n  - .\nignore:\n  - \"*.yml\"\n  - b.go\n", nil, 0,
+			"+ README.md\n- b.go\n\n1 entering, 1 leaving, 1 unchanged\n"},
+		{"against a file", committed, []string{"--against", "old.yml"}, 0,
+			"+ a.go\n+ b.go\n\n2 entering, 0 leaving, 0 unchanged\n"},
+		{"unknown revision", committed, []string{"--against", "nope:codesnap.yml"}, exitError, "git show nope:codesnap.yml failed"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"codesnap.yml": committed,
+				"old.yml":      "folders:\n  - .\nignore:\n  - \"*.yml\"\n  - \"*.md\"\n  - \"*.go\"\n",
+				"README.md":    "# a\n",
+				"a.go":         "package a\n",
+				"b.go":         "package a\n",
+			})
+			git(t, dir, "init", "-q")
+			git(t, dir, "add", ".")
+			git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "a")
+			if err := os.WriteFile(filepath.Join(dir, "codesnap.yml"), []byte(tc.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			r := runCodesnap(t, dir, append([]string{"whatchanged"}, tc.args...)...)
+			if r.code != tc.code {
+				t.Fatalf("exit code %d, want %d; output: %s%s", r.code, tc.code, r.stdout, r.stderr)
+			}
+			if out := r.stdout + r.stderr; !strings.Contains(out, tc.want) {
+				t.Errorf("output lacks %q, got:\n%s", tc.want, out)
+			}
+		})
+	}
+}
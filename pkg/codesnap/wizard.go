@@ -0,0 +1,217 @@
+package codesnap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// projectTypes are recognized by a marker file in the config directory.
+// ignore holds the build output and caches the ecosystem leaves inside
+// source folders.
+var projectTypes = []struct {
+	markers []string
+	name    string
+	ignore  []string
+}{
+	{[]string{"go.mod"}, "Go", nil},
+	{[]string{"package.json"}, "Node.js", []string{"**/dist/**", "**/*.min.js", "**/*.min.css"}},
+	{[]string{"pyproject.toml", "requirements.txt", "setup.py"}, "Python", []string{"**/__pycache__/**", "**/*.egg-info/**"}},
+	{[]string{"Cargo.toml"}, "Rust", []string{"**/target/**"}},
+	{[]string{"Gemfile"}, "Ruby", nil},
+	{[]string{"pom.xml", "build.gradle", "build.gradle.kts"}, "Java", []string{"**/target/**", "**/build/**"}},
+	{[]string{"composer.json"}, "PHP", nil},
+}
+
+// buildDirNames are top-level folders the wizard proposes to leave out, as
+// they usually hold generated output rather than sources
+var buildDirNames = map[string]bool{
+	"dist": true, "build": true, "target": true, "out": true, "bin": true,
+	"obj": true, "coverage": true, "tmp": true, "logs": true,
+}
+
+// wizardSkipGroups are the file groups left out of the proposed root files
+var wizardSkipGroups = []string{"@images", "@video", "@audio", "@fonts", "@archives", "@binaries", "@lockfiles"}
+
+// wizardFolder is a top-level folder offered by the setup wizard
+type wizardFolder struct {
+	name     string
+	files    int
+	bytes    int64
+	selected bool
+}
+
+// wizardConfig is what the setup wizard fills in of the template
+type wizardConfig struct {
+	Folders []string `yaml:"folders"`
+	Files   []string `yaml:"files,omitempty"`
+	Ignore  []string `yaml:"ignore,omitempty"`
+}
+
+// setupWizard builds a first config interactively: it detects the project
+// type, lists the top-level folders with their size and lets the user
+// toggle them. It reports false when the user declines, so the plain
+// template is written instead.
+func (cs *CodeSnap) setupWizard(in io.Reader) (bool, error) {
+	reader := bufio.NewReader(in)
+	var types, ignore []string
+	for _, t := range projectTypes {
+		for _, marker := range t.markers {
+			if _, err := os.Stat(filepath.Join(cs.configDir, marker)); err == nil {
+				types = append(types, t.name)
+				ignore = append(ignore, t.ignore...)
+				break
+			}
+		}
+	}
+	ignore = append(ignore, "@lockfiles")
+
+	folders, files, rootBytes, err := cs.wizardCandidates()
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Printf(T("No codesnap.yml found. Setting one up for %s\n"), cs.configDir)
+	if len(types) > 0 {
+		fmt.Printf(T("Detected project type: %s\n"), strings.Join(types, ", "))
+	}
+	if len(files) > 0 {
+		fmt.Printf(T("Files in the project root: %s\n"), strings.Join(files, ", "))
+	}
+	for {
+		printWizardFolders(folders, rootBytes)
+		if len(folders) == 0 {
+			break
+		}
+		fmt.Print(T("Toggle folders by number (e.g. 2 3), or press Enter to continue: "))
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			if err != nil && err != io.EOF {
+				return false, err
+			}
+			break
+		}
+		for _, field := range strings.Fields(strings.ReplaceAll(answer, ",", " ")) {
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 || n > len(folders) {
+				fmt.Printf(T("No folder %s\n"), field)
+				continue
+			}
+			folders[n-1].selected = !folders[n-1].selected
+		}
+	}
+
+	cfg := wizardConfig{Files: files, Ignore: ignore}
+	for _, f := range folders {
+		if f.selected {
+			cfg.Folders = append(cfg.Folders, f.name)
+		}
+	}
+	if len(cfg.Folders) == 0 && len(cfg.Files) == 0 {
+		fmt.Println(T("Nothing selected."))
+		return false, nil
+	}
+	fmt.Printf(T("Write %s? [Y/n] "), cs.configPath)
+	answer, _ := reader.ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "" && answer != "y" && answer != "yes" {
+		return false, nil
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return false, err
+	}
+	// The filled in settings replace the empty ones at the end of the
+	// template, which keeps its documentation of the other settings
+	content := strings.Replace(templateConfig, "\nfolders:\n\nfiles:\n\nignore:\n\ntree_depth:\n", "\n"+string(out), 1)
+	if err := os.WriteFile(cs.configPath, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf(T("failed to create configuration: %v"), err)
+	}
+	fmt.Printf(T("Created configuration at: %s\n"), cs.configPath)
+	return true, nil
+}
+
+// wizardCandidates lists the top-level folders of the config directory
+// with their sizes, largest first, and the text files beside them with
+// their total size. Hidden entries are left out; dependency and build
+// folders start deselected.
+func (cs *CodeSnap) wizardCandidates() ([]wizardFolder, []string, int64, error) {
+	entries, err := os.ReadDir(cs.configDir)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf(T("failed to read directory: %v"), err)
+	}
+	var folders []wizardFolder
+	var files []string
+	var rootBytes int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !entry.IsDir() {
+			if entry.Type().IsRegular() && name != filepath.Base(cs.configPath) && !inGroups(name, wizardSkipGroups) {
+				files = append(files, name)
+				if info, err := entry.Info(); err == nil {
+					rootBytes += info.Size()
+				}
+			}
+			continue
+		}
+		f := wizardFolder{name: name, selected: !dependencyDirNames[name] && !buildDirNames[name]}
+		filepath.WalkDir(filepath.Join(cs.configDir, name), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != filepath.Join(cs.configDir, name) && (strings.HasPrefix(d.Name(), ".") || dependencyDirNames[d.Name()]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+				f.files++
+				f.bytes += info.Size()
+			}
+			return nil
+		})
+		folders = append(folders, f)
+	}
+	sort.SliceStable(folders, func(i, j int) bool { return folders[i].bytes > folders[j].bytes })
+	return folders, files, rootBytes, nil
+}
+
+// inGroups reports whether name belongs to one of the file groups
+func inGroups(name string, groups []string) bool {
+	for _, group := range groups {
+		if matchPattern(group, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// printWizardFolders shows the folders with their selection and the size
+// estimate of the selected ones. The estimate counts every byte as text,
+// so binaries the snapshot skips make it high.
+func printWizardFolders(folders []wizardFolder, rootBytes int64) {
+	total := rootBytes
+	fmt.Println()
+	for i, f := range folders {
+		mark := " "
+		if f.selected {
+			mark = "x"
+			total += f.bytes
+		}
+		fmt.Printf("  [%s] %2d  %-24s %7d files  %9s  ~%s tokens\n", mark, i+1, f.name, f.files, formatSize(f.bytes), formatCount(int(float64(f.bytes)/defaultCharsPerToken)))
+	}
+	fmt.Printf(T("Selected: %s, ~%s tokens\n\n"), formatSize(total), formatCount(int(float64(total)/defaultCharsPerToken)))
+}
@@ -0,0 +1,89 @@
+package codesnap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSetupWizard(t *testing.T) {
+	project := map[string]string{
+		"go.mod":                  "module x\n",
+		"go.sum":                  "example.com/x v1.0.0 h1:abc=\n",
+		"README.md":               "# x\n",
+		"logo.png":                "\x89PNG",
+		".env":                    "SECRET=1\n",
+		"src/main.go":             strings.Repeat("// main\n", 100),
+		"dist/app.js":             strings.Repeat("x", 50),
+		"node_modules/m/index.js": "module.exports = 1\n",
+		".git/HEAD":               "ref: refs/heads/main\n",
+	}
+	for _, tc := range []struct {
+		name   string
+		files  map[string]string
+		input  string
+		output []string
+		want   *wizardConfig // nil when no config is written
+	}{
+		{"defaults", project, "\n\n", []string{"Detected project type: Go\n", "Files in the project root: README.md, go.mod\n", "  [x]  1  src ", "  [ ]  2  dist "},
+			&wizardConfig{Folders: []string{"src"}, Files: []string{"README.md", "go.mod"}, Ignore: []string{"@lockfiles"}}},
+		{"toggled folders", project, "2, 9 x\n1 3\n\ny\n", []string{"No folder 9\n", "No folder x\n"},
+			&wizardConfig{Folders: []string{"dist", "node_modules"}, Files: []string{"README.md", "go.mod"}, Ignore: []string{"@lockfiles"}}},
+		{"node project", map[string]string{"package.json": "{}\n", "lib/a.js": "a\n"}, "\n\n", []string{"Detected project type: Node.js\n"},
+			&wizardConfig{Folders: []string{"lib"}, Files: []string{"package.json"}, Ignore: []string{"**/dist/**", "**/*.min.js", "**/*.min.css", "@lockfiles"}}},
+		{"declined", project, "\nn\n", []string{"Write "}, nil},
+		{"nothing selected", map[string]string{"src/a.go": "package a\n"}, "1\n\n", []string{"Nothing selected.\n"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			cs := &CodeSnap{configDir: dir, configPath: filepath.Join(dir, "codesnap.yml")}
+			output := filepath.Join(t.TempDir(), "stdout")
+			stdout, err := os.Create(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			saved := os.Stdout
+			os.Stdout = stdout
+			created, err := cs.setupWizard(strings.NewReader(tc.input))
+			os.Stdout = saved
+			stdout.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			printed, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tc.output {
+				if !strings.Contains(string(printed), s) {
+					t.Errorf("wizard output lacks %q, got:\n%s", s, printed)
+				}
+			}
+
+			data, err := os.ReadFile(cs.configPath)
+			if created != (tc.want != nil) || created != (err == nil) {
+				t.Fatalf("created %v (%v), want %v", created, err, tc.want != nil)
+			}
+			if tc.want == nil {
+				return
+			}
+			var got wizardConfig
+			if err := yaml.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got.Folders, " ") != strings.Join(tc.want.Folders, " ") || strings.Join(got.Files, " ") != strings.Join(tc.want.Files, " ") ||
+				strings.Join(got.Ignore, " ") != strings.Join(tc.want.Ignore, " ") {
+				t.Errorf("config = %+v, want %+v", got, *tc.want)
+			}
+			if !strings.HasPrefix(string(data), "# CodeSnap Configuration File") {
+				t.Errorf("config does not keep the template's documentation:\n%s", data)
+			}
+			if err := (&CodeSnap{configDir: dir}).parseConfig(data); err != nil {
+				t.Errorf("the written config does not load: %v", err)
+			}
+		})
+	}
+}